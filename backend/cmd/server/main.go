@@ -2,44 +2,166 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
 	"google.golang.org/grpc"
 
 	"github.com/justicecaban/host-diff-tool/backend/internal/data"
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+	"github.com/justicecaban/host-diff-tool/backend/internal/retention"
+	"github.com/justicecaban/host-diff-tool/backend/internal/scoring"
 	"github.com/justicecaban/host-diff-tool/backend/internal/server"
+	"github.com/justicecaban/host-diff-tool/backend/internal/validation"
 	"github.com/justicecaban/host-diff-tool/proto"
 )
 
 const dbPath = "./data/snapshots.db"
 
+// shutdownTimeout is how long a graceful shutdown waits for in-flight
+// requests to finish.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:], os.Stdout))
+	}
+
 	// Create data directory if it doesn't exist
 	if err := os.MkdirAll("./data", 0755); err != nil {
 		log.Fatalf("failed to create data directory: %v", err)
 	}
 
-	// Initialize database
-	db, err := data.NewDB(dbPath)
+	// Initialize database, applying any pending schema migrations
+	db, err := data.Open(databaseDSN())
 	if err != nil {
 		log.Fatalf("failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
-	// Create a new gRPC server
-	grpcServer := grpc.NewServer()
+	// Load risk scoring weights, falling back to the built-in defaults
+	scoringConfig := scoring.DefaultConfig()
+	if path := os.Getenv("SCORING_CONFIG"); path != "" {
+		scoringConfig, err = scoring.LoadConfig(path)
+		if err != nil {
+			log.Fatalf("failed to load scoring config: %v", err)
+		}
+		log.Printf("Loaded scoring config from %s", path)
+	}
+
+	// Load diff ignore rules, if configured
+	var rules *diff.RuleSet
+	if path := os.Getenv("DIFF_RULES"); path != "" {
+		rules, err = diff.LoadRules(path)
+		if err != nil {
+			log.Fatalf("failed to load diff rules: %v", err)
+		}
+		log.Printf("Loaded %d diff rules from %s", len(rules.Rules), path)
+	}
+
+	// Upload size limit for streamed snapshots and bulk archives
+	maxUploadSize := int64(server.DefaultMaxUploadSize)
+	if value := os.Getenv("MAX_UPLOAD_BYTES"); value != "" {
+		maxUploadSize, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || maxUploadSize <= 0 {
+			log.Fatalf("invalid MAX_UPLOAD_BYTES %q", value)
+		}
+	}
+
+	// Limit on the decompressed size of a bulk upload archive
+	maxArchiveSize := int64(server.DefaultMaxArchiveSize)
+	if value := os.Getenv("MAX_ARCHIVE_BYTES"); value != "" {
+		maxArchiveSize, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || maxArchiveSize <= 0 {
+			log.Fatalf("invalid MAX_ARCHIVE_BYTES %q", value)
+		}
+	}
+
+	// Where uploads take their IP address and timestamp from
+	metadataSource := server.MetadataFromFilename
+	if value := os.Getenv("METADATA_SOURCE"); value != "" {
+		metadataSource, err = server.ParseMetadataSource(value)
+		if err != nil {
+			log.Fatalf("invalid METADATA_SOURCE: %v", err)
+		}
+	}
+
+	// Which schema issues in an uploaded snapshot reject it
+	schemaStrictness := validation.StrictnessLenient
+	if value := os.Getenv("SCHEMA_STRICTNESS"); value != "" {
+		schemaStrictness, err = validation.ParseStrictness(value)
+		if err != nil {
+			log.Fatalf("invalid SCHEMA_STRICTNESS: %v", err)
+		}
+	}
+
+	// Which snapshots to keep as they age, and how often to prune the rest
+	var retentionPolicy *retention.Policy
+	if value := os.Getenv("RETENTION_POLICY"); value != "" {
+		retentionPolicy, err = retention.ParsePolicy(value)
+		if err != nil {
+			log.Fatalf("invalid RETENTION_POLICY: %v", err)
+		}
+	}
+	retentionInterval := 24 * time.Hour
+	if value := os.Getenv("RETENTION_INTERVAL"); value != "" {
+		retentionInterval, err = time.ParseDuration(value)
+		if err != nil || retentionInterval <= 0 {
+			log.Fatalf("invalid RETENTION_INTERVAL %q", value)
+		}
+	}
+	retentionDryRun := false
+	if value := os.Getenv("RETENTION_DRY_RUN"); value != "" {
+		retentionDryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("invalid RETENTION_DRY_RUN %q", value)
+		}
+	}
+
+	// Create a new gRPC server. Unary requests may carry a whole bulk upload
+	// archive, so allow messages up to the upload limit.
+	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(int(maxUploadSize)))
 
 	// Register the HostService
-	hostServiceServer := server.NewServer(db)
+	hostServiceServer := server.NewServer(db,
+		server.WithScoringConfig(scoringConfig),
+		server.WithRules(rules),
+		server.WithMaxUploadSize(maxUploadSize),
+		server.WithMaxArchiveSize(maxArchiveSize),
+		server.WithMetadataSource(metadataSource),
+		server.WithSchemaStrictness(schemaStrictness),
+		server.WithRetention(retentionPolicy),
+	)
 	proto.RegisterHostServiceServer(grpcServer, hostServiceServer)
 
+	// Shut down gracefully on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Enforce the retention policy in the background until shutdown
+	var background sync.WaitGroup
+	if retentionPolicy != nil {
+		log.Printf("Enforcing retention policy %s every %s (dry run: %t)", retentionPolicy, retentionInterval, retentionDryRun)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			hostServiceServer.RunRetention(ctx, retentionInterval, retentionDryRun)
+		}()
+	}
+
 	// Start native gRPC server on port 9090 in a goroutine
 	go func() {
 		lis, err := net.Listen("tcp", ":9090")
@@ -67,10 +189,33 @@ func main() {
 		Handler: mux,
 	}
 
-	log.Println("Starting gRPC-Web HTTP server on :8080")
-	if err := httpServer.ListenAndServe(); err != nil {
-		log.Fatalf("failed to serve HTTP: %v", err)
+	go func() {
+		log.Println("Starting gRPC-Web HTTP server on :8080")
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve HTTP: %v", err)
+		}
+	}()
+
+	// Wait for a shutdown signal, then let in-flight requests and the
+	// retention job finish before the database is closed
+	<-ctx.Done()
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server shutdown error: %v", err)
+	}
+	grpcServer.GracefulStop()
+	background.Wait()
+}
+
+// databaseDSN returns the database to use: SQLite by default, or whatever
+// DATABASE_URL selects.
+func databaseDSN() string {
+	if value := os.Getenv("DATABASE_URL"); value != "" {
+		return value
 	}
+	return dbPath
 }
 
 func loggingMiddleware(next http.Handler) http.Handler {
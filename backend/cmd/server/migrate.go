@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/data"
+)
+
+const migrateUsage = `Usage: server migrate <command> [flags]
+
+Commands:
+  status    List migrations and whether they have been applied
+  up        Apply pending migrations (all, or up to -to)
+  down      Roll back the most recent migrations (-steps, default 1)
+
+Flags:
+`
+
+// runMigrate implements the migrate subcommand and returns the exit code.
+// The database is selected the same way as for the server.
+func runMigrate(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(out)
+	dryRun := flags.Bool("dry-run", false, "print the SQL that would run without changing the database")
+	to := flags.Int("to", 0, "with up: stop after this version")
+	steps := flags.Int("steps", 1, "with down: number of migrations to roll back")
+	flags.Usage = func() {
+		fmt.Fprint(out, migrateUsage)
+		flags.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		flags.Usage()
+		return 2
+	}
+	command := args[0]
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if command != "status" && command != "up" && command != "down" {
+		fmt.Fprintf(out, "unknown migrate command %q\n\n", command)
+		flags.Usage()
+		return 2
+	}
+	if *steps < 1 {
+		fmt.Fprintln(out, "-steps must be at least 1")
+		return 2
+	}
+
+	if err := os.MkdirAll("./data", 0755); err != nil {
+		fmt.Fprintf(out, "failed to create data directory: %v\n", err)
+		return 1
+	}
+	migrator, err := data.OpenMigrator(databaseDSN())
+	if err != nil {
+		fmt.Fprintf(out, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer migrator.Close()
+
+	switch command {
+	case "status":
+		err = printMigrationStatus(out, migrator)
+	case "up":
+		var migrations []data.Migration
+		migrations, err = migrator.Up(*to, *dryRun)
+		printMigrations(out, "Applied", migrations, *dryRun, true)
+	case "down":
+		var migrations []data.Migration
+		migrations, err = migrator.Down(*steps, *dryRun)
+		printMigrations(out, "Rolled back", migrations, *dryRun, false)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "migrate %s failed: %v\n", command, err)
+		return 1
+	}
+	return 0
+}
+
+func printMigrationStatus(out io.Writer, migrator *data.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, status := range statuses {
+		applied := "pending"
+		if status.Applied {
+			applied = status.AppliedAt
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, applied)
+	}
+	return w.Flush()
+}
+
+// printMigrations reports what up or down did, or with dryRun the SQL it
+// would run.
+func printMigrations(out io.Writer, verb string, migrations []data.Migration, dryRun, up bool) {
+	if len(migrations) == 0 {
+		fmt.Fprintln(out, "Nothing to do")
+		return
+	}
+	for _, m := range migrations {
+		if !dryRun {
+			fmt.Fprintf(out, "%s %s\n", verb, m)
+			continue
+		}
+		sql := m.Down
+		if up {
+			sql = m.Up
+		}
+		fmt.Fprintf(out, "-- %s (dry run)\n%s\n", m, sql)
+	}
+}
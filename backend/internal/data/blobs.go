@@ -0,0 +1,170 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Encodings of the content in snapshot_blobs.
+const (
+	encodingGzip = "gzip"
+	// encodingIdentity is used for content that gzip doesn't make smaller.
+	encodingIdentity = "identity"
+)
+
+// moveBatchSize is how many snapshots the blob migration holds in memory.
+const moveBatchSize = 100
+
+// contentHash returns the key of snapshot content in snapshot_blobs: the hex
+// SHA-256 of the uncompressed bytes.
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// putBlob stores snapshot content in snapshot_blobs and returns its hash.
+// Content that is already stored is neither compressed nor written again.
+func (s *sqlStore) putBlob(q queryer, data []byte) (string, error) {
+	hash := contentHash(data)
+	var found int
+	err := q.QueryRow(s.dialect.rebind("SELECT 1 FROM snapshot_blobs WHERE hash = ?"), hash).Scan(&found)
+	if err == nil {
+		return hash, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to look up blob %s: %w", hash, err)
+	}
+
+	encoding, compressed, err := compress(data)
+	if err != nil {
+		return "", err
+	}
+	if _, err := q.Exec(
+		s.dialect.rebind("INSERT INTO snapshot_blobs (hash, encoding, size, data) VALUES (?, ?, ?, ?) ON CONFLICT (hash) DO NOTHING"),
+		hash,
+		encoding,
+		len(data),
+		compressed,
+	); err != nil {
+		return "", fmt.Errorf("failed to store blob %s: %w", hash, err)
+	}
+	return hash, nil
+}
+
+// deleteUnusedBlob removes a blob once no snapshot, live or soft-deleted,
+// refers to it any more.
+func (s *sqlStore) deleteUnusedBlob(q queryer, hash string) error {
+	if _, err := q.Exec(
+		s.dialect.rebind("DELETE FROM snapshot_blobs WHERE hash = ? AND NOT EXISTS (SELECT 1 FROM snapshots WHERE blob_hash = ?)"),
+		hash,
+		hash,
+	); err != nil {
+		return fmt.Errorf("failed to delete blob %s: %w", hash, err)
+	}
+	return nil
+}
+
+// compress gzips snapshot content, keeping it as it is if that doesn't make
+// it smaller, and returns the encoding used.
+func compress(data []byte) (string, []byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return "", nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if buf.Len() >= len(data) {
+		return encodingIdentity, data, nil
+	}
+	return encodingGzip, buf.Bytes(), nil
+}
+
+// decompress returns the content of a blob stored with an encoding.
+func decompress(encoding string, data []byte) ([]byte, error) {
+	switch encoding {
+	case encodingIdentity:
+		return data, nil
+	case encodingGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+		}
+		defer r.Close()
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+		}
+		return content, nil
+	default:
+		return nil, fmt.Errorf("unknown snapshot encoding %q", encoding)
+	}
+}
+
+// moveDataToBlobs is the Go step of the snapshot_blobs migration: it moves
+// the content of snapshots stored before then into snapshot_blobs.
+func (s *sqlStore) moveDataToBlobs(tx *sql.Tx) error {
+	for {
+		rows, err := tx.Query(s.dialect.rebind("SELECT id, data FROM snapshots WHERE blob_hash IS NULL ORDER BY id LIMIT ?"), moveBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to query snapshots without a blob: %w", err)
+		}
+		var batch []Snapshot
+		for rows.Next() {
+			var snap Snapshot
+			var dataStr string
+			if err := rows.Scan(&snap.ID, &dataStr); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan snapshot row: %w", err)
+			}
+			snap.Data = []byte(dataStr)
+			batch = append(batch, snap)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to iterate snapshot rows: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, snap := range batch {
+			hash, err := s.putBlob(tx, snap.Data)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(s.dialect.rebind("UPDATE snapshots SET blob_hash = ?, data = NULL WHERE id = ?"), hash, snap.ID); err != nil {
+				return fmt.Errorf("failed to move content of snapshot %s: %w", snap.ID, err)
+			}
+		}
+	}
+}
+
+// moveDataFromBlobs undoes moveDataToBlobs, copying every snapshot's content
+// back into its data column.
+func (s *sqlStore) moveDataFromBlobs(tx *sql.Tx) error {
+	for {
+		rows, err := tx.Query(s.dialect.rebind(selectSnapshots+" WHERE snapshots.data IS NULL ORDER BY snapshots.id LIMIT ?"), moveBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to query snapshots stored as blobs: %w", err)
+		}
+		batch, err := scanSnapshots(rows)
+		rows.Close()
+		if err != nil || len(batch) == 0 {
+			return err
+		}
+
+		for _, snap := range batch {
+			if _, err := tx.Exec(s.dialect.rebind("UPDATE snapshots SET data = ? WHERE id = ?"), s.dialect.blob(snap.Data), snap.ID); err != nil {
+				return fmt.Errorf("failed to copy back content of snapshot %s: %w", snap.ID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package data
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// rawDB returns the database behind a Store created by forEachStore.
+func rawDB(t *testing.T, store Store) *sqlStore {
+	t.Helper()
+	switch s := store.(type) {
+	case *DB:
+		return &s.sqlStore
+	case *PostgresDB:
+		return &s.sqlStore
+	}
+	t.Fatalf("Unexpected store %T", store)
+	return nil
+}
+
+func countRows(t *testing.T, s *sqlStore, table string) int {
+	t.Helper()
+	var n int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("Failed to count %s: %v", table, err)
+	}
+	return n
+}
+
+// largeSnapshot returns compressible snapshot content with n services.
+func largeSnapshot(n int) []byte {
+	services := make([]string, n)
+	for i := range services {
+		services[i] = fmt.Sprintf(`{"port": %d, "protocol": "HTTP", "software": {"vendor": "nginx", "product": "nginx", "version": "1.24.0"}}`, i+1)
+	}
+	return []byte(`{"services": [` + strings.Join(services, ", ") + `]}`)
+}
+
+func TestStore_BlobDeduplication(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		s := rawDB(t, store)
+		content := largeSnapshot(50)
+
+		// A quiet host: every rescan finds the same services
+		var ids []string
+		for _, ts := range []string{"2025-01-01T00:00:00Z", "2025-01-02T00:00:00Z", "2025-01-03T00:00:00Z"} {
+			id, err := store.InsertSnapshot("10.0.0.1", ts, content)
+			if err != nil {
+				t.Fatalf("InsertSnapshot failed: %v", err)
+			}
+			ids = append(ids, id)
+		}
+		if _, err := store.InsertSnapshot("10.0.0.1", "2025-01-04T00:00:00Z", []byte(`{}`)); err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+		if n := countRows(t, s, "snapshot_blobs"); n != 2 {
+			t.Errorf("Expected 2 blobs for 4 snapshots, got %d", n)
+		}
+
+		var encoding string
+		var size, stored int
+		if err := s.db.QueryRow(s.dialect.rebind("SELECT encoding, size, LENGTH(data) FROM snapshot_blobs WHERE hash = ?"), contentHash(content)).Scan(&encoding, &size, &stored); err != nil {
+			t.Fatalf("Failed to query blob: %v", err)
+		}
+		if encoding != encodingGzip || size != len(content) || stored >= size/4 {
+			t.Errorf("Expected a compressed blob, got %s with %d of %d bytes", encoding, stored, size)
+		}
+
+		for _, id := range ids {
+			snap, err := store.GetSnapshotByID(id)
+			if err != nil || snap == nil || !bytes.Equal(snap.Data, content) {
+				t.Fatalf("Expected snapshot %s to return its content, got %v", id, err)
+			}
+		}
+		history, err := store.GetSnapshotsInRange("10.0.0.1", "", "")
+		if err != nil || len(history) != 4 || !bytes.Equal(history[0].Data, content) || string(history[3].Data) != `{}` {
+			t.Errorf("Expected every snapshot's content in the range, got %d, %v", len(history), err)
+		}
+		if services, _, _ := store.QueryServices(ServiceQuery{Port: 50, Limit: 10}); len(services) != 3 {
+			t.Errorf("Expected every snapshot to be indexed, got %d services", len(services))
+		}
+	})
+}
+
+func TestStore_PurgeRemovesUnusedBlobs(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		s := rawDB(t, store)
+		shared, _ := store.InsertSnapshot("10.0.0.1", "2025-01-01T00:00:00Z", []byte(`{"services": []}`))
+		kept, _ := store.InsertSnapshot("10.0.0.1", "2025-01-02T00:00:00Z", []byte(`{"services": []}`))
+		only, _ := store.InsertSnapshot("10.0.0.1", "2025-01-03T00:00:00Z", []byte(`{}`))
+
+		for _, id := range []string{shared, only} {
+			if _, err := store.DeleteSnapshot(id, Audit{Actor: "alice", Reason: "test"}); err != nil {
+				t.Fatalf("DeleteSnapshot failed: %v", err)
+			}
+		}
+		if n := countRows(t, s, "snapshot_blobs"); n != 2 {
+			t.Errorf("Expected soft-deleted snapshots to keep their blobs, got %d", n)
+		}
+
+		if _, err := store.PurgeDeleted("", Audit{Actor: "alice", Reason: "test"}); err != nil {
+			t.Fatalf("PurgeDeleted failed: %v", err)
+		}
+		if n := countRows(t, s, "snapshot_blobs"); n != 1 {
+			t.Errorf("Expected only the shared blob to be left, got %d", n)
+		}
+		if snap, err := store.GetSnapshotByID(kept); err != nil || snap == nil || string(snap.Data) != `{"services": []}` {
+			t.Errorf("Expected snapshot %s to keep its content, got %v, %v", kept, snap, err)
+		}
+	})
+}
+
+func TestMigrator_MovesDataToBlobs(t *testing.T) {
+	forEachMigrator(t, func(t *testing.T, m *Migrator) {
+		if _, err := m.Up(5, false); err != nil {
+			t.Fatalf("Up failed: %v", err)
+		}
+		legacy := [][]byte{largeSnapshot(20), largeSnapshot(20), []byte(`{}`)}
+		for i, content := range legacy {
+			if _, err := m.db.Exec(
+				m.dialect.rebind("INSERT INTO snapshots (ip_address, timestamp, data) VALUES (?, ?, ?)"),
+				"10.0.0.1",
+				fmt.Sprintf("2025-01-0%dT00:00:00Z", i+1),
+				m.dialect.blob(content),
+			); err != nil {
+				t.Fatalf("Failed to insert legacy row: %v", err)
+			}
+		}
+
+		if _, err := m.Up(0, false); err != nil {
+			t.Fatalf("Up failed: %v", err)
+		}
+		s := &sqlStore{db: m.db, dialect: m.dialect}
+		if n := countRows(t, s, "snapshot_blobs"); n != 2 {
+			t.Errorf("Expected 2 blobs, got %d", n)
+		}
+		var inline int
+		if err := m.db.QueryRow("SELECT COUNT(*) FROM snapshots WHERE data IS NOT NULL OR blob_hash IS NULL").Scan(&inline); err != nil || inline != 0 {
+			t.Errorf("Expected every snapshot's content to move, %d left, %v", inline, err)
+		}
+		if err := s.fillIPKeys(); err != nil {
+			t.Fatalf("fillIPKeys failed: %v", err)
+		}
+		history, err := s.GetSnapshotsInRange("10.0.0.1", "", "")
+		if err != nil || len(history) != 3 {
+			t.Fatalf("Expected 3 snapshots, got %d, %v", len(history), err)
+		}
+		for i, snap := range history {
+			if !bytes.Equal(snap.Data, legacy[i]) {
+				t.Errorf("Snapshot %s: content changed by the migration", snap.ID)
+			}
+		}
+
+		if _, err := m.Down(1, false); err != nil {
+			t.Fatalf("Down failed: %v", err)
+		}
+		rows, err := m.db.Query("SELECT data FROM snapshots ORDER BY timestamp")
+		if err != nil {
+			t.Fatalf("Failed to query snapshots: %v", err)
+		}
+		defer rows.Close()
+		for i := 0; rows.Next(); i++ {
+			var data sql.RawBytes
+			if err := rows.Scan(&data); err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			if !bytes.Equal(data, legacy[i]) {
+				t.Errorf("Expected rollback to restore snapshot %d's content", i)
+			}
+		}
+		if tableExists(t, m, "snapshot_blobs") {
+			t.Error("Expected rollback to drop snapshot_blobs")
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ConflictPolicy decides what StoreSnapshot does when a live snapshot with
+// the same IP address and timestamp but different content is already stored.
+type ConflictPolicy int
+
+const (
+	// ConflictReject fails with a *ConflictError.
+	ConflictReject ConflictPolicy = iota
+	// ConflictReplace soft-deletes the stored snapshots and stores the new one
+	// in their place.
+	ConflictReplace
+	// ConflictKeepBoth stores the new snapshot as the next revision.
+	ConflictKeepBoth
+)
+
+// StoreOutcome says what StoreSnapshot did.
+type StoreOutcome string
+
+const (
+	StoreCreated StoreOutcome = "created"
+	// StoreUnchanged means identical content was already stored.
+	StoreUnchanged StoreOutcome = "unchanged"
+	StoreReplaced  StoreOutcome = "replaced"
+	StoreRevision  StoreOutcome = "revision"
+)
+
+// StoreResult reports what StoreSnapshot did.
+type StoreResult struct {
+	// ID of the new snapshot, or of the stored one for StoreUnchanged.
+	ID       string
+	Outcome  StoreOutcome
+	Revision int
+	// ReplacedIDs are the snapshots soft-deleted by ConflictReplace.
+	ReplacedIDs []string
+}
+
+// ConflictError reports that a different snapshot is already stored for an
+// IP address and timestamp.
+type ConflictError struct {
+	IPAddress  string
+	Timestamp  string
+	ExistingID string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("a different snapshot for %s at %s is already stored as ID %s", e.IPAddress, e.Timestamp, e.ExistingID)
+}
+
+// maxStoreAttempts bounds how often StoreSnapshot retries after losing a race
+// with concurrent uploads of the same IP address and timestamp.
+const maxStoreAttempts = 10
+
+// storedRevision is a live snapshot's ID, revision and content hash.
+type storedRevision struct {
+	id       string
+	revision int
+	hash     string
+}
+
+// StoreSnapshot stores a snapshot, resolving a clash with live snapshots of
+// the same IP address and timestamp. Content with the same hash as any of
+// them is not stored again; its ID is returned with StoreUnchanged.
+// Otherwise policy decides. Snapshots replaced by ConflictReplace are
+// soft-deleted with audit as their reason.
+func (s *sqlStore) StoreSnapshot(snap NewSnapshot, policy ConflictPolicy, audit Audit) (*StoreResult, error) {
+	snap.IPAddress = canonicalIP(snap.IPAddress)
+	for attempt := 1; ; attempt++ {
+		result, err := s.storeOnce(snap, policy, audit)
+		if !errors.Is(err, errRevisionTaken) || attempt == maxStoreAttempts {
+			return result, err
+		}
+	}
+}
+
+// storeOnce runs StoreSnapshot in a transaction. It returns errRevisionTaken
+// if a concurrent upload stored the revision it chose first; a retry then
+// sees that upload and resolves the clash with it.
+func (s *sqlStore) storeOnce(snap NewSnapshot, policy ConflictPolicy, audit Audit) (*StoreResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := s.liveRevisions(tx, snap.IPAddress, snap.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := contentHash(snap.Data)
+	for _, r := range existing {
+		if r.hash == hash {
+			return &StoreResult{ID: r.id, Outcome: StoreUnchanged, Revision: r.revision}, nil
+		}
+	}
+
+	result := &StoreResult{Outcome: StoreCreated}
+	if len(existing) > 0 {
+		latest := existing[len(existing)-1]
+		switch policy {
+		case ConflictReject:
+			return nil, &ConflictError{IPAddress: snap.IPAddress, Timestamp: snap.Timestamp, ExistingID: latest.id}
+		case ConflictReplace:
+			result.Outcome = StoreReplaced
+			for _, r := range existing {
+				if _, err := s.softDelete(tx, r.id, audit); err != nil {
+					return nil, err
+				}
+				result.ReplacedIDs = append(result.ReplacedIDs, r.id)
+			}
+		case ConflictKeepBoth:
+			result.Outcome = StoreRevision
+			result.Revision = latest.revision + 1
+		default:
+			return nil, fmt.Errorf("unknown conflict policy %d", policy)
+		}
+	}
+
+	if result.ID, err = s.insertRow(tx, snap, result.Revision); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	return result, nil
+}
+
+// liveRevisions returns the live snapshots of an IP address and timestamp,
+// oldest revision first. The IP address must already be canonical.
+func (s *sqlStore) liveRevisions(q queryer, ipAddress, timestamp string) ([]storedRevision, error) {
+	rows, err := q.Query(
+		s.dialect.rebind("SELECT id, revision, blob_hash FROM snapshots WHERE ip_address = ? AND timestamp = ? AND deleted_at IS NULL ORDER BY revision"),
+		ipAddress,
+		timestamp,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up existing snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []storedRevision
+	for rows.Next() {
+		var r storedRevision
+		if err := rows.Scan(&r.id, &r.revision, &r.hash); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot row: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate snapshot rows: %w", err)
+	}
+	return revisions, nil
+}
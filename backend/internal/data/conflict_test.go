@@ -0,0 +1,147 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+	"testing"
+)
+
+func TestStore_StoreSnapshot(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		first := NewSnapshot{IPAddress: "10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{"services": [{"port": 22}]}`)}
+		second := NewSnapshot{IPAddress: "10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{"services": [{"port": 80}]}`)}
+		third := NewSnapshot{IPAddress: "10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{"services": [{"port": 443}]}`)}
+		audit := Audit{Actor: "upload", Reason: "replaced"}
+
+		created, err := store.StoreSnapshot(first, ConflictReject, audit)
+		if err != nil || created.Outcome != StoreCreated || created.Revision != 0 {
+			t.Fatalf("StoreSnapshot = %+v, %v", created, err)
+		}
+
+		// Identical content succeeds under any policy
+		for _, policy := range []ConflictPolicy{ConflictReject, ConflictReplace, ConflictKeepBoth} {
+			again, err := store.StoreSnapshot(first, policy, audit)
+			if err != nil || again.Outcome != StoreUnchanged || again.ID != created.ID {
+				t.Errorf("policy %d: expected unchanged %s, got %+v, %v", policy, created.ID, again, err)
+			}
+		}
+
+		_, err = store.StoreSnapshot(second, ConflictReject, audit)
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || conflict.ExistingID != created.ID {
+			t.Fatalf("Expected a ConflictError naming %s, got %v", created.ID, err)
+		}
+
+		revision, err := store.StoreSnapshot(second, ConflictKeepBoth, audit)
+		if err != nil || revision.Outcome != StoreRevision || revision.Revision != 1 {
+			t.Fatalf("StoreSnapshot(keep both) = %+v, %v", revision, err)
+		}
+		history, _ := store.GetSnapshotsByIP("10.0.0.1")
+		if len(history) != 2 || history[0].ID != revision.ID || history[0].Revision != 1 {
+			t.Errorf("Expected both revisions, newest first, got %+v", history)
+		}
+		if again, err := store.StoreSnapshot(first, ConflictReject, audit); err != nil || again.ID != created.ID {
+			t.Errorf("Expected re-upload of an older revision to be unchanged, got %+v, %v", again, err)
+		}
+		fleet, _, _ := store.QueryFleet(FleetQuery{Range: PrefixRange(netip.MustParsePrefix("10.0.0.0/24")), Limit: 10})
+		if len(fleet) != 1 || fleet[0].ID != revision.ID {
+			t.Errorf("Expected the newest revision in the fleet, got %+v", fleet)
+		}
+		latest, _, _ := store.QueryServices(ServiceQuery{LatestOnly: true, Limit: 10})
+		if len(latest) != 1 || latest[0].Port != 80 {
+			t.Errorf("Expected the newest revision's services, got %+v", latest)
+		}
+
+		replaced, err := store.StoreSnapshot(third, ConflictReplace, audit)
+		if err != nil || replaced.Outcome != StoreReplaced || len(replaced.ReplacedIDs) != 2 {
+			t.Fatalf("StoreSnapshot(replace) = %+v, %v", replaced, err)
+		}
+		history, _ = store.GetSnapshotsByIP("10.0.0.1")
+		if len(history) != 1 || history[0].ID != replaced.ID || string(history[0].Data) != string(third.Data) {
+			t.Errorf("Expected only the replacement to be live, got %+v", history)
+		}
+		if services, _, _ := store.QueryServices(ServiceQuery{Limit: 10}); len(services) != 1 || services[0].Port != 443 {
+			t.Errorf("Expected only the replacement's services to be indexed, got %+v", services)
+		}
+	})
+}
+
+func TestStore_InsertSnapshotsFindsRevisions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		snap := NewSnapshot{IPAddress: "10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{}`)}
+		first, _ := store.StoreSnapshot(snap, ConflictReject, Audit{})
+		snap.Data = []byte(`{"services": []}`)
+		second, _ := store.StoreSnapshot(snap, ConflictKeepBoth, Audit{})
+		if _, err := store.DeleteSnapshot(first.ID, Audit{Actor: "alice", Reason: "old"}); err != nil {
+			t.Fatalf("DeleteSnapshot failed: %v", err)
+		}
+
+		// Revision 0 is free again, but revision 1 is still live
+		outcomes, err := store.InsertSnapshots([]NewSnapshot{snap}, true)
+		if err != nil || !outcomes[0].Duplicate || outcomes[0].ID != second.ID {
+			t.Errorf("Expected a duplicate of %s, got %+v, %v", second.ID, outcomes, err)
+		}
+	})
+}
+
+func TestStore_StoreSnapshotConcurrently(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		const uploads = 8
+		var wg sync.WaitGroup
+		results := make([]*StoreResult, uploads)
+		errs := make([]error, uploads)
+		for i := range uploads {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				snap := NewSnapshot{IPAddress: "10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(fmt.Sprintf(`{"services": [{"port": %d}]}`, i+1))}
+				results[i], errs[i] = store.StoreSnapshot(snap, ConflictKeepBoth, Audit{})
+			}()
+		}
+		wg.Wait()
+
+		revisions := make(map[int]bool)
+		for i := range uploads {
+			if errs[i] != nil {
+				t.Fatalf("Upload %d failed: %v", i, errs[i])
+			}
+			if revisions[results[i].Revision] {
+				t.Errorf("Revision %d was stored twice", results[i].Revision)
+			}
+			revisions[results[i].Revision] = true
+		}
+		if history, _ := store.GetSnapshotsByIP("10.0.0.1"); len(history) != uploads {
+			t.Errorf("Expected %d revisions, got %d", uploads, len(history))
+		}
+
+		// Concurrent re-uploads of new content store it exactly once
+		snap := NewSnapshot{IPAddress: "10.0.0.2", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{}`)}
+		outcomes := make([][]InsertOutcome, uploads)
+		for i := range uploads {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				outcomes[i], errs[i] = store.InsertSnapshots([]NewSnapshot{snap}, true)
+			}()
+		}
+		wg.Wait()
+
+		created := 0
+		for i := range uploads {
+			if errs[i] != nil {
+				t.Fatalf("InsertSnapshots %d failed: %v", i, errs[i])
+			}
+			if outcomes[i][0].ID != outcomes[0][0].ID {
+				t.Errorf("Expected every upload to find %s, got %s", outcomes[0][0].ID, outcomes[i][0].ID)
+			}
+			if !outcomes[i][0].Duplicate {
+				created++
+			}
+		}
+		if created != 1 {
+			t.Errorf("Expected exactly one upload to store the snapshot, got %d", created)
+		}
+	})
+}
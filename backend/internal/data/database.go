@@ -7,22 +7,39 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// Snapshot represents a host snapshot stored in the database.
-type Snapshot struct {
-	ID        string
-	IPAddress string
-	Timestamp string
-	Data      []byte
-}
-
-// DB provides database access.
+// DB is the SQLite Store.
 type DB struct {
-	db *sql.DB
+	sqlStore
 }
 
-// NewDB initializes a new SQLite database connection and creates the necessary table.
-// Configures performance pragmas and connection pooling for optimal operation.
+// NewDB initializes a new SQLite database connection and migrates its schema
+// to the latest version.
 func NewDB(dataSourceName string) (*DB, error) {
+	db, err := openSQLite(dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := newMigrator(db, dialectSQLite)
+	if err == nil {
+		_, err = migrator.Up(0, false)
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	store := &DB{sqlStore{db: db, dialect: dialectSQLite}}
+	if err := store.fillIPKeys(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// openSQLite opens an SQLite database and configures performance pragmas and
+// connection pooling for optimal operation.
+func openSQLite(dataSourceName string) (*sql.DB, error) {
 	db, err := sql.Open("sqlite3", dataSourceName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -44,106 +61,15 @@ func NewDB(dataSourceName string) (*DB, error) {
 		"PRAGMA busy_timeout=5000;",       // Wait up to 5 seconds on locks
 	}
 
+	// foreign_keys stays off: migrations rebuild the snapshots table with
+	// DROP TABLE, which would cascade. Rows that refer to a snapshot are
+	// removed explicitly, as unindexServices does.
 	for _, pragma := range pragmas {
 		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
 			return nil, fmt.Errorf("failed to set pragma %s: %w", pragma, err)
 		}
 	}
 
-	// Create the snapshots table if it doesn't exist
-	schema := `
-	CREATE TABLE IF NOT EXISTS snapshots (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		ip_address TEXT NOT NULL,
-		timestamp TEXT NOT NULL,
-		data BLOB NOT NULL,
-		UNIQUE(ip_address, timestamp)
-	);
-	`
-	_, err = db.Exec(schema)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create table: %w", err)
-	}
-
-	// Create index if it doesn't exist (for faster queries by IP)
-	indexSQL := `
-	CREATE INDEX IF NOT EXISTS idx_ip_timestamp
-	ON snapshots(ip_address, timestamp DESC);
-	`
-	_, err = db.Exec(indexSQL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create index: %w", err)
-	}
-
-	return &DB{db: db}, nil
+	return db, nil
 }
-
-// Close closes the database connection.
-func (d *DB) Close() error {
-	return d.db.Close()
-}
-
-// InsertSnapshot inserts a new snapshot into the database.
-func (d *DB) InsertSnapshot(ipAddress, timestamp string, data []byte) (string, error) {
-	res, err := d.db.Exec(
-		"INSERT INTO snapshots (ip_address, timestamp, data) VALUES (?, ?, ?)",
-		ipAddress,
-		timestamp,
-		string(data),
-	)
-	if err != nil {
-		return "", fmt.Errorf("failed to insert snapshot: %w", err)
-	}
-
-	id, err := res.LastInsertId()
-	if err != nil {
-		return "", fmt.Errorf("failed to get last insert ID: %w", err)
-	}
-
-	return fmt.Sprintf("%d", id), nil
-}
-
-// GetSnapshotsByIP retrieves all snapshots for a given IP address.
-func (d *DB) GetSnapshotsByIP(ipAddress string) ([]*Snapshot, error) {
-	rows, err := d.db.Query(
-		"SELECT id, ip_address, timestamp, data FROM snapshots WHERE ip_address = ? ORDER BY timestamp DESC",
-		ipAddress,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query snapshots by IP: %w", err)
-	}
-	defer rows.Close()
-
-	var snapshots []*Snapshot
-	for rows.Next() {
-		var s Snapshot
-		var dataStr string
-		if err := rows.Scan(&s.ID, &s.IPAddress, &s.Timestamp, &dataStr); err != nil {
-			return nil, fmt.Errorf("failed to scan snapshot row: %w", err)
-		}
-		s.Data = []byte(dataStr)
-		snapshots = append(snapshots, &s)
-	}
-
-	return snapshots, nil
-}
-
-// GetSnapshotByID retrieves a single snapshot by its ID.
-func (d *DB) GetSnapshotByID(id string) (*Snapshot, error) {
-	var s Snapshot
-	var dataStr string
-
-	err := d.db.QueryRow(
-		"SELECT id, ip_address, timestamp, data FROM snapshots WHERE id = ?",
-		id,
-	).Scan(&s.ID, &s.IPAddress, &s.Timestamp, &dataStr)
-
-	if err == sql.ErrNoRows {
-		return nil, nil // No snapshot found with this ID
-	} else if err != nil {
-		return nil, fmt.Errorf("failed to query snapshot by ID: %w", err)
-	}
-
-	s.Data = []byte(dataStr)
-	return &s, nil
-}
\ No newline at end of file
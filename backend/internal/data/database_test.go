@@ -108,3 +108,41 @@ func TestGetSnapshotNotFound(t *testing.T) {
 		t.Fatal("Expected nil snapshot for non-existent ID, got non-nil")
 	}
 }
+
+func TestGetSnapshotsInRange(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	for _, ts := range []string{"2025-01-03T00:00:00Z", "2025-01-01T00:00:00Z", "2025-01-02T00:00:00Z"} {
+		if _, err := db.InsertSnapshot("10.0.0.1", ts, []byte(`{}`)); err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+	}
+	if _, err := db.InsertSnapshot("10.0.0.2", "2025-01-02T00:00:00Z", []byte(`{}`)); err != nil {
+		t.Fatalf("InsertSnapshot failed: %v", err)
+	}
+
+	all, err := db.GetSnapshotsInRange("10.0.0.1", "", "")
+	if err != nil {
+		t.Fatalf("GetSnapshotsInRange failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 snapshots, got %d", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Timestamp >= all[i].Timestamp {
+			t.Errorf("Snapshots not in ascending order: %s, %s", all[i-1].Timestamp, all[i].Timestamp)
+		}
+	}
+
+	window, err := db.GetSnapshotsInRange("10.0.0.1", "2025-01-02T00:00:00Z", "2025-01-03T00:00:00Z")
+	if err != nil {
+		t.Fatalf("GetSnapshotsInRange failed: %v", err)
+	}
+	if len(window) != 2 || window[0].Timestamp != "2025-01-02T00:00:00Z" {
+		t.Errorf("Unexpected window result: %d snapshots", len(window))
+	}
+}
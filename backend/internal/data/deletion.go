@@ -0,0 +1,273 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// AuditAction is a change to a snapshot's deletion state.
+type AuditAction string
+
+const (
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+	AuditPrune   AuditAction = "prune"
+)
+
+// Audit says who deleted, restored, purged or pruned snapshots and why. It is
+// recorded in the snapshot_audit table for every snapshot affected.
+type Audit struct {
+	Actor  string
+	Reason string
+}
+
+// DeleteSnapshot soft-deletes a snapshot: it stays in the database but is
+// hidden from every query until it is restored or purged, and no longer
+// blocks a new upload for the same IP address and timestamp. It returns the
+// deleted snapshot without its Data, or nil if there is no live snapshot
+// with that ID.
+func (s *sqlStore) DeleteSnapshot(id string, audit Audit) (*Snapshot, error) {
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snap, err := s.softDelete(tx, id, audit)
+	if err != nil || snap == nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit deletion: %w", err)
+	}
+	return snap, nil
+}
+
+// softDelete marks a live snapshot as deleted and records it in the audit
+// trail. It returns nil if there is no live snapshot with that ID.
+func (s *sqlStore) softDelete(q queryer, id string, audit Audit) (*Snapshot, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	var snap Snapshot
+	err := q.QueryRow(
+		s.dialect.rebind("UPDATE snapshots SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING id, ip_address, timestamp, revision"),
+		now,
+		id,
+	).Scan(&snap.ID, &snap.IPAddress, &snap.Timestamp, &snap.Revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+
+	// The services index only covers live snapshots; RestoreSnapshot rebuilds it
+	if err := s.unindexServices(q, snap.ID); err != nil {
+		return nil, err
+	}
+	if err := s.recordAudit(q, &snap, AuditDelete, audit, now); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// RestoreSnapshot undoes DeleteSnapshot. It returns the restored snapshot
+// without its Data, or nil if there is no deleted snapshot with that ID. A
+// snapshot can't be restored while another live snapshot has its IP address,
+// timestamp and revision; other revisions don't stand in its way.
+func (s *sqlStore) RestoreSnapshot(id string, audit Audit) (*Snapshot, error) {
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var snap Snapshot
+	var encoding string
+	var blob []byte
+	err = tx.QueryRow(
+		s.dialect.rebind(selectSnapshots+" WHERE snapshots.id = ? AND deleted_at IS NOT NULL"),
+		id,
+	).Scan(&snap.ID, &snap.IPAddress, &snap.Timestamp, &snap.Revision, &encoding, &blob)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query deleted snapshot: %w", err)
+	}
+	data, err := decompress(encoding, blob)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", snap.ID, err)
+	}
+
+	var liveID string
+	err = tx.QueryRow(
+		s.dialect.rebind("SELECT id FROM snapshots WHERE ip_address = ? AND timestamp = ? AND revision = ? AND deleted_at IS NULL"),
+		snap.IPAddress,
+		snap.Timestamp,
+		snap.Revision,
+	).Scan(&liveID)
+	if err == nil {
+		return nil, fmt.Errorf("snapshot %s already holds %s at %s, revision %d", liveID, snap.IPAddress, snap.Timestamp, snap.Revision)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to look up live snapshot: %w", err)
+	}
+
+	if _, err := tx.Exec(s.dialect.rebind("UPDATE snapshots SET deleted_at = NULL WHERE id = ?"), snap.ID); err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	if err := s.indexServices(tx, snap.ID, snap.IPAddress, snap.Timestamp, data); err != nil {
+		return nil, err
+	}
+	if err := s.recordAudit(tx, &snap, AuditRestore, audit, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit restore: %w", err)
+	}
+	return &snap, nil
+}
+
+// PurgeDeleted permanently removes the snapshots that were soft-deleted at
+// or before deletedBefore, or all of them if it is empty, and returns them
+// without their Data. Their audit trail is kept; their content is removed
+// unless another snapshot shares it.
+func (s *sqlStore) PurgeDeleted(deletedBefore string, audit Audit) ([]*Snapshot, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := "SELECT id, ip_address, timestamp, blob_hash FROM snapshots WHERE deleted_at IS NOT NULL"
+	var args []interface{}
+	if deletedBefore != "" {
+		query += " AND deleted_at <= ?"
+		args = append(args, deletedBefore)
+	}
+	rows, err := tx.Query(s.dialect.rebind(query+" ORDER BY id"), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted snapshots: %w", err)
+	}
+	var purged []*Snapshot
+	var hashes []string
+	for rows.Next() {
+		var snap Snapshot
+		var hash string
+		if err := rows.Scan(&snap.ID, &snap.IPAddress, &snap.Timestamp, &hash); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan snapshot row: %w", err)
+		}
+		purged = append(purged, &snap)
+		hashes = append(hashes, hash)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate snapshot rows: %w", err)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for i, snap := range purged {
+		if err := s.purge(tx, snap, hashes[i], AuditPurge, audit, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit purge: %w", err)
+	}
+	return purged, nil
+}
+
+// PruneSnapshots permanently removes live snapshots, as a retention policy
+// does, and returns them without their Data. IDs that aren't live snapshots
+// are skipped. Each removal is recorded in the audit trail as a prune.
+func (s *sqlStore) PruneSnapshots(ids []string, audit Audit) ([]*Snapshot, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	var pruned []*Snapshot
+	for _, id := range ids {
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			continue
+		}
+		var snap Snapshot
+		var hash string
+		err := tx.QueryRow(
+			s.dialect.rebind("SELECT id, ip_address, timestamp, revision, blob_hash FROM snapshots WHERE id = ? AND deleted_at IS NULL"),
+			id,
+		).Scan(&snap.ID, &snap.IPAddress, &snap.Timestamp, &snap.Revision, &hash)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to query snapshot %s: %w", id, err)
+		}
+
+		if err := s.unindexServices(tx, snap.ID); err != nil {
+			return nil, err
+		}
+		if err := s.purge(tx, &snap, hash, AuditPrune, audit, now); err != nil {
+			return nil, err
+		}
+		pruned = append(pruned, &snap)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit prune: %w", err)
+	}
+	return pruned, nil
+}
+
+// purge deletes a snapshot's row, and its content unless another snapshot
+// shares it, and records the action in its audit trail.
+func (s *sqlStore) purge(q queryer, snap *Snapshot, hash string, action AuditAction, audit Audit, at string) error {
+	if _, err := q.Exec(s.dialect.rebind("DELETE FROM snapshots WHERE id = ?"), snap.ID); err != nil {
+		return fmt.Errorf("failed to %s snapshot %s: %w", action, snap.ID, err)
+	}
+	if err := s.deleteUnusedBlob(q, hash); err != nil {
+		return err
+	}
+	return s.recordAudit(q, snap, action, audit, at)
+}
+
+// unindexServices removes a snapshot's services and CVEs from the index.
+func (s *sqlStore) unindexServices(q queryer, snapshotID string) error {
+	for _, table := range []string{"vulnerabilities", "services"} {
+		if _, err := q.Exec(s.dialect.rebind("DELETE FROM "+table+" WHERE snapshot_id = ?"), snapshotID); err != nil {
+			return fmt.Errorf("failed to remove snapshot %s from %s: %w", snapshotID, table, err)
+		}
+	}
+	return nil
+}
+
+// recordAudit adds an entry to a snapshot's audit trail.
+func (s *sqlStore) recordAudit(q queryer, snap *Snapshot, action AuditAction, audit Audit, at string) error {
+	if _, err := q.Exec(
+		s.dialect.rebind("INSERT INTO snapshot_audit (snapshot_id, ip_address, timestamp, action, actor, reason, performed_at) VALUES (?, ?, ?, ?, ?, ?, ?)"),
+		snap.ID,
+		snap.IPAddress,
+		snap.Timestamp,
+		string(action),
+		audit.Actor,
+		audit.Reason,
+		at,
+	); err != nil {
+		return fmt.Errorf("failed to record %s of snapshot %s: %w", action, snap.ID, err)
+	}
+	return nil
+}
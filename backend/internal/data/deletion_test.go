@@ -0,0 +1,203 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestStore_DeleteAndRestore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		content := []byte(`{"services": [{"port": 22, "vulnerabilities": ["CVE-1"]}]}`)
+		id, err := store.InsertSnapshot("10.0.0.1", "2025-01-01T00:00:00Z", content)
+		if err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+
+		deleted, err := store.DeleteSnapshot(id, Audit{Actor: "alice", Reason: "wrong host"})
+		if err != nil || deleted == nil {
+			t.Fatalf("DeleteSnapshot(%s) = %v, %v", id, deleted, err)
+		}
+		if deleted.IPAddress != "10.0.0.1" || deleted.Timestamp != "2025-01-01T00:00:00Z" {
+			t.Errorf("Unexpected deleted snapshot: %+v", deleted)
+		}
+
+		// Hidden everywhere
+		if snap, err := store.GetSnapshotByID(id); err != nil || snap != nil {
+			t.Errorf("Expected deleted snapshot to be hidden, got %v, %v", snap, err)
+		}
+		if history, _ := store.GetSnapshotsByIP("10.0.0.1"); len(history) != 0 {
+			t.Errorf("Expected empty history, got %d snapshots", len(history))
+		}
+		if hosts, _, _ := store.ListHosts(HostQuery{Limit: 10}); len(hosts) != 0 {
+			t.Errorf("Expected no hosts, got %d", len(hosts))
+		}
+		if services, _, _ := store.QueryServices(ServiceQuery{CVE: "CVE-1", Limit: 10}); len(services) != 0 {
+			t.Errorf("Expected deleted snapshot's services to be unindexed, got %d", len(services))
+		}
+		if again, err := store.DeleteSnapshot(id, Audit{Actor: "alice", Reason: "again"}); err != nil || again != nil {
+			t.Errorf("Expected nil deleting a deleted snapshot, got %v, %v", again, err)
+		}
+
+		// The deleted upload no longer blocks its replacement
+		replacement, err := store.InsertSnapshot("10.0.0.1", "2025-01-01T00:00:00Z", []byte(`{}`))
+		if err != nil {
+			t.Fatalf("Expected replacement upload to succeed, got %v", err)
+		}
+		if _, err := store.RestoreSnapshot(id, Audit{Actor: "alice"}); err == nil {
+			t.Error("Expected error restoring over a live snapshot")
+		}
+
+		if _, err := store.DeleteSnapshot(replacement, Audit{Actor: "bob", Reason: "empty"}); err != nil {
+			t.Fatalf("DeleteSnapshot failed: %v", err)
+		}
+		restored, err := store.RestoreSnapshot(id, Audit{Actor: "alice", Reason: "was right after all"})
+		if err != nil || restored == nil || restored.ID != id {
+			t.Fatalf("RestoreSnapshot(%s) = %v, %v", id, restored, err)
+		}
+		if snap, err := store.GetSnapshotByID(id); err != nil || snap == nil || string(snap.Data) != string(content) {
+			t.Errorf("Expected restored snapshot, got %v, %v", snap, err)
+		}
+		if services, _, _ := store.QueryServices(ServiceQuery{CVE: "CVE-1", Limit: 10}); len(services) != 1 {
+			t.Errorf("Expected restored snapshot to be reindexed, got %d services", len(services))
+		}
+
+		for _, unknown := range []string{"999999", "not-a-number", id} {
+			if snap, err := store.RestoreSnapshot(unknown, Audit{}); err != nil || snap != nil {
+				t.Errorf("Expected nil restoring %q, got %v, %v", unknown, snap, err)
+			}
+		}
+	})
+}
+
+func TestStore_RestoreRevision(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		snap := NewSnapshot{IPAddress: "10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{}`)}
+		first, err := store.StoreSnapshot(snap, ConflictReject, Audit{})
+		if err != nil {
+			t.Fatalf("StoreSnapshot failed: %v", err)
+		}
+		snap.Data = []byte(`{"services": []}`)
+		second, err := store.StoreSnapshot(snap, ConflictKeepBoth, Audit{})
+		if err != nil {
+			t.Fatalf("StoreSnapshot failed: %v", err)
+		}
+		if _, err := store.DeleteSnapshot(first.ID, Audit{Actor: "alice", Reason: "test"}); err != nil {
+			t.Fatalf("DeleteSnapshot failed: %v", err)
+		}
+
+		// Revision 1 being live doesn't block restoring revision 0
+		restored, err := store.RestoreSnapshot(first.ID, Audit{Actor: "alice", Reason: "test"})
+		if err != nil || restored == nil || restored.Revision != 0 {
+			t.Fatalf("RestoreSnapshot(%s) = %v, %v", first.ID, restored, err)
+		}
+		if history, _ := store.GetSnapshotsByIP("10.0.0.1"); len(history) != 2 || history[0].ID != second.ID {
+			t.Errorf("Expected both revisions to be live, got %+v", history)
+		}
+	})
+}
+
+func TestStore_PurgeDeleted(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		insertHostFixtures(t, store)
+		hosts, _, err := store.ListHosts(HostQuery{Prefix: "192.168.", Limit: 1})
+		if err != nil || len(hosts) != 1 {
+			t.Fatalf("ListHosts = %v, %v", hosts, err)
+		}
+		id := hosts[0].LatestSnapshotID
+
+		if _, err := store.DeleteSnapshot(id, Audit{Actor: "alice", Reason: "test data"}); err != nil {
+			t.Fatalf("DeleteSnapshot failed: %v", err)
+		}
+		if purged, err := store.PurgeDeleted("2000-01-01T00:00:00Z", Audit{Actor: "cron"}); err != nil || len(purged) != 0 {
+			t.Errorf("Expected nothing deleted before 2000, got %v, %v", purged, err)
+		}
+		purged, err := store.PurgeDeleted("", Audit{Actor: "cron", Reason: "cleanup"})
+		if err != nil || len(purged) != 1 || purged[0].ID != id {
+			t.Fatalf("PurgeDeleted = %v, %v", purged, err)
+		}
+		if snap, err := store.RestoreSnapshot(id, Audit{}); err != nil || snap != nil {
+			t.Errorf("Expected purged snapshot to be gone, got %v, %v", snap, err)
+		}
+		if hosts, _, _ := store.ListHosts(HostQuery{Limit: 10}); len(hosts) != 3 {
+			t.Errorf("Expected other hosts to survive the purge, got %d", len(hosts))
+		}
+	})
+}
+
+func TestStore_PruneSnapshots(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		old, _ := store.InsertSnapshot("10.0.0.1", "2025-01-01T00:00:00Z", []byte(`{"services": [{"port": 22}]}`))
+		kept, _ := store.InsertSnapshot("10.0.0.1", "2025-01-02T00:00:00Z", []byte(`{"services": [{"port": 22}]}`))
+		deleted, _ := store.InsertSnapshot("10.0.0.1", "2025-01-03T00:00:00Z", []byte(`{}`))
+		if _, err := store.DeleteSnapshot(deleted, Audit{Actor: "alice", Reason: "test"}); err != nil {
+			t.Fatalf("DeleteSnapshot failed: %v", err)
+		}
+
+		pruned, err := store.PruneSnapshots([]string{old, deleted, "999", "bogus"}, Audit{Actor: "retention", Reason: "all:30d"})
+		if err != nil || len(pruned) != 1 || pruned[0].ID != old || pruned[0].Timestamp != "2025-01-01T00:00:00Z" {
+			t.Fatalf("Expected only %s to be pruned, got %v, %v", old, pruned, err)
+		}
+		if snap, _ := store.GetSnapshotByID(old); snap != nil {
+			t.Error("Expected pruned snapshot to be gone")
+		}
+		if snap, _ := store.RestoreSnapshot(old, Audit{}); snap != nil {
+			t.Error("Expected pruned snapshot not to be restorable")
+		}
+		if snap, err := store.GetSnapshotByID(kept); err != nil || snap == nil || string(snap.Data) != `{"services": [{"port": 22}]}` {
+			t.Errorf("Expected %s to keep the shared content, got %v, %v", kept, snap, err)
+		}
+		if services, _, _ := store.QueryServices(ServiceQuery{Port: 22, Limit: 10}); len(services) != 1 || services[0].SnapshotID != kept {
+			t.Errorf("Expected only %s's services to be indexed, got %+v", kept, services)
+		}
+	})
+}
+
+func TestDB_AuditTrail(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	id, err := db.InsertSnapshot("10.0.0.1", "2025-01-01T00:00:00Z", []byte(`{}`))
+	if err != nil {
+		t.Fatalf("InsertSnapshot failed: %v", err)
+	}
+	if snap, err := db.DeleteSnapshot(id, Audit{Actor: "alice", Reason: "wrong host"}); err != nil || snap == nil {
+		t.Fatalf("DeleteSnapshot failed: %v, %v", snap, err)
+	}
+	if snap, err := db.RestoreSnapshot(id, Audit{Actor: "bob", Reason: "right host"}); err != nil || snap == nil {
+		t.Fatalf("RestoreSnapshot failed: %v, %v", snap, err)
+	}
+	if snap, err := db.DeleteSnapshot(id, Audit{Actor: "bob", Reason: "duplicate"}); err != nil || snap == nil {
+		t.Fatalf("DeleteSnapshot failed: %v, %v", snap, err)
+	}
+	if purged, err := db.PurgeDeleted("", Audit{Actor: "cron", Reason: "cleanup"}); err != nil || len(purged) != 1 {
+		t.Fatalf("PurgeDeleted failed: %v, %v", purged, err)
+	}
+
+	rows, err := db.db.Query("SELECT action, actor, reason, performed_at FROM snapshot_audit WHERE snapshot_id = ? ORDER BY id", id)
+	if err != nil {
+		t.Fatalf("Failed to query audit trail: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var action, actor, reason, at string
+		if err := rows.Scan(&action, &actor, &reason, &at); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		if at == "" {
+			t.Errorf("Expected %s to record when it happened", action)
+		}
+		got = append(got, action+" by "+actor+": "+reason)
+	}
+	want := []string{"delete by alice: wrong host", "restore by bob: right host", "delete by bob: duplicate", "purge by cron: cleanup"}
+	if len(got) != len(want) {
+		t.Fatalf("Got audit trail %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Entry %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HistoryOrder selects how ListSnapshots sorts snapshots. Snapshots with the
+// same timestamp are ordered by ID.
+type HistoryOrder string
+
+const (
+	HistoryOrderNewestFirst HistoryOrder = "newest_first"
+	HistoryOrderOldestFirst HistoryOrder = "oldest_first"
+)
+
+// HistoryCursor marks the snapshot after which ListSnapshots continues.
+type HistoryCursor struct {
+	Timestamp string
+	ID        int64
+}
+
+func snapshotCursor(snap *Snapshot) HistoryCursor {
+	id, _ := strconv.ParseInt(snap.ID, 10, 64)
+	return HistoryCursor{Timestamp: snap.Timestamp, ID: id}
+}
+
+// HistoryQuery selects snapshots for ListSnapshots.
+type HistoryQuery struct {
+	// IPAddress selects one host's snapshots; any textual form of the
+	// address matches.
+	IPAddress string
+	// Range, if set instead of IPAddress, selects the snapshots of every host
+	// in an IP range.
+	Range *IPRange
+	// Start and End, if set, bound the snapshot timestamps. Both are inclusive.
+	Start string
+	End   string
+	// Order defaults to HistoryOrderNewestFirst.
+	Order HistoryOrder
+	Limit int
+	After *HistoryCursor
+}
+
+// ListSnapshots returns up to q.Limit snapshots matching q and the cursor
+// for the next page, or nil if there are no more matches. Only the
+// snapshots' metadata and ContentHash are read; their Data is not loaded.
+func (s *sqlStore) ListSnapshots(q HistoryQuery) ([]*Snapshot, *HistoryCursor, error) {
+	if q.Limit <= 0 {
+		return nil, nil, fmt.Errorf("invalid limit %d", q.Limit)
+	}
+	switch q.Order {
+	case "":
+		q.Order = HistoryOrderNewestFirst
+	case HistoryOrderNewestFirst, HistoryOrderOldestFirst:
+	default:
+		return nil, nil, fmt.Errorf("unknown history order %q", q.Order)
+	}
+
+	query, args := historyQuery(q)
+	rows, err := s.db.Query(s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query snapshot history: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*Snapshot
+	for rows.Next() {
+		var snap Snapshot
+		if err := rows.Scan(&snap.ID, &snap.IPAddress, &snap.Timestamp, &snap.Revision, &snap.ContentHash); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan snapshot row: %w", err)
+		}
+		snapshots = append(snapshots, &snap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate snapshot rows: %w", err)
+	}
+
+	var next *HistoryCursor
+	if len(snapshots) > q.Limit {
+		snapshots = snapshots[:q.Limit]
+		cursor := snapshotCursor(snapshots[len(snapshots)-1])
+		next = &cursor
+	}
+	return snapshots, next, nil
+}
+
+// historyQuery builds the query for the next q.Limit+1 snapshots, one more
+// than requested to find out whether there is a next page.
+func historyQuery(q HistoryQuery) (string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	if q.Range != nil {
+		first, last := q.Range.keys()
+		conditions = append(conditions, "ip_key BETWEEN ? AND ?")
+		args = append(args, first, last)
+	} else {
+		column, value := ipMatch(q.IPAddress)
+		conditions = append(conditions, column+" = ?")
+		args = append(args, value)
+	}
+	if q.Start != "" {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, q.Start)
+	}
+	if q.End != "" {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, q.End)
+	}
+
+	cmp, direction := "<", "DESC"
+	if q.Order == HistoryOrderOldestFirst {
+		cmp, direction = ">", "ASC"
+	}
+	if q.After != nil {
+		conditions = append(conditions, "(timestamp "+cmp+" ? OR (timestamp = ? AND id "+cmp+" ?))")
+		args = append(args, q.After.Timestamp, q.After.Timestamp, q.After.ID)
+	}
+
+	query := "SELECT id, ip_address, timestamp, revision, blob_hash FROM snapshots WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY timestamp " + direction + ", id " + direction + " LIMIT ?"
+	args = append(args, q.Limit+1)
+	return query, args
+}
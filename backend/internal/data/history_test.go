@@ -0,0 +1,102 @@
+package data
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func snapshotTimes(snapshots []*Snapshot) string {
+	times := make([]string, len(snapshots))
+	for i, snap := range snapshots {
+		times[i] = snap.IPAddress + "@" + snap.Timestamp[8:10]
+	}
+	return strings.Join(times, " ")
+}
+
+func TestStore_ListSnapshots(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		insertHostFixtures(t, store)
+
+		tests := []struct {
+			name  string
+			query HistoryQuery
+			want  string
+		}{
+			{"host", HistoryQuery{IPAddress: "10.0.0.1"}, "10.0.0.1@05 10.0.0.1@01"},
+			{"other form of host", HistoryQuery{IPAddress: "::ffff:10.0.0.1"}, "10.0.0.1@05 10.0.0.1@01"},
+			{"oldest first", HistoryQuery{IPAddress: "10.0.0.1", Order: HistoryOrderOldestFirst}, "10.0.0.1@01 10.0.0.1@05"},
+			{"start", HistoryQuery{IPAddress: "10.0.0.1", Start: "2025-01-02T00:00:00Z"}, "10.0.0.1@05"},
+			{"end", HistoryQuery{IPAddress: "10.0.0.1", End: "2025-01-01T00:00:00Z"}, "10.0.0.1@01"},
+			{"range", HistoryQuery{Range: &IPRange{netip.MustParseAddr("10.0.0.0"), netip.MustParseAddr("10.0.0.255")}}, "10.0.0.1@05 10.0.0.10@03 10.0.0.1@01"},
+			{"unknown host", HistoryQuery{IPAddress: "10.9.9.9"}, ""},
+		}
+		for _, tt := range tests {
+			tt.query.Limit = 10
+			snapshots, next, err := store.ListSnapshots(tt.query)
+			if err != nil {
+				t.Fatalf("%s: ListSnapshots failed: %v", tt.name, err)
+			}
+			if got := snapshotTimes(snapshots); got != tt.want {
+				t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+			}
+			if next != nil {
+				t.Errorf("%s: expected no next page", tt.name)
+			}
+			for _, snap := range snapshots {
+				if snap.Data != nil {
+					t.Errorf("%s: expected snapshot %s without data", tt.name, snap.ID)
+				}
+				if stored, _ := store.GetSnapshotByID(snap.ID); stored == nil || snap.ContentHash != contentHash(stored.Data) {
+					t.Errorf("%s: expected snapshot %s with the hash of its content, got %q", tt.name, snap.ID, snap.ContentHash)
+				}
+			}
+		}
+
+		if _, _, err := store.ListSnapshots(HistoryQuery{IPAddress: "10.0.0.1", Limit: 1, Order: "random"}); err == nil {
+			t.Error("Expected error for unknown order")
+		}
+		if _, _, err := store.ListSnapshots(HistoryQuery{IPAddress: "10.0.0.1"}); err == nil {
+			t.Error("Expected error for missing limit")
+		}
+	})
+}
+
+func TestStore_ListSnapshotsPagination(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		insertHostFixtures(t, store)
+		// Same timestamp as 10.0.0.1's newest snapshot, so pages break ties by ID
+		if _, err := store.InsertSnapshot("10.0.0.2", "2025-01-05T00:00:00Z", []byte(`{}`)); err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+
+		all := &IPRange{netip.MustParseAddr("0.0.0.0"), netip.MustParseAddr("255.255.255.255")}
+		tests := []struct {
+			query HistoryQuery
+			want  string
+		}{
+			{HistoryQuery{Range: all}, "10.0.0.2@05 | 10.0.1.5@05 | 10.0.0.1@05 | 10.0.0.10@03 | 192.168.0.1@02 | 10.0.0.1@01"},
+			{HistoryQuery{Range: all, Order: HistoryOrderOldestFirst}, "10.0.0.1@01 | 192.168.0.1@02 | 10.0.0.10@03 | 10.0.0.1@05 | 10.0.1.5@05 | 10.0.0.2@05"},
+			{HistoryQuery{Range: all, Start: "2025-01-03T00:00:00Z", End: "2025-01-04T00:00:00Z"}, "10.0.0.10@03"},
+		}
+		for _, tt := range tests {
+			q := tt.query
+			q.Limit = 1
+			var pages []string
+			for i := 0; i < 10; i++ {
+				snapshots, next, err := store.ListSnapshots(q)
+				if err != nil {
+					t.Fatalf("ListSnapshots failed: %v", err)
+				}
+				pages = append(pages, snapshotTimes(snapshots))
+				if next == nil {
+					break
+				}
+				q.After = next
+			}
+			if got := strings.Join(pages, " | "); got != tt.want {
+				t.Errorf("order %q: got pages %q, want %q", tt.query.Order, got, tt.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,295 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HostSummary describes a host that has snapshots. The port and CVE counts
+// are those of its newest snapshot.
+type HostSummary struct {
+	IPAddress        string
+	SnapshotCount    int
+	FirstSeen        string
+	LastSeen         string
+	LatestSnapshotID string
+	OpenPorts        int
+	CVEs             int
+	// key is the host's ip_key, nil if its address isn't an IP address.
+	key []byte
+}
+
+func (h *HostSummary) cursor() HostCursor {
+	return HostCursor{IPAddress: h.IPAddress, Key: h.key, LastSeen: h.LastSeen}
+}
+
+// HostOrder selects how ListHosts sorts hosts. Ties are broken by address.
+// Addresses sort numerically, IPv4 before IPv6, by their ip_key; the rare
+// address that isn't an IP address sorts last.
+type HostOrder string
+
+const (
+	HostOrderIP           HostOrder = "ip"
+	HostOrderLastSeenDesc HostOrder = "last_seen_desc"
+	HostOrderLastSeenAsc  HostOrder = "last_seen_asc"
+)
+
+// HostCursor marks the host after which ListHosts continues.
+type HostCursor struct {
+	IPAddress string
+	// Key is the host's ip_key. Cursors without one take it from IPAddress.
+	Key      []byte `json:",omitempty"`
+	LastSeen string
+}
+
+// HostQuery selects hosts for ListHosts.
+type HostQuery struct {
+	// Prefix, if set, selects hosts whose IP address starts with it.
+	Prefix string
+	// Range, if set, selects hosts in an IP range.
+	Range *IPRange
+	// Order defaults to HostOrderIP.
+	Order HostOrder
+	Limit int
+	After *HostCursor
+}
+
+// ListHosts returns up to q.Limit hosts matching q with their summary stats,
+// and the cursor for the next page, or nil if there are no more matches.
+func (s *sqlStore) ListHosts(q HostQuery) ([]*HostSummary, *HostCursor, error) {
+	if q.Limit <= 0 {
+		return nil, nil, fmt.Errorf("invalid limit %d", q.Limit)
+	}
+	switch q.Order {
+	case "":
+		q.Order = HostOrderIP
+	case HostOrderIP, HostOrderLastSeenDesc, HostOrderLastSeenAsc:
+	default:
+		return nil, nil, fmt.Errorf("unknown host order %q", q.Order)
+	}
+	if q.After != nil && q.After.Key == nil {
+		after := *q.After
+		after.Key, _ = ipKey(after.IPAddress).([]byte)
+		q.After = &after
+	}
+
+	query, args := hostListQuery(q)
+	hosts, err := s.queryHosts(s.dialect.rebind(query), args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var next *HostCursor
+	if len(hosts) > q.Limit {
+		hosts = hosts[:q.Limit]
+		cursor := hosts[len(hosts)-1].cursor()
+		next = &cursor
+	}
+
+	if err := s.loadHostStats(hosts); err != nil {
+		return nil, nil, err
+	}
+	return hosts, next, nil
+}
+
+// hostAddressOrder sorts hosts by address: by ip_key, with hosts whose
+// address has none last on SQLite and PostgreSQL alike.
+const hostAddressOrder = "ip_key IS NULL, ip_key, ip_address"
+
+// afterHost returns the condition selecting hosts that sort after a cursor's
+// host in hostAddressOrder.
+func afterHost(c *HostCursor) (string, []interface{}) {
+	if c.Key == nil {
+		return "(ip_key IS NULL AND ip_address > ?)", []interface{}{c.IPAddress}
+	}
+	return "(ip_key > ? OR (ip_key = ? AND ip_address > ?) OR ip_key IS NULL)", []interface{}{c.Key, c.Key, c.IPAddress}
+}
+
+// hostListQuery builds the query for the next q.Limit+1 hosts, one more
+// than requested to find out whether there is a next page. Hosts are grouped
+// from the snapshots table, so the last-seen orders compare the cursor
+// against the aggregate.
+func hostListQuery(q HostQuery) (string, []interface{}) {
+	query := "SELECT ip_address, ip_key, COUNT(*), MIN(timestamp), MAX(timestamp) FROM snapshots"
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	if q.Prefix != "" {
+		conditions = append(conditions, `ip_address LIKE ? ESCAPE '\'`)
+		args = append(args, escapeLike(q.Prefix)+"%")
+	}
+	if q.Range != nil {
+		first, last := q.Range.keys()
+		conditions = append(conditions, "ip_key BETWEEN ? AND ?")
+		args = append(args, first, last)
+	}
+	if q.After != nil && q.Order == HostOrderIP {
+		after, afterArgs := afterHost(q.After)
+		conditions = append(conditions, after)
+		args = append(args, afterArgs...)
+	}
+	query += " WHERE " + strings.Join(conditions, " AND ") + " GROUP BY ip_key, ip_address"
+
+	switch q.Order {
+	case HostOrderLastSeenDesc, HostOrderLastSeenAsc:
+		cmp, direction := "<", " DESC"
+		if q.Order == HostOrderLastSeenAsc {
+			cmp, direction = ">", ""
+		}
+		if q.After != nil {
+			after, afterArgs := afterHost(q.After)
+			query += " HAVING MAX(timestamp) " + cmp + " ? OR (MAX(timestamp) = ? AND " + after + ")"
+			args = append(args, q.After.LastSeen, q.After.LastSeen)
+			args = append(args, afterArgs...)
+		}
+		query += " ORDER BY MAX(timestamp)" + direction + ", " + hostAddressOrder
+	default:
+		query += " ORDER BY " + hostAddressOrder
+	}
+
+	query += " LIMIT ?"
+	args = append(args, q.Limit+1)
+	return query, args
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (s *sqlStore) queryHosts(query string, args []interface{}) ([]*HostSummary, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hosts: %w", err)
+	}
+	defer rows.Close()
+
+	var hosts []*HostSummary
+	for rows.Next() {
+		var h HostSummary
+		if err := rows.Scan(&h.IPAddress, &h.key, &h.SnapshotCount, &h.FirstSeen, &h.LastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan host row: %w", err)
+		}
+		hosts = append(hosts, &h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate host rows: %w", err)
+	}
+	return hosts, nil
+}
+
+// loadHostStats fills in each host's newest snapshot ID and its distinct
+// port and CVE counts from the services index.
+func (s *sqlStore) loadHostStats(hosts []*HostSummary) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	byIP := make(map[string]*HostSummary, len(hosts))
+	args := make([]interface{}, len(hosts))
+	for i, h := range hosts {
+		byIP[h.IPAddress] = h
+		args[i] = h.IPAddress
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(hosts)), ", ")
+	rows, err := s.db.Query(
+		s.dialect.rebind(`SELECT s.ip_address, s.id,
+			(SELECT COUNT(DISTINCT sv.port) FROM services sv WHERE sv.snapshot_id = s.id),
+			(SELECT COUNT(DISTINCT v.cve_id) FROM vulnerabilities v WHERE v.snapshot_id = s.id)
+			FROM snapshots s
+			WHERE s.ip_address IN (`+placeholders+`)
+			AND s.id = (`+latestSnapshotQuery("s", "")+`)`),
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query host stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ip, id string
+		var ports, cves int
+		if err := rows.Scan(&ip, &id, &ports, &cves); err != nil {
+			return fmt.Errorf("failed to scan host stats row: %w", err)
+		}
+		if h := byIP[ip]; h != nil {
+			h.LatestSnapshotID, h.OpenPorts, h.CVEs = id, ports, cves
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate host stats rows: %w", err)
+	}
+	return nil
+}
+
+// FleetQuery selects the snapshots for QueryFleet.
+type FleetQuery struct {
+	Range IPRange
+	// AsOf, if set, picks each host's newest snapshot at or before this time
+	// instead of its newest overall.
+	AsOf  string
+	Limit int
+	// After is the IP address of the last host of the previous page.
+	After string
+}
+
+// QueryFleet returns the newest snapshot of up to q.Limit hosts in q.Range,
+// ordered by address, and the IP address to continue after, or "" if there
+// are no more hosts. The snapshots' Data is not loaded.
+func (s *sqlStore) QueryFleet(q FleetQuery) ([]*Snapshot, string, error) {
+	if q.Limit <= 0 {
+		return nil, "", fmt.Errorf("invalid limit %d", q.Limit)
+	}
+
+	first, last := q.Range.keys()
+	if q.After != "" {
+		if key, ok := ipKey(q.After).([]byte); ok {
+			first = increment(key)
+		}
+	}
+
+	args := []interface{}{first, last}
+	asOf := ""
+	if q.AsOf != "" {
+		asOf = " AND m.timestamp <= ?"
+		args = append(args, q.AsOf)
+	}
+	args = append(args, q.Limit+1)
+
+	rows, err := s.db.Query(
+		s.dialect.rebind("SELECT s.id, s.ip_address, s.timestamp, s.revision FROM snapshots s WHERE s.ip_key BETWEEN ? AND ? AND s.id = ("+latestSnapshotQuery("s", asOf)+") ORDER BY s.ip_key LIMIT ?"),
+		args...,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query fleet: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*Snapshot
+	for rows.Next() {
+		var snap Snapshot
+		if err := rows.Scan(&snap.ID, &snap.IPAddress, &snap.Timestamp, &snap.Revision); err != nil {
+			return nil, "", fmt.Errorf("failed to scan fleet row: %w", err)
+		}
+		snapshots = append(snapshots, &snap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to iterate fleet rows: %w", err)
+	}
+
+	var next string
+	if len(snapshots) > q.Limit {
+		snapshots = snapshots[:q.Limit]
+		next = snapshots[len(snapshots)-1].IPAddress
+	}
+	return snapshots, next, nil
+}
+
+// latestSnapshotQuery returns a subquery for the ID of the newest live
+// snapshot, and of its newest revision, of the host in the ip_address
+// column of the outer table alias. condition adds to the subquery's WHERE
+// clause, on the snapshots alias m.
+func latestSnapshotQuery(alias, condition string) string {
+	return "SELECT m.id FROM snapshots m WHERE m.ip_address = " + alias + ".ip_address AND m.deleted_at IS NULL" + condition +
+		" ORDER BY m.timestamp DESC, m.revision DESC LIMIT 1"
+}
@@ -0,0 +1,153 @@
+package data
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func insertHostFixtures(t *testing.T, store Store) {
+	t.Helper()
+	fixtures := []NewSnapshot{
+		{IPAddress: "10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{"services": [{"port": 22}, {"port": 80}, {"port": 443}]}`)},
+		{IPAddress: "10.0.0.1", Timestamp: "2025-01-05T00:00:00Z", Data: []byte(`{"services": [{"port": 22, "vulnerabilities": ["CVE-1"]}, {"port": 22, "protocol": "SSH", "vulnerabilities": ["CVE-1", "CVE-2"]}]}`)},
+		{IPAddress: "10.0.0.10", Timestamp: "2025-01-03T00:00:00Z", Data: []byte(`{}`)},
+		{IPAddress: "10.0.1.5", Timestamp: "2025-01-05T00:00:00Z", Data: []byte(`{"services": [{"port": 8080}]}`)},
+		{IPAddress: "192.168.0.1", Timestamp: "2025-01-02T00:00:00Z", Data: []byte(`{}`)},
+	}
+	for _, f := range fixtures {
+		if _, err := store.InsertSnapshot(f.IPAddress, f.Timestamp, f.Data); err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+	}
+}
+
+func hostIPs(hosts []*HostSummary) string {
+	ips := make([]string, len(hosts))
+	for i, h := range hosts {
+		ips[i] = h.IPAddress
+	}
+	return strings.Join(ips, " ")
+}
+
+func TestStore_ListHosts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		insertHostFixtures(t, store)
+
+		tests := []struct {
+			name  string
+			query HostQuery
+			want  string
+		}{
+			{"by ip", HostQuery{}, "10.0.0.1 10.0.0.10 10.0.1.5 192.168.0.1"},
+			{"last seen desc", HostQuery{Order: HostOrderLastSeenDesc}, "10.0.0.1 10.0.1.5 10.0.0.10 192.168.0.1"},
+			{"last seen asc", HostQuery{Order: HostOrderLastSeenAsc}, "192.168.0.1 10.0.0.10 10.0.0.1 10.0.1.5"},
+			{"prefix", HostQuery{Prefix: "10.0.0."}, "10.0.0.1 10.0.0.10"},
+			{"prefix is not a pattern", HostQuery{Prefix: "10_0"}, ""},
+			{"range", HostQuery{Range: &IPRange{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("10.0.1.5")}}, "10.0.0.10 10.0.1.5"},
+		}
+		for _, tt := range tests {
+			tt.query.Limit = 10
+			hosts, next, err := store.ListHosts(tt.query)
+			if err != nil {
+				t.Fatalf("%s: ListHosts failed: %v", tt.name, err)
+			}
+			if got := hostIPs(hosts); got != tt.want {
+				t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+			}
+			if next != nil {
+				t.Errorf("%s: expected no next page", tt.name)
+			}
+		}
+
+		hosts, _, err := store.ListHosts(HostQuery{Prefix: "10.0.0.1", Limit: 1})
+		if err != nil || len(hosts) != 1 {
+			t.Fatalf("Expected 1 host, got %d, %v", len(hosts), err)
+		}
+		h := hosts[0]
+		if h.SnapshotCount != 2 || h.FirstSeen != "2025-01-01T00:00:00Z" || h.LastSeen != "2025-01-05T00:00:00Z" {
+			t.Errorf("Unexpected host summary: %+v", h)
+		}
+		// Stats come from the newest snapshot only, counting distinct values
+		if h.OpenPorts != 1 || h.CVEs != 2 || h.LatestSnapshotID == "" {
+			t.Errorf("Expected 1 open port and 2 CVEs from the newest snapshot, got %+v", h)
+		}
+
+		if _, _, err := store.ListHosts(HostQuery{Limit: 1, Order: "random"}); err == nil {
+			t.Error("Expected error for unknown order")
+		}
+	})
+}
+
+func TestStore_ListHostsNumericOrder(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		for _, ip := range []string{"10.0.0.10", "2001:db8::1", "10.0.0.2", "9.0.0.1", "::ffff:10.0.0.3"} {
+			if _, err := store.InsertSnapshot(ip, "2025-01-01T00:00:00Z", []byte(`{}`)); err != nil {
+				t.Fatalf("InsertSnapshot failed: %v", err)
+			}
+		}
+
+		// Hosts last seen at the same time are also ordered by address
+		want := "9.0.0.1 | 10.0.0.2 | 10.0.0.3 | 10.0.0.10 | 2001:db8::1"
+		for _, order := range []HostOrder{HostOrderIP, HostOrderLastSeenDesc, HostOrderLastSeenAsc} {
+			q := HostQuery{Order: order, Limit: 1}
+			var pages []string
+			for i := 0; i < 10; i++ {
+				hosts, next, err := store.ListHosts(q)
+				if err != nil {
+					t.Fatalf("ListHosts failed: %v", err)
+				}
+				pages = append(pages, hostIPs(hosts))
+				if next == nil {
+					break
+				}
+				q.After = next
+			}
+			if got := strings.Join(pages, " | "); got != want {
+				t.Errorf("order %q: got pages %q, want %q", order, got, want)
+			}
+		}
+
+		// Cursors without a key, as in page tokens from before keys were
+		// added, continue after their address
+		hosts, _, err := store.ListHosts(HostQuery{Limit: 10, After: &HostCursor{IPAddress: "10.0.0.2"}})
+		if err != nil || hostIPs(hosts) != "10.0.0.3 10.0.0.10 2001:db8::1" {
+			t.Errorf("Expected the hosts after 10.0.0.2, got %q, %v", hostIPs(hosts), err)
+		}
+	})
+}
+
+func TestStore_ListHostsPagination(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		insertHostFixtures(t, store)
+
+		tests := []struct {
+			query HostQuery
+			want  string
+		}{
+			{HostQuery{}, "10.0.0.1 | 10.0.0.10 | 10.0.1.5 | 192.168.0.1"},
+			{HostQuery{Order: HostOrderLastSeenDesc}, "10.0.0.1 | 10.0.1.5 | 10.0.0.10 | 192.168.0.1"},
+			{HostQuery{Order: HostOrderLastSeenAsc}, "192.168.0.1 | 10.0.0.10 | 10.0.0.1 | 10.0.1.5"},
+			{HostQuery{Range: &IPRange{netip.MustParseAddr("10.0.0.0"), netip.MustParseAddr("10.0.0.255")}, Order: HostOrderLastSeenDesc}, "10.0.0.1 | 10.0.0.10"},
+		}
+		for _, tt := range tests {
+			q := tt.query
+			q.Limit = 1
+			var pages []string
+			for i := 0; i < 10; i++ {
+				hosts, next, err := store.ListHosts(q)
+				if err != nil {
+					t.Fatalf("ListHosts failed: %v", err)
+				}
+				pages = append(pages, hostIPs(hosts))
+				if next == nil {
+					break
+				}
+				q.After = next
+			}
+			if got := strings.Join(pages, " | "); got != tt.want {
+				t.Errorf("order %q: got pages %q, want %q", tt.query.Order, got, tt.want)
+			}
+		}
+	})
+}
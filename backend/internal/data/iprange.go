@@ -0,0 +1,123 @@
+package data
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// IPRange is an inclusive range of IP addresses. Both ends must be of the
+// same family.
+type IPRange struct {
+	First netip.Addr
+	Last  netip.Addr
+}
+
+// NewIPRange returns the range from first to last.
+func NewIPRange(first, last netip.Addr) (IPRange, error) {
+	if first.Is4() != last.Is4() {
+		return IPRange{}, fmt.Errorf("range %s-%s mixes IPv4 and IPv6", first, last)
+	}
+	if first.Compare(last) > 0 {
+		return IPRange{}, fmt.Errorf("range start %s is after its end %s", first, last)
+	}
+	return IPRange{First: first, Last: last}, nil
+}
+
+// PrefixRange returns the addresses in a CIDR block.
+func PrefixRange(prefix netip.Prefix) IPRange {
+	prefix = prefix.Masked()
+	first := prefix.Addr()
+
+	last := first.As16()
+	hostBits := first.BitLen() - prefix.Bits()
+	for i := len(last) - 1; hostBits > 0; i-- {
+		if hostBits >= 8 {
+			last[i] = 0xff
+		} else {
+			last[i] |= byte(1<<hostBits - 1)
+		}
+		hostBits -= 8
+	}
+
+	lastAddr := netip.AddrFrom16(last)
+	if first.Is4() {
+		lastAddr = lastAddr.Unmap()
+	}
+	return IPRange{First: first, Last: lastAddr}
+}
+
+// keys returns the ip_key values of the ends of the range.
+func (r IPRange) keys() ([]byte, []byte) {
+	first, last := r.First.WithZone("").As16(), r.Last.WithZone("").As16()
+	return first[:], last[:]
+}
+
+// increment returns the key following key in byte order, which is key with
+// a zero byte appended: no 16-byte key sorts between the two.
+func increment(key []byte) []byte {
+	return append(key[:len(key):len(key)], 0)
+}
+
+// canonicalIP returns the canonical text of an IP address, the form it is
+// stored in, or the text unchanged if it doesn't parse.
+func canonicalIP(ipAddress string) string {
+	addr, err := netip.ParseAddr(ipAddress)
+	if err != nil {
+		return ipAddress
+	}
+	return addr.Unmap().WithZone("").String()
+}
+
+// ipKey returns the ip_key column value for an IP address: its 16-byte form,
+// or NULL if it doesn't parse.
+func ipKey(ipAddress string) interface{} {
+	addr, err := netip.ParseAddr(ipAddress)
+	if err != nil {
+		return nil
+	}
+	key := addr.WithZone("").As16()
+	return key[:]
+}
+
+// ipMatch returns the column and value that select a host's rows: its
+// ip_key, which every textual form of an address shares, or the text itself
+// if it isn't an IP address.
+func ipMatch(ipAddress string) (string, interface{}) {
+	if key := ipKey(ipAddress); key != nil {
+		return "ip_key", key
+	}
+	return "ip_address", ipAddress
+}
+
+// fillIPKeys sets ip_key on rows stored before the column existed.
+func (s *sqlStore) fillIPKeys() error {
+	rows, err := s.db.Query("SELECT id, ip_address FROM snapshots WHERE ip_key IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to query snapshots without ip_key: %w", err)
+	}
+	type row struct{ id, ipAddress string }
+	var pending []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.ipAddress); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan snapshot row: %w", err)
+		}
+		pending = append(pending, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate snapshot rows: %w", err)
+	}
+
+	for _, r := range pending {
+		key := ipKey(r.ipAddress)
+		if key == nil {
+			continue
+		}
+		if _, err := s.db.Exec(s.dialect.rebind("UPDATE snapshots SET ip_key = ? WHERE id = ?"), key, r.id); err != nil {
+			return fmt.Errorf("failed to set ip_key of snapshot %s: %w", r.id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package data
+
+import (
+	"net/netip"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrefixRange(t *testing.T) {
+	tests := []struct {
+		prefix      string
+		first, last string
+	}{
+		{"10.0.0.0/8", "10.0.0.0", "10.255.255.255"},
+		{"192.168.1.77/30", "192.168.1.76", "192.168.1.79"},
+		{"10.1.2.3/32", "10.1.2.3", "10.1.2.3"},
+		{"0.0.0.0/0", "0.0.0.0", "255.255.255.255"},
+		{"2001:db8::/32", "2001:db8::", "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"2001:db8::/61", "2001:db8::", "2001:db8:0:7:ffff:ffff:ffff:ffff"},
+	}
+	for _, tt := range tests {
+		r := PrefixRange(netip.MustParsePrefix(tt.prefix))
+		if r.First.String() != tt.first || r.Last.String() != tt.last {
+			t.Errorf("PrefixRange(%s) = %s-%s, want %s-%s", tt.prefix, r.First, r.Last, tt.first, tt.last)
+		}
+	}
+}
+
+func TestNewIPRange(t *testing.T) {
+	tests := []struct {
+		first, last string
+		wantErr     bool
+	}{
+		{"10.0.0.1", "10.0.0.9", false},
+		{"10.0.0.9", "10.0.0.9", false},
+		{"10.0.0.9", "10.0.0.1", true},
+		{"10.0.0.1", "::ffff:10.0.0.9", true},
+		{"2001:db8::1", "2001:db8::ff", false},
+	}
+	for _, tt := range tests {
+		_, err := NewIPRange(netip.MustParseAddr(tt.first), netip.MustParseAddr(tt.last))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewIPRange(%s, %s) error = %v, wantErr %v", tt.first, tt.last, err, tt.wantErr)
+		}
+	}
+}
+
+func insertRangeFixtures(t *testing.T, store Store) {
+	t.Helper()
+	fixtures := []struct{ ip, timestamp string }{
+		{"10.0.0.5", "2025-01-01T00:00:00Z"},
+		{"10.0.0.5", "2025-01-03T00:00:00Z"},
+		{"10.0.0.20", "2025-01-02T00:00:00Z"},
+		{"10.0.1.1", "2025-01-02T00:00:00Z"},
+		{"9.255.255.255", "2025-01-02T00:00:00Z"},
+		{"2001:db8::1", "2025-01-01T00:00:00Z"},
+		{"2001:db8::1", "2025-01-04T00:00:00Z"},
+		{"2001:db8:1::1", "2025-01-01T00:00:00Z"},
+	}
+	for _, f := range fixtures {
+		if _, err := store.InsertSnapshot(f.ip, f.timestamp, []byte(`{}`)); err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+	}
+}
+
+func snapshotKeys(snapshots []*Snapshot) string {
+	keys := make([]string, len(snapshots))
+	for i, snap := range snapshots {
+		keys[i] = snap.IPAddress + "@" + snap.Timestamp[8:10]
+	}
+	return strings.Join(keys, " ")
+}
+
+func TestStore_GetSnapshotsByIPRange(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		insertRangeFixtures(t, store)
+
+		tests := []struct {
+			prefix string
+			want   string
+		}{
+			{"10.0.0.0/24", "10.0.0.5@03 10.0.0.20@02 10.0.0.5@01"},
+			{"10.0.0.0/8", "10.0.0.5@03 10.0.0.20@02 10.0.1.1@02 10.0.0.5@01"},
+			{"2001:db8::/48", "2001:db8::1@04 2001:db8::1@01"},
+			{"2001:db8::/32", "2001:db8::1@04 2001:db8::1@01 2001:db8:1::1@01"},
+			{"172.16.0.0/12", ""},
+		}
+		for _, tt := range tests {
+			snapshots, err := store.GetSnapshotsByIPRange(PrefixRange(netip.MustParsePrefix(tt.prefix)))
+			if err != nil {
+				t.Fatalf("GetSnapshotsByIPRange(%s) failed: %v", tt.prefix, err)
+			}
+			if got := snapshotKeys(snapshots); got != tt.want {
+				t.Errorf("GetSnapshotsByIPRange(%s) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		}
+
+		r, _ := NewIPRange(netip.MustParseAddr("10.0.0.6"), netip.MustParseAddr("10.0.1.1"))
+		snapshots, err := store.GetSnapshotsByIPRange(r)
+		if err != nil {
+			t.Fatalf("GetSnapshotsByIPRange failed: %v", err)
+		}
+		if got := snapshotKeys(snapshots); got != "10.0.0.20@02 10.0.1.1@02" {
+			t.Errorf("Unexpected snapshots in range: %q", got)
+		}
+	})
+}
+
+func TestStore_QueryFleet(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		insertRangeFixtures(t, store)
+
+		tests := []struct {
+			prefix string
+			asOf   string
+			want   string
+		}{
+			{"10.0.0.0/8", "", "10.0.0.5@03 10.0.0.20@02 10.0.1.1@02"},
+			{"10.0.0.0/8", "2025-01-02T00:00:00Z", "10.0.0.5@01 10.0.0.20@02 10.0.1.1@02"},
+			{"10.0.0.0/8", "2025-01-01T12:00:00Z", "10.0.0.5@01"},
+			{"2001:db8::/32", "2025-01-02T00:00:00Z", "2001:db8::1@01 2001:db8:1::1@01"},
+		}
+		for _, tt := range tests {
+			q := FleetQuery{Range: PrefixRange(netip.MustParsePrefix(tt.prefix)), AsOf: tt.asOf, Limit: 10}
+			snapshots, next, err := store.QueryFleet(q)
+			if err != nil {
+				t.Fatalf("QueryFleet failed: %v", err)
+			}
+			if got := snapshotKeys(snapshots); got != tt.want || next != "" {
+				t.Errorf("QueryFleet(%s, %q) = %q, %q; want %q", tt.prefix, tt.asOf, got, next, tt.want)
+			}
+		}
+
+		// IPv4 addresses sort before IPv6 ones
+		q := FleetQuery{Range: PrefixRange(netip.MustParsePrefix("::/0")), Limit: 2}
+		var pages []string
+		for i := 0; i < 10; i++ {
+			snapshots, next, err := store.QueryFleet(q)
+			if err != nil {
+				t.Fatalf("QueryFleet failed: %v", err)
+			}
+			pages = append(pages, snapshotKeys(snapshots))
+			if next == "" {
+				break
+			}
+			q.After = next
+		}
+		want := "9.255.255.255@02 10.0.0.5@03 | 10.0.0.20@02 10.0.1.1@02 | 2001:db8::1@04 2001:db8:1::1@01"
+		if got := strings.Join(pages, " | "); got != want {
+			t.Errorf("Got pages %q, want %q", got, want)
+		}
+	})
+}
+
+func TestNewDB_FillsIPKeys(t *testing.T) {
+	dbPath := "./test_fill_ip_keys.db"
+	defer os.Remove(dbPath)
+
+	// Rows stored before ip_key existed
+	m, err := OpenMigrator(dbPath)
+	if err != nil {
+		t.Fatalf("OpenMigrator failed: %v", err)
+	}
+	if _, err := m.Up(2, false); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	for _, ip := range []string{"10.0.0.1", "10.1.0.1", "not-an-ip"} {
+		if _, err := m.db.Exec("INSERT INTO snapshots (ip_address, timestamp, data) VALUES (?, ?, ?)", ip, "2025-01-01T00:00:00Z", "{}"); err != nil {
+			t.Fatalf("Failed to insert legacy row: %v", err)
+		}
+	}
+	m.Close()
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	snapshots, err := db.GetSnapshotsByIPRange(PrefixRange(netip.MustParsePrefix("10.0.0.0/16")))
+	if err != nil {
+		t.Fatalf("GetSnapshotsByIPRange failed: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].IPAddress != "10.0.0.1" {
+		t.Errorf("Expected the legacy 10.0.0.1 row, got %d snapshots", len(snapshots))
+	}
+}
+
+func TestStore_CanonicalIPs(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if _, err := store.InsertSnapshot("2001:DB8:0:0:0:0:0:1", "2025-01-01T00:00:00Z", []byte(`{}`)); err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+		if _, err := store.InsertSnapshot("2001:db8::1", "2025-01-02T00:00:00Z", []byte(`{}`)); err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+		if _, err := store.InsertSnapshot("2001:db8:0::1", "2025-01-02T00:00:00Z", []byte(`{}`)); err == nil {
+			t.Error("Expected another form of the same address and timestamp to be a duplicate")
+		}
+		outcomes, err := store.InsertSnapshots([]NewSnapshot{
+			{IPAddress: "::ffff:10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{}`)},
+			{IPAddress: "10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{}`)},
+		}, true)
+		if err != nil {
+			t.Fatalf("InsertSnapshots failed: %v", err)
+		}
+		if outcomes[0].Duplicate || !outcomes[1].Duplicate {
+			t.Errorf("Expected the IPv4-mapped and plain forms to be one host, got %+v", outcomes)
+		}
+
+		for _, form := range []string{"2001:db8::1", "2001:0DB8:0000:0000:0000:0000:0000:0001", "2001:db8::0:1"} {
+			history, err := store.GetSnapshotsByIP(form)
+			if err != nil {
+				t.Fatalf("GetSnapshotsByIP failed: %v", err)
+			}
+			if len(history) != 2 || history[0].IPAddress != "2001:db8::1" || history[1].IPAddress != "2001:db8::1" {
+				t.Errorf("GetSnapshotsByIP(%s): expected 2 snapshots stored as 2001:db8::1, got %d", form, len(history))
+			}
+
+			window, err := store.GetSnapshotsInRange(form, "2025-01-02T00:00:00Z", "")
+			if err != nil {
+				t.Fatalf("GetSnapshotsInRange failed: %v", err)
+			}
+			if len(window) != 1 {
+				t.Errorf("GetSnapshotsInRange(%s): expected 1 snapshot, got %d", form, len(window))
+			}
+		}
+
+		history, err := store.GetSnapshotsByIP("::ffff:10.0.0.1")
+		if err != nil {
+			t.Fatalf("GetSnapshotsByIP failed: %v", err)
+		}
+		if len(history) != 1 || history[0].IPAddress != "10.0.0.1" {
+			t.Errorf("Expected 1 snapshot stored as 10.0.0.1, got %d", len(history))
+		}
+	})
+}
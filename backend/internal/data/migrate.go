@@ -0,0 +1,347 @@
+package data
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationFiles holds the numbered schema migrations for each dialect, named
+// <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+var migrationPattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// migrationStep is Go code for a migration that changes data in a way SQL
+// can't. up runs after the migration's SQL and down before it, in the same
+// transaction.
+type migrationStep struct {
+	up, down func(s *sqlStore, tx *sql.Tx) error
+}
+
+// migrationSteps holds the Go steps of migrations, by file name prefix.
+var migrationSteps = map[string]migrationStep{
+	"0006_snapshot_blobs": {up: (*sqlStore).moveDataToBlobs, down: (*sqlStore).moveDataFromBlobs},
+}
+
+// Migration is one numbered schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// String returns the migration's file name prefix, e.g. "0001_create_snapshots".
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// MigrationStatus reports whether a migration has been applied to a database.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt string
+}
+
+// Migrator applies and rolls back schema migrations. Every migration runs in
+// its own transaction together with its schema_version bookkeeping, so a
+// failed migration leaves the database at the previous version.
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []Migration
+}
+
+// OpenMigrator connects to the database for a data source name, as Open
+// does, without migrating it.
+func OpenMigrator(dsn string) (*Migrator, error) {
+	var db *sql.DB
+	var d dialect
+	var err error
+	if isPostgresDSN(dsn) {
+		db, err = openPostgres(dsn)
+		d = dialectPostgres
+	} else {
+		db, err = openSQLite(trimSQLiteScheme(dsn))
+		d = dialectSQLite
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newMigrator(db, d)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func newMigrator(db *sql.DB, d dialect) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, path.Join("migrations", d.String()))
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: d, migrations: migrations}, nil
+}
+
+// loadMigrations reads the migrations in a directory, ordered by version.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		matches := migrationPattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("unexpected file in migrations: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(matches[1])
+		if version <= 0 {
+			return nil, fmt.Errorf("migration %s: version must be positive", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, m.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %s needs both an up and a down file", m)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Close closes the database connection.
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+// Latest returns the newest migration version this binary knows about.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses[i] = MigrationStatus{Migration: migration, Applied: ok, AppliedAt: appliedAt}
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration up to and including version target, or
+// all of them if target is 0, and returns the migrations applied. With dryRun
+// set nothing is changed and the migrations that would run are returned.
+//
+// Up refuses to run against a database that has migrations this binary
+// doesn't know about, since its code may not match that schema.
+func (m *Migrator) Up(target int, dryRun bool) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	for version := range applied {
+		if version > m.Latest() {
+			return nil, fmt.Errorf("database schema version %d is newer than the latest known migration %d", version, m.Latest())
+		}
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok && (target == 0 || migration.Version <= target) {
+			pending = append(pending, migration)
+		}
+	}
+	if dryRun || len(pending) == 0 {
+		return pending, nil
+	}
+
+	if _, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	var done []Migration
+	for _, migration := range pending {
+		ran, err := m.run(migration, true)
+		if err != nil {
+			return done, err
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Down rolls back the most recently applied migrations, newest first, and
+// returns the migrations rolled back. With dryRun set nothing is changed and
+// the migrations that would be rolled back are returned.
+func (m *Migrator) Down(steps int, dryRun bool) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var rollback []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(rollback) < steps; i-- {
+		if _, ok := applied[m.migrations[i].Version]; ok {
+			rollback = append(rollback, m.migrations[i])
+		}
+	}
+	if dryRun {
+		return rollback, nil
+	}
+
+	var done []Migration
+	for _, migration := range rollback {
+		ran, err := m.run(migration, false)
+		if err != nil {
+			return done, err
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// run applies or rolls back one migration in a transaction. It reports false
+// if another process got there first.
+func (m *Migrator) run(migration Migration, up bool) (bool, error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Serialize concurrent migrators; SQLite already allows only one writer
+	if m.dialect == dialectPostgres {
+		if _, err := tx.Exec("LOCK TABLE schema_version IN EXCLUSIVE MODE"); err != nil {
+			return false, fmt.Errorf("failed to lock schema_version: %w", err)
+		}
+	}
+
+	var count int
+	if err := tx.QueryRow(m.dialect.rebind("SELECT COUNT(*) FROM schema_version WHERE version = ?"), migration.Version).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check migration %s: %w", migration, err)
+	}
+	if (count > 0) == up {
+		return false, nil
+	}
+
+	step := migrationSteps[migration.String()]
+	store := &sqlStore{db: m.db, dialect: m.dialect}
+	if up {
+		if _, err := tx.Exec(migration.Up); err != nil {
+			return false, fmt.Errorf("migration %s failed: %w", migration, err)
+		}
+		if step.up != nil {
+			if err := step.up(store, tx); err != nil {
+				return false, fmt.Errorf("migration %s failed: %w", migration, err)
+			}
+		}
+		if _, err := tx.Exec(
+			m.dialect.rebind("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)"),
+			migration.Version,
+			migration.Name,
+			time.Now().UTC().Format(time.RFC3339),
+		); err != nil {
+			return false, fmt.Errorf("failed to record migration %s: %w", migration, err)
+		}
+	} else {
+		if step.down != nil {
+			if err := step.down(store, tx); err != nil {
+				return false, fmt.Errorf("rollback of %s failed: %w", migration, err)
+			}
+		}
+		if _, err := tx.Exec(migration.Down); err != nil {
+			return false, fmt.Errorf("rollback of %s failed: %w", migration, err)
+		}
+		if _, err := tx.Exec(m.dialect.rebind("DELETE FROM schema_version WHERE version = ?"), migration.Version); err != nil {
+			return false, fmt.Errorf("failed to record rollback of %s: %w", migration, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit migration %s: %w", migration, err)
+	}
+	return true, nil
+}
+
+// applied returns the applied migration versions and when they were applied.
+// A database without a schema_version table has none.
+func (m *Migrator) applied() (map[int]string, error) {
+	var exists int
+	if err := m.db.QueryRow(m.dialect.tableExistsQuery(), "schema_version").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look for schema_version table: %w", err)
+	}
+	applied := make(map[int]string)
+	if exists == 0 {
+		return applied, nil
+	}
+
+	rows, err := m.db.Query("SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_version: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_version row: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate schema_version rows: %w", err)
+	}
+	return applied, nil
+}
@@ -0,0 +1,244 @@
+package data
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// forEachMigrator runs a test against a Migrator on a fresh, unmigrated
+// database for every backend; see forEachStore.
+func forEachMigrator(t *testing.T, test func(t *testing.T, m *Migrator)) {
+	t.Run("sqlite", func(t *testing.T) {
+		m, err := OpenMigrator(":memory:")
+		if err != nil {
+			t.Fatalf("OpenMigrator failed: %v", err)
+		}
+		defer m.Close()
+		test(t, m)
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		dsn := os.Getenv("POSTGRES_TEST_DSN")
+		if dsn == "" {
+			t.Skip("POSTGRES_TEST_DSN not set")
+		}
+		resetPostgres(t, dsn)
+
+		m, err := OpenMigrator(dsn)
+		if err != nil {
+			t.Fatalf("OpenMigrator failed: %v", err)
+		}
+		defer m.Close()
+		test(t, m)
+	})
+}
+
+// withTestMigration adds a second migration after the embedded ones.
+func withTestMigration(m *Migrator) Migration {
+	extra := Migration{
+		Version: m.Latest() + 1,
+		Name:    "add_notes",
+		Up:      "CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT NOT NULL);",
+		Down:    "DROP TABLE notes;",
+	}
+	m.migrations = append(m.migrations, extra)
+	return extra
+}
+
+func tableExists(t *testing.T, m *Migrator, name string) bool {
+	t.Helper()
+	var count int
+	if err := m.db.QueryRow(m.dialect.tableExistsQuery(), name).Scan(&count); err != nil {
+		t.Fatalf("Failed to look up table %s: %v", name, err)
+	}
+	return count > 0
+}
+
+func TestMigrator_UpAndDown(t *testing.T) {
+	forEachMigrator(t, func(t *testing.T, m *Migrator) {
+		extra := withTestMigration(m)
+
+		pending, err := m.Up(0, true)
+		if err != nil {
+			t.Fatalf("Up dry run failed: %v", err)
+		}
+		if len(pending) != len(m.migrations) {
+			t.Errorf("Expected %d pending migrations, got %d", len(m.migrations), len(pending))
+		}
+		if tableExists(t, m, "snapshots") || tableExists(t, m, "schema_version") {
+			t.Fatal("Expected dry run to leave the database untouched")
+		}
+
+		applied, err := m.Up(extra.Version-1, false)
+		if err != nil {
+			t.Fatalf("Up failed: %v", err)
+		}
+		if len(applied) != len(m.migrations)-1 || !tableExists(t, m, "snapshots") || tableExists(t, m, "notes") {
+			t.Fatalf("Expected Up to stop before version %d, applied %v", extra.Version, applied)
+		}
+
+		applied, err = m.Up(0, false)
+		if err != nil {
+			t.Fatalf("Up failed: %v", err)
+		}
+		if len(applied) != 1 || applied[0].Version != extra.Version || !tableExists(t, m, "notes") {
+			t.Fatalf("Expected only %s to be applied, got %v", extra, applied)
+		}
+
+		statuses, err := m.Status()
+		if err != nil {
+			t.Fatalf("Status failed: %v", err)
+		}
+		for _, status := range statuses {
+			if !status.Applied || status.AppliedAt == "" {
+				t.Errorf("Expected %s to be applied, got %+v", status.Migration, status)
+			}
+		}
+
+		if again, err := m.Up(0, false); err != nil || len(again) != 0 {
+			t.Errorf("Expected a second Up to do nothing, got %v, %v", again, err)
+		}
+
+		wouldRollBack, err := m.Down(1, true)
+		if err != nil || len(wouldRollBack) != 1 || !tableExists(t, m, "notes") {
+			t.Fatalf("Expected Down dry run to report %s only, got %v, %v", extra, wouldRollBack, err)
+		}
+
+		rolledBack, err := m.Down(1, false)
+		if err != nil {
+			t.Fatalf("Down failed: %v", err)
+		}
+		if len(rolledBack) != 1 || tableExists(t, m, "notes") || !tableExists(t, m, "snapshots") {
+			t.Errorf("Expected Down to roll back %s only, got %v", extra, rolledBack)
+		}
+
+		statuses, err = m.Status()
+		if err != nil {
+			t.Fatalf("Status failed: %v", err)
+		}
+		if last := statuses[len(statuses)-1]; last.Applied {
+			t.Errorf("Expected %s to be pending after Down", last.Migration)
+		}
+	})
+}
+
+func TestMigrator_FailedMigrationRollsBack(t *testing.T) {
+	forEachMigrator(t, func(t *testing.T, m *Migrator) {
+		if _, err := m.Up(0, false); err != nil {
+			t.Fatalf("Up failed: %v", err)
+		}
+		m.migrations = append(m.migrations, Migration{
+			Version: m.Latest() + 1,
+			Name:    "broken",
+			Up:      "CREATE TABLE half_done (id INTEGER); SELECT * FROM missing_table;",
+			Down:    "DROP TABLE half_done;",
+		})
+
+		if _, err := m.Up(0, false); err == nil {
+			t.Fatal("Expected broken migration to fail")
+		}
+		if tableExists(t, m, "half_done") {
+			t.Error("Expected failed migration to be rolled back")
+		}
+		statuses, err := m.Status()
+		if err != nil {
+			t.Fatalf("Status failed: %v", err)
+		}
+		if statuses[len(statuses)-1].Applied {
+			t.Error("Expected failed migration to stay pending")
+		}
+	})
+}
+
+func TestMigrator_RefusesNewerSchema(t *testing.T) {
+	forEachMigrator(t, func(t *testing.T, m *Migrator) {
+		extra := withTestMigration(m)
+		if _, err := m.Up(0, false); err != nil {
+			t.Fatalf("Up failed: %v", err)
+		}
+
+		// An older binary that doesn't know the extra migration
+		m.migrations = m.migrations[:len(m.migrations)-1]
+		_, err := m.Up(0, false)
+		if err == nil || !strings.Contains(err.Error(), "newer") {
+			t.Errorf("Expected error for schema version %d, got %v", extra.Version, err)
+		}
+	})
+}
+
+func TestNewDB_MigratesExistingDatabase(t *testing.T) {
+	dbPath := "./test_migrate_existing.db"
+	defer os.Remove(dbPath)
+
+	// A database created before migrations existed
+	legacy, err := openSQLite(dbPath)
+	if err != nil {
+		t.Fatalf("openSQLite failed: %v", err)
+	}
+	if _, err := legacy.Exec(`CREATE TABLE snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		ip_address TEXT NOT NULL,
+		timestamp TEXT NOT NULL,
+		data BLOB NOT NULL,
+		UNIQUE(ip_address, timestamp)
+	)`); err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+	if _, err := legacy.Exec(`INSERT INTO snapshots (ip_address, timestamp, data) VALUES ('10.0.0.1', '2025-01-01T00:00:00Z', '{}')`); err != nil {
+		t.Fatalf("Failed to insert legacy row: %v", err)
+	}
+	legacy.Close()
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	history, err := db.GetSnapshotsByIP("10.0.0.1")
+	if err != nil || len(history) != 1 {
+		t.Errorf("Expected legacy snapshot to survive migration, got %d, %v", len(history), err)
+	}
+	var version int
+	if err := db.db.QueryRow("SELECT MAX(version) FROM schema_version").Scan(&version); err != nil || version < 1 {
+		t.Errorf("Expected schema_version to be recorded, got %d, %v", version, err)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	for _, d := range []dialect{dialectSQLite, dialectPostgres} {
+		migrations, err := loadMigrations(migrationFiles, "migrations/"+d.String())
+		if err != nil {
+			t.Fatalf("%s: embedded migrations are invalid: %v", d, err)
+		}
+		for i, m := range migrations {
+			if m.Version != i+1 {
+				t.Errorf("%s: expected migration versions 1..n without gaps, got %s at %d", d, m, i)
+			}
+		}
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"missing down": {
+			"m/0001_init.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"conflicting names": {
+			"m/0001_init.up.sql":    {Data: []byte("SELECT 1;")},
+			"m/0001_other.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"bad file name": {
+			"m/init.sql": {Data: []byte("SELECT 1;")},
+		},
+		"zero version": {
+			"m/0000_init.up.sql":   {Data: []byte("SELECT 1;")},
+			"m/0000_init.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range invalid {
+		if _, err := loadMigrations(fsys, "m"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_ip_timestamp;
+DROP TABLE IF EXISTS snapshots;
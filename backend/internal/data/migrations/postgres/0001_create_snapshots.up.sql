@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS snapshots (
+	id BIGSERIAL PRIMARY KEY,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	data BYTEA NOT NULL,
+	UNIQUE(ip_address, timestamp)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ip_timestamp
+ON snapshots(ip_address, timestamp DESC);
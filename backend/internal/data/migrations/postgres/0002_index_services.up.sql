@@ -0,0 +1,57 @@
+-- One row per service of each snapshot, so snapshots can be searched by port,
+-- software and CVE without decoding every data column. position is the
+-- service's index in the snapshot's services array.
+CREATE TABLE services (
+	snapshot_id BIGINT NOT NULL REFERENCES snapshots(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	port INTEGER NOT NULL,
+	protocol TEXT NOT NULL,
+	vendor TEXT NOT NULL,
+	product TEXT NOT NULL,
+	version TEXT NOT NULL,
+	PRIMARY KEY (snapshot_id, position)
+);
+
+CREATE INDEX idx_services_port ON services(port, protocol);
+CREATE INDEX idx_services_product ON services(product);
+
+CREATE TABLE vulnerabilities (
+	snapshot_id BIGINT NOT NULL,
+	position INTEGER NOT NULL,
+	cve_id TEXT NOT NULL,
+	PRIMARY KEY (snapshot_id, position, cve_id),
+	FOREIGN KEY (snapshot_id, position) REFERENCES services(snapshot_id, position) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_vulnerabilities_cve ON vulnerabilities(cve_id);
+
+-- Backfill existing snapshots the same way data.indexServices does
+INSERT INTO services (snapshot_id, position, ip_address, timestamp, port, protocol, vendor, product, version)
+SELECT s.id, svc.ordinality - 1, s.ip_address, s.timestamp,
+	(svc.value->>'port')::integer,
+	UPPER(COALESCE(svc.value->>'protocol', '')),
+	LOWER(COALESCE(svc.value#>>'{software,vendor}', '')),
+	LOWER(COALESCE(svc.value#>>'{software,product}', '')),
+	COALESCE(svc.value#>>'{software,version}', '')
+FROM snapshots s
+CROSS JOIN LATERAL jsonb_array_elements(
+	CASE WHEN jsonb_typeof(convert_from(s.data, 'UTF8')::jsonb -> 'services') = 'array'
+		THEN convert_from(s.data, 'UTF8')::jsonb -> 'services'
+		ELSE '[]'::jsonb END
+) WITH ORDINALITY AS svc(value, ordinality)
+WHERE jsonb_typeof(svc.value -> 'port') = 'number'
+	AND (svc.value->>'port') ~ '^-?[0-9]+$';
+
+INSERT INTO vulnerabilities (snapshot_id, position, cve_id)
+SELECT sv.snapshot_id, sv.position, UPPER(cve.value #>> '{}')
+FROM services sv
+JOIN snapshots s ON s.id = sv.snapshot_id
+CROSS JOIN LATERAL jsonb_array_elements(
+	CASE WHEN jsonb_typeof(convert_from(s.data, 'UTF8')::jsonb -> 'services' -> sv.position -> 'vulnerabilities') = 'array'
+		THEN convert_from(s.data, 'UTF8')::jsonb -> 'services' -> sv.position -> 'vulnerabilities'
+		ELSE '[]'::jsonb END
+) AS cve(value)
+WHERE jsonb_typeof(cve.value) = 'string'
+ON CONFLICT DO NOTHING;
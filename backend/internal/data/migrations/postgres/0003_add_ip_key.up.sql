@@ -0,0 +1,7 @@
+-- ip_key holds the address as 16 bytes, with IPv4 addresses in their
+-- IPv4-mapped IPv6 form, so that byte order is address order and CIDR blocks
+-- and IP ranges become index range scans. The server fills it in for
+-- existing rows when it starts.
+ALTER TABLE snapshots ADD COLUMN ip_key BYTEA;
+
+CREATE INDEX idx_snapshots_ip_key ON snapshots(ip_key, timestamp);
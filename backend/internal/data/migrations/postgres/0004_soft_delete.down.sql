@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS snapshot_audit;
+
+-- Soft-deleted snapshots can't coexist with the UNIQUE constraint, so they
+-- are purged.
+DELETE FROM snapshots WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_snapshots_live;
+ALTER TABLE snapshots ADD CONSTRAINT snapshots_ip_address_timestamp_key UNIQUE (ip_address, timestamp);
+ALTER TABLE snapshots DROP COLUMN deleted_at;
@@ -0,0 +1,20 @@
+-- deleted_at marks soft-deleted snapshots, which every query skips until
+-- they are restored or purged. Only live snapshots need a unique IP address
+-- and timestamp, so that a deleted upload no longer blocks its replacement.
+ALTER TABLE snapshots ADD COLUMN deleted_at TEXT;
+ALTER TABLE snapshots DROP CONSTRAINT snapshots_ip_address_timestamp_key;
+CREATE UNIQUE INDEX idx_snapshots_live ON snapshots(ip_address, timestamp) WHERE deleted_at IS NULL;
+
+-- One row per delete, restore or purge, kept after the snapshot is purged.
+CREATE TABLE snapshot_audit (
+	id BIGSERIAL PRIMARY KEY,
+	snapshot_id BIGINT NOT NULL,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	action TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	performed_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_snapshot_audit_snapshot ON snapshot_audit(snapshot_id);
@@ -0,0 +1,7 @@
+-- revision numbers the live snapshots that share an IP address and
+-- timestamp, from 0, so that an upload can keep a conflicting snapshot and
+-- be stored next to it.
+ALTER TABLE snapshots ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;
+
+DROP INDEX idx_snapshots_live;
+CREATE UNIQUE INDEX idx_snapshots_live ON snapshots(ip_address, timestamp, revision) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- The server has already copied every snapshot's content back into data
+ALTER TABLE snapshots DROP COLUMN blob_hash;
+ALTER TABLE snapshots ALTER COLUMN data SET NOT NULL;
+DROP TABLE snapshot_blobs;
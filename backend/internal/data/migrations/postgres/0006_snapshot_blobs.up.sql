@@ -0,0 +1,17 @@
+-- Snapshot content is stored once per distinct content in snapshot_blobs,
+-- keyed by the hex SHA-256 of the uncompressed JSON and compressed as
+-- encoding says; size is the uncompressed length. Snapshots refer to their
+-- content by blob_hash, so a rescan that found nothing new stores no content.
+CREATE TABLE snapshot_blobs (
+	hash TEXT PRIMARY KEY,
+	encoding TEXT NOT NULL,
+	size BIGINT NOT NULL,
+	data BYTEA NOT NULL
+);
+
+-- data is only kept for rolling back and is NULL once the content has moved.
+ALTER TABLE snapshots ALTER COLUMN data DROP NOT NULL;
+ALTER TABLE snapshots ADD COLUMN blob_hash TEXT REFERENCES snapshot_blobs(hash);
+CREATE INDEX idx_snapshots_blob_hash ON snapshots(blob_hash);
+
+-- The server then moves the content of existing snapshots into snapshot_blobs
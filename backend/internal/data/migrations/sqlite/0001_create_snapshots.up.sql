@@ -0,0 +1,12 @@
+-- IF NOT EXISTS: databases created before migrations existed already have
+-- this schema and only need to be marked as version 1.
+CREATE TABLE IF NOT EXISTS snapshots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	data BLOB NOT NULL,
+	UNIQUE(ip_address, timestamp)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ip_timestamp
+ON snapshots(ip_address, timestamp DESC);
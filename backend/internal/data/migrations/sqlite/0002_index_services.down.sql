@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vulnerabilities;
+DROP TABLE IF EXISTS services;
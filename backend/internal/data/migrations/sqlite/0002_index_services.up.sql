@@ -0,0 +1,49 @@
+-- One row per service of each snapshot, so snapshots can be searched by port,
+-- software and CVE without decoding every data column. position is the
+-- service's index in the snapshot's services array. SQLite runs without
+-- foreign key enforcement, so there are no cascades: data.unindexServices
+-- deletes a snapshot's rows from both tables.
+CREATE TABLE services (
+	snapshot_id INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	port INTEGER NOT NULL,
+	protocol TEXT NOT NULL,
+	vendor TEXT NOT NULL,
+	product TEXT NOT NULL,
+	version TEXT NOT NULL,
+	PRIMARY KEY (snapshot_id, position)
+);
+
+CREATE INDEX idx_services_port ON services(port, protocol);
+CREATE INDEX idx_services_product ON services(product);
+
+CREATE TABLE vulnerabilities (
+	snapshot_id INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	cve_id TEXT NOT NULL,
+	PRIMARY KEY (snapshot_id, position, cve_id)
+);
+
+CREATE INDEX idx_vulnerabilities_cve ON vulnerabilities(cve_id);
+
+-- Backfill existing snapshots the same way data.indexServices does
+INSERT INTO services (snapshot_id, position, ip_address, timestamp, port, protocol, vendor, product, version)
+SELECT s.id, svc.key, s.ip_address, s.timestamp,
+	json_extract(svc.value, '$.port'),
+	UPPER(COALESCE(json_extract(svc.value, '$.protocol'), '')),
+	LOWER(COALESCE(json_extract(svc.value, '$.software.vendor'), '')),
+	LOWER(COALESCE(json_extract(svc.value, '$.software.product'), '')),
+	COALESCE(json_extract(svc.value, '$.software.version'), '')
+FROM snapshots s, json_each(s.data, '$.services') svc
+WHERE json_valid(s.data)
+	AND json_type(s.data, '$.services') = 'array'
+	AND json_type(svc.value, '$.port') = 'integer';
+
+INSERT OR IGNORE INTO vulnerabilities (snapshot_id, position, cve_id)
+SELECT sv.snapshot_id, sv.position, UPPER(cve.value)
+FROM services sv
+JOIN snapshots s ON s.id = sv.snapshot_id,
+	json_each(s.data, '$.services[' || sv.position || '].vulnerabilities') cve
+WHERE cve.type = 'text';
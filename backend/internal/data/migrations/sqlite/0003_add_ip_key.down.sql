@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_snapshots_ip_key;
+ALTER TABLE snapshots DROP COLUMN ip_key;
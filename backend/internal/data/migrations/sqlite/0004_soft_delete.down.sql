@@ -0,0 +1,25 @@
+DROP TABLE IF EXISTS snapshot_audit;
+
+-- Soft-deleted snapshots can't coexist with the UNIQUE constraint, so they
+-- are purged.
+DELETE FROM vulnerabilities WHERE snapshot_id IN (SELECT id FROM snapshots WHERE deleted_at IS NOT NULL);
+DELETE FROM services WHERE snapshot_id IN (SELECT id FROM snapshots WHERE deleted_at IS NOT NULL);
+DELETE FROM snapshots WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE snapshots_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	data BLOB NOT NULL,
+	ip_key BLOB,
+	UNIQUE(ip_address, timestamp)
+);
+
+INSERT INTO snapshots_old (id, ip_address, timestamp, data, ip_key)
+SELECT id, ip_address, timestamp, data, ip_key FROM snapshots;
+
+DROP TABLE snapshots;
+ALTER TABLE snapshots_old RENAME TO snapshots;
+
+CREATE INDEX idx_ip_timestamp ON snapshots(ip_address, timestamp DESC);
+CREATE INDEX idx_snapshots_ip_key ON snapshots(ip_key, timestamp);
@@ -0,0 +1,37 @@
+-- deleted_at marks soft-deleted snapshots, which every query skips until
+-- they are restored or purged. Only live snapshots need a unique IP address
+-- and timestamp, so that a deleted upload no longer blocks its replacement.
+-- SQLite can't drop the table's UNIQUE constraint, so the table is rebuilt
+-- with a partial unique index instead.
+CREATE TABLE snapshots_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	data BLOB NOT NULL,
+	ip_key BLOB,
+	deleted_at TEXT
+);
+
+INSERT INTO snapshots_new (id, ip_address, timestamp, data, ip_key)
+SELECT id, ip_address, timestamp, data, ip_key FROM snapshots;
+
+DROP TABLE snapshots;
+ALTER TABLE snapshots_new RENAME TO snapshots;
+
+CREATE UNIQUE INDEX idx_snapshots_live ON snapshots(ip_address, timestamp) WHERE deleted_at IS NULL;
+CREATE INDEX idx_ip_timestamp ON snapshots(ip_address, timestamp DESC);
+CREATE INDEX idx_snapshots_ip_key ON snapshots(ip_key, timestamp);
+
+-- One row per delete, restore or purge, kept after the snapshot is purged.
+CREATE TABLE snapshot_audit (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	snapshot_id INTEGER NOT NULL,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	action TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	performed_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_snapshot_audit_snapshot ON snapshot_audit(snapshot_id);
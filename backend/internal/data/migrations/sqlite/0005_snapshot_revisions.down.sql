@@ -0,0 +1,13 @@
+-- Only the newest revision of each IP address and timestamp stays live.
+UPDATE snapshots SET deleted_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE deleted_at IS NULL AND EXISTS (
+	SELECT 1 FROM snapshots newer
+	WHERE newer.ip_address = snapshots.ip_address AND newer.timestamp = snapshots.timestamp
+	AND newer.deleted_at IS NULL AND newer.revision > snapshots.revision
+);
+DELETE FROM vulnerabilities WHERE snapshot_id IN (SELECT id FROM snapshots WHERE deleted_at IS NOT NULL);
+DELETE FROM services WHERE snapshot_id IN (SELECT id FROM snapshots WHERE deleted_at IS NOT NULL);
+
+DROP INDEX idx_snapshots_live;
+CREATE UNIQUE INDEX idx_snapshots_live ON snapshots(ip_address, timestamp) WHERE deleted_at IS NULL;
+ALTER TABLE snapshots DROP COLUMN revision;
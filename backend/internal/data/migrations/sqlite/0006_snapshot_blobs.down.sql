@@ -0,0 +1,22 @@
+-- The server has already copied every snapshot's content back into data
+CREATE TABLE snapshots_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	data BLOB NOT NULL,
+	ip_key BLOB,
+	deleted_at TEXT,
+	revision INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO snapshots_new (id, ip_address, timestamp, data, ip_key, deleted_at, revision)
+SELECT id, ip_address, timestamp, data, ip_key, deleted_at, revision FROM snapshots;
+
+DROP TABLE snapshots;
+ALTER TABLE snapshots_new RENAME TO snapshots;
+
+CREATE UNIQUE INDEX idx_snapshots_live ON snapshots(ip_address, timestamp, revision) WHERE deleted_at IS NULL;
+CREATE INDEX idx_ip_timestamp ON snapshots(ip_address, timestamp DESC);
+CREATE INDEX idx_snapshots_ip_key ON snapshots(ip_key, timestamp);
+
+DROP TABLE snapshot_blobs;
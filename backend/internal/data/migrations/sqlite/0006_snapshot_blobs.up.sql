@@ -0,0 +1,36 @@
+-- Snapshot content is stored once per distinct content in snapshot_blobs,
+-- keyed by the hex SHA-256 of the uncompressed JSON and compressed as
+-- encoding says; size is the uncompressed length. Snapshots refer to their
+-- content by blob_hash, so a rescan that found nothing new stores no content.
+CREATE TABLE snapshot_blobs (
+	hash TEXT PRIMARY KEY,
+	encoding TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	data BLOB NOT NULL
+);
+
+-- data is only kept for rolling back and is NULL once the content has moved.
+-- SQLite can't drop its NOT NULL constraint, so the table is rebuilt.
+CREATE TABLE snapshots_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ip_address TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	data BLOB,
+	ip_key BLOB,
+	deleted_at TEXT,
+	revision INTEGER NOT NULL DEFAULT 0,
+	blob_hash TEXT REFERENCES snapshot_blobs(hash)
+);
+
+INSERT INTO snapshots_new (id, ip_address, timestamp, data, ip_key, deleted_at, revision)
+SELECT id, ip_address, timestamp, data, ip_key, deleted_at, revision FROM snapshots;
+
+DROP TABLE snapshots;
+ALTER TABLE snapshots_new RENAME TO snapshots;
+
+CREATE UNIQUE INDEX idx_snapshots_live ON snapshots(ip_address, timestamp, revision) WHERE deleted_at IS NULL;
+CREATE INDEX idx_ip_timestamp ON snapshots(ip_address, timestamp DESC);
+CREATE INDEX idx_snapshots_ip_key ON snapshots(ip_key, timestamp);
+CREATE INDEX idx_snapshots_blob_hash ON snapshots(blob_hash);
+
+-- The server then moves the content of existing snapshots into snapshot_blobs
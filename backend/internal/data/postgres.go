@@ -0,0 +1,57 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresDB is the PostgreSQL Store. Unlike SQLite it serves uploads and
+// queries over a pool of connections.
+type PostgresDB struct {
+	sqlStore
+}
+
+// NewPostgresDB connects to PostgreSQL using a postgres:// URL and migrates
+// its schema to the latest version.
+func NewPostgresDB(dsn string) (*PostgresDB, error) {
+	db, err := openPostgres(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := newMigrator(db, dialectPostgres)
+	if err == nil {
+		_, err = migrator.Up(0, false)
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	store := &PostgresDB{sqlStore{db: db, dialect: dialectPostgres}}
+	if err := store.fillIPKeys(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// openPostgres opens a connection pool and checks that the server is reachable.
+func openPostgres(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db.SetMaxOpenConns(20)
+	db.SetMaxIdleConns(5)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,255 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ServiceRecord is one service of a stored snapshot, as indexed in the
+// services and vulnerabilities tables. Protocol and CVE IDs are upper case,
+// vendor and product lower case.
+type ServiceRecord struct {
+	SnapshotID string
+	Position   int // Index in the snapshot's services array
+	IPAddress  string
+	Timestamp  string
+	Port       int
+	Protocol   string
+	Vendor     string
+	Product    string
+	Version    string
+	CVEs       []string
+}
+
+func (r *ServiceRecord) cursor() ServiceCursor {
+	id, _ := strconv.ParseInt(r.SnapshotID, 10, 64)
+	return ServiceCursor{SnapshotID: id, Position: r.Position}
+}
+
+// ServiceCursor marks the position after which QueryServices continues.
+type ServiceCursor struct {
+	SnapshotID int64
+	Position   int
+}
+
+// ServiceQuery selects services across all snapshots. Zero values match
+// anything; string filters are case-insensitive.
+type ServiceQuery struct {
+	Port     int
+	Protocol string
+	Product  string
+	CVE      string
+	// LatestOnly restricts the search to each host's most recent snapshot.
+	LatestOnly bool
+	// Match, if set, filters the records the SQL filters selected, e.g. by
+	// version range. CVEs are not yet filled in when it is called.
+	Match func(*ServiceRecord) bool
+	Limit int
+	After *ServiceCursor
+}
+
+// indexedService is the part of a service's JSON that is indexed.
+type indexedService struct {
+	Port     *int   `json:"port"`
+	Protocol string `json:"protocol"`
+	Software struct {
+		Vendor  string `json:"vendor"`
+		Product string `json:"product"`
+		Version string `json:"version"`
+	} `json:"software"`
+	Vulnerabilities []interface{} `json:"vulnerabilities"`
+}
+
+// indexServices records a snapshot's services and their CVEs. Content that
+// doesn't decode as a snapshot, and services without a numeric port, are
+// left out of the index; the snapshot itself is still stored.
+func (s *sqlStore) indexServices(q queryer, snapshotID, ipAddress, timestamp string, data []byte) error {
+	var doc struct {
+		Services []json.RawMessage `json:"services"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	for position, raw := range doc.Services {
+		var svc indexedService
+		if err := json.Unmarshal(raw, &svc); err != nil || svc.Port == nil {
+			continue
+		}
+
+		if _, err := q.Exec(
+			s.dialect.rebind("INSERT INTO services (snapshot_id, position, ip_address, timestamp, port, protocol, vendor, product, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+			snapshotID,
+			position,
+			ipAddress,
+			timestamp,
+			*svc.Port,
+			strings.ToUpper(svc.Protocol),
+			strings.ToLower(svc.Software.Vendor),
+			strings.ToLower(svc.Software.Product),
+			svc.Software.Version,
+		); err != nil {
+			return fmt.Errorf("failed to index service on port %d: %w", *svc.Port, err)
+		}
+
+		seen := make(map[string]bool)
+		for _, v := range svc.Vulnerabilities {
+			cve, ok := v.(string)
+			if !ok || seen[strings.ToUpper(cve)] {
+				continue
+			}
+			seen[strings.ToUpper(cve)] = true
+			if _, err := q.Exec(
+				s.dialect.rebind("INSERT INTO vulnerabilities (snapshot_id, position, cve_id) VALUES (?, ?, ?)"),
+				snapshotID,
+				position,
+				strings.ToUpper(cve),
+			); err != nil {
+				return fmt.Errorf("failed to index %s: %w", cve, err)
+			}
+		}
+	}
+	return nil
+}
+
+// QueryServices returns up to q.Limit services matching q, ordered by
+// snapshot and position, and the cursor for the next page, or nil if there
+// are no more matches.
+func (s *sqlStore) QueryServices(q ServiceQuery) ([]*ServiceRecord, *ServiceCursor, error) {
+	if q.Limit <= 0 {
+		return nil, nil, fmt.Errorf("invalid limit %d", q.Limit)
+	}
+
+	query := "SELECT sv.snapshot_id, sv.position, sv.ip_address, sv.timestamp, sv.port, sv.protocol, sv.vendor, sv.product, sv.version FROM services sv WHERE (sv.snapshot_id > ? OR (sv.snapshot_id = ? AND sv.position > ?))"
+	var filterArgs []interface{}
+	if q.Port != 0 {
+		query += " AND sv.port = ?"
+		filterArgs = append(filterArgs, q.Port)
+	}
+	if q.Protocol != "" {
+		query += " AND sv.protocol = ?"
+		filterArgs = append(filterArgs, strings.ToUpper(q.Protocol))
+	}
+	if q.Product != "" {
+		query += " AND sv.product = ?"
+		filterArgs = append(filterArgs, strings.ToLower(q.Product))
+	}
+	if q.CVE != "" {
+		query += " AND EXISTS (SELECT 1 FROM vulnerabilities v WHERE v.snapshot_id = sv.snapshot_id AND v.position = sv.position AND v.cve_id = ?)"
+		filterArgs = append(filterArgs, strings.ToUpper(q.CVE))
+	}
+	if q.LatestOnly {
+		query += " AND sv.snapshot_id = (" + latestSnapshotQuery("sv", "") + ")"
+	}
+	query += " ORDER BY sv.snapshot_id, sv.position LIMIT ?"
+	query = s.dialect.rebind(query)
+
+	// Read batches until one more match than requested turns up, so we know
+	// whether there is a next page. Match may reject rows, so this can take
+	// more than one batch.
+	after := ServiceCursor{}
+	if q.After != nil {
+		after = *q.After
+	}
+	var records []*ServiceRecord
+	for len(records) <= q.Limit {
+		args := append([]interface{}{after.SnapshotID, after.SnapshotID, after.Position}, filterArgs...)
+		args = append(args, q.Limit+1)
+		batch, err := s.queryServiceBatch(query, args)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, rec := range batch {
+			if q.Match == nil || q.Match(rec) {
+				records = append(records, rec)
+			}
+		}
+		if len(batch) < q.Limit+1 {
+			break
+		}
+		after = batch[len(batch)-1].cursor()
+	}
+
+	var next *ServiceCursor
+	if len(records) > q.Limit {
+		records = records[:q.Limit]
+		cursor := records[len(records)-1].cursor()
+		next = &cursor
+	}
+
+	if err := s.loadCVEs(records); err != nil {
+		return nil, nil, err
+	}
+	return records, next, nil
+}
+
+func (s *sqlStore) queryServiceBatch(query string, args []interface{}) ([]*ServiceRecord, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query services: %w", err)
+	}
+	defer rows.Close()
+
+	var batch []*ServiceRecord
+	for rows.Next() {
+		var rec ServiceRecord
+		if err := rows.Scan(&rec.SnapshotID, &rec.Position, &rec.IPAddress, &rec.Timestamp, &rec.Port,
+			&rec.Protocol, &rec.Vendor, &rec.Product, &rec.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan service row: %w", err)
+		}
+		batch = append(batch, &rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate service rows: %w", err)
+	}
+	return batch, nil
+}
+
+// loadCVEs fills in the CVEs of the given services.
+func (s *sqlStore) loadCVEs(records []*ServiceRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	type key struct {
+		snapshotID string
+		position   int
+	}
+	byKey := make(map[key]*ServiceRecord, len(records))
+	seen := make(map[string]bool)
+	var ids []interface{}
+	for _, rec := range records {
+		byKey[key{rec.SnapshotID, rec.Position}] = rec
+		if !seen[rec.SnapshotID] {
+			seen[rec.SnapshotID] = true
+			ids = append(ids, rec.SnapshotID)
+		}
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	rows, err := s.db.Query(
+		s.dialect.rebind("SELECT snapshot_id, position, cve_id FROM vulnerabilities WHERE snapshot_id IN ("+placeholders+") ORDER BY cve_id"),
+		ids...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query vulnerabilities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var k key
+		var cve string
+		if err := rows.Scan(&k.snapshotID, &k.position, &cve); err != nil {
+			return fmt.Errorf("failed to scan vulnerability row: %w", err)
+		}
+		if rec := byKey[k]; rec != nil {
+			rec.CVEs = append(rec.CVEs, cve)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate vulnerability rows: %w", err)
+	}
+	return nil
+}
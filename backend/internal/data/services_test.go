@@ -0,0 +1,189 @@
+package data
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	servicesHostA1 = `{"services": [
+		{"port": 22, "protocol": "SSH", "software": {"vendor": "OpenBSD", "product": "OpenSSH", "version": "8.9p1"}},
+		{"port": 3389, "protocol": "rdp", "vulnerabilities": ["CVE-2019-0708", "cve-2019-0708"]}
+	]}`
+	servicesHostA2 = `{"services": [
+		{"port": 22, "protocol": "SSH", "software": {"product": "openssh", "version": "9.6p1"}, "vulnerabilities": ["CVE-2024-6387"]},
+		{"port": "bad"},
+		{"protocol": "HTTP"}
+	]}`
+	servicesHostB = `{"services": [
+		{"port": 2222, "protocol": "SSH", "software": {"product": "openssh", "version": "7.4p1"}, "vulnerabilities": ["CVE-2024-6387", 17]}
+	]}`
+)
+
+func insertServiceFixtures(t *testing.T, store Store) {
+	t.Helper()
+	fixtures := []NewSnapshot{
+		{IPAddress: "10.0.0.1", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(servicesHostA1)},
+		{IPAddress: "10.0.0.1", Timestamp: "2025-01-02T00:00:00Z", Data: []byte(servicesHostA2)},
+		{IPAddress: "10.0.0.2", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(servicesHostB)},
+		{IPAddress: "10.0.0.3", Timestamp: "2025-01-01T00:00:00Z", Data: []byte(`{"services": "none"}`)},
+	}
+	for _, f := range fixtures {
+		if _, err := store.InsertSnapshot(f.IPAddress, f.Timestamp, f.Data); err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+	}
+}
+
+// describe summarizes query results as "ip:protocol/cves" entries.
+func describe(records []*ServiceRecord) string {
+	var parts []string
+	for _, rec := range records {
+		parts = append(parts, rec.IPAddress+":"+rec.Protocol+"/"+strings.Join(rec.CVEs, "+"))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestStore_QueryServices(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		insertServiceFixtures(t, store)
+
+		tests := []struct {
+			name  string
+			query ServiceQuery
+			want  string
+		}{
+			{"all indexed", ServiceQuery{}, "10.0.0.1:SSH/ 10.0.0.1:RDP/CVE-2019-0708 10.0.0.1:SSH/CVE-2024-6387 10.0.0.2:SSH/CVE-2024-6387"},
+			{"port", ServiceQuery{Port: 3389}, "10.0.0.1:RDP/CVE-2019-0708"},
+			{"protocol", ServiceQuery{Protocol: "Rdp"}, "10.0.0.1:RDP/CVE-2019-0708"},
+			{"product", ServiceQuery{Product: "OPENSSH"}, "10.0.0.1:SSH/ 10.0.0.1:SSH/CVE-2024-6387 10.0.0.2:SSH/CVE-2024-6387"},
+			{"cve", ServiceQuery{CVE: "cve-2024-6387"}, "10.0.0.1:SSH/CVE-2024-6387 10.0.0.2:SSH/CVE-2024-6387"},
+			{"latest only", ServiceQuery{Port: 3389, LatestOnly: true}, ""},
+			{"latest ssh", ServiceQuery{Protocol: "ssh", LatestOnly: true}, "10.0.0.1:SSH/CVE-2024-6387 10.0.0.2:SSH/CVE-2024-6387"},
+			{"match", ServiceQuery{Match: func(r *ServiceRecord) bool { return r.Port == 2222 }}, "10.0.0.2:SSH/CVE-2024-6387"},
+		}
+		for _, tt := range tests {
+			tt.query.Limit = 10
+			records, next, err := store.QueryServices(tt.query)
+			if err != nil {
+				t.Fatalf("%s: QueryServices failed: %v", tt.name, err)
+			}
+			if got := describe(records); got != tt.want {
+				t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+			}
+			if next != nil {
+				t.Errorf("%s: expected no next page", tt.name)
+			}
+		}
+
+		records, _, err := store.QueryServices(ServiceQuery{Port: 22, Limit: 10})
+		if err != nil || len(records) != 2 {
+			t.Fatalf("Expected 2 services on port 22, got %d, %v", len(records), err)
+		}
+		if rec := records[0]; rec.Vendor != "openbsd" || rec.Product != "openssh" || rec.Version != "8.9p1" || rec.Timestamp != "2025-01-01T00:00:00Z" {
+			t.Errorf("Unexpected record: %+v", rec)
+		}
+	})
+}
+
+func TestStore_QueryServicesPagination(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		insertServiceFixtures(t, store)
+
+		var pages []string
+		q := ServiceQuery{Limit: 1}
+		for i := 0; i < 10; i++ {
+			records, next, err := store.QueryServices(q)
+			if err != nil {
+				t.Fatalf("QueryServices failed: %v", err)
+			}
+			pages = append(pages, describe(records))
+			if next == nil {
+				break
+			}
+			q.After = next
+		}
+		if got := strings.Join(pages, " | "); got != "10.0.0.1:SSH/ | 10.0.0.1:RDP/CVE-2019-0708 | 10.0.0.1:SSH/CVE-2024-6387 | 10.0.0.2:SSH/CVE-2024-6387" {
+			t.Errorf("Unexpected pages: %s", got)
+		}
+
+		// Rejecting the records in between makes each page span two batches
+		ssh := func(r *ServiceRecord) bool { return r.Protocol == "SSH" && r.Version != "9.6p1" }
+		records, next, err := store.QueryServices(ServiceQuery{Limit: 1, Match: ssh})
+		if err != nil {
+			t.Fatalf("QueryServices failed: %v", err)
+		}
+		if len(records) != 1 || next == nil {
+			t.Fatalf("Expected a first page and a cursor, got %d, %v", len(records), next)
+		}
+		records, next, err = store.QueryServices(ServiceQuery{Limit: 1, Match: ssh, After: next})
+		if err != nil {
+			t.Fatalf("QueryServices failed: %v", err)
+		}
+		if got := describe(records); got != "10.0.0.2:SSH/CVE-2024-6387" || next != nil {
+			t.Errorf("Unexpected second page %q, next %v", got, next)
+		}
+
+		if _, _, err := store.QueryServices(ServiceQuery{}); err == nil {
+			t.Error("Expected error for missing limit")
+		}
+	})
+}
+
+func TestNewDB_BackfillsServiceIndex(t *testing.T) {
+	dbPath := "./test_backfill_services.db"
+	defer os.Remove(dbPath)
+
+	// A database at version 1, before services were indexed
+	m, err := OpenMigrator(dbPath)
+	if err != nil {
+		t.Fatalf("OpenMigrator failed: %v", err)
+	}
+	if _, err := m.Up(1, false); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	for i, content := range []string{servicesHostA1, servicesHostA2, servicesHostB, `{"services": "none"}`} {
+		if _, err := m.db.Exec("INSERT INTO snapshots (ip_address, timestamp, data) VALUES (?, ?, ?)",
+			[]string{"10.0.0.1", "10.0.0.1", "10.0.0.2", "10.0.0.3"}[i],
+			[]string{"2025-01-01T00:00:00Z", "2025-01-02T00:00:00Z", "2025-01-01T00:00:00Z", "2025-01-01T00:00:00Z"}[i],
+			content); err != nil {
+			t.Fatalf("Failed to insert legacy row: %v", err)
+		}
+	}
+	m.Close()
+
+	backfilled, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer backfilled.Close()
+
+	indexed, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer indexed.Close()
+	insertServiceFixtures(t, indexed)
+
+	// The backfill must index exactly what inserting the snapshots would have
+	for _, q := range []ServiceQuery{{}, {CVE: "CVE-2019-0708"}, {Product: "openssh"}} {
+		q.Limit = 10
+		want, _, err := indexed.QueryServices(q)
+		if err != nil {
+			t.Fatalf("QueryServices failed: %v", err)
+		}
+		got, _, err := backfilled.QueryServices(q)
+		if err != nil {
+			t.Fatalf("QueryServices failed: %v", err)
+		}
+		if describe(got) != describe(want) {
+			t.Errorf("Backfill differs for %+v:\n got %s\nwant %s", q, describe(got), describe(want))
+		}
+		for i := range got {
+			if i < len(want) && (got[i].Position != want[i].Position || got[i].Vendor != want[i].Vendor || got[i].Version != want[i].Version) {
+				t.Errorf("Backfilled record %+v differs from %+v", got[i], want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,463 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Snapshot represents a host snapshot stored in the database.
+type Snapshot struct {
+	ID        string
+	IPAddress string
+	Timestamp string
+	// Revision tells apart live snapshots with the same IP address and
+	// timestamp, which StoreSnapshot keeps with ConflictKeepBoth.
+	Revision int
+	// ContentHash is the SHA-256 of Data. Only ListSnapshots sets it, so that
+	// callers can tell whether snapshots differ without loading them.
+	ContentHash string
+	Data        []byte
+}
+
+// NewSnapshot is a snapshot to be stored by InsertSnapshots.
+type NewSnapshot struct {
+	IPAddress string
+	Timestamp string
+	Data      []byte
+}
+
+// InsertOutcome reports what happened to one snapshot of a batch.
+type InsertOutcome struct {
+	// ID of the inserted snapshot, or of the existing one for a duplicate.
+	ID        string
+	Duplicate bool
+	Err       error
+}
+
+// Store is the snapshot storage used by the server. DB implements it on
+// SQLite and PostgresDB on PostgreSQL.
+type Store interface {
+	// InsertSnapshot inserts a new snapshot and returns its ID.
+	InsertSnapshot(ipAddress, timestamp string, data []byte) (string, error)
+	// StoreSnapshot stores a snapshot, applying policy if a different one is
+	// already stored for its IP address and timestamp.
+	StoreSnapshot(snap NewSnapshot, policy ConflictPolicy, audit Audit) (*StoreResult, error)
+	// InsertSnapshots stores a batch of snapshots, reporting duplicates
+	// instead of failing on them.
+	InsertSnapshots(snapshots []NewSnapshot, atomic bool) ([]InsertOutcome, error)
+	// GetSnapshotsByIP returns a host's snapshots, newest first.
+	GetSnapshotsByIP(ipAddress string) ([]*Snapshot, error)
+	// GetSnapshotsByIPRange returns the snapshots of every host in a range,
+	// newest first.
+	GetSnapshotsByIPRange(r IPRange) ([]*Snapshot, error)
+	// ListSnapshots pages through snapshot metadata without reading the
+	// snapshots' content.
+	ListSnapshots(q HistoryQuery) ([]*Snapshot, *HistoryCursor, error)
+	// GetSnapshotByID returns a snapshot, or nil if there is none with that ID.
+	GetSnapshotByID(id string) (*Snapshot, error)
+	// GetSnapshotsInRange returns a host's snapshots in a time range, oldest first.
+	GetSnapshotsInRange(ipAddress, start, end string) ([]*Snapshot, error)
+	// QueryServices searches the services of all stored snapshots.
+	QueryServices(q ServiceQuery) ([]*ServiceRecord, *ServiceCursor, error)
+	// ListHosts lists the hosts that have snapshots.
+	ListHosts(q HostQuery) ([]*HostSummary, *HostCursor, error)
+	// QueryFleet returns the newest snapshot of each host in a range.
+	QueryFleet(q FleetQuery) ([]*Snapshot, string, error)
+	// DeleteSnapshot soft-deletes a snapshot, hiding it from every query.
+	DeleteSnapshot(id string, audit Audit) (*Snapshot, error)
+	// RestoreSnapshot brings back a soft-deleted snapshot.
+	RestoreSnapshot(id string, audit Audit) (*Snapshot, error)
+	// PurgeDeleted permanently removes soft-deleted snapshots.
+	PurgeDeleted(deletedBefore string, audit Audit) ([]*Snapshot, error)
+	// PruneSnapshots permanently removes live snapshots.
+	PruneSnapshots(ids []string, audit Audit) ([]*Snapshot, error)
+	Close() error
+}
+
+// Open opens the Store for a data source name. postgres:// and postgresql://
+// URLs select PostgreSQL; anything else is treated as an SQLite database path.
+func Open(dsn string) (Store, error) {
+	if isPostgresDSN(dsn) {
+		return NewPostgresDB(dsn)
+	}
+	return NewDB(trimSQLiteScheme(dsn))
+}
+
+func isPostgresDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
+
+// trimSQLiteScheme turns an sqlite:// DSN into the path the driver expects.
+func trimSQLiteScheme(dsn string) string {
+	return strings.TrimPrefix(dsn, "sqlite://")
+}
+
+// dialect covers the differences between the SQL databases a sqlStore runs on.
+type dialect int
+
+const (
+	dialectSQLite dialect = iota
+	dialectPostgres
+)
+
+// String returns the dialect's name, which is also its migrations directory.
+func (d dialect) String() string {
+	if d == dialectPostgres {
+		return "postgres"
+	}
+	return "sqlite"
+}
+
+// tableExistsQuery returns a query counting the tables with the name given
+// as its only argument.
+func (d dialect) tableExistsQuery() string {
+	if d == dialectPostgres {
+		return "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+	}
+	return "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+}
+
+// rebind rewrites the ? placeholders in a query into the dialect's syntax.
+func (d dialect) rebind(query string) string {
+	if d != dialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// blob converts snapshot content into the value stored in the snapshots
+// table's data column, which held it before snapshot_blobs existed. SQLite
+// stored it as text; PostgreSQL as bytea.
+func (d dialect) blob(data []byte) interface{} {
+	if d == dialectSQLite {
+		return string(data)
+	}
+	return data
+}
+
+// sqlStore implements Store on top of database/sql. Queries are written with
+// ? placeholders and rebound for the dialect.
+type sqlStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// queryer is the subset of *sql.Tx used to insert snapshots.
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Close closes the database connection.
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// selectSnapshots selects the columns scanSnapshots reads: a snapshot with
+// its content from snapshot_blobs.
+const selectSnapshots = "SELECT snapshots.id, ip_address, timestamp, revision, snapshot_blobs.encoding, snapshot_blobs.data FROM snapshots JOIN snapshot_blobs ON snapshot_blobs.hash = snapshots.blob_hash"
+
+// InsertSnapshot inserts a new snapshot into the database, together with
+// its services index. The IP address is stored in canonical form.
+func (s *sqlStore) InsertSnapshot(ipAddress, timestamp string, data []byte) (string, error) {
+	ipAddress = canonicalIP(ipAddress)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, err := s.insertRow(tx, NewSnapshot{IPAddress: ipAddress, Timestamp: timestamp, Data: data}, 0)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	return id, nil
+}
+
+// InsertSnapshots stores a batch of snapshots and returns one outcome per
+// snapshot. A snapshot whose content is already stored for its IP address
+// and timestamp, including earlier in the same batch, is reported as a
+// duplicate of the existing row instead of an error. One whose content
+// differs from what is stored gets a *ConflictError in its outcome.
+//
+// With atomic set the batch is inserted in a single transaction. A conflict
+// rolls back the whole batch: the outcomes then hold only the conflicts'
+// errors. Any other failure rolls back the batch and is returned as the
+// error. Without atomic every snapshot is committed on its own and failures
+// are reported in its outcome.
+func (s *sqlStore) InsertSnapshots(snapshots []NewSnapshot, atomic bool) ([]InsertOutcome, error) {
+	outcomes := make([]InsertOutcome, len(snapshots))
+	if !atomic {
+		for i, snap := range snapshots {
+			outcomes[i].ID, outcomes[i].Duplicate, outcomes[i].Err = s.insertOne(snap)
+		}
+		return outcomes, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	conflicts := false
+	for i, snap := range snapshots {
+		id, duplicate, err := s.insertOrFind(tx, snap)
+		var conflict *ConflictError
+		if errors.As(err, &conflict) {
+			outcomes[i].Err = err
+			conflicts = true
+			continue
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("snapshot %s at %s: %w", snap.IPAddress, snap.Timestamp, err)
+		}
+		outcomes[i] = InsertOutcome{ID: id, Duplicate: duplicate}
+	}
+	if conflicts {
+		tx.Rollback()
+		for i := range outcomes {
+			if outcomes[i].Err == nil {
+				outcomes[i] = InsertOutcome{}
+			}
+		}
+		return outcomes, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return outcomes, nil
+}
+
+// insertOne runs insertOrFind in a transaction of its own.
+func (s *sqlStore) insertOne(snap NewSnapshot) (string, bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, duplicate, err := s.insertOrFind(tx, snap)
+	if err != nil {
+		return "", false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", false, fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	return id, duplicate, nil
+}
+
+// insertOrFind inserts a snapshot unless its content is already stored as
+// a live snapshot of its IP address and timestamp, in which case it returns
+// the existing ID. It returns a *ConflictError if different content is.
+func (s *sqlStore) insertOrFind(q queryer, snap NewSnapshot) (string, bool, error) {
+	snap.IPAddress = canonicalIP(snap.IPAddress)
+
+	id, err := s.findStored(q, snap)
+	if err != nil || id != "" {
+		return id, id != "", err
+	}
+
+	id, err = s.insertRow(q, snap, 0)
+	if errors.Is(err, errRevisionTaken) {
+		// A concurrent upload inserted it after the lookup above
+		if id, err = s.findStored(q, snap); err == nil && id == "" {
+			err = fmt.Errorf("failed to look up existing snapshot of %s at %s", snap.IPAddress, snap.Timestamp)
+		}
+		return id, id != "", err
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return id, false, nil
+}
+
+// findStored returns the ID of the live snapshot of snap's IP address and
+// timestamp with the same content, or "" if there is none. It returns a
+// *ConflictError naming the newest revision if only different content is
+// stored.
+func (s *sqlStore) findStored(q queryer, snap NewSnapshot) (string, error) {
+	existing, err := s.liveRevisions(q, snap.IPAddress, snap.Timestamp)
+	if err != nil || len(existing) == 0 {
+		return "", err
+	}
+	hash := contentHash(snap.Data)
+	for _, r := range existing {
+		if r.hash == hash {
+			return r.id, nil
+		}
+	}
+	return "", &ConflictError{IPAddress: snap.IPAddress, Timestamp: snap.Timestamp, ExistingID: existing[len(existing)-1].id}
+}
+
+// errRevisionTaken is returned by insertRow when a live snapshot with the
+// same IP address, timestamp and revision is already stored.
+var errRevisionTaken = errors.New("a live snapshot with this IP address, timestamp and revision is already stored")
+
+// insertRow stores a snapshot's content in snapshot_blobs, unless identical
+// content is already there, then inserts the snapshot and indexes its
+// services. The IP address must already be canonical.
+//
+// The insert doesn't fail on the unique index of live snapshots, which would
+// abort a Postgres transaction, but returns errRevisionTaken, so that callers
+// that raced a concurrent upload can look it up or retry.
+func (s *sqlStore) insertRow(q queryer, snap NewSnapshot, revision int) (string, error) {
+	hash, err := s.putBlob(q, snap.Data)
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	err = q.QueryRow(
+		s.dialect.rebind("INSERT INTO snapshots (ip_address, ip_key, timestamp, revision, blob_hash) VALUES (?, ?, ?, ?, ?) ON CONFLICT (ip_address, timestamp, revision) WHERE deleted_at IS NULL DO NOTHING RETURNING id"),
+		snap.IPAddress,
+		ipKey(snap.IPAddress),
+		snap.Timestamp,
+		revision,
+		hash,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := s.deleteUnusedBlob(q, hash); err != nil {
+			return "", err
+		}
+		return "", errRevisionTaken
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to insert snapshot: %w", err)
+	}
+	if err := s.indexServices(q, id, snap.IPAddress, snap.Timestamp, snap.Data); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// GetSnapshotsByIP retrieves all snapshots for a given IP address, written
+// in any of its textual forms.
+func (s *sqlStore) GetSnapshotsByIP(ipAddress string) ([]*Snapshot, error) {
+	column, value := ipMatch(ipAddress)
+	rows, err := s.db.Query(
+		s.dialect.rebind(selectSnapshots+" WHERE "+column+" = ? AND deleted_at IS NULL ORDER BY timestamp DESC, revision DESC"),
+		value,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots by IP: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+// GetSnapshotsByIPRange retrieves the snapshots of every host in an IP range,
+// newest first.
+func (s *sqlStore) GetSnapshotsByIPRange(r IPRange) ([]*Snapshot, error) {
+	first, last := r.keys()
+	rows, err := s.db.Query(
+		s.dialect.rebind(selectSnapshots+" WHERE ip_key BETWEEN ? AND ? AND deleted_at IS NULL ORDER BY timestamp DESC, ip_key, revision DESC"),
+		first,
+		last,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots by IP range: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+// GetSnapshotByID retrieves a single snapshot by its ID, with its content
+// decompressed.
+func (s *sqlStore) GetSnapshotByID(id string) (*Snapshot, error) {
+	// IDs are integers; PostgreSQL rejects anything else instead of matching nothing
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, nil
+	}
+
+	var snap Snapshot
+	var encoding string
+	var blob []byte
+
+	err := s.db.QueryRow(
+		s.dialect.rebind(selectSnapshots+" WHERE snapshots.id = ? AND deleted_at IS NULL"),
+		id,
+	).Scan(&snap.ID, &snap.IPAddress, &snap.Timestamp, &snap.Revision, &encoding, &blob)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // No snapshot found with this ID
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query snapshot by ID: %w", err)
+	}
+
+	if snap.Data, err = decompress(encoding, blob); err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", snap.ID, err)
+	}
+	return &snap, nil
+}
+
+// GetSnapshotsInRange retrieves the snapshots for an IP address, written in
+// any of its textual forms, in ascending timestamp order. Empty start or end
+// values leave that side of the range open; both bounds are inclusive.
+func (s *sqlStore) GetSnapshotsInRange(ipAddress, start, end string) ([]*Snapshot, error) {
+	column, value := ipMatch(ipAddress)
+	query := selectSnapshots + " WHERE " + column + " = ? AND deleted_at IS NULL"
+	args := []interface{}{value}
+	if start != "" {
+		query += " AND timestamp >= ?"
+		args = append(args, start)
+	}
+	if end != "" {
+		query += " AND timestamp <= ?"
+		args = append(args, end)
+	}
+	query += " ORDER BY timestamp ASC, revision ASC"
+
+	rows, err := s.db.Query(s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots in range: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+// scanSnapshots reads rows selected by selectSnapshots into snapshots,
+// decompressing their content.
+func scanSnapshots(rows *sql.Rows) ([]*Snapshot, error) {
+	var snapshots []*Snapshot
+	for rows.Next() {
+		var s Snapshot
+		var encoding string
+		var blob []byte
+		if err := rows.Scan(&s.ID, &s.IPAddress, &s.Timestamp, &s.Revision, &encoding, &blob); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot row: %w", err)
+		}
+		data, err := decompress(encoding, blob)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", s.ID, err)
+		}
+		s.Data = data
+		snapshots = append(snapshots, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate snapshot rows: %w", err)
+	}
+
+	return snapshots, nil
+}
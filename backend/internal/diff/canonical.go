@@ -0,0 +1,173 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// sortReport puts every list in the report into canonical order: by port,
+// then protocol, then field or CVE ID. Comparisons range over maps, so
+// without this the same pair of snapshots could produce differently ordered
+// reports and summaries.
+func sortReport(report *DiffReport) {
+	sortServices(report.AddedServices)
+	sortServices(report.RemovedServices)
+
+	sort.SliceStable(report.ChangedServices, func(i, j int) bool {
+		a, b := report.ChangedServices[i], report.ChangedServices[j]
+		return lessPortProtocol(a.Port, a.Protocol, b.Port, b.Protocol)
+	})
+
+	sort.SliceStable(report.MovedServices, func(i, j int) bool {
+		a, b := report.MovedServices[i], report.MovedServices[j]
+		if a.OldPort != b.OldPort {
+			return a.OldPort < b.OldPort
+		}
+		return lessPortProtocol(a.NewPort, a.Protocol, b.NewPort, b.Protocol)
+	})
+
+	sortCVEs(report.AddedCVEs)
+	sortCVEs(report.RemovedCVEs)
+
+	sort.SliceStable(report.CertChanges, func(i, j int) bool {
+		a, b := report.CertChanges[i], report.CertChanges[j]
+		if a.Port != b.Port || a.Protocol != b.Protocol {
+			return lessPortProtocol(a.Port, a.Protocol, b.Port, b.Protocol)
+		}
+		return a.Field < b.Field
+	})
+
+	sort.SliceStable(report.FieldChanges, func(i, j int) bool {
+		a, b := report.FieldChanges[i], report.FieldChanges[j]
+		if a.Port != b.Port || a.Protocol != b.Protocol {
+			return lessPortProtocol(a.Port, a.Protocol, b.Port, b.Protocol)
+		}
+		return a.Path < b.Path
+	})
+
+	sort.SliceStable(report.VersionChanges, func(i, j int) bool {
+		a, b := report.VersionChanges[i], report.VersionChanges[j]
+		return lessPortProtocol(a.Port, a.Protocol, b.Port, b.Protocol)
+	})
+}
+
+func lessPortProtocol(portA int, protocolA string, portB int, protocolB string) bool {
+	if portA != portB {
+		return portA < portB
+	}
+	return protocolA < protocolB
+}
+
+func sortServices(services []ServiceInfo) {
+	sort.SliceStable(services, func(i, j int) bool {
+		return lessPortProtocol(services[i].Port, services[i].Protocol, services[j].Port, services[j].Protocol)
+	})
+}
+
+func sortCVEs(cves []CVEChange) {
+	sort.SliceStable(cves, func(i, j int) bool {
+		a, b := cves[i], cves[j]
+		if a.Port != b.Port || a.Protocol != b.Protocol {
+			return lessPortProtocol(a.Port, a.Protocol, b.Port, b.Protocol)
+		}
+		return a.CVEID < b.CVEID
+	})
+}
+
+// sortedKeys returns a change map's keys in sorted order.
+func sortedKeys(changes map[string]string) []string {
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hashedChange is the part of a ServiceChange that identifies the change.
+// Before and After are left out: they carry unchanged fields too.
+type hashedChange struct {
+	Port     int               `json:"port"`
+	Protocol string            `json:"protocol"`
+	Changes  map[string]string `json:"changes"`
+}
+
+// hashedMove is the part of a ServiceMove that identifies the move.
+type hashedMove struct {
+	OldPort   int         `json:"old_port"`
+	NewPort   int         `json:"new_port"`
+	Protocol  string      `json:"protocol"`
+	MatchedOn []string    `json:"matched_on"`
+	Service   ServiceInfo `json:"service"`
+}
+
+// hashedOSChange is the part of an OSChange that identifies the change.
+type hashedOSChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// hashedReport is the canonical form of a report used by ContentHash.
+type hashedReport struct {
+	OSChange        *hashedOSChange     `json:"os_change,omitempty"`
+	AddedServices   []ServiceInfo       `json:"added_services"`
+	RemovedServices []ServiceInfo       `json:"removed_services"`
+	ChangedServices []hashedChange      `json:"changed_services"`
+	MovedServices   []hashedMove        `json:"moved_services,omitempty"`
+	AddedCVEs       []CVEChange         `json:"added_cves"`
+	RemovedCVEs     []CVEChange         `json:"removed_cves"`
+	CertChanges     []CertificateChange `json:"cert_changes"`
+	FieldChanges    []FieldChange       `json:"field_changes"`
+	VersionChanges  []VersionChange     `json:"version_changes"`
+	Suppressed      []RuleMatch         `json:"suppressed"`
+}
+
+// ContentHash returns a hex SHA-256 of the report's changes in canonical
+// order. Identical diffs hash the same regardless of which snapshots or host
+// they came from, so downstream consumers can deduplicate on it. The summary
+// text and the Before/After service context are not part of the hash. It
+// returns an empty string if the report can't be encoded, which only happens
+// for hand-built services with unencodable Extra values.
+func (r *DiffReport) ContentHash() string {
+	h := hashedReport{
+		AddedServices:   r.AddedServices,
+		RemovedServices: r.RemovedServices,
+		AddedCVEs:       r.AddedCVEs,
+		RemovedCVEs:     r.RemovedCVEs,
+		CertChanges:     r.CertChanges,
+		FieldChanges:    r.FieldChanges,
+		VersionChanges:  r.VersionChanges,
+		Suppressed:      r.Suppressed,
+	}
+	if r.OSChange != nil {
+		h.OSChange = &hashedOSChange{Old: r.OSChange.Old, New: r.OSChange.New}
+	}
+	for _, sc := range r.ChangedServices {
+		h.ChangedServices = append(h.ChangedServices, hashedChange{
+			Port:     sc.Port,
+			Protocol: sc.Protocol,
+			Changes:  sc.Changes,
+		})
+	}
+
+	for _, mv := range r.MovedServices {
+		h.MovedServices = append(h.MovedServices, hashedMove{
+			OldPort:   mv.OldPort,
+			NewPort:   mv.NewPort,
+			Protocol:  mv.Protocol,
+			MatchedOn: mv.MatchedOn,
+			Service:   mv.After,
+		})
+	}
+
+	// encoding/json writes map keys in sorted order, so this is canonical
+	// as long as the lists are sorted.
+	data, err := json.Marshal(h)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,140 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+const canonicalSnapshotA = `{
+	"services": [
+		{"port": 8080, "protocol": "HTTP", "status": 200, "vulnerabilities": ["CVE-2021-0003"]},
+		{"port": 22, "protocol": "SSH", "software": {"product": "openssh", "version": "8.9p1"}},
+		{"port": 443, "protocol": "HTTPS", "status": 200, "tls": {"version": "tlsv1_2", "cipher": "A"}},
+		{"port": 25, "protocol": "SMTP"},
+		{"port": 21, "protocol": "FTP"}
+	]
+}`
+
+const canonicalSnapshotB = `{
+	"services": [
+		{"port": 3389, "protocol": "RDP", "vulnerabilities": ["CVE-2024-0002", "CVE-2023-0001"]},
+		{"port": 443, "protocol": "HTTPS", "status": 500, "tls": {"version": "tlsv1_3", "cipher": "B"}},
+		{"port": 22, "protocol": "SSH", "software": {"product": "openssh", "version": "9.6p1"}},
+		{"port": 8080, "protocol": "HTTP", "status": 404},
+		{"port": 53, "protocol": "DNS"},
+		{"port": 5432, "protocol": "POSTGRES"}
+	]
+}`
+
+func TestCompare_CanonicalOrder(t *testing.T) {
+	report, err := DiffSnapshots([]byte(canonicalSnapshotA), []byte(canonicalSnapshotB))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+
+	ports := func(services []ServiceInfo) []int {
+		var out []int
+		for _, s := range services {
+			out = append(out, s.Port)
+		}
+		return out
+	}
+	if got := ports(report.AddedServices); !equalInts(got, []int{53, 3389, 5432}) {
+		t.Errorf("Added services out of order: %v", got)
+	}
+	if got := ports(report.RemovedServices); !equalInts(got, []int{21, 25}) {
+		t.Errorf("Removed services out of order: %v", got)
+	}
+
+	var changed []int
+	for _, sc := range report.ChangedServices {
+		changed = append(changed, sc.Port)
+	}
+	if !equalInts(changed, []int{22, 443, 8080}) {
+		t.Errorf("Changed services out of order: %v", changed)
+	}
+
+	if len(report.AddedCVEs) != 2 || report.AddedCVEs[0].CVEID != "CVE-2023-0001" || report.AddedCVEs[1].CVEID != "CVE-2024-0002" {
+		t.Errorf("Added CVEs out of order: %+v", report.AddedCVEs)
+	}
+
+	// Change keys within a service are listed alphabetically in the summary
+	status := strings.Index(report.Summary, "status: 200 -> 500")
+	cipher := strings.Index(report.Summary, "tls_cipher: A -> B")
+	version := strings.Index(report.Summary, "tls_version: tlsv1_2 -> tlsv1_3")
+	if status < 0 || cipher < 0 || version < 0 || !(status < cipher && cipher < version) {
+		t.Errorf("Summary change keys out of order:\n%s", report.Summary)
+	}
+}
+
+func TestCompare_Deterministic(t *testing.T) {
+	first, err := DiffSnapshots([]byte(canonicalSnapshotA), []byte(canonicalSnapshotB))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		report, err := DiffSnapshots([]byte(canonicalSnapshotA), []byte(canonicalSnapshotB))
+		if err != nil {
+			t.Fatalf("DiffSnapshots failed: %v", err)
+		}
+		if report.Summary != first.Summary {
+			t.Fatalf("Summary changed between runs:\n%s\n---\n%s", first.Summary, report.Summary)
+		}
+		if report.ContentHash() != first.ContentHash() {
+			t.Fatalf("Content hash changed between runs")
+		}
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	hash := func(a, b string) string {
+		t.Helper()
+		report, err := DiffSnapshots([]byte(a), []byte(b))
+		if err != nil {
+			t.Fatalf("DiffSnapshots failed: %v", err)
+		}
+		return report.ContentHash()
+	}
+
+	base := hash(
+		`{"ip": "10.0.0.1", "services": [{"port": 80, "protocol": "HTTP"}, {"port": 22, "protocol": "SSH"}]}`,
+		`{"ip": "10.0.0.1", "services": [{"port": 443, "protocol": "HTTPS"}, {"port": 22, "protocol": "SSH"}]}`,
+	)
+	if len(base) != 64 {
+		t.Fatalf("Expected hex SHA-256, got %q", base)
+	}
+
+	// Same changes from a different host with services listed in another order
+	reordered := hash(
+		`{"ip": "10.0.0.2", "services": [{"port": 22, "protocol": "SSH"}, {"port": 80, "protocol": "HTTP"}]}`,
+		`{"ip": "10.0.0.2", "services": [{"port": 22, "protocol": "SSH"}, {"port": 443, "protocol": "HTTPS"}]}`,
+	)
+	if reordered != base {
+		t.Error("Expected identical diffs to share a hash")
+	}
+
+	different := hash(
+		`{"services": [{"port": 80, "protocol": "HTTP"}]}`,
+		`{"services": [{"port": 8443, "protocol": "HTTPS"}]}`,
+	)
+	if different == base {
+		t.Error("Expected different diffs to have different hashes")
+	}
+
+	empty := hash(`{"services": []}`, `{"services": []}`)
+	if empty == "" || empty == base {
+		t.Errorf("Unexpected hash for empty report: %q", empty)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CertificateInfo represents the parsed leaf certificate presented by a TLS service.
+// All fields are optional; scanners that only report a fingerprint leave this nil.
+type CertificateInfo struct {
+	Subject         string   `json:"subject,omitempty"`
+	Issuer          string   `json:"issuer,omitempty"`
+	SubjectAltNames []string `json:"subject_alt_names,omitempty"`
+	NotBefore       string   `json:"not_before,omitempty"`
+	NotAfter        string   `json:"not_after,omitempty"`
+	KeyType         string   `json:"key_type,omitempty"`
+	KeySize         int      `json:"key_size,omitempty"`
+	SelfSigned      *bool    `json:"self_signed,omitempty"`
+}
+
+// CertificateField identifies which certificate attribute changed.
+type CertificateField string
+
+// Certificate fields tracked by the diff engine.
+const (
+	CertFieldFingerprint CertificateField = "fingerprint"
+	CertFieldSubject     CertificateField = "subject"
+	CertFieldIssuer      CertificateField = "issuer"
+	CertFieldSANs        CertificateField = "sans"
+	CertFieldNotBefore   CertificateField = "not_before"
+	CertFieldNotAfter    CertificateField = "not_after"
+	CertFieldKeyType     CertificateField = "key_type"
+	CertFieldKeySize     CertificateField = "key_size"
+	CertFieldSelfSigned  CertificateField = "self_signed"
+)
+
+// CertificateChange describes a change in one attribute of a service's TLS certificate.
+type CertificateChange struct {
+	Port     int
+	Protocol string
+	Field    CertificateField
+	OldValue string
+	NewValue string
+}
+
+// compareCertificates returns the certificate changes between two TLS configurations.
+// Port and Protocol are left for the caller to fill in.
+func compareCertificates(tlsA, tlsB *TLSInfo) []CertificateChange {
+	var changes []CertificateChange
+	add := func(field CertificateField, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, CertificateChange{
+				Field:    field,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+
+	// A fingerprint change is a certificate rotation, even when no other
+	// certificate details are available.
+	add(CertFieldFingerprint,
+		strings.ToLower(tlsA.CertFingerprintSHA256),
+		strings.ToLower(tlsB.CertFingerprintSHA256))
+
+	// Only compare detailed fields when both snapshots carry them; a scanner
+	// that starts or stops reporting details is not a certificate change.
+	certA, certB := tlsA.Certificate, tlsB.Certificate
+	if certA == nil || certB == nil {
+		return changes
+	}
+
+	add(CertFieldSubject, certA.Subject, certB.Subject)
+	add(CertFieldIssuer, certA.Issuer, certB.Issuer)
+	add(CertFieldSANs, formatSANs(certA.SubjectAltNames), formatSANs(certB.SubjectAltNames))
+	add(CertFieldNotBefore, normalizeCertTime(certA.NotBefore), normalizeCertTime(certB.NotBefore))
+	add(CertFieldNotAfter, normalizeCertTime(certA.NotAfter), normalizeCertTime(certB.NotAfter))
+	add(CertFieldKeyType, strings.ToUpper(certA.KeyType), strings.ToUpper(certB.KeyType))
+	add(CertFieldKeySize, formatKeySize(certA.KeySize), formatKeySize(certB.KeySize))
+	add(CertFieldSelfSigned, formatSelfSigned(certA), formatSelfSigned(certB))
+
+	return changes
+}
+
+// formatSANs returns a canonical, order-independent representation of a SAN list.
+func formatSANs(sans []string) string {
+	normalized := make([]string, 0, len(sans))
+	seen := make(map[string]bool)
+	for _, san := range sans {
+		san = strings.ToLower(strings.TrimSpace(san))
+		if san == "" || seen[san] {
+			continue
+		}
+		seen[san] = true
+		normalized = append(normalized, san)
+	}
+	sort.Strings(normalized)
+	return strings.Join(normalized, ",")
+}
+
+// normalizeCertTime converts a certificate validity timestamp to RFC 3339 in UTC
+// so that equivalent instants written differently are not reported as changes.
+// Values that cannot be parsed are returned unchanged.
+func normalizeCertTime(value string) string {
+	layouts := []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return value
+}
+
+func formatKeySize(size int) string {
+	if size == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", size)
+}
+
+// formatSelfSigned reports whether a certificate is self-signed. When the scanner
+// does not say explicitly, a certificate whose subject equals its issuer is
+// treated as self-signed.
+func formatSelfSigned(cert *CertificateInfo) string {
+	if cert.SelfSigned != nil {
+		return fmt.Sprintf("%t", *cert.SelfSigned)
+	}
+	if cert.Subject == "" && cert.Issuer == "" {
+		return ""
+	}
+	return fmt.Sprintf("%t", cert.Subject == cert.Issuer)
+}
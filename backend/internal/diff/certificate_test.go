@@ -0,0 +1,178 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffSnapshots_CertRotation(t *testing.T) {
+	snapshotA := []byte(`{
+		"ip": "127.0.0.1",
+		"services": [
+			{
+				"port": 443,
+				"protocol": "HTTPS",
+				"tls": {"version": "tlsv1_3", "cipher": "TLS_AES_256_GCM_SHA384", "cert_fingerprint_sha256": "aaaa"}
+			}
+		]
+	}`)
+	snapshotB := []byte(`{
+		"ip": "127.0.0.1",
+		"services": [
+			{
+				"port": 443,
+				"protocol": "HTTPS",
+				"tls": {"version": "tlsv1_3", "cipher": "TLS_AES_256_GCM_SHA384", "cert_fingerprint_sha256": "bbbb"}
+			}
+		]
+	}`)
+
+	report, err := DiffSnapshots(snapshotA, snapshotB)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+
+	if strings.Contains(report.Summary, "No meaningful differences found") {
+		t.Errorf("Expected certificate rotation to be reported, got: %s", report.Summary)
+	}
+	if len(report.CertChanges) != 1 {
+		t.Fatalf("Expected 1 certificate change, got %d", len(report.CertChanges))
+	}
+	cc := report.CertChanges[0]
+	if cc.Field != CertFieldFingerprint || cc.OldValue != "aaaa" || cc.NewValue != "bbbb" {
+		t.Errorf("Unexpected certificate change: %+v", cc)
+	}
+	if cc.Port != 443 || cc.Protocol != "HTTPS" {
+		t.Errorf("Expected change on 443/HTTPS, got %d/%s", cc.Port, cc.Protocol)
+	}
+	if _, ok := report.ChangedServices[0].Changes["tls_cert_fingerprint"]; !ok {
+		t.Errorf("Expected tls_cert_fingerprint change, got changes: %v", report.ChangedServices[0].Changes)
+	}
+}
+
+func TestDiffSnapshots_CertFingerprintCaseInsensitive(t *testing.T) {
+	snapshotA := []byte(`{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cert_fingerprint_sha256": "ABCDEF"}}]}`)
+	snapshotB := []byte(`{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cert_fingerprint_sha256": "abcdef"}}]}`)
+
+	report, err := DiffSnapshots(snapshotA, snapshotB)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.CertChanges) != 0 || len(report.ChangedServices) != 0 {
+		t.Errorf("Expected no changes for fingerprint case difference, got %+v", report.CertChanges)
+	}
+}
+
+func TestDiffSnapshots_CertDetailFields(t *testing.T) {
+	snapshotA := []byte(`{
+		"services": [{
+			"port": 443,
+			"protocol": "HTTPS",
+			"tls": {
+				"cert_fingerprint_sha256": "aaaa",
+				"certificate": {
+					"subject": "CN=example.com",
+					"issuer": "CN=Example CA",
+					"subject_alt_names": ["www.example.com", "example.com"],
+					"not_before": "2025-01-01T00:00:00Z",
+					"not_after": "2025-04-01T00:00:00Z",
+					"key_type": "RSA",
+					"key_size": 2048
+				}
+			}
+		}]
+	}`)
+	snapshotB := []byte(`{
+		"services": [{
+			"port": 443,
+			"protocol": "HTTPS",
+			"tls": {
+				"cert_fingerprint_sha256": "bbbb",
+				"certificate": {
+					"subject": "CN=example.com",
+					"issuer": "CN=example.com",
+					"subject_alt_names": ["example.com", "api.example.com"],
+					"not_before": "2025-04-01T00:00:00Z",
+					"not_after": "2026-04-01T00:00:00Z",
+					"key_type": "ecdsa",
+					"key_size": 256
+				}
+			}
+		}]
+	}`)
+
+	report, err := DiffSnapshots(snapshotA, snapshotB)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+
+	got := make(map[CertificateField]CertificateChange)
+	for _, cc := range report.CertChanges {
+		got[cc.Field] = cc
+	}
+
+	expected := []CertificateField{
+		CertFieldFingerprint, CertFieldIssuer, CertFieldSANs, CertFieldNotBefore,
+		CertFieldNotAfter, CertFieldKeyType, CertFieldKeySize, CertFieldSelfSigned,
+	}
+	for _, field := range expected {
+		if _, ok := got[field]; !ok {
+			t.Errorf("Expected %s change, got %+v", field, report.CertChanges)
+		}
+	}
+	if _, ok := got[CertFieldSubject]; ok {
+		t.Errorf("Did not expect subject change, got %+v", got[CertFieldSubject])
+	}
+	if cc := got[CertFieldSelfSigned]; cc.OldValue != "false" || cc.NewValue != "true" {
+		t.Errorf("Expected self_signed false -> true, got %s -> %s", cc.OldValue, cc.NewValue)
+	}
+	if cc := got[CertFieldSANs]; cc.OldValue != "example.com,www.example.com" || cc.NewValue != "api.example.com,example.com" {
+		t.Errorf("Unexpected SAN change: %s -> %s", cc.OldValue, cc.NewValue)
+	}
+}
+
+func TestDiffSnapshots_CertEquivalentValues(t *testing.T) {
+	// Reordered SANs and equivalent timestamps are not changes.
+	snapshotA := []byte(`{
+		"services": [{
+			"port": 443,
+			"protocol": "HTTPS",
+			"tls": {"certificate": {
+				"subject_alt_names": ["b.example.com", "a.example.com"],
+				"not_after": "2026-01-01T02:00:00+02:00"
+			}}
+		}]
+	}`)
+	snapshotB := []byte(`{
+		"services": [{
+			"port": 443,
+			"protocol": "HTTPS",
+			"tls": {"certificate": {
+				"subject_alt_names": ["A.example.com", "b.example.com"],
+				"not_after": "2026-01-01T00:00:00Z"
+			}}
+		}]
+	}`)
+
+	report, err := DiffSnapshots(snapshotA, snapshotB)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.CertChanges) != 0 {
+		t.Errorf("Expected no certificate changes, got %+v", report.CertChanges)
+	}
+}
+
+func TestDiffSnapshots_CertDetailsOnlyOneSide(t *testing.T) {
+	// A scanner that starts reporting certificate details is not a certificate change.
+	snapshotA := []byte(`{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cert_fingerprint_sha256": "aaaa"}}]}`)
+	snapshotB := []byte(`{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cert_fingerprint_sha256": "aaaa", "certificate": {"subject": "CN=example.com"}}}]}`)
+
+	report, err := DiffSnapshots(snapshotA, snapshotB)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.CertChanges) != 0 {
+		t.Errorf("Expected no certificate changes, got %+v", report.CertChanges)
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // HostSnapshot represents the actual Censys host snapshot JSON structure.
 type HostSnapshot struct {
 	IP           string        `json:"ip"`
 	Timestamp    string        `json:"timestamp"`
+	OS           *OSInfo       `json:"os,omitempty"`
 	Services     []ServiceInfo `json:"services"`
 	ServiceCount int           `json:"service_count"`
 }
@@ -23,7 +26,7 @@ type ServiceInfo struct {
 	Software        SoftwareInfo           `json:"software,omitempty"`
 	TLS             *TLSInfo               `json:"tls,omitempty"`
 	Vulnerabilities []string               `json:"vulnerabilities,omitempty"`
-	Extra           map[string]interface{} `json:"-"` // For any additional fields
+	Extra           map[string]interface{} `json:"-"` // Unknown scanner fields, see UnmarshalJSON
 }
 
 // SoftwareInfo represents software details.
@@ -35,26 +38,76 @@ type SoftwareInfo struct {
 
 // TLSInfo represents TLS/SSL configuration details.
 type TLSInfo struct {
-	Version              string `json:"version,omitempty"`
-	Cipher               string `json:"cipher,omitempty"`
-	CertFingerprintSHA256 string `json:"cert_fingerprint_sha256,omitempty"`
+	Version               string           `json:"version,omitempty"`
+	Cipher                string           `json:"cipher,omitempty"`
+	CertFingerprintSHA256 string           `json:"cert_fingerprint_sha256,omitempty"`
+	Certificate           *CertificateInfo `json:"certificate,omitempty"`
 }
 
 // DiffReport contains the structured differences between two snapshots.
 type DiffReport struct {
 	Summary         string
+	OSChange        *OSChange
 	AddedServices   []ServiceInfo
 	RemovedServices []ServiceInfo
 	ChangedServices []ServiceChange
+	MovedServices   []ServiceMove
 	AddedCVEs       []CVEChange
 	RemovedCVEs     []CVEChange
+	CertChanges     []CertificateChange
+	FieldChanges    []FieldChange
+	VersionChanges  []VersionChange
+	// Suppressed holds changes removed from the report by ignore rules, so
+	// they are still accounted for. Downgraded holds changes kept in the
+	// report whose severity a rule caps.
+	Suppressed []RuleMatch
+	Downgraded []RuleMatch
+}
+
+// Downgrades returns the software version changes that move to an older version.
+func (r *DiffReport) Downgrades() []VersionChange {
+	var downgrades []VersionChange
+	for _, vc := range r.VersionChanges {
+		if vc.Kind.IsDowngrade() {
+			downgrades = append(downgrades, vc)
+		}
+	}
+	return downgrades
+}
+
+// HasChanges reports whether the report found any difference between the
+// snapshots. Changes removed by ignore rules don't count.
+func (r *DiffReport) HasChanges() bool {
+	return r.OSChange != nil ||
+		len(r.AddedServices) > 0 ||
+		len(r.RemovedServices) > 0 ||
+		len(r.ChangedServices) > 0 ||
+		len(r.MovedServices) > 0 ||
+		len(r.AddedCVEs) > 0 ||
+		len(r.RemovedCVEs) > 0 ||
+		len(r.CertChanges) > 0 ||
+		len(r.FieldChanges) > 0 ||
+		len(r.VersionChanges) > 0
 }
 
 // ServiceChange describes a change in a service's attributes.
+// Changes holds a readable description of each changed attribute and Values
+// the raw old and new values, under the same keys. Before and After hold the
+// full service as seen in each snapshot.
 type ServiceChange struct {
 	Port     int
 	Protocol string
 	Changes  map[string]string
+	Values   map[string]ValueChange
+	Before   ServiceInfo
+	After    ServiceInfo
+}
+
+// ValueChange is the old and new value of a changed attribute. A value that
+// is absent on one side is empty.
+type ValueChange struct {
+	Old string
+	New string
 }
 
 // CVEChange describes a CVE addition or removal.
@@ -64,28 +117,86 @@ type CVEChange struct {
 	Protocol string
 }
 
-// DiffSnapshots compares two JSON snapshots and returns a DiffReport.
-func DiffSnapshots(snapshotA, snapshotB []byte) (*DiffReport, error) {
-	var snapA, snapB HostSnapshot
+// Option configures how snapshots are compared.
+type Option func(*options)
+
+type options struct {
+	host  string
+	rules *RuleSet
+}
 
-	if err := json.Unmarshal(snapshotA, &snapA); err != nil {
+// WithRules applies an ignore rule set to the comparison.
+func WithRules(rules *RuleSet) Option {
+	return func(o *options) {
+		o.rules = rules
+	}
+}
+
+// WithHost sets the host IP that rules are matched against. It defaults to
+// the IP in the snapshot content.
+func WithHost(host string) Option {
+	return func(o *options) {
+		o.host = host
+	}
+}
+
+// DiffSnapshots compares two JSON snapshots and returns a DiffReport.
+func DiffSnapshots(snapshotA, snapshotB []byte, opts ...Option) (*DiffReport, error) {
+	snapA, err := ParseSnapshot(snapshotA)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal snapshot A: %w", err)
 	}
-	if err := json.Unmarshal(snapshotB, &snapB); err != nil {
+	snapB, err := ParseSnapshot(snapshotB)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal snapshot B: %w", err)
 	}
 
+	return Compare(snapA, snapB, opts...), nil
+}
+
+// ParseSnapshot unmarshals a JSON snapshot. Callers diffing many snapshots
+// can parse each one once and pass the results to Compare.
+func ParseSnapshot(data []byte) (*HostSnapshot, error) {
+	var snap HostSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// Compare returns the DiffReport between two parsed snapshots.
+func Compare(snapA, snapB *HostSnapshot, opts ...Option) *DiffReport {
+	o := options{host: snapB.IP}
+	if o.host == "" {
+		o.host = snapA.IP
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	report := &DiffReport{}
 
+	// Compare the detected operating system
+	compareOS(snapA.OS, snapB.OS, report)
+
 	// Compare Services (which include ports)
 	compareServices(snapA.Services, snapB.Services, report)
 
+	// Pair removed and added services that are the same service on a new port
+	detectMoves(report)
+
 	// Compare Vulnerabilities
 	compareVulnerabilities(snapA.Services, snapB.Services, report)
 
+	// Canonical order, so identical inputs give identical reports
+	sortReport(report)
+
+	// Drop ignored changes before summarizing
+	applyRules(report, o.host, o.rules)
+
 	// Generate a summary string
 	report.Summary = generateSummary(report)
-	return report, nil
+	return report
 }
 
 func compareServices(servicesA, servicesB []ServiceInfo, report *DiffReport) {
@@ -106,51 +217,7 @@ func compareServices(servicesA, servicesB []ServiceInfo, report *DiffReport) {
 	for key, sA := range mapA {
 		if sB, ok := mapB[key]; ok {
 			// Service exists in both, check for changes
-			changes := make(map[string]string)
-
-			if sA.Protocol != sB.Protocol {
-				changes["protocol"] = fmt.Sprintf("%s -> %s", sA.Protocol, sB.Protocol)
-			}
-
-			if sA.Status != sB.Status && (sA.Status != 0 || sB.Status != 0) {
-				changes["status"] = fmt.Sprintf("%d -> %d", sA.Status, sB.Status)
-			}
-
-			if sA.Software.Product != sB.Software.Product {
-				changes["software_product"] = fmt.Sprintf("%s -> %s", sA.Software.Product, sB.Software.Product)
-			}
-
-			if sA.Software.Version != sB.Software.Version {
-				changes["software_version"] = fmt.Sprintf("%s -> %s", sA.Software.Version, sB.Software.Version)
-			}
-
-			if sA.Software.Vendor != sB.Software.Vendor {
-				changes["software_vendor"] = fmt.Sprintf("%s -> %s", sA.Software.Vendor, sB.Software.Vendor)
-			}
-
-			// TLS changes
-			if (sA.TLS == nil) != (sB.TLS == nil) {
-				if sA.TLS == nil {
-					changes["tls"] = "added TLS"
-				} else {
-					changes["tls"] = "removed TLS"
-				}
-			} else if sA.TLS != nil && sB.TLS != nil {
-				if sA.TLS.Version != sB.TLS.Version {
-					changes["tls_version"] = fmt.Sprintf("%s -> %s", sA.TLS.Version, sB.TLS.Version)
-				}
-				if sA.TLS.Cipher != sB.TLS.Cipher {
-					changes["tls_cipher"] = fmt.Sprintf("%s -> %s", sA.TLS.Cipher, sB.TLS.Cipher)
-				}
-			}
-
-			if len(changes) > 0 {
-				report.ChangedServices = append(report.ChangedServices, ServiceChange{
-					Port:     sA.Port,
-					Protocol: sB.Protocol,
-					Changes:  changes,
-				})
-			}
+			compareService(sA, sB, report)
 		} else {
 			// Service removed
 			report.RemovedServices = append(report.RemovedServices, sA)
@@ -166,6 +233,98 @@ func compareServices(servicesA, servicesB []ServiceInfo, report *DiffReport) {
 	}
 }
 
+// compareService compares the attributes of one service in two snapshots,
+// and reports any change under the port of the second. It is also used for
+// services that moved to a different port.
+func compareService(sA, sB ServiceInfo, report *DiffReport) {
+	changes := make(map[string]string)
+	values := make(map[string]ValueChange)
+
+	if sA.Protocol != sB.Protocol {
+		changes["protocol"] = fmt.Sprintf("%s -> %s", sA.Protocol, sB.Protocol)
+		values["protocol"] = ValueChange{sA.Protocol, sB.Protocol}
+	}
+
+	if sA.Status != sB.Status && (sA.Status != 0 || sB.Status != 0) {
+		changes["status"] = fmt.Sprintf("%d -> %d", sA.Status, sB.Status)
+		values["status"] = ValueChange{strconv.Itoa(sA.Status), strconv.Itoa(sB.Status)}
+	}
+
+	if sA.Software.Product != sB.Software.Product {
+		changes["software_product"] = fmt.Sprintf("%s -> %s", sA.Software.Product, sB.Software.Product)
+		values["software_product"] = ValueChange{sA.Software.Product, sB.Software.Product}
+	}
+
+	if sA.Software.Version != sB.Software.Version {
+		kind := ClassifyVersionChange(sA.Software.Version, sB.Software.Version)
+		changes["software_version"] = fmt.Sprintf("%s -> %s (%s)", sA.Software.Version, sB.Software.Version, kind)
+		values["software_version"] = ValueChange{sA.Software.Version, sB.Software.Version}
+		report.VersionChanges = append(report.VersionChanges, VersionChange{
+			Port:       sB.Port,
+			Protocol:   sB.Protocol,
+			Product:    sB.Software.Product,
+			OldVersion: sA.Software.Version,
+			NewVersion: sB.Software.Version,
+			Kind:       kind,
+		})
+	}
+
+	if sA.Software.Vendor != sB.Software.Vendor {
+		changes["software_vendor"] = fmt.Sprintf("%s -> %s", sA.Software.Vendor, sB.Software.Vendor)
+		values["software_vendor"] = ValueChange{sA.Software.Vendor, sB.Software.Vendor}
+	}
+
+	// TLS changes
+	if (sA.TLS == nil) != (sB.TLS == nil) {
+		if sA.TLS == nil {
+			changes["tls"] = "added TLS"
+			values["tls"] = ValueChange{"", sB.TLS.Version}
+		} else {
+			changes["tls"] = "removed TLS"
+			values["tls"] = ValueChange{sA.TLS.Version, ""}
+		}
+	} else if sA.TLS != nil && sB.TLS != nil {
+		if sA.TLS.Version != sB.TLS.Version {
+			changes["tls_version"] = fmt.Sprintf("%s -> %s", sA.TLS.Version, sB.TLS.Version)
+			values["tls_version"] = ValueChange{sA.TLS.Version, sB.TLS.Version}
+		}
+		if sA.TLS.Cipher != sB.TLS.Cipher {
+			changes["tls_cipher"] = fmt.Sprintf("%s -> %s", sA.TLS.Cipher, sB.TLS.Cipher)
+			values["tls_cipher"] = ValueChange{sA.TLS.Cipher, sB.TLS.Cipher}
+		}
+
+		// Certificate changes are reported both in the flat change map
+		// (for the summary) and as typed entries on the report.
+		for _, cc := range compareCertificates(sA.TLS, sB.TLS) {
+			cc.Port = sB.Port
+			cc.Protocol = sB.Protocol
+			changes["tls_cert_"+string(cc.Field)] = fmt.Sprintf("%s -> %s", cc.OldValue, cc.NewValue)
+			values["tls_cert_"+string(cc.Field)] = ValueChange{cc.OldValue, cc.NewValue}
+			report.CertChanges = append(report.CertChanges, cc)
+		}
+	}
+
+	// Scanner fields without a typed counterpart, keyed by JSON pointer
+	for _, fc := range compareExtra(sA.Extra, sB.Extra) {
+		fc.Port = sB.Port
+		fc.Protocol = sB.Protocol
+		changes[fc.Path] = formatFieldChange(fc)
+		values[fc.Path] = ValueChange{fc.OldValue, fc.NewValue}
+		report.FieldChanges = append(report.FieldChanges, fc)
+	}
+
+	if len(changes) > 0 {
+		report.ChangedServices = append(report.ChangedServices, ServiceChange{
+			Port:     sB.Port,
+			Protocol: sB.Protocol,
+			Changes:  changes,
+			Values:   values,
+			Before:   sA,
+			After:    sB,
+		})
+	}
+}
+
 func compareVulnerabilities(servicesA, servicesB []ServiceInfo, report *DiffReport) {
 	// Create maps of CVE+Port combination to track CVEs per service
 	// Key format: "CVE-ID:Port" to handle same CVE on different ports
@@ -173,8 +332,10 @@ func compareVulnerabilities(servicesA, servicesB []ServiceInfo, report *DiffRepo
 	cveMapB := make(map[string]CVEChange)
 
 	for _, s := range servicesA {
+		// A moved service keeps its CVEs, so key them by the port it moved to
+		port := report.movedPort(s.Port, s.Protocol)
 		for _, cve := range s.Vulnerabilities {
-			key := fmt.Sprintf("%s:%d", cve, s.Port)
+			key := fmt.Sprintf("%s:%d", cve, port)
 			cveMapA[key] = CVEChange{
 				CVEID:    cve,
 				Port:     s.Port,
@@ -215,6 +376,25 @@ func generateSummary(report *DiffReport) string {
 
 	foundChanges := false
 
+	// Downgrades are a strong compromise signal, so they lead the summary
+	if downgrades := report.Downgrades(); len(downgrades) > 0 {
+		foundChanges = true
+		summary.WriteString(fmt.Sprintf("\n  !! Software Downgrades (%d):\n", len(downgrades)))
+		for _, vc := range downgrades {
+			summary.WriteString(fmt.Sprintf("    ! Port %d (%s)", vc.Port, vc.Protocol))
+			if vc.Product != "" {
+				summary.WriteString(fmt.Sprintf(" - %s", vc.Product))
+			}
+			summary.WriteString(fmt.Sprintf(" %s -> %s (%s)\n", vc.OldVersion, vc.NewVersion, vc.Kind))
+		}
+	}
+
+	if oc := report.OSChange; oc != nil {
+		foundChanges = true
+		summary.WriteString("\n  Operating System:\n")
+		summary.WriteString(fmt.Sprintf("    ~ %s -> %s\n", displayOS(oc.Old), displayOS(oc.New)))
+	}
+
 	if len(report.AddedServices) > 0 {
 		foundChanges = true
 		summary.WriteString(fmt.Sprintf("\n  Added Services (%d):\n", len(report.AddedServices)))
@@ -248,13 +428,28 @@ func generateSummary(report *DiffReport) string {
 		}
 	}
 
+	if len(report.MovedServices) > 0 {
+		foundChanges = true
+		summary.WriteString(fmt.Sprintf("\n  Moved Services (%d):\n", len(report.MovedServices)))
+		for _, mv := range report.MovedServices {
+			summary.WriteString(fmt.Sprintf("    > Port %d -> %d (%s)", mv.OldPort, mv.NewPort, mv.Protocol))
+			if mv.After.Software.Product != "" {
+				summary.WriteString(fmt.Sprintf(" - %s", mv.After.Software.Product))
+				if mv.After.Software.Version != "" {
+					summary.WriteString(fmt.Sprintf(" %s", mv.After.Software.Version))
+				}
+			}
+			summary.WriteString(fmt.Sprintf(" [matched on %s]\n", strings.Join(mv.MatchedOn, ", ")))
+		}
+	}
+
 	if len(report.ChangedServices) > 0 {
 		foundChanges = true
 		summary.WriteString(fmt.Sprintf("\n  Changed Services (%d):\n", len(report.ChangedServices)))
 		for _, c := range report.ChangedServices {
 			summary.WriteString(fmt.Sprintf("    ~ Port %d (%s):\n", c.Port, c.Protocol))
-			for key, change := range c.Changes {
-				summary.WriteString(fmt.Sprintf("        %s: %s\n", key, change))
+			for _, key := range sortedKeys(c.Changes) {
+				summary.WriteString(fmt.Sprintf("        %s: %s\n", key, c.Changes[key]))
 			}
 		}
 	}
@@ -279,5 +474,9 @@ func generateSummary(report *DiffReport) string {
 		summary.WriteString("  No meaningful differences found.\n")
 	}
 
+	if len(report.Suppressed) > 0 {
+		summary.WriteString(fmt.Sprintf("\n  Suppressed by rules: %d change(s)\n", len(report.Suppressed)))
+	}
+
 	return summary.String()
 }
@@ -66,6 +66,34 @@ func TestDiffSnapshots_ServiceAdded(t *testing.T) {
 	}
 }
 
+func TestDiffReport_HasChanges(t *testing.T) {
+	service := ServiceInfo{Port: 443, Protocol: "HTTPS"}
+	tests := []struct {
+		name   string
+		report DiffReport
+		want   bool
+	}{
+		{"empty", DiffReport{}, false},
+		{"os change", DiffReport{OSChange: &OSChange{Old: "Ubuntu", New: "Debian"}}, true},
+		{"service added", DiffReport{AddedServices: []ServiceInfo{service}}, true},
+		{"service removed", DiffReport{RemovedServices: []ServiceInfo{service}}, true},
+		{"service changed", DiffReport{ChangedServices: []ServiceChange{{Port: 443, Protocol: "HTTPS"}}}, true},
+		{"service moved", DiffReport{MovedServices: []ServiceMove{{OldPort: 443, NewPort: 8443, Protocol: "HTTPS"}}}, true},
+		{"CVE added", DiffReport{AddedCVEs: []CVEChange{{CVEID: "CVE-2023-0001", Port: 443}}}, true},
+		{"CVE removed", DiffReport{RemovedCVEs: []CVEChange{{CVEID: "CVE-2023-0001", Port: 443}}}, true},
+		{"certificate changed", DiffReport{CertChanges: []CertificateChange{{Port: 443, Field: CertFieldSubject, OldValue: "CN=a", NewValue: "CN=b"}}}, true},
+		{"field changed", DiffReport{FieldChanges: []FieldChange{{Port: 443, Path: "/banner", Kind: FieldChanged}}}, true},
+		{"version changed", DiffReport{VersionChanges: []VersionChange{{Port: 443, OldVersion: "1.0.0", NewVersion: "2.0.0", Kind: VersionMajorUpgrade}}}, true},
+		{"everything suppressed", DiffReport{Suppressed: []RuleMatch{{Rule: "ignore-banner", Action: ActionSuppress, Port: 443, Field: "banner"}}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.report.HasChanges(); got != tt.want {
+			t.Errorf("%s: HasChanges() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestDiffSnapshots_ServiceRemoved(t *testing.T) {
 	snapshotA := []byte(`{
 		"ip": "127.0.0.1",
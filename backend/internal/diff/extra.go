@@ -0,0 +1,274 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// serviceInfoType is the struct whose JSON keys are decoded into typed fields.
+// Every other key in a service object, including unknown keys nested inside
+// typed objects such as "tls" or "software", is preserved in ServiceInfo.Extra.
+var serviceInfoType = reflect.TypeOf(ServiceInfo{})
+
+// serviceInfoFields is ServiceInfo without its methods, used to avoid recursion
+// in the custom JSON (un)marshalers.
+type serviceInfoFields ServiceInfo
+
+// UnmarshalJSON decodes the typed service fields and keeps every unknown key in
+// Extra. Unknown keys of a typed object are kept under that object's key, so a
+// scanner's "tls": {"alpn": ...} ends up as Extra["tls"]["alpn"]. Numbers in
+// Extra are json.Number, so large integers keep their precision.
+func (s *ServiceInfo) UnmarshalJSON(data []byte) error {
+	var fields serviceInfoFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+
+	*s = ServiceInfo(fields)
+	s.Extra = unknownFields(raw, serviceInfoType)
+	return nil
+}
+
+// unknownFields returns the keys of a decoded JSON object that a struct type
+// doesn't decode, recursing into fields of struct type. It returns nil if
+// every key is known.
+func unknownFields(raw map[string]interface{}, t reflect.Type) map[string]interface{} {
+	fields := jsonFields(t)
+	var unknown map[string]interface{}
+	for key, value := range raw {
+		var keep interface{} = value
+		if field, ok := lookupField(fields, key); ok {
+			nested, isObject := value.(map[string]interface{})
+			structType := field.Type
+			if structType.Kind() == reflect.Ptr {
+				structType = structType.Elem()
+			}
+			if !isObject || structType.Kind() != reflect.Struct {
+				continue
+			}
+			leftover := unknownFields(nested, structType)
+			if leftover == nil {
+				continue
+			}
+			keep = leftover
+		}
+		if unknown == nil {
+			unknown = make(map[string]interface{})
+		}
+		unknown[key] = keep
+	}
+	return unknown
+}
+
+// MarshalJSON encodes the typed service fields together with any Extra keys,
+// so a decoded service round-trips without losing scanner fields. Typed fields
+// win over Extra keys with the same name.
+func (s ServiceInfo) MarshalJSON() ([]byte, error) {
+	typed, err := json.Marshal(serviceInfoFields(s))
+	if err != nil || len(s.Extra) == 0 {
+		return typed, err
+	}
+
+	var merged map[string]interface{}
+	if err := json.Unmarshal(typed, &merged); err != nil {
+		return nil, err
+	}
+	mergeFields(merged, s.Extra)
+	return json.Marshal(merged)
+}
+
+// mergeFields adds the keys of extra that dst lacks, merging nested objects
+// key by key.
+func mergeFields(dst, extra map[string]interface{}) {
+	for key, value := range extra {
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		dstObject, dstIsObject := existing.(map[string]interface{})
+		extraObject, extraIsObject := value.(map[string]interface{})
+		if dstIsObject && extraIsObject {
+			mergeFields(dstObject, extraObject)
+		}
+	}
+}
+
+// jsonFields returns the fields of a struct type by the JSON object key they
+// are decoded from.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// lookupField finds the field a JSON object key decodes into. Like
+// encoding/json it prefers an exact match but otherwise ignores case, so
+// "Port" fills the port field rather than ending up in Extra.
+func lookupField(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if field, ok := fields[key]; ok {
+		return field, true
+	}
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// FieldChangeKind describes how a value at a JSON path changed.
+type FieldChangeKind string
+
+// Kinds of structural change reported for untyped service fields.
+const (
+	FieldAdded   FieldChangeKind = "added"
+	FieldRemoved FieldChangeKind = "removed"
+	FieldChanged FieldChangeKind = "changed"
+)
+
+// FieldChange describes a change in a service field that has no typed counterpart
+// in ServiceInfo. Path is a JSON pointer (RFC 6901) relative to the service object,
+// and OldValue/NewValue hold the compact JSON encoding of each side.
+type FieldChange struct {
+	Port     int
+	Protocol string
+	Path     string
+	Kind     FieldChangeKind
+	OldValue string
+	NewValue string
+}
+
+// compareExtra recursively compares the untyped fields of two services.
+// Port and Protocol are left for the caller to fill in.
+func compareExtra(extraA, extraB map[string]interface{}) []FieldChange {
+	var changes []FieldChange
+	deepDiff("", toValue(extraA), toValue(extraB), &changes)
+	return changes
+}
+
+// toValue converts a nil map into an empty object so that missing Extra maps
+// compare as "no keys" rather than as a null value.
+func toValue(m map[string]interface{}) interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+// deepDiff walks two decoded JSON values and appends a FieldChange for every
+// leaf that was added, removed or changed. Objects are compared key by key and
+// arrays index by index; any other type mismatch is reported as a change of the
+// whole value at that path.
+func deepDiff(path string, a, b interface{}, changes *[]FieldChange) {
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool, len(va)+len(vb))
+		for key := range va {
+			keys[key] = true
+		}
+		for key := range vb {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			childPath := path + "/" + escapePointerToken(key)
+			childA, inA := va[key]
+			childB, inB := vb[key]
+			switch {
+			case !inA:
+				*changes = append(*changes, FieldChange{Path: childPath, Kind: FieldAdded, NewValue: encodeValue(childB)})
+			case !inB:
+				*changes = append(*changes, FieldChange{Path: childPath, Kind: FieldRemoved, OldValue: encodeValue(childA)})
+			default:
+				deepDiff(childPath, childA, childB, changes)
+			}
+		}
+		return
+
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(va) || i < len(vb); i++ {
+			childPath := path + "/" + strconv.Itoa(i)
+			switch {
+			case i >= len(va):
+				*changes = append(*changes, FieldChange{Path: childPath, Kind: FieldAdded, NewValue: encodeValue(vb[i])})
+			case i >= len(vb):
+				*changes = append(*changes, FieldChange{Path: childPath, Kind: FieldRemoved, OldValue: encodeValue(va[i])})
+			default:
+				deepDiff(childPath, va[i], vb[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, FieldChange{
+			Path:     path,
+			Kind:     FieldChanged,
+			OldValue: encodeValue(a),
+			NewValue: encodeValue(b),
+		})
+	}
+}
+
+// formatFieldChange renders a FieldChange for the flat change map used in summaries.
+func formatFieldChange(fc FieldChange) string {
+	switch fc.Kind {
+	case FieldAdded:
+		return "added " + fc.NewValue
+	case FieldRemoved:
+		return "removed " + fc.OldValue
+	default:
+		return fmt.Sprintf("%s -> %s", fc.OldValue, fc.NewValue)
+	}
+}
+
+// escapePointerToken escapes a key for use as a JSON pointer reference token.
+func escapePointerToken(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+func encodeValue(v interface{}) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,232 @@
+package diff
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestServiceInfo_UnmarshalKeepsUnknownFields(t *testing.T) {
+	data := []byte(`{
+		"port": 443,
+		"protocol": "HTTPS",
+		"status": 200,
+		"jarm": "2ad2ad16d2ad2ad",
+		"http": {"headers": {"server": "nginx"}},
+		"labels": ["login-page"]
+	}`)
+
+	var s ServiceInfo
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if s.Port != 443 || s.Protocol != "HTTPS" || s.Status != 200 {
+		t.Errorf("Typed fields not decoded: %+v", s)
+	}
+	if len(s.Extra) != 3 {
+		t.Fatalf("Expected 3 extra fields, got %d: %v", len(s.Extra), s.Extra)
+	}
+	for _, key := range []string{"port", "protocol", "status"} {
+		if _, ok := s.Extra[key]; ok {
+			t.Errorf("Known field %q should not be in Extra", key)
+		}
+	}
+	if s.Extra["jarm"] != "2ad2ad16d2ad2ad" {
+		t.Errorf("Expected jarm in Extra, got %v", s.Extra["jarm"])
+	}
+}
+
+func TestServiceInfo_MarshalRoundTrip(t *testing.T) {
+	data := []byte(`{"port":22,"protocol":"SSH","banner":"SSH-2.0-OpenSSH_8.9"}`)
+
+	var s ServiceInfo
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var roundTrip ServiceInfo
+	if err := json.Unmarshal(encoded, &roundTrip); err != nil {
+		t.Fatalf("Unmarshal of round trip failed: %v", err)
+	}
+	if roundTrip.Port != 22 || roundTrip.Extra["banner"] != "SSH-2.0-OpenSSH_8.9" {
+		t.Errorf("Round trip lost data: %s", encoded)
+	}
+}
+
+func TestDiffSnapshots_ExtraFields(t *testing.T) {
+	snapshotA := []byte(`{
+		"services": [{
+			"port": 443,
+			"protocol": "HTTPS",
+			"jarm": "aaa",
+			"http": {"headers": {"server": "nginx", "x-powered-by": "php"}},
+			"labels": ["login-page"]
+		}]
+	}`)
+	snapshotB := []byte(`{
+		"services": [{
+			"port": 443,
+			"protocol": "HTTPS",
+			"jarm": "bbb",
+			"http": {"headers": {"server": "nginx", "x-frame-options": "DENY"}},
+			"labels": ["login-page", "wordpress"],
+			"banner": "hello"
+		}]
+	}`)
+
+	report, err := DiffSnapshots(snapshotA, snapshotB)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+
+	got := make(map[string]FieldChange)
+	for _, fc := range report.FieldChanges {
+		got[fc.Path] = fc
+	}
+
+	tests := []struct {
+		path     string
+		kind     FieldChangeKind
+		oldValue string
+		newValue string
+	}{
+		{"/banner", FieldAdded, "", `"hello"`},
+		{"/http/headers/x-frame-options", FieldAdded, "", `"DENY"`},
+		{"/http/headers/x-powered-by", FieldRemoved, `"php"`, ""},
+		{"/jarm", FieldChanged, `"aaa"`, `"bbb"`},
+		{"/labels/1", FieldAdded, "", `"wordpress"`},
+	}
+
+	if len(report.FieldChanges) != len(tests) {
+		t.Errorf("Expected %d field changes, got %d: %+v", len(tests), len(report.FieldChanges), report.FieldChanges)
+	}
+	for _, tt := range tests {
+		fc, ok := got[tt.path]
+		if !ok {
+			t.Errorf("Expected change at %s", tt.path)
+			continue
+		}
+		if fc.Kind != tt.kind || fc.OldValue != tt.oldValue || fc.NewValue != tt.newValue {
+			t.Errorf("%s: got %s %s -> %s, want %s %s -> %s",
+				tt.path, fc.Kind, fc.OldValue, fc.NewValue, tt.kind, tt.oldValue, tt.newValue)
+		}
+		if fc.Port != 443 || fc.Protocol != "HTTPS" {
+			t.Errorf("%s: expected 443/HTTPS, got %d/%s", tt.path, fc.Port, fc.Protocol)
+		}
+	}
+
+	if len(report.ChangedServices) != 1 {
+		t.Fatalf("Expected 1 changed service, got %d", len(report.ChangedServices))
+	}
+	if _, ok := report.ChangedServices[0].Changes["/jarm"]; !ok {
+		t.Errorf("Expected /jarm in change map, got %v", report.ChangedServices[0].Changes)
+	}
+}
+
+func TestDiffSnapshots_ExtraFieldsUnchanged(t *testing.T) {
+	snapshot := []byte(`{"services": [{"port": 80, "protocol": "HTTP", "http": {"headers": {"server": "nginx"}}}]}`)
+
+	report, err := DiffSnapshots(snapshot, snapshot)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.FieldChanges) != 0 || len(report.ChangedServices) != 0 {
+		t.Errorf("Expected no changes, got %+v", report.FieldChanges)
+	}
+}
+
+func TestDeepDiff_TypeChangeAndEscaping(t *testing.T) {
+	var changes []FieldChange
+	deepDiff("",
+		map[string]interface{}{"a/b": "x", "m~n": map[string]interface{}{"k": 1.0}},
+		map[string]interface{}{"a/b": []interface{}{"x"}, "m~n": map[string]interface{}{"k": 2.0}},
+		&changes)
+
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d: %+v", len(changes), changes)
+	}
+	if changes[0].Path != "/a~1b" || changes[0].Kind != FieldChanged || changes[0].NewValue != `["x"]` {
+		t.Errorf("Unexpected change: %+v", changes[0])
+	}
+	if changes[1].Path != "/m~0n/k" || changes[1].OldValue != "1" || changes[1].NewValue != "2" {
+		t.Errorf("Unexpected change: %+v", changes[1])
+	}
+}
+
+func TestDiffSnapshots_NestedExtraFields(t *testing.T) {
+	snapshotA := []byte(`{"services": [{
+		"port": 443,
+		"protocol": "HTTPS",
+		"software": {"product": "nginx", "cpe": "cpe:/a:nginx:nginx"},
+		"tls": {"version": "TLSv1.3", "alpn": ["h2"], "certificate": {"subject": "CN=a", "serial": 18446744073709551615}}
+	}]}`)
+	snapshotB := []byte(`{"services": [{
+		"port": 443,
+		"protocol": "HTTPS",
+		"software": {"product": "nginx", "cpe": "cpe:/a:f5:nginx"},
+		"tls": {"version": "TLSv1.3", "alpn": ["h2"], "certificate": {"subject": "CN=a", "serial": 18446744073709551614}}
+	}]}`)
+
+	var s ServiceInfo
+	if err := json.Unmarshal([]byte(`{"port": 443, "tls": {"version": "TLSv1.3", "alpn": ["h2"]}}`), &s); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if s.TLS == nil || s.TLS.Version != "TLSv1.3" {
+		t.Errorf("Typed TLS fields not decoded: %+v", s.TLS)
+	}
+	if tls, ok := s.Extra["tls"].(map[string]interface{}); !ok || len(tls) != 1 || tls["alpn"] == nil {
+		t.Errorf("Expected only alpn under Extra[tls], got %v", s.Extra)
+	}
+	encoded, err := json.Marshal(s)
+	if err != nil || string(encoded) != `{"port":443,"protocol":"","software":{},"tls":{"alpn":["h2"],"version":"TLSv1.3"}}` {
+		t.Errorf("Expected nested fields to round-trip, got %s, %v", encoded, err)
+	}
+
+	report, err := DiffSnapshots(snapshotA, snapshotB)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	want := map[string][2]string{
+		"/software/cpe":           {`"cpe:/a:nginx:nginx"`, `"cpe:/a:f5:nginx"`},
+		"/tls/certificate/serial": {"18446744073709551615", "18446744073709551614"},
+	}
+	if len(report.FieldChanges) != len(want) {
+		t.Fatalf("Expected %d field changes, got %+v", len(want), report.FieldChanges)
+	}
+	for _, fc := range report.FieldChanges {
+		if w, ok := want[fc.Path]; !ok || fc.OldValue != w[0] || fc.NewValue != w[1] {
+			t.Errorf("Unexpected change %s: %s -> %s", fc.Path, fc.OldValue, fc.NewValue)
+		}
+	}
+}
+
+func TestDiffSnapshots_CaseVariantKeys(t *testing.T) {
+	snapshotA := []byte(`{"services": [{"Port": 443, "Protocol": "HTTPS", "TLS": {"Version": "TLSv1.2", "ALPN": ["h2"]}}]}`)
+	snapshotB := []byte(`{"services": [{"Port": 443, "Protocol": "HTTPS", "TLS": {"Version": "TLSv1.3", "ALPN": ["h2"]}}]}`)
+
+	var s ServiceInfo
+	if err := json.Unmarshal([]byte(`{"Port": 443, "TLS": {"Version": "TLSv1.2", "ALPN": ["h2"]}}`), &s); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if s.Port != 443 || s.TLS == nil || s.TLS.Version != "TLSv1.2" {
+		t.Errorf("Typed fields not decoded: %+v", s)
+	}
+	if tls, ok := s.Extra["TLS"].(map[string]interface{}); len(s.Extra) != 1 || !ok || len(tls) != 1 || tls["ALPN"] == nil {
+		t.Errorf("Expected only ALPN under Extra[TLS], got %v", s.Extra)
+	}
+
+	report, err := DiffSnapshots(snapshotA, snapshotB)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.FieldChanges) != 0 {
+		t.Errorf("Expected typed fields not to be reported as field changes, got %+v", report.FieldChanges)
+	}
+	if len(report.ChangedServices) != 1 {
+		t.Errorf("Expected the TLS version change, got %+v", report.ChangedServices)
+	}
+}
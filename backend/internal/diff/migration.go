@@ -0,0 +1,161 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+)
+
+// Signals a moved service can be matched on.
+const (
+	MatchTLSFingerprint = "tls_fingerprint"
+	MatchSoftware       = "software"
+)
+
+// ServiceMove is a service that disappeared from one port and reappeared on
+// another: same protocol, and the same certificate or software identity.
+type ServiceMove struct {
+	OldPort   int
+	NewPort   int
+	Protocol  string
+	MatchedOn []string // MatchTLSFingerprint and/or MatchSoftware
+	Before    ServiceInfo
+	After     ServiceInfo
+}
+
+// moveCandidate is a possible pairing of a removed and an added service.
+type moveCandidate struct {
+	removed, added int // Indexes into RemovedServices and AddedServices
+	score          int
+	matchedOn      []string
+}
+
+// detectMoves pairs removed services with added services that look like the
+// same service on a new port, and reports them as moves instead. A pair is
+// only used when neither side has an equally good alternative, so ambiguous
+// cases stay as separate additions and removals. Other changes to a moved
+// service, such as a new certificate, are reported on its new port.
+func detectMoves(report *DiffReport) {
+	var candidates []moveCandidate
+	for i, removed := range report.RemovedServices {
+		for j, added := range report.AddedServices {
+			if score, matchedOn := matchMove(removed, added); score > 0 {
+				candidates = append(candidates, moveCandidate{removed: i, added: j, score: score, matchedOn: matchedOn})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	// Best score for each service on either side, and how many pairings reach it
+	bestRemoved := make(map[int]int)
+	bestAdded := make(map[int]int)
+	for _, c := range candidates {
+		if c.score > bestRemoved[c.removed] {
+			bestRemoved[c.removed] = c.score
+		}
+		if c.score > bestAdded[c.added] {
+			bestAdded[c.added] = c.score
+		}
+	}
+	tiesRemoved := make(map[int]int)
+	tiesAdded := make(map[int]int)
+	for _, c := range candidates {
+		if c.score == bestRemoved[c.removed] {
+			tiesRemoved[c.removed]++
+		}
+		if c.score == bestAdded[c.added] {
+			tiesAdded[c.added]++
+		}
+	}
+
+	movedRemoved := make(map[int]bool)
+	movedAdded := make(map[int]bool)
+	for _, c := range candidates {
+		if c.score != bestRemoved[c.removed] || c.score != bestAdded[c.added] ||
+			tiesRemoved[c.removed] != 1 || tiesAdded[c.added] != 1 {
+			continue
+		}
+		movedRemoved[c.removed] = true
+		movedAdded[c.added] = true
+		before, after := report.RemovedServices[c.removed], report.AddedServices[c.added]
+		report.MovedServices = append(report.MovedServices, ServiceMove{
+			OldPort:   before.Port,
+			NewPort:   after.Port,
+			Protocol:  after.Protocol,
+			MatchedOn: c.matchedOn,
+			Before:    before,
+			After:     after,
+		})
+		compareService(before, after, report)
+	}
+
+	report.RemovedServices = dropIndexes(report.RemovedServices, movedRemoved)
+	report.AddedServices = dropIndexes(report.AddedServices, movedAdded)
+}
+
+// matchMove scores how likely two services on different ports are the same
+// service. A matching certificate fingerprint counts for more than matching
+// software; any conflicting signal rules the pair out.
+func matchMove(removed, added ServiceInfo) (int, []string) {
+	if !strings.EqualFold(removed.Protocol, added.Protocol) {
+		return 0, nil
+	}
+
+	score := 0
+	var matchedOn []string
+
+	fpRemoved, fpAdded := certFingerprint(removed), certFingerprint(added)
+	if fpRemoved != "" && fpAdded != "" {
+		if fpRemoved != fpAdded {
+			return 0, nil
+		}
+		score += 2
+		matchedOn = append(matchedOn, MatchTLSFingerprint)
+	}
+
+	if removed.Software.Product != "" && added.Software.Product != "" {
+		if !strings.EqualFold(removed.Software.Vendor, added.Software.Vendor) ||
+			!strings.EqualFold(removed.Software.Product, added.Software.Product) ||
+			removed.Software.Version != added.Software.Version {
+			return 0, nil
+		}
+		score++
+		matchedOn = append(matchedOn, MatchSoftware)
+	}
+
+	sort.Strings(matchedOn)
+	return score, matchedOn
+}
+
+// certFingerprint returns the service's normalized certificate fingerprint, if any.
+func certFingerprint(s ServiceInfo) string {
+	if s.TLS == nil {
+		return ""
+	}
+	return strings.ToLower(s.TLS.CertFingerprintSHA256)
+}
+
+// movedPort returns the port a service on the given port moved to, or the
+// port itself if it didn't move.
+func (r *DiffReport) movedPort(port int, protocol string) int {
+	for _, mv := range r.MovedServices {
+		if mv.OldPort == port && strings.EqualFold(mv.Before.Protocol, protocol) {
+			return mv.NewPort
+		}
+	}
+	return port
+}
+
+func dropIndexes(services []ServiceInfo, drop map[int]bool) []ServiceInfo {
+	if len(drop) == 0 {
+		return services
+	}
+	var kept []ServiceInfo
+	for i, s := range services {
+		if !drop[i] {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
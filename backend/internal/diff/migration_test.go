@@ -0,0 +1,190 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompare_ServiceMoved(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshotA string
+		snapshotB string
+		matchedOn string
+	}{
+		{
+			name:      "certificate fingerprint",
+			snapshotA: `{"services": [{"port": 8443, "protocol": "HTTPS", "tls": {"cert_fingerprint_sha256": "AB12"}}]}`,
+			snapshotB: `{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cert_fingerprint_sha256": "ab12"}}]}`,
+			matchedOn: "tls_fingerprint",
+		},
+		{
+			name:      "software identity",
+			snapshotA: `{"services": [{"port": 8080, "protocol": "HTTP", "software": {"vendor": "f5", "product": "nginx", "version": "1.25.0"}}]}`,
+			snapshotB: `{"services": [{"port": 80, "protocol": "HTTP", "software": {"vendor": "f5", "product": "nginx", "version": "1.25.0"}}]}`,
+			matchedOn: "software",
+		},
+		{
+			name: "both",
+			snapshotA: `{"services": [{"port": 8443, "protocol": "HTTPS", "software": {"product": "nginx", "version": "1.25.0"},
+				"tls": {"cert_fingerprint_sha256": "ab12"}}]}`,
+			snapshotB: `{"services": [{"port": 443, "protocol": "HTTPS", "software": {"product": "nginx", "version": "1.25.0"},
+				"tls": {"cert_fingerprint_sha256": "ab12"}}]}`,
+			matchedOn: "software, tls_fingerprint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := DiffSnapshots([]byte(tt.snapshotA), []byte(tt.snapshotB))
+			if err != nil {
+				t.Fatalf("DiffSnapshots failed: %v", err)
+			}
+			if len(report.AddedServices) != 0 || len(report.RemovedServices) != 0 {
+				t.Errorf("Expected no added or removed services, got %d added and %d removed",
+					len(report.AddedServices), len(report.RemovedServices))
+			}
+			if len(report.MovedServices) != 1 {
+				t.Fatalf("Expected 1 moved service, got %d", len(report.MovedServices))
+			}
+			mv := report.MovedServices[0]
+			if got := strings.Join(mv.MatchedOn, ", "); got != tt.matchedOn {
+				t.Errorf("Expected match on %q, got %q", tt.matchedOn, got)
+			}
+			if mv.OldPort == mv.NewPort {
+				t.Errorf("Expected different ports, got %d -> %d", mv.OldPort, mv.NewPort)
+			}
+			if !strings.Contains(report.Summary, "Moved Services (1)") {
+				t.Errorf("Expected moved service in summary, got:\n%s", report.Summary)
+			}
+		})
+	}
+}
+
+func TestCompare_ServiceMovedKeepsCVEs(t *testing.T) {
+	snapshotA := `{"services": [{"port": 8080, "protocol": "HTTP", "software": {"product": "tomcat", "version": "9.0.1"},
+		"vulnerabilities": ["CVE-2020-0001", "CVE-2020-0002"]}]}`
+	snapshotB := `{"services": [{"port": 80, "protocol": "HTTP", "software": {"product": "tomcat", "version": "9.0.1"},
+		"vulnerabilities": ["CVE-2020-0001", "CVE-2020-0003"]}]}`
+
+	report, err := DiffSnapshots([]byte(snapshotA), []byte(snapshotB))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.MovedServices) != 1 {
+		t.Fatalf("Expected 1 moved service, got %d", len(report.MovedServices))
+	}
+	if len(report.AddedCVEs) != 1 || report.AddedCVEs[0].CVEID != "CVE-2020-0003" {
+		t.Errorf("Expected only CVE-2020-0003 added, got %+v", report.AddedCVEs)
+	}
+	if len(report.RemovedCVEs) != 1 || report.RemovedCVEs[0].CVEID != "CVE-2020-0002" {
+		t.Errorf("Expected only CVE-2020-0002 removed, got %+v", report.RemovedCVEs)
+	}
+}
+
+func TestCompare_ServiceMovedWithChanges(t *testing.T) {
+	snapshotA := `{"services": [{"port": 8443, "protocol": "HTTPS", "software": {"product": "nginx", "version": "1.25.0"},
+		"tls": {"version": "TLSv1.3", "certificate": {"subject": "CN=old"}}, "vulnerabilities": ["CVE-2020-0001"]}]}`
+	snapshotB := `{"services": [{"port": 443, "protocol": "https", "software": {"product": "nginx", "version": "1.25.0"},
+		"tls": {"version": "TLSv1.2", "certificate": {"subject": "CN=new"}}, "vulnerabilities": ["CVE-2020-0001"]}]}`
+
+	report, err := DiffSnapshots([]byte(snapshotA), []byte(snapshotB))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.MovedServices) != 1 {
+		t.Fatalf("Expected 1 moved service, got %d", len(report.MovedServices))
+	}
+	if len(report.ChangedServices) != 1 {
+		t.Fatalf("Expected the moved service to be compared, got %d changed services", len(report.ChangedServices))
+	}
+	sc := report.ChangedServices[0]
+	if sc.Port != 443 {
+		t.Errorf("Expected changes on the new port, got %d", sc.Port)
+	}
+	for _, field := range []string{"protocol", "tls_version", "tls_cert_subject"} {
+		if _, ok := sc.Changes[field]; !ok {
+			t.Errorf("Expected %s in changes, got %v", field, sc.Changes)
+		}
+	}
+	if len(report.CertChanges) == 0 || report.CertChanges[0].Port != 443 {
+		t.Errorf("Expected a certificate change on port 443, got %+v", report.CertChanges)
+	}
+	// The service kept its CVE across the move
+	if len(report.AddedCVEs) != 0 || len(report.RemovedCVEs) != 0 {
+		t.Errorf("Expected no CVE changes, got %+v added and %+v removed", report.AddedCVEs, report.RemovedCVEs)
+	}
+
+	unchanged, err := DiffSnapshots(
+		[]byte(`{"services": [{"port": 8080, "protocol": "HTTP", "software": {"product": "nginx", "version": "1.25.0"}}]}`),
+		[]byte(`{"services": [{"port": 80, "protocol": "HTTP", "software": {"product": "nginx", "version": "1.25.0"}}]}`),
+	)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(unchanged.MovedServices) != 1 || len(unchanged.ChangedServices) != 0 {
+		t.Errorf("Expected only a move, got %+v", unchanged.ChangedServices)
+	}
+}
+
+func TestCompare_ServiceNotMoved(t *testing.T) {
+	tests := map[string]struct {
+		snapshotA string
+		snapshotB string
+	}{
+		"no identity": {
+			`{"services": [{"port": 8080, "protocol": "HTTP"}]}`,
+			`{"services": [{"port": 80, "protocol": "HTTP"}]}`,
+		},
+		"different protocol": {
+			`{"services": [{"port": 8080, "protocol": "HTTP", "software": {"product": "nginx", "version": "1.0"}}]}`,
+			`{"services": [{"port": 443, "protocol": "HTTPS", "software": {"product": "nginx", "version": "1.0"}}]}`,
+		},
+		"different version": {
+			`{"services": [{"port": 8080, "protocol": "HTTP", "software": {"product": "nginx", "version": "1.0"}}]}`,
+			`{"services": [{"port": 80, "protocol": "HTTP", "software": {"product": "nginx", "version": "1.1"}}]}`,
+		},
+		"conflicting certificate": {
+			`{"services": [{"port": 8443, "protocol": "HTTPS", "software": {"product": "nginx"}, "tls": {"cert_fingerprint_sha256": "aa"}}]}`,
+			`{"services": [{"port": 443, "protocol": "HTTPS", "software": {"product": "nginx"}, "tls": {"cert_fingerprint_sha256": "bb"}}]}`,
+		},
+		"ambiguous": {
+			`{"services": [{"port": 8080, "protocol": "HTTP", "software": {"product": "nginx", "version": "1.0"}}]}`,
+			`{"services": [{"port": 80, "protocol": "HTTP", "software": {"product": "nginx", "version": "1.0"}},
+				{"port": 8000, "protocol": "HTTP", "software": {"product": "nginx", "version": "1.0"}}]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		report, err := DiffSnapshots([]byte(tt.snapshotA), []byte(tt.snapshotB))
+		if err != nil {
+			t.Fatalf("%s: DiffSnapshots failed: %v", name, err)
+		}
+		if len(report.MovedServices) != 0 {
+			t.Errorf("%s: expected no moves, got %+v", name, report.MovedServices)
+		}
+		if len(report.RemovedServices) != 1 {
+			t.Errorf("%s: expected the old service to be reported as removed", name)
+		}
+	}
+}
+
+func TestCompare_ServiceMovedPrefersFingerprint(t *testing.T) {
+	// Both new services run the same software, but only one has the old certificate
+	snapshotA := `{"services": [{"port": 8443, "protocol": "HTTPS", "software": {"product": "nginx"}, "tls": {"cert_fingerprint_sha256": "aa"}}]}`
+	snapshotB := `{"services": [
+		{"port": 443, "protocol": "HTTPS", "software": {"product": "nginx"}, "tls": {"cert_fingerprint_sha256": "aa"}},
+		{"port": 9443, "protocol": "HTTPS", "software": {"product": "nginx"}}
+	]}`
+
+	report, err := DiffSnapshots([]byte(snapshotA), []byte(snapshotB))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.MovedServices) != 1 || report.MovedServices[0].NewPort != 443 {
+		t.Fatalf("Expected a move to 443, got %+v", report.MovedServices)
+	}
+	if len(report.AddedServices) != 1 || report.AddedServices[0].Port != 9443 {
+		t.Errorf("Expected 9443 to stay an added service, got %+v", report.AddedServices)
+	}
+}
@@ -0,0 +1,49 @@
+package diff
+
+import "strings"
+
+// OSInfo is the operating system a scanner identified on the host.
+type OSInfo struct {
+	Vendor  string `json:"vendor,omitempty"`
+	Product string `json:"product,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// Name returns the OS as a single string, e.g. "canonical ubuntu 22.04".
+func (o *OSInfo) Name() string {
+	if o == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(o.Vendor+" "+o.Product+" "+o.Version), " ")
+}
+
+// OSChange describes a change in the host's detected operating system. Old
+// or New is empty when one snapshot has no OS.
+type OSChange struct {
+	Old    string
+	New    string
+	Before *OSInfo
+	After  *OSInfo
+}
+
+// compareOS records an OS change if the snapshots disagree on the host's OS.
+func compareOS(osA, osB *OSInfo, report *DiffReport) {
+	oldName, newName := osA.Name(), osB.Name()
+	if strings.EqualFold(oldName, newName) {
+		return
+	}
+	report.OSChange = &OSChange{
+		Old:    oldName,
+		New:    newName,
+		Before: osA,
+		After:  osB,
+	}
+}
+
+// displayOS returns an OS name for the summary, or "unknown" if there is none.
+func displayOS(name string) string {
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompare_OSChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		osA, osB  string
+		wantOld   string
+		wantNew   string
+		wantEmpty bool
+	}{
+		{"upgrade", `{"vendor": "canonical", "product": "ubuntu", "version": "20.04"}`,
+			`{"vendor": "canonical", "product": "ubuntu", "version": "22.04"}`, "canonical ubuntu 20.04", "canonical ubuntu 22.04", false},
+		{"detected", `null`, `{"product": "windows"}`, "", "windows", false},
+		{"lost", `{"product": "linux"}`, `null`, "linux", "", false},
+		{"same", `{"product": "Linux"}`, `{"product": "linux"}`, "", "", true},
+		{"neither", `null`, `null`, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := DiffSnapshots(
+				[]byte(`{"os": `+tt.osA+`, "services": []}`),
+				[]byte(`{"os": `+tt.osB+`, "services": []}`),
+			)
+			if err != nil {
+				t.Fatalf("DiffSnapshots failed: %v", err)
+			}
+			if tt.wantEmpty {
+				if report.OSChange != nil {
+					t.Errorf("Expected no OS change, got %+v", report.OSChange)
+				}
+				return
+			}
+			if report.OSChange == nil {
+				t.Fatal("Expected an OS change")
+			}
+			if report.OSChange.Old != tt.wantOld || report.OSChange.New != tt.wantNew {
+				t.Errorf("Expected %q -> %q, got %q -> %q", tt.wantOld, tt.wantNew, report.OSChange.Old, report.OSChange.New)
+			}
+			if !strings.Contains(report.Summary, "Operating System:") {
+				t.Errorf("Expected OS change in summary, got:\n%s", report.Summary)
+			}
+		})
+	}
+}
+
+func TestCompare_OSChangeSuppressed(t *testing.T) {
+	rs := &RuleSet{Rules: []Rule{{Name: "os", Field: RuleFieldOS, Action: ActionSuppress}}}
+	if err := rs.Compile(); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	report, err := DiffSnapshots(
+		[]byte(`{"os": {"product": "linux"}, "services": []}`),
+		[]byte(`{"os": {"product": "windows"}, "services": []}`),
+		WithRules(rs),
+	)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if report.OSChange != nil || len(report.Suppressed) != 1 {
+		t.Errorf("Expected OS change to be suppressed, got %+v", report.OSChange)
+	}
+}
+
+func TestCompare_ChangeValues(t *testing.T) {
+	report, err := DiffSnapshots(
+		[]byte(`{"services": [{"port": 443, "protocol": "HTTPS", "status": 200, "tls": {"cipher": "A"}, "banner": "old"}]}`),
+		[]byte(`{"services": [{"port": 443, "protocol": "HTTPS", "status": 301, "tls": {"cipher": "B"}, "banner": "new"}]}`),
+	)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.ChangedServices) != 1 {
+		t.Fatalf("Expected 1 changed service, got %d", len(report.ChangedServices))
+	}
+	sc := report.ChangedServices[0]
+
+	expected := map[string]ValueChange{
+		"status":     {"200", "301"},
+		"tls_cipher": {"A", "B"},
+		"/banner":    {`"old"`, `"new"`},
+	}
+	for field, want := range expected {
+		if got := sc.Values[field]; got != want {
+			t.Errorf("%s: expected %+v, got %+v", field, want, got)
+		}
+	}
+	if len(sc.Values) != len(sc.Changes) {
+		t.Errorf("Expected a value for every change, got %d values for %d changes", len(sc.Values), len(sc.Changes))
+	}
+}
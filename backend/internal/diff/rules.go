@@ -0,0 +1,343 @@
+package diff
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Field names used to match rules against changes that aren't attribute changes.
+// Attribute changes use their ServiceChange.Changes key (e.g. "status",
+// "tls_cipher", "tls_cert_fingerprint") and untyped fields use their JSON pointer.
+// OS changes are host-wide and are matched with port 0 and no protocol.
+const (
+	RuleFieldService       = "service"
+	RuleFieldVulnerability = "vulnerability"
+	RuleFieldOS            = "os"
+)
+
+// RuleAction says what happens to a change matched by a rule.
+type RuleAction string
+
+// Rule actions.
+const (
+	// ActionSuppress removes the change from the report but still counts it.
+	ActionSuppress RuleAction = "suppress"
+	// ActionDowngrade keeps the change but caps its severity.
+	ActionDowngrade RuleAction = "downgrade"
+)
+
+// ruleSeverities are the severities a downgrade rule may cap a change at.
+var ruleSeverities = map[string]bool{"info": true, "low": true, "medium": true, "high": true}
+
+// Rule matches changes by host, port, protocol and field. Empty matchers match
+// everything.
+type Rule struct {
+	Name string `yaml:"name" json:"name"`
+	// Host is an IP address or CIDR block.
+	Host string `yaml:"host" json:"host"`
+	// Ports is a comma-separated list of ports and ranges, e.g. "80,443,49152-65535".
+	Ports    string `yaml:"ports" json:"ports"`
+	Protocol string `yaml:"protocol" json:"protocol"`
+	// Field is a glob pattern (path.Match syntax) over change field names,
+	// e.g. "status", "tls_*" or "/http/headers/*".
+	Field  string     `yaml:"field" json:"field"`
+	Action RuleAction `yaml:"action" json:"action"`
+	// Severity is the maximum severity for downgrade rules: info, low, medium or high.
+	Severity string `yaml:"severity" json:"severity"`
+
+	hostPrefix netip.Prefix
+	portRanges [][2]int
+}
+
+// RuleSet is an ordered list of rules. The first matching rule wins.
+type RuleSet struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// RuleMatch records a change that matched a rule.
+type RuleMatch struct {
+	Rule     string
+	Action   RuleAction
+	Port     int
+	Protocol string
+	Field    string
+	Detail   string
+	Severity string // Severity cap, for downgrade rules
+}
+
+// LoadRules reads a YAML or JSON rule file.
+func LoadRules(path string) (*RuleSet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	return ParseRules(content)
+}
+
+// ParseRules parses and validates a YAML or JSON rule set.
+func ParseRules(content []byte) (*RuleSet, error) {
+	var rs RuleSet
+	if err := yaml.Unmarshal(content, &rs); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	if err := rs.Compile(); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// Compile validates every rule and prepares its matchers. It must be called
+// on rule sets that weren't built by ParseRules.
+func (rs *RuleSet) Compile() error {
+	for i := range rs.Rules {
+		if err := rs.Rules[i].compile(); err != nil {
+			name := rs.Rules[i].Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return fmt.Errorf("invalid rule %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) compile() error {
+	switch r.Action {
+	case ActionSuppress:
+	case ActionDowngrade:
+		r.Severity = strings.ToLower(r.Severity)
+		if !ruleSeverities[r.Severity] {
+			return fmt.Errorf("downgrade severity must be info, low, medium or high, got %q", r.Severity)
+		}
+	default:
+		return fmt.Errorf("action must be %q or %q, got %q", ActionSuppress, ActionDowngrade, r.Action)
+	}
+
+	r.hostPrefix = netip.Prefix{}
+	if r.Host != "" {
+		if strings.Contains(r.Host, "/") {
+			prefix, err := netip.ParsePrefix(r.Host)
+			if err != nil {
+				return fmt.Errorf("invalid host CIDR %q: %w", r.Host, err)
+			}
+			r.hostPrefix = prefix.Masked()
+		} else {
+			addr, err := netip.ParseAddr(r.Host)
+			if err != nil {
+				return fmt.Errorf("invalid host %q: %w", r.Host, err)
+			}
+			r.hostPrefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+	}
+
+	r.portRanges = nil
+	if r.Ports != "" {
+		for _, part := range strings.Split(r.Ports, ",") {
+			part = strings.TrimSpace(part)
+			lo, hi, isRange := strings.Cut(part, "-")
+			if !isRange {
+				hi = lo
+			}
+			min, errLo := strconv.Atoi(strings.TrimSpace(lo))
+			max, errHi := strconv.Atoi(strings.TrimSpace(hi))
+			if errLo != nil || errHi != nil || min < 0 || max > 65535 || min > max {
+				return fmt.Errorf("invalid port range %q", part)
+			}
+			r.portRanges = append(r.portRanges, [2]int{min, max})
+		}
+	}
+
+	if r.Field != "" {
+		if _, err := path.Match(r.Field, ""); err != nil {
+			return fmt.Errorf("invalid field pattern %q: %w", r.Field, err)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a change.
+func (r *Rule) matches(host string, port int, protocol, field string) bool {
+	if r.hostPrefix.IsValid() {
+		addr, err := netip.ParseAddr(host)
+		if err != nil || !r.hostPrefix.Contains(addr.Unmap()) {
+			return false
+		}
+	}
+	if r.portRanges != nil {
+		inRange := false
+		for _, pr := range r.portRanges {
+			if port >= pr[0] && port <= pr[1] {
+				inRange = true
+				break
+			}
+		}
+		if !inRange {
+			return false
+		}
+	}
+	if r.Protocol != "" && !strings.EqualFold(r.Protocol, protocol) {
+		return false
+	}
+	if r.Field != "" {
+		if ok, _ := path.Match(r.Field, field); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// match returns the first rule matching a change, or nil.
+func (rs *RuleSet) match(host string, port int, protocol, field string) *Rule {
+	if rs == nil {
+		return nil
+	}
+	for i := range rs.Rules {
+		if rs.Rules[i].matches(host, port, protocol, field) {
+			return &rs.Rules[i]
+		}
+	}
+	return nil
+}
+
+// applyRules removes suppressed changes from the report and records matches
+// for both suppressed and downgraded changes.
+func applyRules(report *DiffReport, host string, rules *RuleSet) {
+	if rules == nil || len(rules.Rules) == 0 {
+		return
+	}
+
+	// check returns true if the change should be kept in the report.
+	check := func(port int, protocol, field, detail string) bool {
+		rule := rules.match(host, port, protocol, field)
+		if rule == nil {
+			return true
+		}
+		m := RuleMatch{
+			Rule:     rule.Name,
+			Action:   rule.Action,
+			Port:     port,
+			Protocol: protocol,
+			Field:    field,
+			Detail:   detail,
+			Severity: rule.Severity,
+		}
+		if rule.Action == ActionSuppress {
+			report.Suppressed = append(report.Suppressed, m)
+			return false
+		}
+		report.Downgraded = append(report.Downgraded, m)
+		return true
+	}
+
+	if oc := report.OSChange; oc != nil {
+		if !check(0, "", RuleFieldOS, fmt.Sprintf("%s -> %s", oc.Old, oc.New)) {
+			report.OSChange = nil
+		}
+	}
+
+	report.AddedServices = filterServices(report.AddedServices, func(s ServiceInfo) bool {
+		return check(s.Port, s.Protocol, RuleFieldService, "service added")
+	})
+	report.RemovedServices = filterServices(report.RemovedServices, func(s ServiceInfo) bool {
+		return check(s.Port, s.Protocol, RuleFieldService, "service removed")
+	})
+
+	// Moves are matched as service changes on the port the service moved to
+	var moved []ServiceMove
+	for _, mv := range report.MovedServices {
+		detail := fmt.Sprintf("service moved from port %d", mv.OldPort)
+		if check(mv.NewPort, mv.Protocol, RuleFieldService, detail) {
+			moved = append(moved, mv)
+		}
+	}
+	report.MovedServices = moved
+
+	// Attribute changes are matched once through the flat change map; the typed
+	// lists below are then filtered against the keys that were suppressed.
+	suppressed := make(map[string]bool)
+	var changed []ServiceChange
+	for _, sc := range report.ChangedServices {
+		for _, key := range sortedKeys(sc.Changes) {
+			if !check(sc.Port, sc.Protocol, key, sc.Changes[key]) {
+				delete(sc.Changes, key)
+				delete(sc.Values, key)
+				suppressed[fmt.Sprintf("%d-%s-%s", sc.Port, sc.Protocol, key)] = true
+			}
+		}
+		if len(sc.Changes) > 0 {
+			changed = append(changed, sc)
+		}
+	}
+	report.ChangedServices = changed
+
+	isSuppressed := func(port int, protocol, field string) bool {
+		return suppressed[fmt.Sprintf("%d-%s-%s", port, protocol, field)]
+	}
+
+	var certChanges []CertificateChange
+	for _, cc := range report.CertChanges {
+		if !isSuppressed(cc.Port, cc.Protocol, "tls_cert_"+string(cc.Field)) {
+			certChanges = append(certChanges, cc)
+		}
+	}
+	report.CertChanges = certChanges
+
+	var fieldChanges []FieldChange
+	for _, fc := range report.FieldChanges {
+		if !isSuppressed(fc.Port, fc.Protocol, fc.Path) {
+			fieldChanges = append(fieldChanges, fc)
+		}
+	}
+	report.FieldChanges = fieldChanges
+
+	var versionChanges []VersionChange
+	for _, vc := range report.VersionChanges {
+		if !isSuppressed(vc.Port, vc.Protocol, "software_version") {
+			versionChanges = append(versionChanges, vc)
+		}
+	}
+	report.VersionChanges = versionChanges
+
+	report.AddedCVEs = filterCVEs(report.AddedCVEs, func(c CVEChange) bool {
+		return check(c.Port, c.Protocol, RuleFieldVulnerability, c.CVEID+" added")
+	})
+	report.RemovedCVEs = filterCVEs(report.RemovedCVEs, func(c CVEChange) bool {
+		return check(c.Port, c.Protocol, RuleFieldVulnerability, c.CVEID+" removed")
+	})
+}
+
+// SeverityCap returns the severity a downgrade rule caps a change at, if any.
+func (r *DiffReport) SeverityCap(port int, protocol, field string) (severity, rule string, ok bool) {
+	for _, m := range r.Downgraded {
+		if m.Port == port && m.Protocol == protocol && m.Field == field {
+			return m.Severity, m.Rule, true
+		}
+	}
+	return "", "", false
+}
+
+func filterServices(services []ServiceInfo, keep func(ServiceInfo) bool) []ServiceInfo {
+	var kept []ServiceInfo
+	for _, s := range services {
+		if keep(s) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+func filterCVEs(cves []CVEChange, keep func(CVEChange) bool) []CVEChange {
+	var kept []CVEChange
+	for _, c := range cves {
+		if keep(c) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
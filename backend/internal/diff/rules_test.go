@@ -0,0 +1,212 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	rs, err := ParseRules([]byte(`
+rules:
+  - name: ephemeral
+    host: 10.0.0.0/8
+    ports: "80, 49152-65535"
+    field: service
+    action: suppress
+  - name: cipher
+    field: tls_*
+    action: downgrade
+    severity: LOW
+`))
+	if err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+	if len(rs.Rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %d", len(rs.Rules))
+	}
+	if rs.Rules[1].Severity != "low" {
+		t.Errorf("Expected severity to be normalized, got %q", rs.Rules[1].Severity)
+	}
+}
+
+func TestParseRules_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown action":     "rules:\n  - action: ignore\n",
+		"missing severity":   "rules:\n  - action: downgrade\n",
+		"critical severity":  "rules:\n  - action: downgrade\n    severity: critical\n",
+		"bad host":           "rules:\n  - action: suppress\n    host: example.com\n",
+		"bad cidr":           "rules:\n  - action: suppress\n    host: 10.0.0.0/40\n",
+		"bad port":           "rules:\n  - action: suppress\n    ports: \"http\"\n",
+		"port out of range":  "rules:\n  - action: suppress\n    ports: \"70000\"\n",
+		"reversed range":     "rules:\n  - action: suppress\n    ports: \"90-80\"\n",
+		"bad field pattern":  "rules:\n  - action: suppress\n    field: \"[\"\n",
+		"malformed document": "rules: [",
+	}
+	for name, content := range tests {
+		if _, err := ParseRules([]byte(content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	rs, err := ParseRules([]byte(`
+rules:
+  - action: suppress
+    host: 10.1.0.0/16
+    ports: "443,8000-8100"
+    protocol: https
+    field: tls_cert_*
+`))
+	if err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+	rule := &rs.Rules[0]
+
+	tests := []struct {
+		host     string
+		port     int
+		protocol string
+		field    string
+		want     bool
+	}{
+		{"10.1.2.3", 443, "HTTPS", "tls_cert_fingerprint", true},
+		{"10.1.2.3", 8050, "HTTPS", "tls_cert_issuer", true},
+		{"10.2.0.1", 443, "HTTPS", "tls_cert_fingerprint", false},
+		{"10.1.2.3", 8443, "HTTPS", "tls_cert_fingerprint", false},
+		{"10.1.2.3", 443, "HTTP", "tls_cert_fingerprint", false},
+		{"10.1.2.3", 443, "HTTPS", "tls_cipher", false},
+		{"not-an-ip", 443, "HTTPS", "tls_cert_fingerprint", false},
+	}
+	for _, tt := range tests {
+		if got := rule.matches(tt.host, tt.port, tt.protocol, tt.field); got != tt.want {
+			t.Errorf("matches(%s, %d, %s, %s) = %v, want %v", tt.host, tt.port, tt.protocol, tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestCompare_SuppressRules(t *testing.T) {
+	snapshotA := `{
+		"ip": "10.0.0.5",
+		"services": [
+			{"port": 80, "protocol": "HTTP", "status": 200, "software": {"product": "nginx", "version": "1.24.0"}},
+			{"port": 443, "protocol": "HTTPS", "vulnerabilities": ["CVE-2023-0001"]}
+		]
+	}`
+	snapshotB := `{
+		"ip": "10.0.0.5",
+		"services": [
+			{"port": 80, "protocol": "HTTP", "status": 503, "software": {"product": "nginx", "version": "1.25.0"}},
+			{"port": 443, "protocol": "HTTPS", "vulnerabilities": ["CVE-2023-0001", "CVE-2024-0002"]},
+			{"port": 51000, "protocol": "TCP"}
+		]
+	}`
+	rs, err := ParseRules([]byte(`
+rules:
+  - name: status
+    ports: "80"
+    field: status
+    action: suppress
+  - name: ephemeral
+    ports: "49152-65535"
+    field: service
+    action: suppress
+  - name: other-host
+    host: 192.168.0.0/16
+    field: vulnerability
+    action: suppress
+`))
+	if err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+
+	report, err := DiffSnapshots([]byte(snapshotA), []byte(snapshotB), WithRules(rs))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+
+	if len(report.AddedServices) != 0 {
+		t.Errorf("Expected ephemeral port to be suppressed, got %v", report.AddedServices)
+	}
+	if len(report.ChangedServices) != 1 {
+		t.Fatalf("Expected 1 changed service, got %d", len(report.ChangedServices))
+	}
+	changes := report.ChangedServices[0].Changes
+	if _, ok := changes["status"]; ok {
+		t.Error("Expected status change to be suppressed")
+	}
+	if _, ok := changes["software_version"]; !ok {
+		t.Error("Expected version change to be kept")
+	}
+	if len(report.VersionChanges) != 1 {
+		t.Errorf("Expected version change to be kept, got %d", len(report.VersionChanges))
+	}
+	if len(report.AddedCVEs) != 1 {
+		t.Errorf("Expected CVE on a non-matching host to be kept, got %d", len(report.AddedCVEs))
+	}
+	if len(report.Suppressed) != 2 {
+		t.Errorf("Expected 2 suppressed changes, got %+v", report.Suppressed)
+	}
+	if !strings.Contains(report.Summary, "Suppressed by rules: 2 change(s)") {
+		t.Errorf("Expected suppressed count in summary, got:\n%s", report.Summary)
+	}
+}
+
+func TestCompare_SuppressDropsEmptyServiceChange(t *testing.T) {
+	snapshotA := `{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cipher": "A", "cert_fingerprint_sha256": "aa"}}]}`
+	snapshotB := `{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cipher": "B", "cert_fingerprint_sha256": "bb"}}]}`
+	rs := &RuleSet{Rules: []Rule{{Name: "tls", Field: "tls_*", Action: ActionSuppress}}}
+	if err := rs.Compile(); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	report, err := DiffSnapshots([]byte(snapshotA), []byte(snapshotB), WithRules(rs))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.ChangedServices) != 0 {
+		t.Errorf("Expected no changed services, got %+v", report.ChangedServices)
+	}
+	if len(report.CertChanges) != 0 {
+		t.Errorf("Expected certificate changes to be suppressed, got %+v", report.CertChanges)
+	}
+	if !strings.Contains(report.Summary, "No meaningful differences found") {
+		t.Errorf("Expected empty summary, got:\n%s", report.Summary)
+	}
+}
+
+func TestCompare_DowngradeRules(t *testing.T) {
+	snapshotA := `{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cipher": "A"}}]}`
+	snapshotB := `{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cipher": "B"}}]}`
+	rs := &RuleSet{Rules: []Rule{{Name: "cipher", Field: "tls_cipher", Action: ActionDowngrade, Severity: "info"}}}
+	if err := rs.Compile(); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	report, err := DiffSnapshots([]byte(snapshotA), []byte(snapshotB), WithRules(rs))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if len(report.ChangedServices) != 1 {
+		t.Fatalf("Expected downgraded change to stay in the report")
+	}
+	severity, rule, ok := report.SeverityCap(443, "HTTPS", "tls_cipher")
+	if !ok || severity != "info" || rule != "cipher" {
+		t.Errorf("Expected info cap from rule cipher, got %q %q %v", severity, rule, ok)
+	}
+	if len(report.Suppressed) != 0 {
+		t.Errorf("Expected nothing suppressed, got %+v", report.Suppressed)
+	}
+}
+
+func TestLoadRules_RepoDefault(t *testing.T) {
+	path := filepath.Join("..", "..", "..", "config", "rules.yaml")
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("rules file not found: %v", err)
+	}
+	if _, err := LoadRules(path); err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+}
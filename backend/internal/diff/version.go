@@ -0,0 +1,319 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed software version. It covers semver ("1.2.3-rc.1+build"),
+// Debian/RPM package versions with epochs and revisions ("1:2.4.57-1ubuntu2"),
+// OpenSSH portable releases ("8.9p1") and plain dotted numeric versions ("10.0").
+type Version struct {
+	Epoch      int
+	Release    []int  // Dotted numeric components, e.g. [8 9] for "8.9p1"
+	PatchLevel int    // OpenSSH "pN" suffix, or a trailing letter ("1.1.1w" -> 23)
+	PreRelease string // Semver pre-release; sorts before the plain release
+	Revision   string // Debian/RPM package revision; sorts after the upstream version
+}
+
+// upstreamPattern matches the upstream part of a version after the epoch,
+// revision and build metadata have been removed.
+var upstreamPattern = regexp.MustCompile(`^[vV]?([0-9]+(?:\.[0-9]+)*)(?:p([0-9]+)|([a-z]))?$`)
+
+// semverCore matches the MAJOR.MINOR.PATCH core of a semver version.
+var semverCore = regexp.MustCompile(`^[vV]?[0-9]+\.[0-9]+\.[0-9]+$`)
+
+// isSemverPreRelease reports whether the suffix of a semver core is a
+// pre-release rather than a package revision: it starts with a letter
+// ("rc.1"), or is made of numeric identifiers starting with 0 ("0.3.7"),
+// which package revisions, counted from 1, don't use.
+func isSemverPreRelease(suffix string) bool {
+	if c := suffix[0]; c < '0' || c > '9' {
+		return true
+	}
+	identifiers := strings.Split(suffix, ".")
+	if identifiers[0] != "0" {
+		return false
+	}
+	for _, id := range identifiers {
+		if _, err := strconv.Atoi(id); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseVersion parses a version string. It returns an error for versions that
+// don't follow any of the supported schemes.
+func ParseVersion(s string) (*Version, error) {
+	raw := strings.TrimSpace(s)
+	if raw == "" {
+		return nil, fmt.Errorf("empty version")
+	}
+
+	v := &Version{}
+	rest := raw
+
+	// Epoch: "1:2.4.57"
+	if before, after, found := strings.Cut(rest, ":"); found {
+		epoch, err := strconv.Atoi(before)
+		if err != nil || epoch < 0 {
+			return nil, fmt.Errorf("invalid epoch in version %q", s)
+		}
+		v.Epoch = epoch
+		rest = after
+	}
+
+	// Build metadata and Debian repack markers carry no ordering: "1.2.3+build.5",
+	// "1.2+dfsg". They go first, since build metadata may itself contain hyphens.
+	rest, _, _ = strings.Cut(rest, "+")
+
+	// Hyphenated suffix. A semver version ("1.2.3-rc.1", "1.0.0-0.3.7") has a
+	// pre-release after its first hyphen. Otherwise a Debian/RPM revision
+	// follows the last hyphen if it starts with a digit ("2.4.57-1ubuntu2"),
+	// and the upstream version before it may have a pre-release of its own
+	// ("2.0-beta2-1").
+	if before, after, found := strings.Cut(rest, "-"); found {
+		if after == "" {
+			return nil, fmt.Errorf("empty suffix in version %q", s)
+		}
+		if semverCore.MatchString(before) && isSemverPreRelease(after) {
+			v.PreRelease = after
+			rest = before
+		} else {
+			i := strings.LastIndex(rest, "-")
+			if revision := rest[i+1:]; revision != "" && revision[0] >= '0' && revision[0] <= '9' {
+				v.Revision = revision
+				rest = rest[:i]
+			}
+			if before, after, found := strings.Cut(rest, "-"); found {
+				if after == "" || (after[0] >= '0' && after[0] <= '9') {
+					return nil, fmt.Errorf("invalid suffix in version %q", s)
+				}
+				v.PreRelease = after
+				rest = before
+			}
+		}
+	}
+
+	m := upstreamPattern.FindStringSubmatch(strings.ToLower(rest))
+	if m == nil {
+		return nil, fmt.Errorf("unrecognized version format %q", s)
+	}
+
+	for _, part := range strings.Split(m[1], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version component %q in %q", part, s)
+		}
+		v.Release = append(v.Release, n)
+	}
+
+	switch {
+	case m[2] != "":
+		v.PatchLevel, _ = strconv.Atoi(m[2])
+	case m[3] != "":
+		v.PatchLevel = int(m[3][0]-'a') + 1
+	}
+
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is older than, equal to or
+// newer than other. It also returns the VersionLevel of the first difference.
+func (v *Version) Compare(other *Version) (int, VersionLevel) {
+	if c := compareInts(v.Epoch, other.Epoch); c != 0 {
+		return c, LevelMajor
+	}
+
+	for i := 0; i < len(v.Release) || i < len(other.Release); i++ {
+		if c := compareInts(componentAt(v.Release, i), componentAt(other.Release, i)); c != 0 {
+			switch i {
+			case 0:
+				return c, LevelMajor
+			case 1:
+				return c, LevelMinor
+			default:
+				return c, LevelPatch
+			}
+		}
+	}
+
+	if c := compareInts(v.PatchLevel, other.PatchLevel); c != 0 {
+		return c, LevelPatch
+	}
+	if c := comparePreRelease(v.PreRelease, other.PreRelease); c != 0 {
+		return c, LevelPatch
+	}
+	if c := compareRevision(v.Revision, other.Revision); c != 0 {
+		return c, LevelPatch
+	}
+	return 0, ""
+}
+
+// VersionLevel is the most significant version component that differs.
+type VersionLevel string
+
+// Version levels, from most to least significant.
+const (
+	LevelMajor VersionLevel = "major"
+	LevelMinor VersionLevel = "minor"
+	LevelPatch VersionLevel = "patch"
+)
+
+// VersionChangeKind classifies a software version change.
+type VersionChangeKind string
+
+// Version change classifications.
+const (
+	VersionMajorUpgrade   VersionChangeKind = "major_upgrade"
+	VersionMinorUpgrade   VersionChangeKind = "minor_upgrade"
+	VersionPatchUpgrade   VersionChangeKind = "patch_upgrade"
+	VersionMajorDowngrade VersionChangeKind = "major_downgrade"
+	VersionMinorDowngrade VersionChangeKind = "minor_downgrade"
+	VersionPatchDowngrade VersionChangeKind = "patch_downgrade"
+	VersionEquivalent     VersionChangeKind = "equivalent"  // Different spelling, same version ("8.2" vs "8.2.0")
+	VersionUnparseable    VersionChangeKind = "unparseable" // One side is missing or in an unknown format
+)
+
+// IsDowngrade reports whether the change moves to an older version.
+func (k VersionChangeKind) IsDowngrade() bool {
+	return k == VersionMajorDowngrade || k == VersionMinorDowngrade || k == VersionPatchDowngrade
+}
+
+// String returns a human-readable label such as "minor upgrade".
+func (k VersionChangeKind) String() string {
+	return strings.ReplaceAll(string(k), "_", " ")
+}
+
+// VersionChange describes a classified software version change on a service.
+type VersionChange struct {
+	Port       int
+	Protocol   string
+	Product    string
+	OldVersion string
+	NewVersion string
+	Kind       VersionChangeKind
+}
+
+// ClassifyVersionChange parses both versions and classifies the change between them.
+func ClassifyVersionChange(oldVersion, newVersion string) VersionChangeKind {
+	vOld, err := ParseVersion(oldVersion)
+	if err != nil {
+		return VersionUnparseable
+	}
+	vNew, err := ParseVersion(newVersion)
+	if err != nil {
+		return VersionUnparseable
+	}
+
+	c, level := vNew.Compare(vOld)
+	if c == 0 {
+		return VersionEquivalent
+	}
+
+	upgrade := c > 0
+	switch level {
+	case LevelMajor:
+		if upgrade {
+			return VersionMajorUpgrade
+		}
+		return VersionMajorDowngrade
+	case LevelMinor:
+		if upgrade {
+			return VersionMinorUpgrade
+		}
+		return VersionMinorDowngrade
+	default:
+		if upgrade {
+			return VersionPatchUpgrade
+		}
+		return VersionPatchDowngrade
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func componentAt(components []int, i int) int {
+	if i < len(components) {
+		return components[i]
+	}
+	return 0
+}
+
+// comparePreRelease orders semver pre-release strings. A release without a
+// pre-release sorts after any pre-release of the same version.
+func comparePreRelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numA, errA := strconv.Atoi(partsA[i])
+		numB, errB := strconv.Atoi(partsB[i])
+		var c int
+		switch {
+		case errA == nil && errB == nil:
+			c = compareInts(numA, numB)
+		case errA == nil:
+			c = -1 // Numeric identifiers sort before alphanumeric ones
+		case errB == nil:
+			c = 1
+		default:
+			c = strings.Compare(partsA[i], partsB[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(partsA), len(partsB))
+}
+
+// revisionSegment matches alternating digit and letter runs in a package revision.
+var revisionSegment = regexp.MustCompile(`[0-9]+|[a-zA-Z]+`)
+
+// compareRevision orders Debian/RPM package revisions segment by segment,
+// comparing digit runs numerically and letter runs lexically.
+func compareRevision(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	segA, segB := revisionSegment.FindAllString(a, -1), revisionSegment.FindAllString(b, -1)
+	for i := 0; i < len(segA) && i < len(segB); i++ {
+		numA, errA := strconv.Atoi(segA[i])
+		numB, errB := strconv.Atoi(segB[i])
+		var c int
+		switch {
+		case errA == nil && errB == nil:
+			c = compareInts(numA, numB)
+		case errA == nil:
+			c = 1 // RPM: numeric segments are newer than alphabetic ones
+		case errB == nil:
+			c = -1
+		default:
+			c = strings.Compare(segA[i], segB[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(segA), len(segB))
+}
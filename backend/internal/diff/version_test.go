@@ -0,0 +1,137 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Version
+		wantErr bool
+	}{
+		{name: "dotted numeric", input: "10.0", want: Version{Release: []int{10, 0}}},
+		{name: "semver", input: "v1.2.3", want: Version{Release: []int{1, 2, 3}}},
+		{name: "semver pre-release and build", input: "1.2.3-rc.1+build.5", want: Version{Release: []int{1, 2, 3}, PreRelease: "rc.1"}},
+		{name: "hyphenated build metadata", input: "1.2.3+build-5", want: Version{Release: []int{1, 2, 3}}},
+		{name: "openssh portable", input: "8.9p1", want: Version{Release: []int{8, 9}, PatchLevel: 1}},
+		{name: "debian epoch and revision", input: "1:2.4.57-1ubuntu2", want: Version{Epoch: 1, Release: []int{2, 4, 57}, Revision: "1ubuntu2"}},
+		{name: "rpm revision", input: "2.4.6-97.el8", want: Version{Release: []int{2, 4, 6}, Revision: "97.el8"}},
+		{name: "semver numeric pre-release", input: "1.0.0-0.3.7", want: Version{Release: []int{1, 0, 0}, PreRelease: "0.3.7"}},
+		{name: "semver hyphenated pre-release", input: "1.2.3-rc-1", want: Version{Release: []int{1, 2, 3}, PreRelease: "rc-1"}},
+		{name: "debian hyphenated upstream", input: "2.0-beta2-1", want: Version{Release: []int{2, 0}, PreRelease: "beta2", Revision: "1"}},
+		{name: "debian revision on semver core", input: "1.2.3-1", want: Version{Release: []int{1, 2, 3}, Revision: "1"}},
+		{name: "empty suffix", input: "1.0-", wantErr: true},
+		{name: "numeric upstream suffix", input: "1.0-2-3", wantErr: true},
+		{name: "letter suffix", input: "1.1.1w", want: Version{Release: []int{1, 1, 1}, PatchLevel: 23}},
+		{name: "empty", input: "", wantErr: true},
+		{name: "garbage", input: "latest", wantErr: true},
+		{name: "bad epoch", input: "x:1.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVersion(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseVersion(%q) expected error, got %+v", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) unexpected error: %v", tt.input, err)
+			}
+			if got.Epoch != tt.want.Epoch || got.PatchLevel != tt.want.PatchLevel ||
+				got.PreRelease != tt.want.PreRelease || got.Revision != tt.want.Revision ||
+				len(got.Release) != len(tt.want.Release) {
+				t.Fatalf("ParseVersion(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			for i := range got.Release {
+				if got.Release[i] != tt.want.Release[i] {
+					t.Errorf("ParseVersion(%q) release = %v, want %v", tt.input, got.Release, tt.want.Release)
+				}
+			}
+		})
+	}
+}
+
+func TestClassifyVersionChange(t *testing.T) {
+	tests := []struct {
+		oldVersion string
+		newVersion string
+		want       VersionChangeKind
+	}{
+		{"8.2p1", "8.9p1", VersionMinorUpgrade},
+		{"8.9p1", "8.2p1", VersionMinorDowngrade},
+		{"8.9p1", "8.9p2", VersionPatchUpgrade},
+		{"8.5", "10.0", VersionMajorUpgrade},
+		{"10.0", "8.5", VersionMajorDowngrade},
+		{"2.4.57", "2.4.58", VersionPatchUpgrade},
+		{"2.4.58", "2.4.57", VersionPatchDowngrade},
+		{"1.2.3-rc.1", "1.2.3", VersionPatchUpgrade},
+		{"1.2.3-rc.2", "1.2.3-rc.10", VersionPatchUpgrade},
+		{"1.2.3-alpha", "1.2.3-beta", VersionPatchUpgrade},
+		{"2.4.57-1ubuntu2", "2.4.57-1ubuntu10", VersionPatchUpgrade},
+		{"1:1.0", "2.0", VersionMajorDowngrade},
+		{"8.2", "8.2.0", VersionEquivalent},
+		{"1.2.3+build.1", "1.2.3+build.2", VersionEquivalent},
+		{"1.2.3+build-5", "1.2.3", VersionEquivalent},
+		{"1.2.3-rc.1+build-5", "1.2.3", VersionPatchUpgrade},
+		{"1.0.0-0.3.7", "1.0.0", VersionPatchUpgrade},
+		{"1.0.0", "1.0.0-0.3.7", VersionPatchDowngrade},
+		{"2.0-beta2-1", "2.0-beta2-2", VersionPatchUpgrade},
+		{"2.0-beta2-1", "2.0-1", VersionPatchUpgrade},
+		{"", "1.0", VersionUnparseable},
+		{"1.0", "nightly", VersionUnparseable},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyVersionChange(tt.oldVersion, tt.newVersion); got != tt.want {
+			t.Errorf("ClassifyVersionChange(%q, %q) = %s, want %s", tt.oldVersion, tt.newVersion, got, tt.want)
+		}
+	}
+}
+
+func TestDiffSnapshots_VersionDowngrade(t *testing.T) {
+	snapshotA := []byte(`{
+		"services": [
+			{"port": 22, "protocol": "SSH", "software": {"vendor": "openbsd", "product": "openssh", "version": "8.9p1"}},
+			{"port": 80, "protocol": "HTTP", "software": {"vendor": "apache", "product": "httpd", "version": "2.4.57"}}
+		]
+	}`)
+	snapshotB := []byte(`{
+		"services": [
+			{"port": 22, "protocol": "SSH", "software": {"vendor": "openbsd", "product": "openssh", "version": "8.2p1"}},
+			{"port": 80, "protocol": "HTTP", "software": {"vendor": "apache", "product": "httpd", "version": "2.4.58"}}
+		]
+	}`)
+
+	report, err := DiffSnapshots(snapshotA, snapshotB)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+
+	if len(report.VersionChanges) != 2 {
+		t.Fatalf("Expected 2 version changes, got %d", len(report.VersionChanges))
+	}
+
+	downgrades := report.Downgrades()
+	if len(downgrades) != 1 {
+		t.Fatalf("Expected 1 downgrade, got %d", len(downgrades))
+	}
+	if downgrades[0].Port != 22 || downgrades[0].Kind != VersionMinorDowngrade || downgrades[0].Product != "openssh" {
+		t.Errorf("Unexpected downgrade: %+v", downgrades[0])
+	}
+
+	if !strings.Contains(report.Summary, "Software Downgrades (1)") {
+		t.Errorf("Expected downgrade section in summary, got: %s", report.Summary)
+	}
+	if !strings.Contains(report.Summary, "8.9p1 -> 8.2p1 (minor downgrade)") {
+		t.Errorf("Expected labelled downgrade in summary, got: %s", report.Summary)
+	}
+	if strings.Index(report.Summary, "Software Downgrades") > strings.Index(report.Summary, "Changed Services") {
+		t.Errorf("Expected downgrades to lead the summary, got: %s", report.Summary)
+	}
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvHeader lists the columns of the CSV export. Each scored change is one
+// row; suppressed changes follow with change_type "suppressed".
+var csvHeader = []string{"host", "port", "protocol", "change_type", "field", "severity", "weight", "detail", "rule"}
+
+// formulaPrefixes are the characters that make a spreadsheet read a cell as a
+// formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// csvCell neutralizes a cell that a spreadsheet would run as a formula by
+// prefixing it with a quote. Details and product names come from scanner
+// output, so they can't be trusted (CSV injection).
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// writeCSVRecord writes a record with every cell neutralized by csvCell.
+func writeCSVRecord(out *csv.Writer, record []string) error {
+	for i, value := range record {
+		record[i] = csvCell(value)
+	}
+	return out.Write(record)
+}
+
+func renderCSV(w io.Writer, doc *Document) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, sc := range doc.Risk.Changes {
+		record := []string{
+			doc.Host,
+			strconv.Itoa(sc.Port),
+			sc.Protocol,
+			string(sc.Type),
+			sc.Field,
+			sc.Severity.String(),
+			strconv.FormatFloat(sc.Weight, 'g', -1, 64),
+			sc.Detail,
+			sc.DowngradedBy,
+		}
+		if err := writeCSVRecord(out, record); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range doc.Report.Suppressed {
+		record := []string{
+			doc.Host,
+			strconv.Itoa(m.Port),
+			m.Protocol,
+			"suppressed",
+			m.Field,
+			"",
+			"",
+			m.Detail,
+			m.Rule,
+		}
+		if err := writeCSVRecord(out, record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
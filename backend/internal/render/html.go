@@ -0,0 +1,126 @@
+package render
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlTemplate is a standalone page with inline styles, so the file can be
+// opened or attached without any other assets.
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Host diff: {{.Doc.Host}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+table { border-collapse: collapse; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; text-align: left; }
+th { background: #f6f8fa; }
+code { font-size: 0.9em; }
+.severity { font-weight: 600; text-transform: uppercase; font-size: 0.8em; }
+.critical { color: #a40e26; }
+.high { color: #cf222e; }
+.medium { color: #bc4c00; }
+.low { color: #9a6700; }
+.info { color: #57606a; }
+footer { color: #57606a; font-size: 0.8em; }
+</style>
+</head>
+<body>
+<h1>Host diff: {{.Doc.Host}}</h1>
+<table>
+<tr><th></th><th>Snapshot</th><th>Timestamp</th></tr>
+<tr><th>From</th><td><code>{{.Doc.From.ID}}</code></td><td>{{.Doc.From.Timestamp}}</td></tr>
+<tr><th>To</th><td><code>{{.Doc.To.ID}}</code></td><td>{{.Doc.To.Timestamp}}</td></tr>
+</table>
+<p><strong>Risk score:</strong> {{.Doc.Risk.Score}} <span class="severity {{.Doc.Risk.Severity}}">{{.Doc.Risk.Severity}}</span></p>
+{{- with .Doc.Report.OSChange}}
+<p><strong>Operating system:</strong> {{.Old}} &rarr; {{.New}}</p>
+{{- end}}
+{{- with .Doc.Risk.Changes}}
+<h2>Changes ({{len .}})</h2>
+<table>
+<tr><th>Severity</th><th>Type</th><th>Port</th><th>Protocol</th><th>Detail</th></tr>
+{{- range .}}
+<tr><td class="severity {{.Severity}}">{{.Severity}}</td><td>{{.Type}}</td><td>{{.Port}}</td><td>{{.Protocol}}</td><td>{{.Detail}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No meaningful differences found.</p>
+{{- end}}
+{{- with .Doc.Report.AddedServices}}
+<h2>Added services ({{len .}})</h2>
+<table>
+<tr><th>Port</th><th>Protocol</th><th>Software</th><th>CVEs</th></tr>
+{{- range .}}
+<tr><td>{{.Port}}</td><td>{{.Protocol}}</td><td>{{.Software.Product}} {{.Software.Version}}</td><td>{{len .Vulnerabilities}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Doc.Report.RemovedServices}}
+<h2>Removed services ({{len .}})</h2>
+<table>
+<tr><th>Port</th><th>Protocol</th><th>Software</th></tr>
+{{- range .}}
+<tr><td>{{.Port}}</td><td>{{.Protocol}}</td><td>{{.Software.Product}} {{.Software.Version}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Doc.Report.MovedServices}}
+<h2>Moved services ({{len .}})</h2>
+<table>
+<tr><th>Old port</th><th>New port</th><th>Protocol</th><th>Software</th><th>Matched on</th></tr>
+{{- range .}}
+<tr><td>{{.OldPort}}</td><td>{{.NewPort}}</td><td>{{.Protocol}}</td><td>{{.After.Software.Product}} {{.After.Software.Version}}</td><td>{{range $i, $m := .MatchedOn}}{{if $i}}, {{end}}{{$m}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Changed}}
+<h2>Changed services</h2>
+<table>
+<tr><th>Port</th><th>Protocol</th><th>Field</th><th>Change</th></tr>
+{{- range .}}
+<tr><td>{{.Port}}</td><td>{{.Protocol}}</td><td><code>{{.Field}}</code></td><td>{{.Change}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if or .Doc.Report.AddedCVEs .Doc.Report.RemovedCVEs}}
+<h2>Vulnerabilities</h2>
+<table>
+<tr><th></th><th>CVE</th><th>Port</th><th>Protocol</th></tr>
+{{- range .Doc.Report.AddedCVEs}}
+<tr><td>added</td><td>{{.CVEID}}</td><td>{{.Port}}</td><td>{{.Protocol}}</td></tr>
+{{- end}}
+{{- range .Doc.Report.RemovedCVEs}}
+<tr><td>removed</td><td>{{.CVEID}}</td><td>{{.Port}}</td><td>{{.Protocol}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Doc.Report.Suppressed}}
+<details>
+<summary>Suppressed by rules ({{len .}})</summary>
+<table>
+<tr><th>Rule</th><th>Port</th><th>Protocol</th><th>Field</th><th>Detail</th></tr>
+{{- range .}}
+<tr><td>{{.Rule}}</td><td>{{.Port}}</td><td>{{.Protocol}}</td><td><code>{{.Field}}</code></td><td>{{.Detail}}</td></tr>
+{{- end}}
+</table>
+</details>
+{{- end}}
+<footer>Report hash <code>{{.Hash}}</code></footer>
+</body>
+</html>
+`))
+
+func renderHTML(w io.Writer, doc *Document) error {
+	return htmlTemplate.Execute(w, struct {
+		Doc     *Document
+		Changed []changeRow
+		Hash    string
+	}{
+		Doc:     doc,
+		Changed: changedFields(doc.Report),
+		Hash:    doc.Report.ContentHash(),
+	})
+}
@@ -0,0 +1,240 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+)
+
+// The JSON export uses its own snake_case types so its schema doesn't change
+// when the Go report types do. Lists keep the report's canonical order and
+// encoding/json sorts map keys, so the same report always encodes to the
+// same bytes.
+
+type jsonSnapshot struct {
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"`
+}
+
+type jsonRisk struct {
+	Score    float64            `json:"score"`
+	Severity string             `json:"severity"`
+	Changes  []jsonScoredChange `json:"changes"`
+}
+
+type jsonScoredChange struct {
+	Type         string  `json:"type"`
+	Port         int     `json:"port"`
+	Protocol     string  `json:"protocol"`
+	Field        string  `json:"field"`
+	Detail       string  `json:"detail"`
+	Weight       float64 `json:"weight"`
+	Severity     string  `json:"severity"`
+	DowngradedBy string  `json:"downgraded_by,omitempty"`
+}
+
+type jsonOSChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+type jsonServiceChange struct {
+	Port     int                        `json:"port"`
+	Protocol string                     `json:"protocol"`
+	Changes  map[string]string          `json:"changes"`
+	Values   map[string]jsonValueChange `json:"values"`
+}
+
+type jsonValueChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+type jsonServiceMove struct {
+	OldPort   int              `json:"old_port"`
+	NewPort   int              `json:"new_port"`
+	Protocol  string           `json:"protocol"`
+	MatchedOn []string         `json:"matched_on"`
+	Service   diff.ServiceInfo `json:"service"`
+}
+
+type jsonCVEChange struct {
+	CVEID    string `json:"cve_id"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+type jsonCertificateChange struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Field    string `json:"field"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+type jsonFieldChange struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	OldValue string `json:"old_value,omitempty"`
+	NewValue string `json:"new_value,omitempty"`
+}
+
+type jsonVersionChange struct {
+	Port       int    `json:"port"`
+	Protocol   string `json:"protocol"`
+	Product    string `json:"product"`
+	OldVersion string `json:"old_version"`
+	NewVersion string `json:"new_version"`
+	Kind       string `json:"kind"`
+}
+
+type jsonRuleMatch struct {
+	Rule     string `json:"rule"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Field    string `json:"field"`
+	Detail   string `json:"detail"`
+}
+
+type jsonReport struct {
+	Host               string                  `json:"host"`
+	From               jsonSnapshot            `json:"from"`
+	To                 jsonSnapshot            `json:"to"`
+	ContentHash        string                  `json:"content_hash"`
+	Risk               jsonRisk                `json:"risk"`
+	OSChange           *jsonOSChange           `json:"os_change"`
+	AddedServices      []diff.ServiceInfo      `json:"added_services"`
+	RemovedServices    []diff.ServiceInfo      `json:"removed_services"`
+	ChangedServices    []jsonServiceChange     `json:"changed_services"`
+	MovedServices      []jsonServiceMove       `json:"moved_services"`
+	AddedCVEs          []jsonCVEChange         `json:"added_cves"`
+	RemovedCVEs        []jsonCVEChange         `json:"removed_cves"`
+	CertificateChanges []jsonCertificateChange `json:"certificate_changes"`
+	FieldChanges       []jsonFieldChange       `json:"field_changes"`
+	VersionChanges     []jsonVersionChange     `json:"version_changes"`
+	Suppressed         []jsonRuleMatch         `json:"suppressed"`
+}
+
+func renderJSON(w io.Writer, doc *Document) error {
+	report := doc.Report
+	out := jsonReport{
+		Host:        doc.Host,
+		From:        jsonSnapshot{ID: doc.From.ID, Timestamp: doc.From.Timestamp},
+		To:          jsonSnapshot{ID: doc.To.ID, Timestamp: doc.To.Timestamp},
+		ContentHash: report.ContentHash(),
+		Risk: jsonRisk{
+			Score:    doc.Risk.Score,
+			Severity: doc.Risk.Severity.String(),
+			Changes:  []jsonScoredChange{},
+		},
+		AddedServices:      emptyIfNil(report.AddedServices),
+		RemovedServices:    emptyIfNil(report.RemovedServices),
+		ChangedServices:    []jsonServiceChange{},
+		MovedServices:      []jsonServiceMove{},
+		AddedCVEs:          cvesToJSON(report.AddedCVEs),
+		RemovedCVEs:        cvesToJSON(report.RemovedCVEs),
+		CertificateChanges: []jsonCertificateChange{},
+		FieldChanges:       []jsonFieldChange{},
+		VersionChanges:     []jsonVersionChange{},
+		Suppressed:         []jsonRuleMatch{},
+	}
+
+	for _, sc := range doc.Risk.Changes {
+		out.Risk.Changes = append(out.Risk.Changes, jsonScoredChange{
+			Type:         string(sc.Type),
+			Port:         sc.Port,
+			Protocol:     sc.Protocol,
+			Field:        sc.Field,
+			Detail:       sc.Detail,
+			Weight:       sc.Weight,
+			Severity:     sc.Severity.String(),
+			DowngradedBy: sc.DowngradedBy,
+		})
+	}
+	if oc := report.OSChange; oc != nil {
+		out.OSChange = &jsonOSChange{Old: oc.Old, New: oc.New}
+	}
+	for _, sc := range report.ChangedServices {
+		values := make(map[string]jsonValueChange, len(sc.Values))
+		for field, v := range sc.Values {
+			values[field] = jsonValueChange{Old: v.Old, New: v.New}
+		}
+		out.ChangedServices = append(out.ChangedServices, jsonServiceChange{
+			Port:     sc.Port,
+			Protocol: sc.Protocol,
+			Changes:  sc.Changes,
+			Values:   values,
+		})
+	}
+	for _, mv := range report.MovedServices {
+		out.MovedServices = append(out.MovedServices, jsonServiceMove{
+			OldPort:   mv.OldPort,
+			NewPort:   mv.NewPort,
+			Protocol:  mv.Protocol,
+			MatchedOn: mv.MatchedOn,
+			Service:   mv.After,
+		})
+	}
+	for _, cc := range report.CertChanges {
+		out.CertificateChanges = append(out.CertificateChanges, jsonCertificateChange{
+			Port:     cc.Port,
+			Protocol: cc.Protocol,
+			Field:    string(cc.Field),
+			OldValue: cc.OldValue,
+			NewValue: cc.NewValue,
+		})
+	}
+	for _, fc := range report.FieldChanges {
+		out.FieldChanges = append(out.FieldChanges, jsonFieldChange{
+			Port:     fc.Port,
+			Protocol: fc.Protocol,
+			Path:     fc.Path,
+			Kind:     string(fc.Kind),
+			OldValue: fc.OldValue,
+			NewValue: fc.NewValue,
+		})
+	}
+	for _, vc := range report.VersionChanges {
+		out.VersionChanges = append(out.VersionChanges, jsonVersionChange{
+			Port:       vc.Port,
+			Protocol:   vc.Protocol,
+			Product:    vc.Product,
+			OldVersion: vc.OldVersion,
+			NewVersion: vc.NewVersion,
+			Kind:       string(vc.Kind),
+		})
+	}
+	for _, m := range report.Suppressed {
+		out.Suppressed = append(out.Suppressed, jsonRuleMatch{
+			Rule:     m.Rule,
+			Port:     m.Port,
+			Protocol: m.Protocol,
+			Field:    m.Field,
+			Detail:   m.Detail,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+func cvesToJSON(cves []diff.CVEChange) []jsonCVEChange {
+	out := []jsonCVEChange{}
+	for _, cve := range cves {
+		out = append(out, jsonCVEChange{CVEID: cve.CVEID, Port: cve.Port, Protocol: cve.Protocol})
+	}
+	return out
+}
+
+// emptyIfNil makes nil lists encode as [] rather than null.
+func emptyIfNil(services []diff.ServiceInfo) []diff.ServiceInfo {
+	if services == nil {
+		return []diff.ServiceInfo{}
+	}
+	return services
+}
@@ -0,0 +1,110 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// codeEscaper escapes characters that would break a code span in a Markdown
+// table cell. Markdown shows code spans literally, so HTML needs no escaping.
+var codeEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "\r", "", "`", "'")
+
+// markdownEscaper escapes characters that would break a Markdown table cell,
+// and HTML, which Markdown viewers would otherwise render: scanned banners
+// and titles are untrusted.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "\r", "", "`", "'", "&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func renderMarkdown(w io.Writer, doc *Document) error {
+	out := bufio.NewWriter(w)
+	report, risk := doc.Report, doc.Risk
+
+	fmt.Fprintf(out, "## Host diff: %s\n\n", mdCell(doc.Host))
+	fmt.Fprintf(out, "| | Snapshot | Timestamp |\n|---|---|---|\n")
+	fmt.Fprintf(out, "| From | `%s` | %s |\n", mdCode(doc.From.ID), mdCell(doc.From.Timestamp))
+	fmt.Fprintf(out, "| To | `%s` | %s |\n\n", mdCode(doc.To.ID), mdCell(doc.To.Timestamp))
+	fmt.Fprintf(out, "**Risk score:** %g (%s)\n", risk.Score, risk.Severity)
+	if oc := report.OSChange; oc != nil {
+		fmt.Fprintf(out, "\n**Operating system:** %s -> %s\n", mdCell(oc.Old), mdCell(oc.New))
+	}
+
+	if len(risk.Changes) == 0 {
+		fmt.Fprintf(out, "\nNo meaningful differences found.\n")
+	}
+
+	if len(risk.Changes) > 0 {
+		fmt.Fprintf(out, "\n### Changes (%d)\n\n", len(risk.Changes))
+		fmt.Fprintf(out, "| Severity | Type | Port | Protocol | Detail |\n|---|---|---|---|---|\n")
+		for _, sc := range risk.Changes {
+			fmt.Fprintf(out, "| %s | %s | %d | %s | %s |\n",
+				sc.Severity, sc.Type, sc.Port, mdCell(sc.Protocol), mdCell(sc.Detail))
+		}
+	}
+
+	if len(report.AddedServices) > 0 {
+		fmt.Fprintf(out, "\n### Added services (%d)\n\n", len(report.AddedServices))
+		fmt.Fprintf(out, "| Port | Protocol | Software | CVEs |\n|---|---|---|---|\n")
+		for _, s := range report.AddedServices {
+			fmt.Fprintf(out, "| %d | %s | %s | %d |\n", s.Port, mdCell(s.Protocol), mdCell(serviceLabel(s)), len(s.Vulnerabilities))
+		}
+	}
+
+	if len(report.RemovedServices) > 0 {
+		fmt.Fprintf(out, "\n### Removed services (%d)\n\n", len(report.RemovedServices))
+		fmt.Fprintf(out, "| Port | Protocol | Software |\n|---|---|---|\n")
+		for _, s := range report.RemovedServices {
+			fmt.Fprintf(out, "| %d | %s | %s |\n", s.Port, mdCell(s.Protocol), mdCell(serviceLabel(s)))
+		}
+	}
+
+	if len(report.MovedServices) > 0 {
+		fmt.Fprintf(out, "\n### Moved services (%d)\n\n", len(report.MovedServices))
+		fmt.Fprintf(out, "| Old port | New port | Protocol | Software | Matched on |\n|---|---|---|---|---|\n")
+		for _, mv := range report.MovedServices {
+			fmt.Fprintf(out, "| %d | %d | %s | %s | %s |\n", mv.OldPort, mv.NewPort, mdCell(mv.Protocol),
+				mdCell(serviceLabel(mv.After)), mdCell(strings.Join(mv.MatchedOn, ", ")))
+		}
+	}
+
+	if rows := changedFields(report); len(rows) > 0 {
+		fmt.Fprintf(out, "\n### Changed services (%d)\n\n", len(report.ChangedServices))
+		fmt.Fprintf(out, "| Port | Protocol | Field | Change |\n|---|---|---|---|\n")
+		for _, row := range rows {
+			fmt.Fprintf(out, "| %d | %s | `%s` | %s |\n", row.Port, mdCell(row.Protocol), mdCode(row.Field), mdCell(row.Change))
+		}
+	}
+
+	if len(report.AddedCVEs) > 0 || len(report.RemovedCVEs) > 0 {
+		fmt.Fprintf(out, "\n### Vulnerabilities\n\n")
+		fmt.Fprintf(out, "| | CVE | Port | Protocol |\n|---|---|---|---|\n")
+		for _, cve := range report.AddedCVEs {
+			fmt.Fprintf(out, "| added | %s | %d | %s |\n", mdCell(cve.CVEID), cve.Port, mdCell(cve.Protocol))
+		}
+		for _, cve := range report.RemovedCVEs {
+			fmt.Fprintf(out, "| removed | %s | %d | %s |\n", mdCell(cve.CVEID), cve.Port, mdCell(cve.Protocol))
+		}
+	}
+
+	if len(report.Suppressed) > 0 {
+		fmt.Fprintf(out, "\n<details><summary>Suppressed by rules (%d)</summary>\n\n", len(report.Suppressed))
+		fmt.Fprintf(out, "| Rule | Port | Protocol | Field | Detail |\n|---|---|---|---|---|\n")
+		for _, m := range report.Suppressed {
+			fmt.Fprintf(out, "| %s | %d | %s | `%s` | %s |\n",
+				mdCell(m.Rule), m.Port, mdCell(m.Protocol), mdCode(m.Field), mdCell(m.Detail))
+		}
+		fmt.Fprintf(out, "\n</details>\n")
+	}
+
+	fmt.Fprintf(out, "\n<sub>Report hash `%s`</sub>\n", report.ContentHash())
+	return out.Flush()
+}
+
+func mdCell(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// mdCode escapes text for a code span in a table cell.
+func mdCode(s string) string {
+	return codeEscaper.Replace(s)
+}
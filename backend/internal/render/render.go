@@ -0,0 +1,127 @@
+// Package render formats diff reports for export: plain text, Markdown,
+// standalone HTML, CSV, canonical JSON and SARIF 2.1.0.
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+	"github.com/justicecaban/host-diff-tool/backend/internal/scoring"
+)
+
+// Format is an export format for diff reports.
+type Format string
+
+// Supported formats.
+const (
+	FormatText     Format = "text"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatCSV      Format = "csv"
+	FormatJSON     Format = "json"
+	FormatSARIF    Format = "sarif"
+)
+
+// contentTypes maps each format to its MIME type.
+var contentTypes = map[Format]string{
+	FormatText:     "text/plain; charset=utf-8",
+	FormatMarkdown: "text/markdown; charset=utf-8",
+	FormatHTML:     "text/html; charset=utf-8",
+	FormatCSV:      "text/csv; charset=utf-8",
+	FormatJSON:     "application/json",
+	FormatSARIF:    "application/sarif+json",
+}
+
+// ParseFormat parses a format name such as "markdown" or "sarif". "md" is
+// accepted for Markdown.
+func ParseFormat(name string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(name)))
+	if format == "md" {
+		format = FormatMarkdown
+	}
+	if _, ok := contentTypes[format]; !ok {
+		return "", fmt.Errorf("unsupported report format: %q", name)
+	}
+	return format, nil
+}
+
+// ContentType returns the MIME type for a format.
+func ContentType(format Format) string {
+	return contentTypes[format]
+}
+
+// Snapshot identifies one side of a comparison.
+type Snapshot struct {
+	ID        string
+	Timestamp string
+}
+
+// Document is a diff report along with the context needed to render it.
+type Document struct {
+	Host   string
+	From   Snapshot
+	To     Snapshot
+	Report *diff.DiffReport
+	// Risk is scored with the default config if nil.
+	Risk *scoring.Result
+}
+
+// Render writes the document in the given format.
+func Render(w io.Writer, format Format, doc *Document) error {
+	if doc.Risk == nil {
+		scored := *doc
+		scored.Risk = scoring.Score(doc.Report, nil)
+		doc = &scored
+	}
+
+	switch format {
+	case FormatText:
+		_, err := io.WriteString(w, doc.Report.Summary)
+		return err
+	case FormatMarkdown:
+		return renderMarkdown(w, doc)
+	case FormatHTML:
+		return renderHTML(w, doc)
+	case FormatCSV:
+		return renderCSV(w, doc)
+	case FormatJSON:
+		return renderJSON(w, doc)
+	case FormatSARIF:
+		return renderSARIF(w, doc)
+	default:
+		return fmt.Errorf("unsupported report format: %q", format)
+	}
+}
+
+// changeRow is a single attribute change of a service.
+type changeRow struct {
+	Port     int
+	Protocol string
+	Field    string
+	Change   string
+}
+
+// changedFields flattens the report's service attribute changes, with each
+// service's fields in sorted order.
+func changedFields(report *diff.DiffReport) []changeRow {
+	var rows []changeRow
+	for _, sc := range report.ChangedServices {
+		fields := make([]string, 0, len(sc.Changes))
+		for field := range sc.Changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			rows = append(rows, changeRow{Port: sc.Port, Protocol: sc.Protocol, Field: field, Change: sc.Changes[field]})
+		}
+	}
+	return rows
+}
+
+// serviceLabel describes a service's software, e.g. "nginx 1.25.0".
+func serviceLabel(s diff.ServiceInfo) string {
+	return strings.TrimSpace(s.Software.Product + " " + s.Software.Version)
+}
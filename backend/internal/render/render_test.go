@@ -0,0 +1,271 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+	"github.com/justicecaban/host-diff-tool/backend/internal/scoring"
+)
+
+const (
+	snapshotA = `{"services": [
+		{"port": 80, "protocol": "HTTP", "status": 200, "software": {"product": "nginx", "version": "1.25.0"}},
+		{"port": 443, "protocol": "HTTPS", "tls": {"version": "tlsv1_3"}}
+	]}`
+	snapshotB = `{"services": [
+		{"port": 80, "protocol": "HTTP", "status": 503, "software": {"product": "nginx", "version": "1.24.0"}},
+		{"port": 443, "protocol": "HTTPS"},
+		{"port": 8080, "protocol": "HTTP", "software": {"product": "<script>alert(1)</script>|x"}, "vulnerabilities": ["CVE-2024-0001"]}
+	]}`
+)
+
+func testDocument(t *testing.T) *Document {
+	t.Helper()
+	report, err := diff.DiffSnapshots([]byte(snapshotA), []byte(snapshotB))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	return &Document{
+		Host:   "10.0.0.1",
+		From:   Snapshot{ID: "1", Timestamp: "2025-01-01T00:00:00Z"},
+		To:     Snapshot{ID: "2", Timestamp: "2025-01-02T00:00:00Z"},
+		Report: report,
+	}
+}
+
+func render(t *testing.T, format Format, doc *Document) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Render(&buf, format, doc); err != nil {
+		t.Fatalf("Render(%s) failed: %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]Format{
+		"markdown": FormatMarkdown,
+		"MD":       FormatMarkdown,
+		" sarif ":  FormatSARIF,
+		"csv":      FormatCSV,
+	}
+	for name, want := range tests {
+		got, err := ParseFormat(name)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestRender_Text(t *testing.T) {
+	doc := testDocument(t)
+	if got := render(t, FormatText, doc); got != doc.Report.Summary {
+		t.Errorf("Expected text format to be the summary, got:\n%s", got)
+	}
+}
+
+func TestRender_Markdown(t *testing.T) {
+	out := render(t, FormatMarkdown, testDocument(t))
+
+	for _, want := range []string{
+		"## Host diff: 10.0.0.1",
+		"| high | tls_removed | 443 | HTTPS | TLS removed |",
+		"### Added services (1)",
+		"| `status` | 200 -&gt; 503 |",
+		"| added | CVE-2024-0001 | 8080 | HTTP |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "</script>|x") {
+		t.Error("Expected pipes in table cells to be escaped")
+	}
+}
+
+func TestRender_MarkdownEscapesHTML(t *testing.T) {
+	report, err := diff.DiffSnapshots(
+		[]byte(`{"services": [{"port": 22, "protocol": "SSH", "banner": "SSH-2.0"}]}`),
+		[]byte(`{"services": [
+			{"port": 22, "protocol": "SSH", "banner": "<script>alert(1)</script>"},
+			{"port": 80, "protocol": "HTTP", "software": {"product": "<script>alert(1)</script> & <b>x</b>"}}
+		]}`),
+	)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	out := render(t, FormatMarkdown, &Document{Host: "10.0.0.1", Report: report})
+
+	if strings.Contains(out, "<script>") || strings.Contains(out, "<b>") {
+		t.Errorf("Expected HTML in table cells to be escaped, got:\n%s", out)
+	}
+	if !strings.Contains(out, "&lt;script&gt;alert(1)&lt;/script&gt; &amp; &lt;b&gt;x&lt;/b&gt;") {
+		t.Errorf("Expected the escaped banner, got:\n%s", out)
+	}
+	if !strings.Contains(out, "| `/banner` |") {
+		t.Errorf("Expected the field path in a code span, got:\n%s", out)
+	}
+}
+
+func TestRender_HTML(t *testing.T) {
+	out := render(t, FormatHTML, testDocument(t))
+
+	if !strings.HasPrefix(out, "<!DOCTYPE html>") {
+		t.Errorf("Expected a standalone HTML document, got:\n%.100s", out)
+	}
+	if strings.Contains(out, "<script>") {
+		t.Error("Expected report content to be HTML-escaped")
+	}
+	if !strings.Contains(out, "CVE-2024-0001") {
+		t.Error("Expected vulnerabilities in HTML output")
+	}
+}
+
+func TestRender_CSV(t *testing.T) {
+	doc := testDocument(t)
+	records, err := csv.NewReader(strings.NewReader(render(t, FormatCSV, doc))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("Unexpected header: %v", records[0])
+	}
+
+	if want := len(scoring.Score(doc.Report, nil).Changes) + 1; len(records) != want {
+		t.Errorf("Expected %d records, got %d", want, len(records))
+	}
+	for _, record := range records[1:] {
+		if record[0] != "10.0.0.1" {
+			t.Errorf("Expected host column on every row, got %v", record)
+		}
+	}
+}
+
+func TestRender_CSVInjection(t *testing.T) {
+	report, err := diff.DiffSnapshots(
+		[]byte(`{"services": []}`),
+		[]byte(`{"services": [{"port": 80, "protocol": "=HYPERLINK(\"http://evil\")"}]}`),
+	)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(render(t, FormatCSV, &Document{Host: "10.0.0.1", Report: report}))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(records) != 2 || records[1][2] != `'=HYPERLINK("http://evil")` {
+		t.Errorf("Expected the formula to be quoted, got %v", records)
+	}
+
+	for value, want := range map[string]string{
+		"+1":      "'+1",
+		"-1":      "'-1",
+		"@SUM(1)": "'@SUM(1)",
+		"nginx":   "nginx",
+		"":        "",
+	} {
+		if got := csvCell(value); got != want {
+			t.Errorf("csvCell(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestRender_JSON(t *testing.T) {
+	doc := testDocument(t)
+	out := render(t, FormatJSON, doc)
+
+	var decoded jsonReport
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if decoded.ContentHash != doc.Report.ContentHash() {
+		t.Errorf("Expected content hash %s, got %s", doc.Report.ContentHash(), decoded.ContentHash)
+	}
+	if len(decoded.AddedServices) != 1 || len(decoded.RemovedServices) != 0 {
+		t.Errorf("Unexpected services: %+v", decoded)
+	}
+	if decoded.RemovedCVEs == nil || decoded.Suppressed == nil {
+		t.Error("Expected empty lists to encode as []")
+	}
+
+	if again := render(t, FormatJSON, testDocument(t)); again != out {
+		t.Error("Expected canonical JSON to be byte-identical across runs")
+	}
+}
+
+func TestRender_SARIF(t *testing.T) {
+	out := render(t, FormatSARIF, testDocument(t))
+
+	var log sarifLog
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("Invalid SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: version %s, %d runs", log.Version, len(log.Runs))
+	}
+
+	run := log.Runs[0]
+	levels := make(map[string]string)
+	for _, result := range run.Results {
+		if run.Tool.Driver.Rules[result.RuleIndex].ID != result.RuleID {
+			t.Errorf("Result %s points at rule %d", result.RuleID, result.RuleIndex)
+		}
+		if result.PartialFingerprints["hostDiff/v1"] == "" {
+			t.Errorf("Result %s has no fingerprint", result.RuleID)
+		}
+		levels[result.RuleID] = result.Level
+	}
+	expected := map[string]string{
+		"cve_added":          "error",
+		"port_added":         "warning",
+		"software_downgrade": "error",
+		"status_5xx":         "warning",
+	}
+	for ruleID, level := range expected {
+		if levels[ruleID] != level {
+			t.Errorf("%s: expected level %s, got %q", ruleID, level, levels[ruleID])
+		}
+	}
+}
+
+func TestRender_SARIFFingerprints(t *testing.T) {
+	report, err := diff.DiffSnapshots(
+		[]byte(`{"services": [{"port": 443, "protocol": "HTTPS"}]}`),
+		[]byte(`{"services": [{"port": 443, "protocol": "HTTPS", "vulnerabilities": ["CVE-2024-0001", "CVE-2024-0002"]}]}`),
+	)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	out := render(t, FormatSARIF, &Document{Host: "fe80::1%eth0", Report: report})
+
+	var log sarifLog
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("Invalid SARIF: %v", err)
+	}
+	results := log.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].PartialFingerprints["hostDiff/v1"] == results[1].PartialFingerprints["hostDiff/v1"] {
+		t.Error("Expected different CVEs on one service to have different fingerprints")
+	}
+	uri := results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI
+	if parsed, err := url.Parse(uri); err != nil || parsed.Path != "hosts/fe80::1%eth0" {
+		t.Errorf("Expected a valid URI for the host, got %q: %v", uri, err)
+	}
+}
+
+func TestRender_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, Format("pdf"), testDocument(t)); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
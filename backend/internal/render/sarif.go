@@ -0,0 +1,212 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/scoring"
+)
+
+// SARIF 2.1.0 constants. Each scored change type becomes a reporting rule,
+// and each scored change a result against the host.
+const (
+	sarifVersion  = "2.1.0"
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName = "host-diff-tool"
+)
+
+// changeDescriptions are the short descriptions of each change type's rule.
+var changeDescriptions = map[scoring.ChangeType]string{
+	scoring.PortAdded:         "A new port is exposed",
+	scoring.PortRemoved:       "A port was closed",
+	scoring.ServiceMoved:      "A service moved to a different port",
+	scoring.CVEAdded:          "A new vulnerability was detected",
+	scoring.CVERemoved:        "A vulnerability was resolved",
+	scoring.TLSAdded:          "TLS was enabled",
+	scoring.TLSRemoved:        "TLS was removed",
+	scoring.TLSDowngrade:      "The TLS version was downgraded",
+	scoring.TLSUpgrade:        "The TLS version was upgraded",
+	scoring.TLSVersionChanged: "The TLS version changed",
+	scoring.TLSCipherChanged:  "The TLS cipher changed",
+	scoring.CertRotated:       "The TLS certificate was rotated",
+	scoring.CertDetailChanged: "A TLS certificate field changed",
+	scoring.CertSelfSigned:    "The TLS certificate became self-signed",
+	scoring.SoftwareDowngrade: "Software was downgraded",
+	scoring.SoftwareUpgrade:   "Software was upgraded",
+	scoring.SoftwareChanged:   "Software changed",
+	scoring.StatusServerError: "The service started returning server errors",
+	scoring.StatusChanged:     "The service status changed",
+	scoring.FieldChanged:      "A scanner field changed",
+	scoring.OSChanged:         "The detected operating system changed",
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool              `json:"tool"`
+	Results []sarifResult          `json:"results"`
+	Props   map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             sarifMessage           `json:"message"`
+	Locations           []sarifLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints"`
+	Properties          map[string]interface{} `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevel maps a severity onto a SARIF result level.
+func sarifLevel(severity scoring.Severity) string {
+	switch {
+	case severity >= scoring.SeverityHigh:
+		return "error"
+	case severity >= scoring.SeverityLow:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func renderSARIF(w io.Writer, doc *Document) error {
+	// Only the change types that occur are listed as rules, in sorted order
+	// so results can refer to them by index. A rule's default level is that
+	// of its most severe result.
+	maxSeverity := make(map[scoring.ChangeType]scoring.Severity)
+	var types []string
+	for _, sc := range doc.Risk.Changes {
+		current, ok := maxSeverity[sc.Type]
+		if !ok {
+			types = append(types, string(sc.Type))
+		}
+		if !ok || sc.Severity > current {
+			maxSeverity[sc.Type] = sc.Severity
+		}
+	}
+	sort.Strings(types)
+
+	ruleIndex := make(map[scoring.ChangeType]int)
+	rules := []sarifRule{}
+	for i, t := range types {
+		changeType := scoring.ChangeType(t)
+		ruleIndex[changeType] = i
+		rules = append(rules, sarifRule{
+			ID:                   t,
+			ShortDescription:     sarifMessage{Text: changeDescriptions[changeType]},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(maxSeverity[changeType])},
+		})
+	}
+
+	// Results point at the host rather than a source file. Escaping keeps
+	// IPv6 zones ("fe80::1%eth0") and other hosts valid in a URI.
+	uri := "hosts/" + url.PathEscape(doc.Host)
+	results := []sarifResult{}
+	for _, sc := range doc.Risk.Changes {
+		service := fmt.Sprintf("%d/%s", sc.Port, sc.Protocol)
+		// Changes to different vulnerabilities of one service share a field
+		fingerprint := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%s", doc.Host, sc.Type, service, sc.Field, sc.CVEID)))
+		props := map[string]interface{}{
+			"port":     sc.Port,
+			"protocol": sc.Protocol,
+			"field":    sc.Field,
+			"severity": sc.Severity.String(),
+			"weight":   sc.Weight,
+		}
+		if sc.CVEID != "" {
+			props["cve"] = sc.CVEID
+		}
+		if sc.DowngradedBy != "" {
+			props["downgradedBy"] = sc.DowngradedBy
+		}
+		results = append(results, sarifResult{
+			RuleID:    string(sc.Type),
+			RuleIndex: ruleIndex[sc.Type],
+			Level:     sarifLevel(sc.Severity),
+			Message:   sarifMessage{Text: fmt.Sprintf("%s: %s on %s", doc.Host, sc.Detail, service)},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uri}},
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               service,
+					FullyQualifiedName: doc.Host + ":" + service,
+					Kind:               "module",
+				}},
+			}},
+			PartialFingerprints: map[string]string{"hostDiff/v1": hex.EncodeToString(fingerprint[:])},
+			Properties:          props,
+		})
+	}
+
+	out := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: sarifToolName, Rules: rules}},
+			Results: results,
+			Props: map[string]interface{}{
+				"host":        doc.Host,
+				"from":        doc.From.ID,
+				"to":          doc.To.ID,
+				"riskScore":   doc.Risk.Score,
+				"contentHash": doc.Report.ContentHash(),
+			},
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
@@ -0,0 +1,197 @@
+// Package retention decides which of a host's snapshots to keep as they age.
+package retention
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Granularity is how many snapshots a tier keeps: all of them, or one per
+// calendar period (in UTC).
+type Granularity string
+
+const (
+	KeepAll Granularity = "all"
+	Hourly  Granularity = "hourly"
+	Daily   Granularity = "daily"
+	Weekly  Granularity = "weekly"
+	Monthly Granularity = "monthly"
+	Yearly  Granularity = "yearly"
+)
+
+// period returns the calendar period a time falls in, or "" for KeepAll.
+func (g Granularity) period(t time.Time) string {
+	t = t.UTC()
+	switch g {
+	case Hourly:
+		return t.Format("2006-01-02T15")
+	case Daily:
+		return t.Format("2006-01-02")
+	case Weekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Monthly:
+		return t.Format("2006-01")
+	case Yearly:
+		return t.Format("2006")
+	default:
+		return ""
+	}
+}
+
+// Tier applies to snapshots younger than MaxAge and at least as old as the
+// previous tier's MaxAge. A zero MaxAge means no limit.
+type Tier struct {
+	Keep   Granularity
+	MaxAge time.Duration
+}
+
+// Policy is a list of tiers, youngest first. Snapshots older than the last
+// tier's MaxAge are pruned. Whatever the tiers say, a host's latest snapshot
+// and every snapshot that changed something are kept.
+type Policy struct {
+	Tiers []Tier
+}
+
+// ageUnits are the units a tier's maximum age can be written in.
+var ageUnits = map[byte]time.Duration{
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+	'y': 365 * 24 * time.Hour,
+}
+
+// ParsePolicy parses a comma-separated list of tiers, each a granularity
+// optionally followed by ":" and a maximum age in hours (h), days (d), weeks
+// (w) or years (y), such as "all:30d,daily:1y,monthly". Ages must increase
+// from tier to tier, and only the last tier may leave it out.
+func ParsePolicy(spec string) (*Policy, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, fmt.Errorf("retention policy is empty")
+	}
+
+	policy := &Policy{}
+	for i, field := range strings.Split(spec, ",") {
+		keep, age, hasAge := strings.Cut(strings.TrimSpace(field), ":")
+		tier := Tier{Keep: Granularity(strings.ToLower(keep))}
+		switch tier.Keep {
+		case KeepAll, Hourly, Daily, Weekly, Monthly, Yearly:
+		default:
+			return nil, fmt.Errorf("tier %d: unknown granularity %q: expected all, hourly, daily, weekly, monthly or yearly", i+1, keep)
+		}
+
+		if hasAge {
+			maxAge, err := parseAge(age)
+			if err != nil {
+				return nil, fmt.Errorf("tier %d: %w", i+1, err)
+			}
+			if i > 0 && maxAge <= policy.Tiers[i-1].MaxAge {
+				return nil, fmt.Errorf("tier %d: age %s is not older than the previous tier's", i+1, age)
+			}
+			tier.MaxAge = maxAge
+		}
+		if i > 0 && policy.Tiers[i-1].MaxAge == 0 {
+			return nil, fmt.Errorf("tier %d: only the last tier may have no age", i)
+		}
+		policy.Tiers = append(policy.Tiers, tier)
+	}
+	return policy, nil
+}
+
+// parseAge parses a positive whole number of hours, days, weeks or years.
+func parseAge(age string) (time.Duration, error) {
+	age = strings.TrimSpace(age)
+	if age == "" {
+		return 0, fmt.Errorf("age is empty")
+	}
+	unit, ok := ageUnits[age[len(age)-1]]
+	n, err := strconv.Atoi(age[:len(age)-1])
+	if !ok || err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid age %q: expected a number of hours, days, weeks or years such as 30d", age)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// String returns the policy in the form ParsePolicy accepts.
+func (p *Policy) String() string {
+	tiers := make([]string, len(p.Tiers))
+	for i, tier := range p.Tiers {
+		tiers[i] = string(tier.Keep)
+		switch {
+		case tier.MaxAge == 0:
+		case tier.MaxAge%ageUnits['d'] == 0:
+			tiers[i] += fmt.Sprintf(":%dd", tier.MaxAge/ageUnits['d'])
+		default:
+			tiers[i] += fmt.Sprintf(":%dh", tier.MaxAge/time.Hour)
+		}
+	}
+	return strings.Join(tiers, ",")
+}
+
+// tier returns the index of the tier for a snapshot of some age, or -1 if
+// it is older than every tier.
+func (p *Policy) tier(age time.Duration) int {
+	for i, tier := range p.Tiers {
+		if tier.MaxAge == 0 || age < tier.MaxAge {
+			return i
+		}
+	}
+	return -1
+}
+
+// Snapshot is what a Policy needs to know about a stored snapshot.
+type Snapshot struct {
+	ID   string
+	Time time.Time
+	// Changed is set if the snapshot differs from the host's previous one,
+	// or is its first.
+	Changed bool
+}
+
+// bucket is a calendar period within a tier.
+type bucket struct {
+	tier   int
+	period string
+}
+
+// Prune returns the snapshots of one host, oldest first, that the policy
+// doesn't keep at time now. In a tier that keeps one snapshot per period,
+// the newest one of each period is kept, unless a changed snapshot already
+// represents it.
+func (p *Policy) Prune(snapshots []Snapshot, now time.Time) []Snapshot {
+	keep := make([]bool, len(snapshots))
+	newest := make(map[bucket]int)
+	represented := make(map[bucket]bool)
+	for i, snap := range snapshots {
+		required := snap.Changed || i == len(snapshots)-1
+		tier := p.tier(now.Sub(snap.Time))
+		switch {
+		case tier < 0:
+			keep[i] = required
+		case p.Tiers[tier].Keep == KeepAll:
+			keep[i] = true
+		default:
+			b := bucket{tier: tier, period: p.Tiers[tier].Keep.period(snap.Time)}
+			if required {
+				keep[i] = true
+				represented[b] = true
+				delete(newest, b)
+			} else if !represented[b] {
+				newest[b] = i
+			}
+		}
+	}
+	for _, i := range newest {
+		keep[i] = true
+	}
+
+	var pruned []Snapshot
+	for i, snap := range snapshots {
+		if !keep[i] {
+			pruned = append(pruned, snap)
+		}
+	}
+	return pruned
+}
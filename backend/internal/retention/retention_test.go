@@ -0,0 +1,98 @@
+package retention
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy(" all:30d, Daily:1y ,monthly")
+	if err != nil {
+		t.Fatalf("ParsePolicy failed: %v", err)
+	}
+	want := []Tier{
+		{Keep: KeepAll, MaxAge: 30 * 24 * time.Hour},
+		{Keep: Daily, MaxAge: 365 * 24 * time.Hour},
+		{Keep: Monthly},
+	}
+	if len(policy.Tiers) != len(want) {
+		t.Fatalf("Expected %d tiers, got %+v", len(want), policy.Tiers)
+	}
+	for i := range want {
+		if policy.Tiers[i] != want[i] {
+			t.Errorf("Tier %d: expected %+v, got %+v", i, want[i], policy.Tiers[i])
+		}
+	}
+	if got := policy.String(); got != "all:30d,daily:365d,monthly" {
+		t.Errorf("String() = %q", got)
+	}
+	if got, _ := ParsePolicy("hourly:36h,weekly:8w"); got.String() != "hourly:36h,weekly:56d" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for spec, wantErr := range map[string]string{
+		"":                  "empty",
+		"all:30d,sometimes": "unknown granularity",
+		"all:30":            "invalid age",
+		"all:-1d":           "invalid age",
+		"all:0d":            "invalid age",
+		"all:30d,daily:7d":  "not older",
+		"all,daily:1y":      "only the last tier",
+	} {
+		if _, err := ParsePolicy(spec); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ParsePolicy(%q): expected error containing %q, got %v", spec, wantErr, err)
+		}
+	}
+}
+
+func TestPolicy_Prune(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy, _ := ParsePolicy("all:2d,daily:30d,monthly:1y")
+	day := 24 * time.Hour
+
+	snapshots := []Snapshot{
+		{ID: "first", Time: now.Add(-500 * day), Changed: true},
+		{ID: "expired", Time: now.Add(-400 * day)},
+		{ID: "expired-change", Time: now.Add(-390 * day), Changed: true},
+		// One per month: the newest of March is kept
+		{ID: "march-1", Time: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: "march-2", Time: time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)},
+		// A change already represents April
+		{ID: "april-change", Time: time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC), Changed: true},
+		{ID: "april", Time: time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC)},
+		// One per day
+		{ID: "may-20-morning", Time: time.Date(2025, 5, 20, 6, 0, 0, 0, time.UTC)},
+		{ID: "may-20-evening", Time: time.Date(2025, 5, 20, 18, 0, 0, 0, time.UTC)},
+		{ID: "may-21", Time: time.Date(2025, 5, 21, 6, 0, 0, 0, time.UTC)},
+		// Everything from the last two days
+		{ID: "recent-1", Time: now.Add(-30 * time.Hour)},
+		{ID: "recent-2", Time: now.Add(-time.Hour)},
+	}
+
+	var got []string
+	for _, snap := range policy.Prune(snapshots, now) {
+		got = append(got, snap.ID)
+	}
+	want := "expired march-1 april may-20-morning"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Expected to prune %q, got %q", want, strings.Join(got, " "))
+	}
+}
+
+func TestPolicy_PruneKeepsLatest(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy, _ := ParsePolicy("all:7d")
+	snapshots := []Snapshot{
+		{ID: "a", Time: now.Add(-30 * 24 * time.Hour), Changed: true},
+		{ID: "b", Time: now.Add(-20 * 24 * time.Hour)},
+		{ID: "c", Time: now.Add(-10 * 24 * time.Hour)},
+	}
+	pruned := policy.Prune(snapshots, now)
+	if len(pruned) != 1 || pruned[0].ID != "b" {
+		t.Errorf("Expected only b to be pruned, got %+v", pruned)
+	}
+	if pruned := policy.Prune(nil, now); len(pruned) != 0 {
+		t.Errorf("Expected nothing to prune, got %+v", pruned)
+	}
+}
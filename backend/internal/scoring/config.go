@@ -0,0 +1,168 @@
+// Package scoring assigns severities to the changes in a diff report and rolls
+// them up into an overall host-change risk score.
+package scoring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeType identifies a kind of scored change.
+type ChangeType string
+
+// Change types recognized by the scorer. Each one has a configurable weight.
+const (
+	PortAdded         ChangeType = "port_added"
+	PortRemoved       ChangeType = "port_removed"
+	ServiceMoved      ChangeType = "service_moved"
+	CVEAdded          ChangeType = "cve_added"
+	CVERemoved        ChangeType = "cve_removed"
+	TLSAdded          ChangeType = "tls_added"
+	TLSRemoved        ChangeType = "tls_removed"
+	TLSDowngrade      ChangeType = "tls_downgrade"
+	TLSUpgrade        ChangeType = "tls_upgrade"
+	TLSVersionChanged ChangeType = "tls_version_changed" // Either side is an unrecognized version
+	TLSCipherChanged  ChangeType = "tls_cipher_changed"
+	CertRotated       ChangeType = "cert_rotated"
+	CertDetailChanged ChangeType = "cert_detail_changed"
+	CertSelfSigned    ChangeType = "cert_self_signed"
+	SoftwareDowngrade ChangeType = "software_downgrade"
+	SoftwareUpgrade   ChangeType = "software_upgrade"
+	SoftwareChanged   ChangeType = "software_changed"
+	StatusServerError ChangeType = "status_5xx"
+	StatusChanged     ChangeType = "status_changed"
+	FieldChanged      ChangeType = "field_changed"
+	OSChanged         ChangeType = "os_changed"
+)
+
+// Thresholds are the minimum weights at which a change reaches each severity.
+// Changes weighing less than Low are informational.
+type Thresholds struct {
+	Low      float64 `yaml:"low"`
+	Medium   float64 `yaml:"medium"`
+	High     float64 `yaml:"high"`
+	Critical float64 `yaml:"critical"`
+}
+
+// Config holds the scoring weights and severity thresholds.
+type Config struct {
+	Weights    map[ChangeType]float64 `yaml:"weights"`
+	Thresholds Thresholds             `yaml:"thresholds"`
+	// MaxScore caps the overall risk score.
+	MaxScore float64 `yaml:"max_score"`
+}
+
+// DefaultConfig returns the built-in weights used when no config file is given.
+func DefaultConfig() *Config {
+	return &Config{
+		Weights: map[ChangeType]float64{
+			PortAdded:         5,
+			PortRemoved:       1,
+			ServiceMoved:      2,
+			CVEAdded:          9,
+			CVERemoved:        0.5,
+			TLSAdded:          0.5,
+			TLSRemoved:        8,
+			TLSDowngrade:      7,
+			TLSUpgrade:        0.5,
+			TLSVersionChanged: 3,
+			TLSCipherChanged:  2,
+			CertRotated:       1.5,
+			CertDetailChanged: 1,
+			CertSelfSigned:    6,
+			SoftwareDowngrade: 8,
+			SoftwareUpgrade:   0.5,
+			SoftwareChanged:   3,
+			StatusServerError: 4,
+			StatusChanged:     0.5,
+			FieldChanged:      0.5,
+			OSChanged:         3,
+		},
+		Thresholds: Thresholds{
+			Low:      1,
+			Medium:   4,
+			High:     7,
+			Critical: 9,
+		},
+		MaxScore: 100,
+	}
+}
+
+// LoadConfig reads a YAML or JSON scoring config. Weights and thresholds that
+// the file doesn't set keep their default values.
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scoring config: %w", err)
+	}
+	return ParseConfig(content)
+}
+
+// configFile is the layout of a scoring config file. Pointers tell an omitted
+// threshold or max score apart from one set to zero.
+type configFile struct {
+	Weights    map[ChangeType]float64 `yaml:"weights"`
+	Thresholds struct {
+		Low      *float64 `yaml:"low"`
+		Medium   *float64 `yaml:"medium"`
+		High     *float64 `yaml:"high"`
+		Critical *float64 `yaml:"critical"`
+	} `yaml:"thresholds"`
+	MaxScore *float64 `yaml:"max_score"`
+}
+
+// ParseConfig parses a YAML or JSON scoring config on top of DefaultConfig.
+// Unknown keys are rejected.
+func ParseConfig(content []byte) (*Config, error) {
+	cfg := DefaultConfig()
+
+	var file configFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse scoring config: %w", err)
+	}
+
+	for changeType, weight := range file.Weights {
+		if _, ok := cfg.Weights[changeType]; !ok {
+			return nil, fmt.Errorf("unknown change type in scoring config: %s", changeType)
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("negative weight for %s: %v", changeType, weight)
+		}
+		cfg.Weights[changeType] = weight
+	}
+
+	for _, threshold := range []struct {
+		value *float64
+		dst   *float64
+	}{
+		{file.Thresholds.Low, &cfg.Thresholds.Low},
+		{file.Thresholds.Medium, &cfg.Thresholds.Medium},
+		{file.Thresholds.High, &cfg.Thresholds.High},
+		{file.Thresholds.Critical, &cfg.Thresholds.Critical},
+	} {
+		if threshold.value != nil {
+			*threshold.dst = *threshold.value
+		}
+	}
+	if file.MaxScore != nil {
+		if *file.MaxScore <= 0 {
+			return nil, fmt.Errorf("max_score must be positive, got %v", *file.MaxScore)
+		}
+		cfg.MaxScore = *file.MaxScore
+	}
+
+	t := cfg.Thresholds
+	if !(t.Low <= t.Medium && t.Medium <= t.High && t.High <= t.Critical) {
+		return nil, fmt.Errorf("severity thresholds must be ascending: low=%v medium=%v high=%v critical=%v",
+			t.Low, t.Medium, t.High, t.Critical)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,284 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+)
+
+// Severity ranks how concerning a change is.
+type Severity int
+
+// Severities, from least to most severe.
+const (
+	SeverityInfo Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+// String returns the lowercase severity name.
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// ScoredChange is a single change from a DiffReport with its weight and severity.
+type ScoredChange struct {
+	Type     ChangeType
+	Port     int
+	Protocol string
+	Field    string // Field name the change was matched against by diff rules
+	Detail   string
+	// CVEID is the vulnerability of a cve_added or cve_removed change.
+	CVEID    string
+	Weight   float64
+	Severity Severity
+	// DowngradedBy names the rule that capped this change's severity, if any.
+	DowngradedBy string
+}
+
+// Result is the risk assessment of a DiffReport.
+type Result struct {
+	// Score is the sum of all change weights, capped at Config.MaxScore.
+	Score float64
+	// Severity is the highest severity of any single change.
+	Severity Severity
+	Changes  []ScoredChange
+}
+
+// Score assigns a weight and severity to every change in the report and
+// rolls them up into an overall risk score.
+func Score(report *diff.DiffReport, cfg *Config) *Result {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	result := &Result{}
+	add := func(changeType ChangeType, port int, protocol, field, detail string) {
+		weight := cfg.Weights[changeType]
+		sc := ScoredChange{
+			Type:     changeType,
+			Port:     port,
+			Protocol: protocol,
+			Field:    field,
+			Detail:   detail,
+			Weight:   weight,
+			Severity: cfg.severityFor(weight),
+		}
+		if capName, rule, ok := report.SeverityCap(port, protocol, field); ok {
+			if capped := ParseSeverity(capName); capped < sc.Severity {
+				sc.Severity = capped
+				sc.Weight = math.Min(sc.Weight, cfg.minWeightFor(capped))
+				sc.DowngradedBy = rule
+			}
+		}
+		result.Changes = append(result.Changes, sc)
+		result.Score += sc.Weight
+		if sc.Severity > result.Severity {
+			result.Severity = sc.Severity
+		}
+	}
+
+	if oc := report.OSChange; oc != nil {
+		add(OSChanged, 0, "", diff.RuleFieldOS, fmt.Sprintf("operating system %s -> %s", oc.Old, oc.New))
+	}
+	for _, s := range report.AddedServices {
+		add(PortAdded, s.Port, s.Protocol, diff.RuleFieldService, fmt.Sprintf("new exposed port %d/%s", s.Port, s.Protocol))
+	}
+	for _, s := range report.RemovedServices {
+		add(PortRemoved, s.Port, s.Protocol, diff.RuleFieldService, fmt.Sprintf("port %d/%s closed", s.Port, s.Protocol))
+	}
+	for _, mv := range report.MovedServices {
+		add(ServiceMoved, mv.NewPort, mv.Protocol, diff.RuleFieldService,
+			fmt.Sprintf("service moved from port %d to %d", mv.OldPort, mv.NewPort))
+	}
+	addCVE := func(changeType ChangeType, cve diff.CVEChange, detail string) {
+		add(changeType, cve.Port, cve.Protocol, diff.RuleFieldVulnerability, detail)
+		result.Changes[len(result.Changes)-1].CVEID = cve.CVEID
+	}
+	for _, cve := range report.AddedCVEs {
+		addCVE(CVEAdded, cve, fmt.Sprintf("new vulnerability %s", cve.CVEID))
+	}
+	for _, cve := range report.RemovedCVEs {
+		addCVE(CVERemoved, cve, fmt.Sprintf("vulnerability %s resolved", cve.CVEID))
+	}
+
+	for _, sc := range report.ChangedServices {
+		scoreServiceChange(sc, add)
+	}
+
+	for _, vc := range report.VersionChanges {
+		detail := fmt.Sprintf("%s %s -> %s (%s)", vc.Product, vc.OldVersion, vc.NewVersion, vc.Kind)
+		switch {
+		case vc.Kind == diff.VersionEquivalent:
+			// Same version spelled differently; nothing to score
+		case vc.Kind.IsDowngrade():
+			add(SoftwareDowngrade, vc.Port, vc.Protocol, "software_version", detail)
+		case vc.Kind == diff.VersionMajorUpgrade || vc.Kind == diff.VersionMinorUpgrade ||
+			vc.Kind == diff.VersionPatchUpgrade:
+			add(SoftwareUpgrade, vc.Port, vc.Protocol, "software_version", detail)
+		default:
+			add(SoftwareChanged, vc.Port, vc.Protocol, "software_version", detail)
+		}
+	}
+
+	for _, cc := range report.CertChanges {
+		field := "tls_cert_" + string(cc.Field)
+		detail := fmt.Sprintf("certificate %s: %s -> %s", cc.Field, cc.OldValue, cc.NewValue)
+		switch {
+		case cc.Field == diff.CertFieldFingerprint:
+			add(CertRotated, cc.Port, cc.Protocol, field, detail)
+		case cc.Field == diff.CertFieldSelfSigned && cc.NewValue == "true":
+			add(CertSelfSigned, cc.Port, cc.Protocol, field, detail)
+		default:
+			add(CertDetailChanged, cc.Port, cc.Protocol, field, detail)
+		}
+	}
+
+	for _, fc := range report.FieldChanges {
+		add(FieldChanged, fc.Port, fc.Protocol, fc.Path, fmt.Sprintf("%s %s", fc.Path, fc.Kind))
+	}
+
+	result.Score = math.Min(result.Score, cfg.MaxScore)
+	return result
+}
+
+// scoreServiceChange scores the typed attribute changes of a single service.
+// Software versions, certificates and untyped fields are scored from their own
+// report sections.
+func scoreServiceChange(sc diff.ServiceChange, add func(ChangeType, int, string, string, string)) {
+	before, after := sc.Before, sc.After
+
+	switch {
+	case before.TLS != nil && after.TLS == nil:
+		add(TLSRemoved, sc.Port, sc.Protocol, "tls", "TLS removed")
+	case before.TLS == nil && after.TLS != nil:
+		add(TLSAdded, sc.Port, sc.Protocol, "tls", "TLS added")
+	case before.TLS != nil && after.TLS != nil:
+		if before.TLS.Version != after.TLS.Version {
+			detail := fmt.Sprintf("TLS %s -> %s", before.TLS.Version, after.TLS.Version)
+			rankBefore, rankAfter := TLSVersionRank(before.TLS.Version), TLSVersionRank(after.TLS.Version)
+			switch {
+			case rankBefore == 0 || rankAfter == 0:
+				add(TLSVersionChanged, sc.Port, sc.Protocol, "tls_version", detail)
+			case rankAfter < rankBefore:
+				add(TLSDowngrade, sc.Port, sc.Protocol, "tls_version", detail)
+			default:
+				add(TLSUpgrade, sc.Port, sc.Protocol, "tls_version", detail)
+			}
+		}
+		if before.TLS.Cipher != after.TLS.Cipher {
+			add(TLSCipherChanged, sc.Port, sc.Protocol, "tls_cipher",
+				fmt.Sprintf("cipher %s -> %s", before.TLS.Cipher, after.TLS.Cipher))
+		}
+	}
+
+	if before.Status != after.Status && (before.Status != 0 || after.Status != 0) {
+		detail := fmt.Sprintf("status %d -> %d", before.Status, after.Status)
+		if after.Status >= 500 && after.Status < 600 && (before.Status < 500 || before.Status >= 600) {
+			add(StatusServerError, sc.Port, sc.Protocol, "status", detail)
+		} else {
+			add(StatusChanged, sc.Port, sc.Protocol, "status", detail)
+		}
+	}
+
+	if before.Software.Product != after.Software.Product {
+		add(SoftwareChanged, sc.Port, sc.Protocol, "software_product",
+			fmt.Sprintf("product %s -> %s", before.Software.Product, after.Software.Product))
+	}
+	if before.Software.Vendor != after.Software.Vendor {
+		add(SoftwareChanged, sc.Port, sc.Protocol, "software_vendor",
+			fmt.Sprintf("vendor %s -> %s", before.Software.Vendor, after.Software.Vendor))
+	}
+}
+
+// severityFor maps a change weight onto a severity using the configured thresholds.
+func (c *Config) severityFor(weight float64) Severity {
+	t := c.Thresholds
+	switch {
+	case weight >= t.Critical:
+		return SeverityCritical
+	case weight >= t.High:
+		return SeverityHigh
+	case weight >= t.Medium:
+		return SeverityMedium
+	case weight >= t.Low:
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
+
+// minWeightFor returns the smallest weight that reaches a severity. Changes
+// capped by a downgrade rule contribute at most this much to the score.
+func (c *Config) minWeightFor(severity Severity) float64 {
+	t := c.Thresholds
+	switch severity {
+	case SeverityCritical:
+		return t.Critical
+	case SeverityHigh:
+		return t.High
+	case SeverityMedium:
+		return t.Medium
+	case SeverityLow:
+		return t.Low
+	default:
+		return 0
+	}
+}
+
+// ParseSeverity parses a lowercase severity name. Unknown names map to SeverityInfo.
+func ParseSeverity(name string) Severity {
+	switch strings.ToLower(name) {
+	case "low":
+		return SeverityLow
+	case "medium":
+		return SeverityMedium
+	case "high":
+		return SeverityHigh
+	case "critical":
+		return SeverityCritical
+	default:
+		return SeverityInfo
+	}
+}
+
+// tlsVersionNonAlnum strips separators so "TLSv1.2", "tlsv1_2" and "TLS 1.2" compare equal.
+var tlsVersionNonAlnum = regexp.MustCompile(`[^a-z0-9]`)
+
+// TLSVersionRank orders TLS/SSL protocol versions from oldest to newest.
+// Unknown versions rank 0.
+func TLSVersionRank(version string) int {
+	normalized := tlsVersionNonAlnum.ReplaceAllString(strings.ToLower(version), "")
+	switch normalized {
+	case "sslv2", "ssl2":
+		return 1
+	case "sslv3", "ssl3":
+		return 2
+	case "tlsv1", "tlsv10", "tls1", "tls10":
+		return 3
+	case "tlsv11", "tls11":
+		return 4
+	case "tlsv12", "tls12":
+		return 5
+	case "tlsv13", "tls13":
+		return 6
+	default:
+		return 0
+	}
+}
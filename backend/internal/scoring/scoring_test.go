@@ -0,0 +1,223 @@
+package scoring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+)
+
+func mustDiff(t *testing.T, a, b string) *diff.DiffReport {
+	t.Helper()
+	report, err := diff.DiffSnapshots([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	return report
+}
+
+func changeTypes(result *Result) map[ChangeType]ScoredChange {
+	types := make(map[ChangeType]ScoredChange)
+	for _, sc := range result.Changes {
+		types[sc.Type] = sc
+	}
+	return types
+}
+
+func TestScore_NoChanges(t *testing.T) {
+	snapshot := `{"services": [{"port": 80, "protocol": "HTTP", "status": 200}]}`
+	result := Score(mustDiff(t, snapshot, snapshot), nil)
+
+	if result.Score != 0 || result.Severity != SeverityInfo || len(result.Changes) != 0 {
+		t.Errorf("Expected empty result, got %+v", result)
+	}
+}
+
+func TestScore_ChangeTypes(t *testing.T) {
+	snapshotA := `{
+		"services": [
+			{"port": 22, "protocol": "SSH", "software": {"product": "openssh", "version": "8.9p1"}},
+			{"port": 80, "protocol": "HTTP", "status": 200},
+			{"port": 443, "protocol": "HTTPS", "tls": {"version": "tlsv1_3", "cipher": "A"}},
+			{"port": 8443, "protocol": "HTTPS", "tls": {"version": "tlsv1_2"}}
+		]
+	}`
+	snapshotB := `{
+		"services": [
+			{"port": 22, "protocol": "SSH", "software": {"product": "openssh", "version": "8.2p1"}},
+			{"port": 80, "protocol": "HTTP", "status": 503},
+			{"port": 443, "protocol": "HTTPS", "tls": {"version": "tlsv1_0", "cipher": "B"}},
+			{"port": 8443, "protocol": "HTTPS"},
+			{"port": 3389, "protocol": "RDP", "vulnerabilities": ["CVE-2023-0001"]}
+		]
+	}`
+
+	result := Score(mustDiff(t, snapshotA, snapshotB), nil)
+	types := changeTypes(result)
+
+	expected := map[ChangeType]Severity{
+		PortAdded:         SeverityMedium,
+		CVEAdded:          SeverityCritical,
+		TLSDowngrade:      SeverityHigh,
+		TLSCipherChanged:  SeverityLow,
+		TLSRemoved:        SeverityHigh,
+		SoftwareDowngrade: SeverityHigh,
+		StatusServerError: SeverityMedium,
+	}
+	for changeType, severity := range expected {
+		sc, ok := types[changeType]
+		if !ok {
+			t.Errorf("Expected %s change, got %+v", changeType, result.Changes)
+			continue
+		}
+		if sc.Severity != severity {
+			t.Errorf("%s: expected severity %s, got %s", changeType, severity, sc.Severity)
+		}
+	}
+
+	if result.Severity != SeverityCritical {
+		t.Errorf("Expected overall severity critical, got %s", result.Severity)
+	}
+	// 5 + 9 + 7 + 2 + 8 + 8 + 4
+	if result.Score != 43 {
+		t.Errorf("Expected score 43, got %v", result.Score)
+	}
+}
+
+func TestScore_MaxScoreCap(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxScore = 10
+
+	result := Score(mustDiff(t,
+		`{"services": []}`,
+		`{"services": [{"port": 1, "protocol": "TCP"}, {"port": 2, "protocol": "TCP"}, {"port": 3, "protocol": "TCP"}]}`,
+	), cfg)
+
+	if result.Score != 10 {
+		t.Errorf("Expected score capped at 10, got %v", result.Score)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+weights:
+  port_added: 2
+thresholds:
+  low: 1
+  medium: 2
+  high: 3
+  critical: 4
+`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	if cfg.Weights[PortAdded] != 2 {
+		t.Errorf("Expected port_added weight 2, got %v", cfg.Weights[PortAdded])
+	}
+	if cfg.Weights[CVEAdded] != DefaultConfig().Weights[CVEAdded] {
+		t.Errorf("Expected unset weights to keep defaults")
+	}
+	if cfg.severityFor(2) != SeverityMedium {
+		t.Errorf("Expected custom thresholds to apply")
+	}
+}
+
+func TestParseConfig_PartialThresholds(t *testing.T) {
+	cfg, err := ParseConfig([]byte("thresholds:\n  critical: 10\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	want := DefaultConfig().Thresholds
+	want.Critical = 10
+	if cfg.Thresholds != want {
+		t.Errorf("Expected only critical to change, got %+v", cfg.Thresholds)
+	}
+
+	if cfg, err := ParseConfig(nil); err != nil || cfg.Thresholds != DefaultConfig().Thresholds {
+		t.Errorf("Expected an empty config to keep the defaults, got %+v, %v", cfg, err)
+	}
+}
+
+func TestParseConfig_JSON(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"weights": {"cve_added": 10}, "max_score": 50}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	if cfg.Weights[CVEAdded] != 10 || cfg.MaxScore != 50 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown change type":  "weights:\n  port_opened: 1\n",
+		"negative weight":      "weights:\n  port_added: -1\n",
+		"descending threshold": "thresholds:\n  low: 5\n  medium: 4\n  high: 7\n  critical: 9\n",
+		"malformed":            "weights: [",
+		"misspelled threshold": "thresholds:\n  critcal: 10\n",
+		"misspelled key":       "weight:\n  port_added: 1\n",
+		"zero max score":       "max_score: 0\n",
+		"negative max score":   "max_score: -10\n",
+	}
+	for name, content := range tests {
+		if _, err := ParseConfig([]byte(content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoadConfig_RepoDefault(t *testing.T) {
+	path := filepath.Join("..", "..", "..", "config", "scoring.yaml")
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("config file not found: %v", err)
+	}
+	if _, err := LoadConfig(path); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+}
+
+func TestTLSVersionRank(t *testing.T) {
+	if TLSVersionRank("tlsv1_2") != TLSVersionRank("TLSv1.2") {
+		t.Error("Expected TLS version spellings to rank equally")
+	}
+	if TLSVersionRank("tlsv1_3") <= TLSVersionRank("tlsv1_2") {
+		t.Error("Expected TLS 1.3 to rank above TLS 1.2")
+	}
+	if TLSVersionRank("quic") != 0 {
+		t.Error("Expected unknown version to rank 0")
+	}
+}
+
+func TestScore_DowngradeRule(t *testing.T) {
+	rules, err := diff.ParseRules([]byte(`
+rules:
+  - name: cipher-rotation
+    field: tls_cipher
+    action: downgrade
+    severity: info
+`))
+	if err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+	report, err := diff.DiffSnapshots(
+		[]byte(`{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cipher": "A"}}]}`),
+		[]byte(`{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cipher": "B"}}]}`),
+		diff.WithRules(rules),
+	)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+
+	result := Score(report, nil)
+	sc, ok := changeTypes(result)[TLSCipherChanged]
+	if !ok {
+		t.Fatalf("Expected cipher change to be scored, got %+v", result.Changes)
+	}
+	if sc.Severity != SeverityInfo || sc.DowngradedBy != "cipher-rotation" {
+		t.Errorf("Expected info severity from cipher-rotation, got %s by %q", sc.Severity, sc.DowngradedBy)
+	}
+	if result.Score >= DefaultConfig().Weights[TLSCipherChanged] {
+		t.Errorf("Expected capped weight, got score %v", result.Score)
+	}
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/data"
+	"github.com/justicecaban/host-diff-tool/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxArchiveEntries caps the number of files read from one bulk upload.
+const maxArchiveEntries = 10000
+
+// DefaultMaxArchiveSize is the limit on the total size of the files in one
+// bulk upload, after decompression, used unless WithMaxArchiveSize overrides
+// it.
+const DefaultMaxArchiveSize = 256 << 20
+
+// errArchiveTooLarge is returned when the files in an archive add up to more
+// than the server's limit, such as for an archive bomb.
+var errArchiveTooLarge = errors.New("archive too large")
+
+// archiveEntry is a file read from an uploaded archive. Err is set when the
+// entry itself can't be used, e.g. because it is too large.
+type archiveEntry struct {
+	Name    string
+	Content []byte
+	Err     error
+}
+
+// BulkUpload handles the BulkUpload RPC. Every file in the archive is
+// validated the same way as a single upload. Valid files are then stored
+// either one by one or, for an atomic upload, in a single transaction that
+// is skipped entirely if any file was rejected.
+func (s *Server) BulkUpload(ctx context.Context, req *proto.BulkUploadRequest) (*proto.BulkUploadResponse, error) {
+	entries, err := readArchive(req.GetArchive(), req.GetFormat(), s.maxUploadSize, s.maxArchiveSize)
+	if err != nil {
+		log.Printf("BulkUpload error: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "failed to read archive: %v", err)
+	}
+
+	resp := &proto.BulkUploadResponse{Results: make([]*proto.BulkUploadResult, len(entries))}
+	var batch []data.NewSnapshot
+	var batchIndexes []int
+	for i, entry := range entries {
+		result := &proto.BulkUploadResult{Filename: entry.Name}
+		resp.Results[i] = result
+
+		err := entry.Err
+		var meta *snapshotMetadata
+		if err == nil {
+			meta, result.Warnings, err = s.checkUpload(path.Base(entry.Name), entry.Content)
+		}
+		if err != nil {
+			result.Status = proto.BulkUploadStatus_BULK_UPLOAD_STATUS_REJECTED
+			result.Reason = err.Error()
+			resp.Rejected++
+			continue
+		}
+
+		result.IpAddress, result.Timestamp = meta.IPAddress, meta.Timestamp
+		batch = append(batch, data.NewSnapshot{IPAddress: meta.IPAddress, Timestamp: meta.Timestamp, Data: entry.Content})
+		batchIndexes = append(batchIndexes, i)
+	}
+
+	if req.GetAtomic() && resp.Rejected > 0 {
+		rollBack(resp, batchIndexes)
+		return resp, nil
+	}
+
+	outcomes, err := s.db.InsertSnapshots(batch, req.GetAtomic())
+	if err != nil {
+		log.Printf("BulkUpload error: %v", err)
+		return nil, fmt.Errorf("failed to insert snapshots: %w", err)
+	}
+	for j, outcome := range outcomes {
+		result := resp.Results[batchIndexes[j]]
+		switch {
+		case outcome.Err != nil:
+			result.Status = proto.BulkUploadStatus_BULK_UPLOAD_STATUS_REJECTED
+			result.Reason = outcome.Err.Error()
+			resp.Rejected++
+		case outcome.Duplicate:
+			result.Status = proto.BulkUploadStatus_BULK_UPLOAD_STATUS_DUPLICATE
+			result.Id = outcome.ID
+			resp.Duplicates++
+		case outcome.ID != "":
+			result.Status = proto.BulkUploadStatus_BULK_UPLOAD_STATUS_INSERTED
+			result.Id = outcome.ID
+			resp.Inserted++
+		}
+	}
+	if req.GetAtomic() && resp.Rejected > 0 {
+		rollBack(resp, batchIndexes)
+		return resp, nil
+	}
+	resp.Committed = true
+
+	return resp, nil
+}
+
+// rollBack marks the files of a rolled-back atomic upload that weren't
+// rejected themselves as skipped.
+func rollBack(resp *proto.BulkUploadResponse, batchIndexes []int) {
+	for _, i := range batchIndexes {
+		if result := resp.Results[i]; result.Status != proto.BulkUploadStatus_BULK_UPLOAD_STATUS_REJECTED {
+			result.Status = proto.BulkUploadStatus_BULK_UPLOAD_STATUS_SKIPPED
+			result.Reason = fmt.Sprintf("atomic upload rolled back: %d file(s) rejected", resp.Rejected)
+		}
+	}
+}
+
+// readArchive returns the regular files in a tar.gz or zip archive, in
+// archive order. Directories are skipped; files larger than maxSize and
+// other entry types are returned with an error. It fails with
+// errArchiveTooLarge as soon as the files add up to more than maxTotal bytes,
+// without decompressing any further.
+func readArchive(archive []byte, format proto.ArchiveFormat, maxSize, maxTotal int64) ([]archiveEntry, error) {
+	if format == proto.ArchiveFormat_ARCHIVE_FORMAT_UNSPECIFIED {
+		format = detectArchiveFormat(archive)
+	}
+
+	budget := &archiveBudget{maxSize: maxSize, maxTotal: maxTotal, remaining: maxTotal}
+	switch format {
+	case proto.ArchiveFormat_ARCHIVE_FORMAT_TAR_GZ:
+		return readTarGz(archive, budget)
+	case proto.ArchiveFormat_ARCHIVE_FORMAT_ZIP:
+		return readZip(archive, budget)
+	default:
+		return nil, errors.New("unrecognized archive format: expected tar.gz or zip")
+	}
+}
+
+// detectArchiveFormat identifies an archive by its magic bytes.
+func detectArchiveFormat(archive []byte) proto.ArchiveFormat {
+	switch {
+	case bytes.HasPrefix(archive, []byte{0x1f, 0x8b}):
+		return proto.ArchiveFormat_ARCHIVE_FORMAT_TAR_GZ
+	case bytes.HasPrefix(archive, []byte("PK\x03\x04")), bytes.HasPrefix(archive, []byte("PK\x05\x06")):
+		return proto.ArchiveFormat_ARCHIVE_FORMAT_ZIP
+	default:
+		return proto.ArchiveFormat_ARCHIVE_FORMAT_UNSPECIFIED
+	}
+}
+
+func readTarGz(archive []byte, budget *archiveBudget) ([]archiveEntry, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	var entries []archiveEntry
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if len(entries) == maxArchiveEntries {
+			return nil, fmt.Errorf("archive has more than %d files", maxArchiveEntries)
+		}
+
+		entry := archiveEntry{Name: hdr.Name}
+		if hdr.Typeflag != tar.TypeReg {
+			entry.Err = errors.New("not a regular file")
+		} else {
+			entry.Content, entry.Err = budget.readEntry(tr)
+			if errors.Is(entry.Err, errArchiveTooLarge) {
+				return nil, entry.Err
+			}
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func readZip(archive []byte, budget *archiveBudget) ([]archiveEntry, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	var entries []archiveEntry
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if len(entries) == maxArchiveEntries {
+			return nil, fmt.Errorf("archive has more than %d files", maxArchiveEntries)
+		}
+
+		entry := archiveEntry{Name: f.Name}
+		if !f.Mode().IsRegular() {
+			entry.Err = errors.New("not a regular file")
+		} else if rc, err := f.Open(); err != nil {
+			entry.Err = fmt.Errorf("failed to open file: %w", err)
+		} else {
+			entry.Content, entry.Err = budget.readEntry(rc)
+			rc.Close()
+			if errors.Is(entry.Err, errArchiveTooLarge) {
+				return nil, entry.Err
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// archiveBudget limits how much of an archive is decompressed: maxSize bytes
+// per file and maxTotal for all files together.
+type archiveBudget struct {
+	maxSize   int64
+	maxTotal  int64
+	remaining int64
+}
+
+// readEntry reads one archive file, refusing files larger than maxSize
+// without decompressing more than that. It fails with errArchiveTooLarge if
+// the file doesn't fit in what is left of the total.
+func (b *archiveBudget) readEntry(r io.Reader) ([]byte, error) {
+	limit := b.maxSize
+	if b.remaining < limit {
+		limit = b.remaining
+	}
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	b.remaining -= int64(len(content))
+	if int64(len(content)) > b.maxSize {
+		return nil, fmt.Errorf("file exceeds the %d byte limit", b.maxSize)
+	}
+	if b.remaining < 0 {
+		return nil, fmt.Errorf("%w: the files exceed the %d byte limit", errArchiveTooLarge, b.maxTotal)
+	}
+	return content, nil
+}
@@ -0,0 +1,276 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/data"
+	"github.com/justicecaban/host-diff-tool/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type archiveFile struct {
+	name    string
+	content string
+}
+
+func tarGzArchive(t *testing.T, files []archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "scan/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		t.Fatalf("Failed to write tar header: %v", err)
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(f.content))}); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(f.content)); err != nil {
+			t.Fatalf("Failed to write tar entry: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar writer: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files []archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatalf("Failed to create zip entry: %v", err)
+		}
+		if _, err := w.Write([]byte(f.content)); err != nil {
+			t.Fatalf("Failed to write zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+var bulkFiles = []archiveFile{
+	{"scan/host_10.0.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`},
+	{"scan/host_10.0.0.2_2025-01-01T00-00-00Z.json", `{"services": []}`},
+	{"scan/host_10.0.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`},
+	{"scan/notes.txt", "not a snapshot"},
+	{"scan/host_10.0.0.3_2025-01-01T00-00-00Z.json", `{"services": [`},
+}
+
+func bulkStatuses(resp *proto.BulkUploadResponse) []proto.BulkUploadStatus {
+	statuses := make([]proto.BulkUploadStatus, len(resp.Results))
+	for i, result := range resp.Results {
+		statuses[i] = result.Status
+	}
+	return statuses
+}
+
+func TestBulkUpload_BestEffort(t *testing.T) {
+	archives := map[string][]byte{
+		"tar.gz": tarGzArchive(t, bulkFiles),
+		"zip":    zipArchive(t, bulkFiles),
+	}
+
+	for name, archive := range archives {
+		server := newTestServer(t)
+		resp, err := server.BulkUpload(context.Background(), &proto.BulkUploadRequest{Archive: archive})
+		if err != nil {
+			t.Fatalf("%s: BulkUpload failed: %v", name, err)
+		}
+
+		want := []proto.BulkUploadStatus{
+			proto.BulkUploadStatus_BULK_UPLOAD_STATUS_INSERTED,
+			proto.BulkUploadStatus_BULK_UPLOAD_STATUS_INSERTED,
+			proto.BulkUploadStatus_BULK_UPLOAD_STATUS_DUPLICATE,
+			proto.BulkUploadStatus_BULK_UPLOAD_STATUS_REJECTED,
+			proto.BulkUploadStatus_BULK_UPLOAD_STATUS_REJECTED,
+		}
+		got := bulkStatuses(resp)
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %d results, got %v", name, len(want), got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: %s: expected %v, got %v", name, resp.Results[i].Filename, want[i], got[i])
+			}
+		}
+
+		if !resp.Committed || resp.Inserted != 2 || resp.Duplicates != 1 || resp.Rejected != 2 {
+			t.Errorf("%s: unexpected totals: %+v", name, resp)
+		}
+		if resp.Results[2].Id != resp.Results[0].Id {
+			t.Errorf("%s: expected duplicate to point at snapshot %s, got %s", name, resp.Results[0].Id, resp.Results[2].Id)
+		}
+		if resp.Results[3].Reason == "" || resp.Results[4].Reason == "" {
+			t.Errorf("%s: expected rejections to carry a reason", name)
+		}
+	}
+}
+
+func TestBulkUpload_Atomic(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := server.BulkUpload(context.Background(), &proto.BulkUploadRequest{
+		Archive: zipArchive(t, bulkFiles),
+		Atomic:  true,
+	})
+	if err != nil {
+		t.Fatalf("BulkUpload failed: %v", err)
+	}
+	if resp.Committed || resp.Inserted != 0 || resp.Rejected != 2 {
+		t.Errorf("Expected atomic upload with rejections to be rolled back, got %+v", resp)
+	}
+	for _, result := range resp.Results[:3] {
+		if result.Status != proto.BulkUploadStatus_BULK_UPLOAD_STATUS_SKIPPED {
+			t.Errorf("%s: expected skipped, got %v", result.Filename, result.Status)
+		}
+	}
+	history, err := server.db.GetSnapshotsByIP("10.0.0.1")
+	if err != nil {
+		t.Fatalf("GetSnapshotsByIP failed: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("Expected nothing stored, got %d snapshots", len(history))
+	}
+
+	resp, err = server.BulkUpload(context.Background(), &proto.BulkUploadRequest{
+		Archive: tarGzArchive(t, bulkFiles[:3]),
+		Format:  proto.ArchiveFormat_ARCHIVE_FORMAT_TAR_GZ,
+		Atomic:  true,
+	})
+	if err != nil {
+		t.Fatalf("BulkUpload failed: %v", err)
+	}
+	if !resp.Committed || resp.Inserted != 2 || resp.Duplicates != 1 {
+		t.Errorf("Expected valid atomic upload to commit, got %+v", resp)
+	}
+}
+
+func TestBulkUpload_Conflict(t *testing.T) {
+	files := []archiveFile{
+		{"scan/host_10.0.0.1_2025-01-01T00-00-00Z.json", `{"services": [{"port": 22}]}`},
+		{"scan/host_10.0.0.2_2025-01-01T00-00-00Z.json", `{"services": []}`},
+		{"scan/host_10.0.0.2_2025-01-01T00-00-00Z.json", `{"services": [{"port": 80}]}`},
+	}
+
+	for _, atomic := range []bool{false, true} {
+		server := newTestServer(t)
+		stored, err := server.db.InsertSnapshot("10.0.0.1", "2025-01-01T00:00:00Z", []byte(`{"services": []}`))
+		if err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+
+		resp, err := server.BulkUpload(context.Background(), &proto.BulkUploadRequest{
+			Archive: zipArchive(t, files),
+			Atomic:  atomic,
+		})
+		if err != nil {
+			t.Fatalf("atomic=%v: BulkUpload failed: %v", atomic, err)
+		}
+
+		want := []proto.BulkUploadStatus{
+			proto.BulkUploadStatus_BULK_UPLOAD_STATUS_REJECTED,
+			proto.BulkUploadStatus_BULK_UPLOAD_STATUS_INSERTED,
+			proto.BulkUploadStatus_BULK_UPLOAD_STATUS_REJECTED,
+		}
+		if atomic {
+			want[1] = proto.BulkUploadStatus_BULK_UPLOAD_STATUS_SKIPPED
+		}
+		got := bulkStatuses(resp)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("atomic=%v: %s: expected %v, got %v", atomic, resp.Results[i].Filename, want[i], got[i])
+			}
+		}
+		if !strings.Contains(resp.Results[0].Reason, "already stored as ID "+stored) {
+			t.Errorf("atomic=%v: expected conflict with snapshot %s, got reason %q", atomic, stored, resp.Results[0].Reason)
+		}
+		if resp.Results[2].Reason == "" {
+			t.Errorf("atomic=%v: expected conflict within the archive to carry a reason", atomic)
+		}
+		if resp.Committed == atomic || resp.Rejected != 2 || resp.Duplicates != 0 {
+			t.Errorf("atomic=%v: unexpected totals: %+v", atomic, resp)
+		}
+
+		history, err := server.db.GetSnapshotsByIP("10.0.0.2")
+		if err != nil {
+			t.Fatalf("GetSnapshotsByIP failed: %v", err)
+		}
+		wantStored := 1
+		if atomic {
+			wantStored = 0
+		}
+		if len(history) != wantStored {
+			t.Errorf("atomic=%v: expected %d snapshots of 10.0.0.2, got %d", atomic, wantStored, len(history))
+		}
+	}
+}
+
+func TestBulkUpload_ArchiveBomb(t *testing.T) {
+	server := newTestServer(t, WithMaxUploadSize(1<<20), WithMaxArchiveSize(4<<20))
+
+	// Eight highly compressible files of 1 MiB each: within the per-file
+	// limit, but twice the total
+	padding := strings.Repeat(" ", 1<<20-len(`{"services": []}`))
+	var files []archiveFile
+	for i := 1; i <= 8; i++ {
+		files = append(files, archiveFile{fmt.Sprintf("host_10.0.0.%d_2025-01-01T00-00-00Z.json", i), `{"services": []}` + padding})
+	}
+
+	for name, archive := range map[string][]byte{"tar.gz": tarGzArchive(t, files), "zip": zipArchive(t, files)} {
+		if len(archive) > 64<<10 {
+			t.Fatalf("%s: expected a small archive, got %d bytes", name, len(archive))
+		}
+		_, err := server.BulkUpload(context.Background(), &proto.BulkUploadRequest{Archive: archive})
+		if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "archive too large") {
+			t.Errorf("%s: expected InvalidArgument for an archive bomb, got %v", name, err)
+		}
+	}
+	if hosts, _, _ := server.db.ListHosts(data.HostQuery{}); len(hosts) != 0 {
+		t.Errorf("Expected nothing to be stored, got %d hosts", len(hosts))
+	}
+}
+
+func TestBulkUpload_Limits(t *testing.T) {
+	server := newTestServer(t, WithMaxUploadSize(20))
+
+	resp, err := server.BulkUpload(context.Background(), &proto.BulkUploadRequest{
+		Archive: tarGzArchive(t, []archiveFile{
+			{"host_10.0.0.1_2025-01-01T00-00-00Z.json", `{}`},
+			{"host_10.0.0.2_2025-01-01T00-00-00Z.json", `{"services": [], "padding": "xxxxxxxx"}`},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("BulkUpload failed: %v", err)
+	}
+	if got := bulkStatuses(resp); got[0] != proto.BulkUploadStatus_BULK_UPLOAD_STATUS_INSERTED ||
+		got[1] != proto.BulkUploadStatus_BULK_UPLOAD_STATUS_REJECTED {
+		t.Errorf("Expected oversized file to be rejected, got %v", got)
+	}
+
+	if _, err := server.BulkUpload(context.Background(), &proto.BulkUploadRequest{Archive: []byte("plain text")}); err == nil {
+		t.Error("Expected error for unrecognized archive")
+	}
+	if _, err := server.BulkUpload(context.Background(), &proto.BulkUploadRequest{
+		Archive: []byte("plain text"),
+		Format:  proto.ArchiveFormat_ARCHIVE_FORMAT_ZIP,
+	}); err == nil {
+		t.Error("Expected error for corrupt zip archive")
+	}
+}
@@ -0,0 +1,424 @@
+package server
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/data"
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+	"github.com/justicecaban/host-diff-tool/backend/internal/render"
+	"github.com/justicecaban/host-diff-tool/backend/internal/scoring"
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+// snapshotInfoToProto converts a stored snapshot's metadata into a proto SnapshotInfo.
+func snapshotInfoToProto(snap *data.Snapshot) *proto.SnapshotInfo {
+	return &proto.SnapshotInfo{
+		Id:        snap.ID,
+		IpAddress: snap.IPAddress,
+		Timestamp: snap.Timestamp,
+		Revision:  int32(snap.Revision),
+	}
+}
+
+// serviceRecordToProto converts an indexed service into a proto ServiceRecord.
+func serviceRecordToProto(rec *data.ServiceRecord) *proto.ServiceRecord {
+	return &proto.ServiceRecord{
+		SnapshotId: rec.SnapshotID,
+		IpAddress:  rec.IPAddress,
+		Timestamp:  rec.Timestamp,
+		Port:       int32(rec.Port),
+		Protocol:   rec.Protocol,
+		Software: &proto.Software{
+			Vendor:  rec.Vendor,
+			Product: rec.Product,
+			Version: rec.Version,
+		},
+		CveIds: rec.CVEs,
+	}
+}
+
+// hostSummaryToProto converts a data.HostSummary into a proto HostSummary.
+func hostSummaryToProto(h *data.HostSummary) *proto.HostSummary {
+	return &proto.HostSummary{
+		IpAddress:        h.IPAddress,
+		SnapshotCount:    int32(h.SnapshotCount),
+		FirstSeen:        h.FirstSeen,
+		LastSeen:         h.LastSeen,
+		LatestSnapshotId: h.LatestSnapshotID,
+		OpenPortCount:    int32(h.OpenPorts),
+		CveCount:         int32(h.CVEs),
+	}
+}
+
+// reportToProto converts a diff.DiffReport into its proto representation.
+func reportToProto(report *diff.DiffReport) *proto.DiffReport {
+	protoReport := &proto.DiffReport{
+		Summary: report.Summary,
+	}
+
+	if oc := report.OSChange; oc != nil {
+		protoReport.OsChanges = &proto.OSChange{
+			Oldname: oc.Old,
+			Newname: oc.New,
+		}
+	}
+
+	for _, svc := range report.AddedServices {
+		protoReport.AddedPorts = append(protoReport.AddedPorts, &proto.PortChange{
+			Port:        int32(svc.Port),
+			Protocol:    svc.Protocol,
+			OldState:    portStateClosed,
+			NewState:    portStateOpen,
+			NewService:  serviceLabel(svc),
+			NewSoftware: softwareToProto(svc.Software),
+		})
+		protoReport.AddedServices = append(protoReport.AddedServices, &proto.ServiceChange{
+			Name:       serviceName(svc),
+			NewVersion: svc.Software.Version,
+			Port:       int32(svc.Port),
+			Protocol:   svc.Protocol,
+		})
+	}
+
+	for _, svc := range report.RemovedServices {
+		protoReport.RemovedPorts = append(protoReport.RemovedPorts, &proto.PortChange{
+			Port:        int32(svc.Port),
+			Protocol:    svc.Protocol,
+			OldState:    portStateOpen,
+			NewState:    portStateClosed,
+			OldService:  serviceLabel(svc),
+			OldSoftware: softwareToProto(svc.Software),
+		})
+		protoReport.RemovedServices = append(protoReport.RemovedServices, &proto.ServiceChange{
+			Name:       serviceName(svc),
+			OldVersion: svc.Software.Version,
+			Port:       int32(svc.Port),
+			Protocol:   svc.Protocol,
+		})
+	}
+
+	for _, sc := range report.ChangedServices {
+		attributes := attributesToProto(sc)
+		protoReport.ChangedPorts = append(protoReport.ChangedPorts, &proto.PortChange{
+			Port:        int32(sc.Port),
+			Protocol:    sc.Protocol,
+			OldState:    portStateOpen,
+			NewState:    portStateOpen,
+			OldService:  serviceLabel(sc.Before),
+			NewService:  serviceLabel(sc.After),
+			Changes:     sc.Changes,
+			Attributes:  attributes,
+			OldSoftware: softwareToProto(sc.Before.Software),
+			NewSoftware: softwareToProto(sc.After.Software),
+		})
+		protoReport.ChangedServices = append(protoReport.ChangedServices, &proto.ServiceChange{
+			Name:       serviceName(sc.After),
+			OldVersion: sc.Before.Software.Version,
+			NewVersion: sc.After.Software.Version,
+			Changes:    sc.Changes,
+			Port:       int32(sc.Port),
+			Protocol:   sc.Protocol,
+			Attributes: attributes,
+		})
+	}
+
+	for _, mv := range report.MovedServices {
+		protoReport.MovedServices = append(protoReport.MovedServices, &proto.ServiceMove{
+			OldPort:   int32(mv.OldPort),
+			NewPort:   int32(mv.NewPort),
+			Protocol:  mv.Protocol,
+			MatchedOn: mv.MatchedOn,
+			Product:   mv.After.Software.Product,
+			Version:   mv.After.Software.Version,
+		})
+	}
+
+	for _, cve := range report.AddedCVEs {
+		protoReport.AddedCves = append(protoReport.AddedCves, &proto.CVEChange{
+			CveId:    cve.CVEID,
+			Port:     int32(cve.Port),
+			Protocol: cve.Protocol,
+		})
+	}
+
+	for _, cve := range report.RemovedCVEs {
+		protoReport.RemovedCves = append(protoReport.RemovedCves, &proto.CVEChange{
+			CveId:    cve.CVEID,
+			Port:     int32(cve.Port),
+			Protocol: cve.Protocol,
+		})
+	}
+
+	for _, cc := range report.CertChanges {
+		protoReport.CertificateChanges = append(protoReport.CertificateChanges, &proto.CertificateChange{
+			Port:     int32(cc.Port),
+			Protocol: cc.Protocol,
+			Field:    certificateFieldToProto(cc.Field),
+			OldValue: cc.OldValue,
+			NewValue: cc.NewValue,
+		})
+	}
+
+	for _, fc := range report.FieldChanges {
+		protoReport.FieldChanges = append(protoReport.FieldChanges, &proto.FieldChange{
+			Port:     int32(fc.Port),
+			Protocol: fc.Protocol,
+			Path:     fc.Path,
+			Kind:     fieldChangeKindToProto(fc.Kind),
+			OldValue: fc.OldValue,
+			NewValue: fc.NewValue,
+		})
+	}
+
+	for _, vc := range report.VersionChanges {
+		protoReport.VersionChanges = append(protoReport.VersionChanges, &proto.VersionChange{
+			Port:       int32(vc.Port),
+			Protocol:   vc.Protocol,
+			Product:    vc.Product,
+			OldVersion: vc.OldVersion,
+			NewVersion: vc.NewVersion,
+			Kind:       versionChangeKindToProto(vc.Kind),
+		})
+	}
+	protoReport.HasSoftwareDowngrade = len(report.Downgrades()) > 0
+
+	for _, m := range report.Suppressed {
+		protoReport.SuppressedChanges = append(protoReport.SuppressedChanges, &proto.SuppressedChange{
+			Rule:     m.Rule,
+			Port:     int32(m.Port),
+			Protocol: m.Protocol,
+			Field:    m.Field,
+			Detail:   m.Detail,
+		})
+	}
+	protoReport.SuppressedCount = int32(len(report.Suppressed))
+	protoReport.ContentHash = report.ContentHash()
+
+	return protoReport
+}
+
+// Port states used in PortChange.
+const (
+	portStateOpen   = "open"
+	portStateClosed = "closed"
+)
+
+// serviceName names a service by its software product, falling back to the protocol.
+func serviceName(svc diff.ServiceInfo) string {
+	if svc.Software.Product != "" {
+		return svc.Software.Product
+	}
+	return svc.Protocol
+}
+
+// serviceLabel describes a service as "product version", falling back to the protocol.
+func serviceLabel(svc diff.ServiceInfo) string {
+	if svc.Software.Product == "" {
+		return svc.Protocol
+	}
+	return strings.TrimSpace(svc.Software.Product + " " + svc.Software.Version)
+}
+
+// softwareToProto converts software details, returning nil if there are none.
+func softwareToProto(sw diff.SoftwareInfo) *proto.Software {
+	if sw == (diff.SoftwareInfo{}) {
+		return nil
+	}
+	return &proto.Software{
+		Vendor:  sw.Vendor,
+		Product: sw.Product,
+		Version: sw.Version,
+	}
+}
+
+// attributesToProto converts a service change's old and new values, sorted by field.
+func attributesToProto(sc diff.ServiceChange) []*proto.AttributeChange {
+	fields := make([]string, 0, len(sc.Values))
+	for field := range sc.Values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	attributes := make([]*proto.AttributeChange, 0, len(fields))
+	for _, field := range fields {
+		attributes = append(attributes, &proto.AttributeChange{
+			Field:    field,
+			OldValue: sc.Values[field].Old,
+			NewValue: sc.Values[field].New,
+		})
+	}
+	return attributes
+}
+
+// certificateFieldToProto maps a diff.CertificateField to its proto enum value.
+func certificateFieldToProto(field diff.CertificateField) proto.CertificateField {
+	switch field {
+	case diff.CertFieldFingerprint:
+		return proto.CertificateField_CERTIFICATE_FIELD_FINGERPRINT
+	case diff.CertFieldSubject:
+		return proto.CertificateField_CERTIFICATE_FIELD_SUBJECT
+	case diff.CertFieldIssuer:
+		return proto.CertificateField_CERTIFICATE_FIELD_ISSUER
+	case diff.CertFieldSANs:
+		return proto.CertificateField_CERTIFICATE_FIELD_SANS
+	case diff.CertFieldNotBefore:
+		return proto.CertificateField_CERTIFICATE_FIELD_NOT_BEFORE
+	case diff.CertFieldNotAfter:
+		return proto.CertificateField_CERTIFICATE_FIELD_NOT_AFTER
+	case diff.CertFieldKeyType:
+		return proto.CertificateField_CERTIFICATE_FIELD_KEY_TYPE
+	case diff.CertFieldKeySize:
+		return proto.CertificateField_CERTIFICATE_FIELD_KEY_SIZE
+	case diff.CertFieldSelfSigned:
+		return proto.CertificateField_CERTIFICATE_FIELD_SELF_SIGNED
+	default:
+		return proto.CertificateField_CERTIFICATE_FIELD_UNSPECIFIED
+	}
+}
+
+// fieldChangeKindToProto maps a diff.FieldChangeKind to its proto enum value.
+func fieldChangeKindToProto(kind diff.FieldChangeKind) proto.FieldChangeKind {
+	switch kind {
+	case diff.FieldAdded:
+		return proto.FieldChangeKind_FIELD_CHANGE_KIND_ADDED
+	case diff.FieldRemoved:
+		return proto.FieldChangeKind_FIELD_CHANGE_KIND_REMOVED
+	case diff.FieldChanged:
+		return proto.FieldChangeKind_FIELD_CHANGE_KIND_CHANGED
+	default:
+		return proto.FieldChangeKind_FIELD_CHANGE_KIND_UNSPECIFIED
+	}
+}
+
+// versionChangeKindToProto maps a diff.VersionChangeKind to its proto enum value.
+func versionChangeKindToProto(kind diff.VersionChangeKind) proto.VersionChangeKind {
+	switch kind {
+	case diff.VersionMajorUpgrade:
+		return proto.VersionChangeKind_VERSION_CHANGE_KIND_MAJOR_UPGRADE
+	case diff.VersionMinorUpgrade:
+		return proto.VersionChangeKind_VERSION_CHANGE_KIND_MINOR_UPGRADE
+	case diff.VersionPatchUpgrade:
+		return proto.VersionChangeKind_VERSION_CHANGE_KIND_PATCH_UPGRADE
+	case diff.VersionMajorDowngrade:
+		return proto.VersionChangeKind_VERSION_CHANGE_KIND_MAJOR_DOWNGRADE
+	case diff.VersionMinorDowngrade:
+		return proto.VersionChangeKind_VERSION_CHANGE_KIND_MINOR_DOWNGRADE
+	case diff.VersionPatchDowngrade:
+		return proto.VersionChangeKind_VERSION_CHANGE_KIND_PATCH_DOWNGRADE
+	case diff.VersionEquivalent:
+		return proto.VersionChangeKind_VERSION_CHANGE_KIND_EQUIVALENT
+	case diff.VersionUnparseable:
+		return proto.VersionChangeKind_VERSION_CHANGE_KIND_UNPARSEABLE
+	default:
+		return proto.VersionChangeKind_VERSION_CHANGE_KIND_UNSPECIFIED
+	}
+}
+
+// riskScoreToProto converts a scoring.Result into its proto representation.
+func riskScoreToProto(result *scoring.Result) *proto.RiskScore {
+	risk := &proto.RiskScore{
+		Score:    result.Score,
+		Severity: severityToProto(result.Severity),
+	}
+	for _, sc := range result.Changes {
+		risk.Changes = append(risk.Changes, &proto.ScoredChange{
+			Type:         string(sc.Type),
+			Port:         int32(sc.Port),
+			Protocol:     sc.Protocol,
+			Detail:       sc.Detail,
+			Weight:       sc.Weight,
+			Severity:     severityToProto(sc.Severity),
+			Field:        sc.Field,
+			DowngradedBy: sc.DowngradedBy,
+		})
+	}
+	return risk
+}
+
+// severityToProto maps a scoring.Severity to its proto enum value.
+func severityToProto(severity scoring.Severity) proto.Severity {
+	switch severity {
+	case scoring.SeverityInfo:
+		return proto.Severity_SEVERITY_INFO
+	case scoring.SeverityLow:
+		return proto.Severity_SEVERITY_LOW
+	case scoring.SeverityMedium:
+		return proto.Severity_SEVERITY_MEDIUM
+	case scoring.SeverityHigh:
+		return proto.Severity_SEVERITY_HIGH
+	case scoring.SeverityCritical:
+		return proto.Severity_SEVERITY_CRITICAL
+	default:
+		return proto.Severity_SEVERITY_UNSPECIFIED
+	}
+}
+
+// rulesFromProto converts request rules into a compiled diff.RuleSet.
+func rulesFromProto(rules []*proto.SuppressionRule) (*diff.RuleSet, error) {
+	rs := &diff.RuleSet{}
+	for _, r := range rules {
+		rule := diff.Rule{
+			Name:     r.GetName(),
+			Host:     r.GetHost(),
+			Ports:    r.GetPorts(),
+			Protocol: r.GetProtocol(),
+			Field:    r.GetField(),
+		}
+		switch r.GetAction() {
+		case proto.RuleAction_RULE_ACTION_SUPPRESS:
+			rule.Action = diff.ActionSuppress
+		case proto.RuleAction_RULE_ACTION_DOWNGRADE:
+			rule.Action = diff.ActionDowngrade
+			// Compile rejects the enum name of a severity without a cap
+			rule.Severity = r.GetSeverity().String()
+			if severity, ok := severityFromProto(r.GetSeverity()); ok {
+				rule.Severity = severity.String()
+			}
+		}
+		rs.Rules = append(rs.Rules, rule)
+	}
+	if err := rs.Compile(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// severityFromProto maps a proto Severity to a scoring.Severity. It reports
+// false for SEVERITY_UNSPECIFIED and unknown values.
+func severityFromProto(severity proto.Severity) (scoring.Severity, bool) {
+	switch severity {
+	case proto.Severity_SEVERITY_INFO:
+		return scoring.SeverityInfo, true
+	case proto.Severity_SEVERITY_LOW:
+		return scoring.SeverityLow, true
+	case proto.Severity_SEVERITY_MEDIUM:
+		return scoring.SeverityMedium, true
+	case proto.Severity_SEVERITY_HIGH:
+		return scoring.SeverityHigh, true
+	case proto.Severity_SEVERITY_CRITICAL:
+		return scoring.SeverityCritical, true
+	default:
+		return 0, false
+	}
+}
+
+// reportFormatFromProto maps a proto ReportFormat to a render.Format.
+func reportFormatFromProto(format proto.ReportFormat) (render.Format, bool) {
+	switch format {
+	case proto.ReportFormat_REPORT_FORMAT_TEXT:
+		return render.FormatText, true
+	case proto.ReportFormat_REPORT_FORMAT_MARKDOWN:
+		return render.FormatMarkdown, true
+	case proto.ReportFormat_REPORT_FORMAT_HTML:
+		return render.FormatHTML, true
+	case proto.ReportFormat_REPORT_FORMAT_CSV:
+		return render.FormatCSV, true
+	case proto.ReportFormat_REPORT_FORMAT_JSON:
+		return render.FormatJSON, true
+	case proto.ReportFormat_REPORT_FORMAT_SARIF:
+		return render.FormatSARIF, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/data"
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+// DeleteSnapshot handles the DeleteSnapshot RPC.
+func (s *Server) DeleteSnapshot(ctx context.Context, req *proto.DeleteSnapshotRequest) (*proto.DeleteSnapshotResponse, error) {
+	if req.GetReason() == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	snap, err := s.db.DeleteSnapshot(req.GetId(), audit(ctx, req.GetActor(), req.GetReason()))
+	if err != nil {
+		log.Printf("DeleteSnapshot error: %v", err)
+		return nil, fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	if snap == nil {
+		return nil, fmt.Errorf("snapshot with ID %s not found", req.GetId())
+	}
+	return &proto.DeleteSnapshotResponse{Snapshot: snapshotInfoToProto(snap)}, nil
+}
+
+// RestoreSnapshot handles the RestoreSnapshot RPC.
+func (s *Server) RestoreSnapshot(ctx context.Context, req *proto.RestoreSnapshotRequest) (*proto.RestoreSnapshotResponse, error) {
+	snap, err := s.db.RestoreSnapshot(req.GetId(), audit(ctx, req.GetActor(), req.GetReason()))
+	if err != nil {
+		log.Printf("RestoreSnapshot error: %v", err)
+		return nil, fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	if snap == nil {
+		return nil, fmt.Errorf("deleted snapshot with ID %s not found", req.GetId())
+	}
+	return &proto.RestoreSnapshotResponse{Snapshot: snapshotInfoToProto(snap)}, nil
+}
+
+// PurgeDeleted handles the PurgeDeleted RPC.
+func (s *Server) PurgeDeleted(ctx context.Context, req *proto.PurgeDeletedRequest) (*proto.PurgeDeletedResponse, error) {
+	if req.GetReason() == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	deletedBefore, err := normalizeTimeBound(req.GetDeletedBefore())
+	if err != nil {
+		return nil, fmt.Errorf("invalid deleted_before: %w", err)
+	}
+
+	purged, err := s.db.PurgeDeleted(deletedBefore, audit(ctx, req.GetActor(), req.GetReason()))
+	if err != nil {
+		log.Printf("PurgeDeleted error: %v", err)
+		return nil, fmt.Errorf("failed to purge deleted snapshots: %w", err)
+	}
+
+	resp := &proto.PurgeDeletedResponse{Snapshots: make([]*proto.SnapshotInfo, len(purged))}
+	for i, snap := range purged {
+		resp.Snapshots[i] = snapshotInfoToProto(snap)
+		log.Printf("Purged snapshot %s (%s at %s)", snap.ID, snap.IPAddress, snap.Timestamp)
+	}
+	return resp, nil
+}
+
+// audit describes who made a request and why. Without authentication the
+// actor is whatever the client says, falling back to its network address.
+func audit(ctx context.Context, actor, reason string) data.Audit {
+	if actor == "" {
+		actor = "unknown"
+		if p, ok := peerFromContext(ctx); ok && p.Addr != nil {
+			actor = p.Addr.String()
+		}
+	}
+	return data.Audit{Actor: actor, Reason: reason}
+}
+
+// peerFromContext is peer.FromContext, tolerating a nil context.
+func peerFromContext(ctx context.Context) (*peer.Peer, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	return peer.FromContext(ctx)
+}
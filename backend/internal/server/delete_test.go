@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+func TestDeleteSnapshot(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	const filename = "host_10.0.0.1_2025-01-01T00-00-00Z.json"
+	bad := upload(t, server, filename, `{"services": []}`)
+	good := upload(t, server, "host_10.0.0.1_2025-01-02T00-00-00Z.json", `{"services": []}`)
+
+	if _, err := server.DeleteSnapshot(ctx, &proto.DeleteSnapshotRequest{Id: bad}); err == nil {
+		t.Error("Expected error deleting without a reason")
+	}
+	resp, err := server.DeleteSnapshot(ctx, &proto.DeleteSnapshotRequest{Id: bad, Actor: "alice", Reason: "wrong scanner"})
+	if err != nil {
+		t.Fatalf("DeleteSnapshot failed: %v", err)
+	}
+	if resp.Snapshot.Id != bad || resp.Snapshot.IpAddress != "10.0.0.1" {
+		t.Errorf("Unexpected deleted snapshot: %v", resp.Snapshot)
+	}
+	if _, err := server.DeleteSnapshot(ctx, &proto.DeleteSnapshotRequest{Id: bad, Reason: "again"}); err == nil {
+		t.Error("Expected error deleting a deleted snapshot")
+	}
+
+	history, err := server.GetHostHistory(ctx, &proto.GetHostHistoryRequest{IpAddress: "10.0.0.1"})
+	if err != nil || len(history.Snapshots) != 1 || history.Snapshots[0].Id != good {
+		t.Errorf("Expected only the live snapshot in history, got %v, %v", history, err)
+	}
+	if _, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{SnapshotIdA: bad, SnapshotIdB: good}); err == nil {
+		t.Error("Expected error comparing a deleted snapshot")
+	}
+
+	// The correct file for the same timestamp can now be uploaded
+	fixed := upload(t, server, filename, `{"services": [{"port": 22}]}`)
+	if _, err := server.RestoreSnapshot(ctx, &proto.RestoreSnapshotRequest{Id: bad}); err == nil {
+		t.Error("Expected error restoring over the replacement")
+	}
+	if _, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{SnapshotIdA: fixed, SnapshotIdB: good}); err != nil {
+		t.Errorf("CompareSnapshots failed: %v", err)
+	}
+}
+
+func TestRestoreAndPurgeDeleted(t *testing.T) {
+	server := newTestServer(t)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 4000}})
+
+	a := upload(t, server, "host_10.0.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`)
+	b := upload(t, server, "host_10.0.0.1_2025-01-02T00-00-00Z.json", `{"services": []}`)
+	for _, id := range []string{a, b} {
+		if _, err := server.DeleteSnapshot(ctx, &proto.DeleteSnapshotRequest{Id: id, Reason: "test"}); err != nil {
+			t.Fatalf("DeleteSnapshot failed: %v", err)
+		}
+	}
+
+	restored, err := server.RestoreSnapshot(ctx, &proto.RestoreSnapshotRequest{Id: a})
+	if err != nil || restored.Snapshot.Id != a {
+		t.Fatalf("RestoreSnapshot = %v, %v", restored, err)
+	}
+	if _, err := server.RestoreSnapshot(ctx, &proto.RestoreSnapshotRequest{Id: a}); err == nil {
+		t.Error("Expected error restoring a live snapshot")
+	}
+
+	for _, req := range []*proto.PurgeDeletedRequest{
+		{},
+		{Reason: "cleanup", DeletedBefore: "yesterday"},
+	} {
+		if _, err := server.PurgeDeleted(ctx, req); err == nil {
+			t.Errorf("Expected error for %v", req)
+		}
+	}
+	purged, err := server.PurgeDeleted(ctx, &proto.PurgeDeletedRequest{Reason: "cleanup"})
+	if err != nil {
+		t.Fatalf("PurgeDeleted failed: %v", err)
+	}
+	if len(purged.Snapshots) != 1 || purged.Snapshots[0].Id != b {
+		t.Errorf("Expected only snapshot %s to be purged, got %v", b, purged.Snapshots)
+	}
+	if _, err := server.RestoreSnapshot(ctx, &proto.RestoreSnapshotRequest{Id: b}); err == nil {
+		t.Error("Expected error restoring a purged snapshot")
+	}
+}
+
+func TestAudit(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 4000}})
+	if got := audit(ctx, "", "why"); got.Actor != "192.0.2.1:4000" || got.Reason != "why" {
+		t.Errorf("Expected the peer address as actor, got %+v", got)
+	}
+	if got := audit(ctx, "alice", ""); got.Actor != "alice" {
+		t.Errorf("Expected the given actor, got %+v", got)
+	}
+	if got := audit(context.Background(), "", ""); got.Actor != "unknown" {
+		t.Errorf("Expected unknown actor without a peer, got %+v", got)
+	}
+}
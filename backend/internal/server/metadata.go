@@ -0,0 +1,211 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/validation"
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+// MetadataSource selects where an upload's IP address and timestamp come
+// from: the filename, the snapshot's own "ip" and "timestamp" fields, or both.
+type MetadataSource string
+
+const (
+	// MetadataFromFilename uses the filename and reports content that
+	// disagrees with it as warnings. This is the default.
+	MetadataFromFilename MetadataSource = "filename"
+	// MetadataFromContent uses the content and makes the filename optional;
+	// a filename that disagrees with the content is reported as warnings.
+	MetadataFromContent MetadataSource = "content"
+	// MetadataStrict requires both and rejects uploads where they disagree.
+	MetadataStrict MetadataSource = "strict"
+)
+
+// ParseMetadataSource parses a metadata source name.
+func ParseMetadataSource(name string) (MetadataSource, error) {
+	switch source := MetadataSource(strings.ToLower(strings.TrimSpace(name))); source {
+	case MetadataFromFilename, MetadataFromContent, MetadataStrict:
+		return source, nil
+	default:
+		return "", fmt.Errorf("unknown metadata source %q: expected filename, content or strict", name)
+	}
+}
+
+// WithMetadataSource sets where uploads take their IP address and timestamp
+// from.
+func WithMetadataSource(source MetadataSource) Option {
+	return func(s *Server) {
+		s.metadataSource = source
+	}
+}
+
+// snapshotMetadata is the host and time a snapshot is stored under.
+type snapshotMetadata struct {
+	IPAddress string
+	Timestamp string
+}
+
+// contentMetadata holds the "ip" and "timestamp" fields of a snapshot, in
+// canonical form. A field that is missing is empty; one that is present but
+// invalid is empty and has an error.
+type contentMetadata struct {
+	snapshotMetadata
+	IPErr        error
+	TimestampErr error
+}
+
+// readContentMetadata extracts the metadata fields from valid JSON content.
+func readContentMetadata(content []byte) contentMetadata {
+	var fields map[string]interface{}
+	var meta contentMetadata
+	if json.Unmarshal(content, &fields) != nil {
+		// Not an object, so there are no fields to read
+		return meta
+	}
+
+	if raw, ok := fields["ip"]; ok {
+		ip, isString := raw.(string)
+		if !isString {
+			meta.IPErr = fmt.Errorf("ip must be a string")
+		} else if meta.IPAddress, meta.IPErr = validation.CanonicalIP(ip); meta.IPErr != nil {
+			meta.IPAddress = ""
+		}
+	}
+	if raw, ok := fields["timestamp"]; ok {
+		timestamp, isString := raw.(string)
+		if !isString {
+			meta.TimestampErr = fmt.Errorf("timestamp must be a string")
+		} else if meta.Timestamp, meta.TimestampErr = normalizeTimeBound(timestamp); meta.TimestampErr != nil {
+			meta.Timestamp = ""
+			meta.TimestampErr = fmt.Errorf("invalid timestamp %q: expected RFC 3339", timestamp)
+		}
+	}
+	return meta
+}
+
+// checkUpload validates an uploaded file and decides which IP address and
+// timestamp it is stored under, according to the server's metadata source.
+// Discrepancies between the filename and the content that don't prevent the
+// upload are returned as warnings.
+func (s *Server) checkUpload(filename string, content []byte) (*snapshotMetadata, []*proto.UploadWarning, error) {
+	var fromName *snapshotMetadata
+	var nameErr error
+	if filename == "" {
+		nameErr = fmt.Errorf("filename is required")
+	} else if parsed, err := validation.ParseFilename(filename); err != nil {
+		nameErr = err
+	} else {
+		fromName = &snapshotMetadata{IPAddress: parsed.IPAddress, Timestamp: parsed.Timestamp}
+	}
+	if nameErr != nil && s.metadataSource != MetadataFromContent {
+		return nil, nil, fmt.Errorf("invalid filename: %w", nameErr)
+	}
+
+	schemaWarnings, err := s.checkSchema(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta, warnings, err := s.reconcileMetadata(filename, fromName, nameErr, readContentMetadata(content))
+	if err != nil {
+		return nil, nil, err
+	}
+	return meta, append(warnings, schemaWarnings...), nil
+}
+
+// reconcileMetadata picks the metadata an upload is stored under from its
+// filename, if that parsed, and its content.
+func (s *Server) reconcileMetadata(filename string, fromName *snapshotMetadata, nameErr error, fromContent contentMetadata) (*snapshotMetadata, []*proto.UploadWarning, error) {
+	switch s.metadataSource {
+	case MetadataFromContent:
+		if err := requireContentMetadata(fromContent); err != nil {
+			return nil, nil, err
+		}
+		var warnings []*proto.UploadWarning
+		if fromName == nil && filename != "" {
+			warnings = append(warnings, &proto.UploadWarning{
+				Code:    proto.UploadWarningCode_UPLOAD_WARNING_CODE_INVALID_FILENAME,
+				Message: fmt.Sprintf("filename ignored: %v", nameErr),
+			})
+		}
+		if fromName != nil {
+			warnings = append(warnings, mismatches(fromName, &fromContent.snapshotMetadata)...)
+		}
+		return &fromContent.snapshotMetadata, warnings, nil
+
+	case MetadataStrict:
+		if err := requireContentMetadata(fromContent); err != nil {
+			return nil, nil, err
+		}
+		if found := mismatches(fromName, &fromContent.snapshotMetadata); len(found) > 0 {
+			messages := make([]string, len(found))
+			for i, w := range found {
+				messages[i] = w.Message
+			}
+			return nil, nil, fmt.Errorf("filename and content disagree: %s", strings.Join(messages, "; "))
+		}
+		return fromName, nil, nil
+
+	default:
+		var warnings []*proto.UploadWarning
+		if fromContent.IPErr != nil {
+			warnings = append(warnings, invalidContentField("/ip", fromContent.IPErr))
+		}
+		if fromContent.TimestampErr != nil {
+			warnings = append(warnings, invalidContentField("/timestamp", fromContent.TimestampErr))
+		}
+		warnings = append(warnings, mismatches(fromName, &fromContent.snapshotMetadata)...)
+		return fromName, warnings, nil
+	}
+}
+
+// requireContentMetadata checks that the content has a valid ip and timestamp.
+func requireContentMetadata(meta contentMetadata) error {
+	switch {
+	case meta.IPErr != nil:
+		return fmt.Errorf("invalid content: %w", meta.IPErr)
+	case meta.IPAddress == "":
+		return fmt.Errorf("invalid content: ip is required")
+	case meta.TimestampErr != nil:
+		return fmt.Errorf("invalid content: %w", meta.TimestampErr)
+	case meta.Timestamp == "":
+		return fmt.Errorf("invalid content: timestamp is required")
+	}
+	return nil
+}
+
+// mismatches compares the filename's metadata with the content's. Fields
+// missing from the content are not compared.
+func mismatches(fromName, fromContent *snapshotMetadata) []*proto.UploadWarning {
+	var warnings []*proto.UploadWarning
+	if fromContent.IPAddress != "" && fromContent.IPAddress != fromName.IPAddress {
+		warnings = append(warnings, &proto.UploadWarning{
+			Code:          proto.UploadWarningCode_UPLOAD_WARNING_CODE_IP_MISMATCH,
+			Pointer:       "/ip",
+			Message:       fmt.Sprintf("filename has IP %s but content has %s", fromName.IPAddress, fromContent.IPAddress),
+			FilenameValue: fromName.IPAddress,
+			ContentValue:  fromContent.IPAddress,
+		})
+	}
+	if fromContent.Timestamp != "" && fromContent.Timestamp != fromName.Timestamp {
+		warnings = append(warnings, &proto.UploadWarning{
+			Code:          proto.UploadWarningCode_UPLOAD_WARNING_CODE_TIMESTAMP_MISMATCH,
+			Pointer:       "/timestamp",
+			Message:       fmt.Sprintf("filename has timestamp %s but content has %s", fromName.Timestamp, fromContent.Timestamp),
+			FilenameValue: fromName.Timestamp,
+			ContentValue:  fromContent.Timestamp,
+		})
+	}
+	return warnings
+}
+
+func invalidContentField(pointer string, err error) *proto.UploadWarning {
+	return &proto.UploadWarning{
+		Code:    proto.UploadWarningCode_UPLOAD_WARNING_CODE_INVALID_CONTENT_FIELD,
+		Pointer: pointer,
+		Message: fmt.Sprintf("ignored: %v", err),
+	}
+}
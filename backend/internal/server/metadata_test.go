@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+func TestUploadSnapshot_MetadataSource(t *testing.T) {
+	const (
+		filename = "host_10.0.0.1_2025-01-01T00-00-00Z.json"
+		matching = `{"ip": "10.0.0.1", "timestamp": "2025-01-01T02:00:00+02:00", "services": []}`
+		otherIP  = `{"ip": "10.0.0.2", "timestamp": "2025-01-01T00:00:00Z", "services": []}`
+		noFields = `{"services": []}`
+		badIP    = `{"ip": "10.0.0.256", "timestamp": 17, "services": []}`
+	)
+	ipMismatch := proto.UploadWarningCode_UPLOAD_WARNING_CODE_IP_MISMATCH
+	invalidField := proto.UploadWarningCode_UPLOAD_WARNING_CODE_INVALID_CONTENT_FIELD
+	invalidName := proto.UploadWarningCode_UPLOAD_WARNING_CODE_INVALID_FILENAME
+
+	tests := []struct {
+		name     string
+		source   MetadataSource
+		filename string
+		content  string
+		wantIP   string // empty when the upload must fail
+		warnings []proto.UploadWarningCode
+	}{
+		{"filename: match", MetadataFromFilename, filename, matching, "10.0.0.1", nil},
+		{"filename: mismatch warns", MetadataFromFilename, filename, otherIP, "10.0.0.1", []proto.UploadWarningCode{ipMismatch}},
+		{"filename: content without metadata", MetadataFromFilename, filename, noFields, "10.0.0.1", nil},
+		{"filename: invalid content fields warn", MetadataFromFilename, filename, badIP, "10.0.0.1", []proto.UploadWarningCode{invalidField, invalidField}},
+		{"filename: filename required", MetadataFromFilename, "", matching, "", nil},
+
+		{"content: no filename", MetadataFromContent, "", otherIP, "10.0.0.2", nil},
+		{"content: mismatch warns", MetadataFromContent, filename, otherIP, "10.0.0.2", []proto.UploadWarningCode{ipMismatch}},
+		{"content: unconventional filename warns", MetadataFromContent, "scan-0001.json", otherIP, "10.0.0.2", []proto.UploadWarningCode{invalidName}},
+		{"content: metadata required", MetadataFromContent, filename, noFields, "", nil},
+		{"content: invalid ip", MetadataFromContent, "", badIP, "", nil},
+
+		{"strict: match", MetadataStrict, filename, matching, "10.0.0.1", nil},
+		{"strict: mismatch rejected", MetadataStrict, filename, otherIP, "", nil},
+		{"strict: metadata required", MetadataStrict, filename, noFields, "", nil},
+		{"strict: filename required", MetadataStrict, "", matching, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, WithMetadataSource(tt.source))
+			resp, err := server.UploadSnapshot(context.Background(), &proto.UploadSnapshotRequest{
+				Filename:    tt.filename,
+				FileContent: []byte(tt.content),
+			})
+			if tt.wantIP == "" {
+				if err == nil {
+					t.Fatalf("Expected error, got %v", resp)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UploadSnapshot failed: %v", err)
+			}
+			if resp.IpAddress != tt.wantIP {
+				t.Errorf("Expected IP %s, got %s", tt.wantIP, resp.IpAddress)
+			}
+			if len(resp.Warnings) != len(tt.warnings) {
+				t.Fatalf("Expected warnings %v, got %v", tt.warnings, resp.Warnings)
+			}
+			for i, w := range resp.Warnings {
+				if w.Code != tt.warnings[i] {
+					t.Errorf("Warning %d: expected %v, got %v", i, tt.warnings[i], w)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadSnapshot_MismatchWarning(t *testing.T) {
+	server := newTestServer(t)
+	resp, err := server.UploadSnapshot(context.Background(), &proto.UploadSnapshotRequest{
+		Filename:    "host_10.0.0.1_2025-01-01T00-00-00Z.json",
+		FileContent: []byte(`{"ip": "10.0.0.1", "timestamp": "2025-01-02T00:00:00Z", "services": []}`),
+	})
+	if err != nil {
+		t.Fatalf("UploadSnapshot failed: %v", err)
+	}
+	if len(resp.Warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", resp.Warnings)
+	}
+	w := resp.Warnings[0]
+	if w.Code != proto.UploadWarningCode_UPLOAD_WARNING_CODE_TIMESTAMP_MISMATCH || w.Pointer != "/timestamp" ||
+		w.FilenameValue != "2025-01-01T00:00:00Z" || w.ContentValue != "2025-01-02T00:00:00Z" || w.Message == "" {
+		t.Errorf("Unexpected warning: %v", w)
+	}
+	if resp.Timestamp != "2025-01-01T00:00:00Z" {
+		t.Errorf("Expected the filename's timestamp to be used, got %s", resp.Timestamp)
+	}
+}
+
+func TestUploadSnapshotStream_ContentMetadata(t *testing.T) {
+	server := newTestServer(t, WithMetadataSource(MetadataFromContent))
+	content := `{"ip": "2001:DB8::1", "timestamp": "2025-01-01T00:00:00Z", "services": []}`
+
+	stream := &fakeUploadStream{frames: uploadFrames(&proto.UploadSnapshotHeader{}, content, 8)}
+	if err := server.UploadSnapshotStream(stream); err != nil {
+		t.Fatalf("UploadSnapshotStream failed: %v", err)
+	}
+	if stream.resp.IpAddress != "2001:db8::1" || stream.resp.Timestamp != "2025-01-01T00:00:00Z" {
+		t.Errorf("Unexpected response: %v", stream.resp)
+	}
+}
+
+func TestBulkUpload_ContentMetadata(t *testing.T) {
+	server := newTestServer(t, WithMetadataSource(MetadataFromContent))
+	archive := zipArchive(t, []archiveFile{
+		{"scans/a.json", `{"ip": "10.0.0.1", "timestamp": "2025-01-01T00:00:00Z", "services": []}`},
+		{"scans/host_10.0.0.9_2025-01-01T00-00-00Z.json", `{"ip": "10.0.0.2", "timestamp": "2025-01-01T00:00:00Z", "services": []}`},
+		{"scans/b.json", `{"services": []}`},
+	})
+
+	resp, err := server.BulkUpload(context.Background(), &proto.BulkUploadRequest{Archive: archive})
+	if err != nil {
+		t.Fatalf("BulkUpload failed: %v", err)
+	}
+	if resp.Inserted != 2 || resp.Rejected != 1 {
+		t.Fatalf("Expected 2 inserted and 1 rejected, got %v", resp)
+	}
+	if r := resp.Results[0]; r.IpAddress != "10.0.0.1" || len(r.Warnings) != 1 {
+		t.Errorf("Unexpected result for a.json: %v", r)
+	}
+	if r := resp.Results[1]; r.IpAddress != "10.0.0.2" || len(r.Warnings) != 1 ||
+		r.Warnings[0].Code != proto.UploadWarningCode_UPLOAD_WARNING_CODE_IP_MISMATCH {
+		t.Errorf("Unexpected result for the misnamed file: %v", r)
+	}
+}
+
+func TestParseMetadataSource(t *testing.T) {
+	for name, want := range map[string]MetadataSource{
+		"filename": MetadataFromFilename,
+		"Content":  MetadataFromContent,
+		" strict ": MetadataStrict,
+	} {
+		if got, err := ParseMetadataSource(name); err != nil || got != want {
+			t.Errorf("ParseMetadataSource(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseMetadataSource("both"); err == nil {
+		t.Error("Expected error for unknown source")
+	}
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/netip"
+	"strings"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/data"
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+// Page sizes for paginated RPCs.
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// QueryServices handles the QueryServices RPC.
+func (s *Server) QueryServices(ctx context.Context, req *proto.QueryServicesRequest) (*proto.QueryServicesResponse, error) {
+	resp, err := s.queryServices(req)
+	if err != nil {
+		log.Printf("QueryServices error: %v", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) queryServices(req *proto.QueryServicesRequest) (*proto.QueryServicesResponse, error) {
+	limit, err := pageSize(req.GetPageSize())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetPort() < 0 || req.GetPort() > 65535 {
+		return nil, fmt.Errorf("invalid port %d", req.GetPort())
+	}
+
+	q := data.ServiceQuery{
+		Port:       int(req.GetPort()),
+		Protocol:   req.GetProtocol(),
+		Product:    req.GetProduct(),
+		CVE:        req.GetCveId(),
+		LatestOnly: req.GetLatestOnly(),
+		Limit:      limit,
+	}
+	if q.Match, err = versionRange(req.GetMinVersion(), req.GetMaxVersion()); err != nil {
+		return nil, err
+	}
+	if req.GetPageToken() != "" {
+		q.After = &data.ServiceCursor{}
+		if err := decodePageToken(req.GetPageToken(), q.After); err != nil {
+			return nil, err
+		}
+	}
+
+	records, next, err := s.db.QueryServices(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query services: %w", err)
+	}
+
+	resp := &proto.QueryServicesResponse{Services: make([]*proto.ServiceRecord, len(records))}
+	for i, rec := range records {
+		resp.Services[i] = serviceRecordToProto(rec)
+	}
+	if next != nil {
+		resp.NextPageToken = encodePageToken(next)
+	}
+	return resp, nil
+}
+
+// ListHosts handles the ListHosts RPC.
+func (s *Server) ListHosts(ctx context.Context, req *proto.ListHostsRequest) (*proto.ListHostsResponse, error) {
+	resp, err := s.listHosts(req)
+	if err != nil {
+		log.Printf("ListHosts error: %v", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) listHosts(req *proto.ListHostsRequest) (*proto.ListHostsResponse, error) {
+	limit, err := pageSize(req.GetPageSize())
+	if err != nil {
+		return nil, err
+	}
+	order, ok := hostOrders[req.GetOrder()]
+	if !ok {
+		return nil, fmt.Errorf("unknown host order %v", req.GetOrder())
+	}
+
+	q := data.HostQuery{Prefix: req.GetIpPrefix(), Order: order, Limit: limit}
+	if req.GetCidr() != "" {
+		network, err := netip.ParsePrefix(req.GetCidr())
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr: %w", err)
+		}
+		r := data.PrefixRange(network)
+		q.Range = &r
+	}
+	if req.GetPageToken() != "" {
+		q.After = &data.HostCursor{}
+		if err := decodePageToken(req.GetPageToken(), q.After); err != nil {
+			return nil, err
+		}
+	}
+
+	hosts, next, err := s.db.ListHosts(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %w", err)
+	}
+
+	resp := &proto.ListHostsResponse{Hosts: make([]*proto.HostSummary, len(hosts))}
+	for i, h := range hosts {
+		resp.Hosts[i] = hostSummaryToProto(h)
+	}
+	if next != nil {
+		resp.NextPageToken = encodePageToken(next)
+	}
+	return resp, nil
+}
+
+var hostOrders = map[proto.HostOrder]data.HostOrder{
+	proto.HostOrder_HOST_ORDER_UNSPECIFIED:    data.HostOrderIP,
+	proto.HostOrder_HOST_ORDER_LAST_SEEN_DESC: data.HostOrderLastSeenDesc,
+	proto.HostOrder_HOST_ORDER_LAST_SEEN_ASC:  data.HostOrderLastSeenAsc,
+}
+
+// QueryFleet handles the QueryFleet RPC.
+func (s *Server) QueryFleet(ctx context.Context, req *proto.QueryFleetRequest) (*proto.QueryFleetResponse, error) {
+	resp, err := s.queryFleet(req)
+	if err != nil {
+		log.Printf("QueryFleet error: %v", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) queryFleet(req *proto.QueryFleetRequest) (*proto.QueryFleetResponse, error) {
+	limit, err := pageSize(req.GetPageSize())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetIpRange() == "" {
+		return nil, fmt.Errorf("ip_range is required")
+	}
+	r, err := parseIPRange(req.GetIpRange())
+	if err != nil {
+		return nil, err
+	}
+	asOf, err := normalizeTimeBound(req.GetAsOf())
+	if err != nil {
+		return nil, fmt.Errorf("invalid as_of: %w", err)
+	}
+
+	q := data.FleetQuery{Range: r, AsOf: asOf, Limit: limit}
+	if req.GetPageToken() != "" {
+		if err := decodePageToken(req.GetPageToken(), &q.After); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshots, next, err := s.db.QueryFleet(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fleet: %w", err)
+	}
+
+	resp := &proto.QueryFleetResponse{Snapshots: make([]*proto.SnapshotInfo, len(snapshots))}
+	for i, snap := range snapshots {
+		resp.Snapshots[i] = snapshotInfoToProto(snap)
+	}
+	if next != "" {
+		resp.NextPageToken = encodePageToken(next)
+	}
+	return resp, nil
+}
+
+// parseIPRange parses a CIDR block such as "10.0.0.0/24" or an inclusive
+// range such as "10.0.0.1-10.0.0.99", of IPv4 or IPv6 addresses.
+func parseIPRange(value string) (data.IPRange, error) {
+	if strings.Contains(value, "/") {
+		network, err := netip.ParsePrefix(value)
+		if err != nil {
+			return data.IPRange{}, fmt.Errorf("invalid ip_range: %w", err)
+		}
+		return data.PrefixRange(network), nil
+	}
+
+	first, last, ok := strings.Cut(value, "-")
+	if !ok {
+		return data.IPRange{}, fmt.Errorf("invalid ip_range %q: expected a CIDR block or first-last", value)
+	}
+	firstAddr, err := netip.ParseAddr(strings.TrimSpace(first))
+	if err != nil {
+		return data.IPRange{}, fmt.Errorf("invalid ip_range: %w", err)
+	}
+	lastAddr, err := netip.ParseAddr(strings.TrimSpace(last))
+	if err != nil {
+		return data.IPRange{}, fmt.Errorf("invalid ip_range: %w", err)
+	}
+	r, err := data.NewIPRange(firstAddr, lastAddr)
+	if err != nil {
+		return data.IPRange{}, fmt.Errorf("invalid ip_range: %w", err)
+	}
+	return r, nil
+}
+
+// versionRange returns a filter for services whose software version lies in
+// the inclusive range [min, max], or nil if neither bound is set.
+func versionRange(min, max string) (func(*data.ServiceRecord) bool, error) {
+	if min == "" && max == "" {
+		return nil, nil
+	}
+
+	var lower, upper *diff.Version
+	var err error
+	if min != "" {
+		if lower, err = diff.ParseVersion(min); err != nil {
+			return nil, fmt.Errorf("invalid min_version: %w", err)
+		}
+	}
+	if max != "" {
+		if upper, err = diff.ParseVersion(max); err != nil {
+			return nil, fmt.Errorf("invalid max_version: %w", err)
+		}
+	}
+
+	return func(rec *data.ServiceRecord) bool {
+		v, err := diff.ParseVersion(rec.Version)
+		if err != nil {
+			return false
+		}
+		if lower != nil {
+			if c, _ := v.Compare(lower); c < 0 {
+				return false
+			}
+		}
+		if upper != nil {
+			if c, _ := v.Compare(upper); c > 0 {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// pageSize validates a requested page size, applying the default for 0.
+func pageSize(requested int32) (int, error) {
+	switch {
+	case requested == 0:
+		return defaultPageSize, nil
+	case requested < 0:
+		return 0, fmt.Errorf("invalid page_size %d", requested)
+	case requested > maxPageSize:
+		return maxPageSize, nil
+	default:
+		return int(requested), nil
+	}
+}
+
+// encodePageToken packs a keyset cursor into an opaque page token.
+func encodePageToken(cursor interface{}) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodePageToken unpacks a page token into the cursor it was made from.
+func decodePageToken(token string, cursor interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(raw, cursor)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid page_token %q", token)
+	}
+	return nil
+}
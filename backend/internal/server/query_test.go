@@ -0,0 +1,368 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+func TestQueryServices(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	upload(t, server, "host_10.0.0.1_2025-01-01T00-00-00Z.json",
+		`{"services": [{"port": 22, "protocol": "SSH", "software": {"product": "OpenSSH", "version": "8.9p1"}, "vulnerabilities": ["CVE-2023-38408"]}]}`)
+	upload(t, server, "host_10.0.0.1_2025-01-02T00-00-00Z.json",
+		`{"services": [{"port": 22, "protocol": "SSH", "software": {"product": "OpenSSH", "version": "9.6p1"}, "vulnerabilities": ["CVE-2024-6387"]}]}`)
+	upload(t, server, "host_10.0.0.2_2025-01-01T00-00-00Z.json",
+		`{"services": [{"port": 80, "protocol": "HTTP", "software": {"product": "nginx", "version": "1.18.0"}}, {"port": 22, "protocol": "SSH", "software": {"product": "openssh", "version": "7.4"}}]}`)
+
+	tests := []struct {
+		name string
+		req  *proto.QueryServicesRequest
+		want []string // ip/version per result
+	}{
+		{"by port", &proto.QueryServicesRequest{Port: 80}, []string{"10.0.0.2/1.18.0"}},
+		{"by product", &proto.QueryServicesRequest{Product: "openssh"}, []string{"10.0.0.1/8.9p1", "10.0.0.1/9.6p1", "10.0.0.2/7.4"}},
+		{"by cve", &proto.QueryServicesRequest{CveId: "CVE-2024-6387"}, []string{"10.0.0.1/9.6p1"}},
+		{"latest only", &proto.QueryServicesRequest{Protocol: "ssh", LatestOnly: true}, []string{"10.0.0.1/9.6p1", "10.0.0.2/7.4"}},
+		{"version range", &proto.QueryServicesRequest{Product: "openssh", MinVersion: "8.0", MaxVersion: "9.0"}, []string{"10.0.0.1/8.9p1"}},
+		{"max version", &proto.QueryServicesRequest{Product: "openssh", MaxVersion: "8.0"}, []string{"10.0.0.2/7.4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.QueryServices(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("QueryServices failed: %v", err)
+			}
+			if len(resp.Services) != len(tt.want) {
+				t.Fatalf("Expected %d services, got %d", len(tt.want), len(resp.Services))
+			}
+			for i, svc := range resp.Services {
+				if got := svc.IpAddress + "/" + svc.GetSoftware().GetVersion(); got != tt.want[i] {
+					t.Errorf("Result %d: got %s, want %s", i, got, tt.want[i])
+				}
+			}
+			if resp.NextPageToken != "" {
+				t.Errorf("Expected no next page, got %q", resp.NextPageToken)
+			}
+		})
+	}
+
+	resp, err := server.QueryServices(ctx, &proto.QueryServicesRequest{CveId: "cve-2023-38408"})
+	if err != nil || len(resp.Services) != 1 {
+		t.Fatalf("Expected 1 service, got %v, %v", resp, err)
+	}
+	if svc := resp.Services[0]; svc.Port != 22 || svc.Protocol != "SSH" || svc.SnapshotId == "" || len(svc.CveIds) != 1 || svc.CveIds[0] != "CVE-2023-38408" {
+		t.Errorf("Unexpected service: %v", svc)
+	}
+}
+
+func TestQueryServices_Pagination(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	upload(t, server, "host_10.0.0.1_2025-01-01T00-00-00Z.json",
+		`{"services": [{"port": 22, "protocol": "SSH"}, {"port": 80, "protocol": "HTTP"}, {"port": 443, "protocol": "HTTPS"}]}`)
+
+	var ports []int32
+	req := &proto.QueryServicesRequest{PageSize: 2}
+	for pages := 0; ; pages++ {
+		if pages == 3 {
+			t.Fatal("Pagination did not terminate")
+		}
+		resp, err := server.QueryServices(ctx, req)
+		if err != nil {
+			t.Fatalf("QueryServices failed: %v", err)
+		}
+		for _, svc := range resp.Services {
+			ports = append(ports, svc.Port)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	if len(ports) != 3 || ports[0] != 22 || ports[1] != 80 || ports[2] != 443 {
+		t.Errorf("Expected ports 22, 80, 443 across pages, got %v", ports)
+	}
+}
+
+func TestQueryServices_InvalidRequest(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	tests := map[string]*proto.QueryServicesRequest{
+		"negative page size": {PageSize: -1},
+		"port out of range":  {Port: 70000},
+		"bad page token":     {PageToken: "not a token"},
+		"bad min version":    {MinVersion: " "},
+	}
+	for name, req := range tests {
+		if _, err := server.QueryServices(ctx, req); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	tests := []struct {
+		requested int32
+		want      int
+	}{
+		{0, defaultPageSize},
+		{10, 10},
+		{maxPageSize + 1, maxPageSize},
+	}
+	for _, tt := range tests {
+		if got, err := pageSize(tt.requested); err != nil || got != tt.want {
+			t.Errorf("pageSize(%d) = %d, %v; want %d", tt.requested, got, err, tt.want)
+		}
+	}
+}
+
+func TestListHosts(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	upload(t, server, "host_10.0.0.1_2025-01-01T00-00-00Z.json", `{"services": [{"port": 22}]}`)
+	upload(t, server, "host_10.0.0.1_2025-01-03T00-00-00Z.json",
+		`{"services": [{"port": 22, "vulnerabilities": ["CVE-2024-6387"]}, {"port": 443}]}`)
+	upload(t, server, "host_10.1.0.1_2025-01-02T00-00-00Z.json", `{"services": []}`)
+	upload(t, server, "host_192.168.1.1_2025-01-04T00-00-00Z.json", `{"services": []}`)
+
+	tests := []struct {
+		name string
+		req  *proto.ListHostsRequest
+		want []string
+	}{
+		{"all", &proto.ListHostsRequest{}, []string{"10.0.0.1", "10.1.0.1", "192.168.1.1"}},
+		{"prefix", &proto.ListHostsRequest{IpPrefix: "10."}, []string{"10.0.0.1", "10.1.0.1"}},
+		{"cidr", &proto.ListHostsRequest{Cidr: "10.0.0.0/16"}, []string{"10.0.0.1"}},
+		{"last seen", &proto.ListHostsRequest{Order: proto.HostOrder_HOST_ORDER_LAST_SEEN_DESC}, []string{"192.168.1.1", "10.0.0.1", "10.1.0.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.ListHosts(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("ListHosts failed: %v", err)
+			}
+			if len(resp.Hosts) != len(tt.want) {
+				t.Fatalf("Expected %d hosts, got %d", len(tt.want), len(resp.Hosts))
+			}
+			for i, h := range resp.Hosts {
+				if h.IpAddress != tt.want[i] {
+					t.Errorf("Host %d: got %s, want %s", i, h.IpAddress, tt.want[i])
+				}
+			}
+		})
+	}
+
+	resp, err := server.ListHosts(ctx, &proto.ListHostsRequest{PageSize: 1})
+	if err != nil {
+		t.Fatalf("ListHosts failed: %v", err)
+	}
+	h := resp.Hosts[0]
+	if h.SnapshotCount != 2 || h.FirstSeen != "2025-01-01T00:00:00Z" || h.LastSeen != "2025-01-03T00:00:00Z" ||
+		h.OpenPortCount != 2 || h.CveCount != 1 || h.LatestSnapshotId == "" {
+		t.Errorf("Unexpected summary: %v", h)
+	}
+	if resp.NextPageToken == "" {
+		t.Fatal("Expected a next page token")
+	}
+
+	resp, err = server.ListHosts(ctx, &proto.ListHostsRequest{PageSize: 5, PageToken: resp.NextPageToken})
+	if err != nil {
+		t.Fatalf("ListHosts failed: %v", err)
+	}
+	if len(resp.Hosts) != 2 || resp.Hosts[0].IpAddress != "10.1.0.1" || resp.NextPageToken != "" {
+		t.Errorf("Unexpected second page: %v", resp)
+	}
+}
+
+func TestListHosts_InvalidRequest(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	tests := map[string]*proto.ListHostsRequest{
+		"negative page size": {PageSize: -1},
+		"bad cidr":           {Cidr: "10.0.0.0/33"},
+		"bad page token":     {PageToken: "not a token"},
+		"unknown order":      {Order: proto.HostOrder(99)},
+	}
+	for name, req := range tests {
+		if _, err := server.ListHosts(ctx, req); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParseIPRange(t *testing.T) {
+	tests := []struct {
+		value       string
+		first, last string
+	}{
+		{"10.0.0.0/24", "10.0.0.0", "10.0.0.255"},
+		{"10.0.0.7/24", "10.0.0.0", "10.0.0.255"},
+		{"10.0.0.1-10.0.0.99", "10.0.0.1", "10.0.0.99"},
+		{"10.0.0.1 - 10.0.0.1", "10.0.0.1", "10.0.0.1"},
+		{"2001:db8::/48", "2001:db8::", "2001:db8:0:ffff:ffff:ffff:ffff:ffff"},
+		{"2001:db8::1-2001:db8::ff", "2001:db8::1", "2001:db8::ff"},
+	}
+	for _, tt := range tests {
+		r, err := parseIPRange(tt.value)
+		if err != nil {
+			t.Errorf("parseIPRange(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if r.First.String() != tt.first || r.Last.String() != tt.last {
+			t.Errorf("parseIPRange(%q) = %s-%s, want %s-%s", tt.value, r.First, r.Last, tt.first, tt.last)
+		}
+	}
+
+	for _, value := range []string{"10.0.0.1", "10.0.0.0/33", "10.0.0.9-10.0.0.1", "10.0.0.1-2001:db8::1", "a-b"} {
+		if _, err := parseIPRange(value); err == nil {
+			t.Errorf("parseIPRange(%q): expected error", value)
+		}
+	}
+}
+
+func TestGetHostHistory_IPRange(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	upload(t, server, "host_10.0.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`)
+	upload(t, server, "host_10.0.0.200_2025-01-02T00-00-00Z.json", `{"services": []}`)
+	upload(t, server, "host_10.0.1.1_2025-01-03T00-00-00Z.json", `{"services": []}`)
+
+	resp, err := server.GetHostHistory(ctx, &proto.GetHostHistoryRequest{IpRange: "10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("GetHostHistory failed: %v", err)
+	}
+	if len(resp.Snapshots) != 2 || resp.Snapshots[0].IpAddress != "10.0.0.200" || resp.Snapshots[1].IpAddress != "10.0.0.1" {
+		t.Errorf("Expected the two hosts in 10.0.0.0/24 newest first, got %v", resp.Snapshots)
+	}
+
+	resp, err = server.GetHostHistory(ctx, &proto.GetHostHistoryRequest{IpRange: "10.0.0.100-10.0.1.1"})
+	if err != nil {
+		t.Fatalf("GetHostHistory failed: %v", err)
+	}
+	if len(resp.Snapshots) != 2 {
+		t.Errorf("Expected 2 snapshots in range, got %d", len(resp.Snapshots))
+	}
+
+	for _, req := range []*proto.GetHostHistoryRequest{
+		{IpRange: "10.0.0.0/40"},
+		{IpAddress: "10.0.0.1", IpRange: "10.0.0.0/24"},
+	} {
+		if _, err := server.GetHostHistory(ctx, req); err == nil {
+			t.Errorf("Expected error for %v", req)
+		}
+	}
+}
+
+func TestGetHostHistory_Pagination(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	for day := 1; day <= 5; day++ {
+		upload(t, server, fmt.Sprintf("host_10.0.0.1_2025-01-0%dT00-00-00Z.json", day), `{"services": []}`)
+	}
+
+	req := &proto.GetHostHistoryRequest{
+		IpAddress: "10.0.0.1",
+		StartTime: "2025-01-02T00:00:00Z",
+		EndTime:   "2025-01-05T00:00:00+02:00",
+		Order:     proto.HistoryOrder_HISTORY_ORDER_OLDEST_FIRST,
+		PageSize:  2,
+	}
+	var got []string
+	for pages := 0; pages < 5; pages++ {
+		resp, err := server.GetHostHistory(ctx, req)
+		if err != nil {
+			t.Fatalf("GetHostHistory failed: %v", err)
+		}
+		for _, snap := range resp.Snapshots {
+			got = append(got, snap.Timestamp[:10])
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	// end_time is 2025-01-04T22:00:00Z, which excludes the snapshot of the 5th
+	if want := "2025-01-02 2025-01-03 2025-01-04"; strings.Join(got, " ") != want {
+		t.Errorf("Got %v, want %s", got, want)
+	}
+
+	for _, req := range []*proto.GetHostHistoryRequest{
+		{IpAddress: "10.0.0.1", PageSize: -1},
+		{IpAddress: "10.0.0.1", PageToken: "!"},
+		{IpAddress: "10.0.0.1", StartTime: "yesterday"},
+		{IpAddress: "10.0.0.1", StartTime: "2025-01-03T00:00:00Z", EndTime: "2025-01-02T00:00:00Z"},
+		{IpAddress: "10.0.0.1", Order: proto.HistoryOrder(42)},
+	} {
+		if _, err := server.GetHostHistory(ctx, req); err == nil {
+			t.Errorf("Expected error for %v", req)
+		}
+	}
+}
+
+func TestQueryFleet(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	upload(t, server, "host_10.0.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`)
+	upload(t, server, "host_10.0.0.1_2025-01-05T00-00-00Z.json", `{"services": []}`)
+	upload(t, server, "host_10.0.0.2_2025-01-03T00-00-00Z.json", `{"services": []}`)
+	upload(t, server, "host_10.0.0.3_2025-01-01T00-00-00Z.json", `{"services": []}`)
+	upload(t, server, "host_10.1.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`)
+
+	resp, err := server.QueryFleet(ctx, &proto.QueryFleetRequest{IpRange: "10.0.0.0/24", AsOf: "2025-01-04T00:00:00+02:00"})
+	if err != nil {
+		t.Fatalf("QueryFleet failed: %v", err)
+	}
+	var got []string
+	for _, snap := range resp.Snapshots {
+		got = append(got, snap.IpAddress+"@"+snap.Timestamp)
+	}
+	want := "10.0.0.1@2025-01-01T00:00:00Z 10.0.0.2@2025-01-03T00:00:00Z 10.0.0.3@2025-01-01T00:00:00Z"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Got %v, want %s", got, want)
+	}
+
+	var pages int
+	req := &proto.QueryFleetRequest{IpRange: "10.0.0.0/8", PageSize: 3}
+	for ; pages < 3; pages++ {
+		resp, err := server.QueryFleet(ctx, req)
+		if err != nil {
+			t.Fatalf("QueryFleet failed: %v", err)
+		}
+		if pages == 0 && resp.Snapshots[0].Timestamp != "2025-01-05T00:00:00Z" {
+			t.Errorf("Expected the newest snapshot of 10.0.0.1, got %v", resp.Snapshots[0])
+		}
+		if resp.NextPageToken == "" {
+			if len(resp.Snapshots) != 1 || resp.Snapshots[0].IpAddress != "10.1.0.1" {
+				t.Errorf("Unexpected last page: %v", resp.Snapshots)
+			}
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	if pages != 1 {
+		t.Errorf("Expected 2 pages, got %d", pages+1)
+	}
+
+	for _, req := range []*proto.QueryFleetRequest{
+		{},
+		{IpRange: "10.0.0.0/8", AsOf: "yesterday"},
+		{IpRange: "10.0.0.0/8", PageToken: "!"},
+	} {
+		if _, err := server.QueryFleet(ctx, req); err == nil {
+			t.Errorf("Expected error for %v", req)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/data"
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+	"github.com/justicecaban/host-diff-tool/backend/internal/retention"
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+// retentionActor is recorded in the audit trail for snapshots pruned by
+// RunRetention.
+const retentionActor = "retention"
+
+// WithRetention sets the retention policy enforced by RunRetention and used
+// by PruneSnapshots requests that don't name one.
+func WithRetention(policy *retention.Policy) Option {
+	return func(s *Server) {
+		s.retention = policy
+	}
+}
+
+// PruneSnapshots handles the PruneSnapshots RPC.
+func (s *Server) PruneSnapshots(ctx context.Context, req *proto.PruneSnapshotsRequest) (*proto.PruneSnapshotsResponse, error) {
+	policy := s.retention
+	if req.GetPolicy() != "" {
+		var err error
+		if policy, err = retention.ParsePolicy(req.GetPolicy()); err != nil {
+			return nil, fmt.Errorf("invalid policy: %w", err)
+		}
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("no retention policy is configured; set policy")
+	}
+
+	pruned, err := s.prune(policy, time.Now(), req.GetDryRun(), audit(ctx, req.GetActor(), "retention policy "+policy.String()))
+	if err != nil {
+		log.Printf("PruneSnapshots error: %v", err)
+		return nil, fmt.Errorf("failed to prune snapshots: %w", err)
+	}
+
+	resp := &proto.PruneSnapshotsResponse{Snapshots: make([]*proto.SnapshotInfo, len(pruned))}
+	for i, snap := range pruned {
+		resp.Snapshots[i] = snapshotInfoToProto(snap)
+	}
+	return resp, nil
+}
+
+// RunRetention enforces the configured retention policy now and then every
+// interval until ctx is done. With dryRun set it only logs what it would
+// prune.
+func (s *Server) RunRetention(ctx context.Context, interval time.Duration, dryRun bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.enforceRetention(dryRun)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enforceRetention runs the configured retention policy once and logs the
+// result.
+func (s *Server) enforceRetention(dryRun bool) {
+	reason := "retention policy " + s.retention.String()
+	pruned, err := s.prune(s.retention, time.Now(), dryRun, data.Audit{Actor: retentionActor, Reason: reason})
+	if err != nil {
+		log.Printf("Retention error: %v", err)
+		return
+	}
+
+	verb := "Pruned"
+	if dryRun {
+		verb = "Would prune"
+	}
+	for _, snap := range pruned {
+		log.Printf("%s snapshot %s (%s at %s)", verb, snap.ID, snap.IPAddress, snap.Timestamp)
+	}
+	log.Printf("Retention (%s): %s %d snapshots", s.retention, verb, len(pruned))
+}
+
+// prune applies a retention policy to every host in turn and returns the
+// snapshots it removed, or would remove with dryRun set.
+func (s *Server) prune(policy *retention.Policy, now time.Time, dryRun bool, audit data.Audit) ([]*data.Snapshot, error) {
+	var pruned []*data.Snapshot
+	q := data.HostQuery{Limit: maxPageSize}
+	for {
+		hosts, next, err := s.db.ListHosts(q)
+		if err != nil {
+			return pruned, err
+		}
+		for _, host := range hosts {
+			candidates, err := s.pruneCandidates(policy, host.IPAddress, now)
+			if err != nil {
+				return pruned, err
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+
+			if dryRun {
+				pruned = append(pruned, candidates...)
+				continue
+			}
+			ids := make([]string, len(candidates))
+			for i, snap := range candidates {
+				ids[i] = snap.ID
+			}
+			removed, err := s.db.PruneSnapshots(ids, audit)
+			if err != nil {
+				return pruned, fmt.Errorf("host %s: %w", host.IPAddress, err)
+			}
+			pruned = append(pruned, removed...)
+		}
+		if next == nil {
+			return pruned, nil
+		}
+		q.After = next
+	}
+}
+
+// pruneCandidates returns the snapshots of a host that a retention policy
+// doesn't keep. A snapshot counts as changed if comparing it with the
+// host's previous snapshot, under the server's ignore rules, reports
+// anything; snapshots that can't be compared are kept. The history is read
+// a page of metadata at a time, and only snapshots whose content differs
+// from their predecessor's are loaded and compared.
+func (s *Server) pruneCandidates(policy *retention.Policy, ipAddress string, now time.Time) ([]*data.Snapshot, error) {
+	byID := make(map[string]*data.Snapshot)
+	var candidates []retention.Snapshot
+	var prev *data.Snapshot
+	// prevParsed is prev's parsed content, if it has been loaded
+	var prevParsed *diff.HostSnapshot
+
+	q := data.HistoryQuery{IPAddress: ipAddress, Order: data.HistoryOrderOldestFirst, Limit: maxPageSize}
+	for {
+		snapshots, next, err := s.db.ListSnapshots(q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list snapshots of %s: %w", ipAddress, err)
+		}
+		for _, snap := range snapshots {
+			byID[snap.ID] = snap
+			candidate := retention.Snapshot{ID: snap.ID, Changed: true}
+
+			var parsed *diff.HostSnapshot
+			switch {
+			case prev == nil:
+			case snap.ContentHash == prev.ContentHash:
+				candidate.Changed = false
+				parsed = prevParsed
+			default:
+				if prevParsed == nil {
+					if prevParsed, err = s.loadParsed(prev.ID); err != nil {
+						return nil, err
+					}
+				}
+				if parsed, err = s.loadParsed(snap.ID); err != nil {
+					return nil, err
+				}
+				if prevParsed != nil && parsed != nil {
+					candidate.Changed = diff.Compare(prevParsed, parsed, diff.WithHost(snap.IPAddress), diff.WithRules(s.rules)).HasChanges()
+				}
+			}
+			if candidate.Time, err = time.Parse(time.RFC3339, snap.Timestamp); err != nil {
+				candidate.Changed = true
+			}
+			candidates = append(candidates, candidate)
+			prev, prevParsed = snap, parsed
+		}
+		if next == nil {
+			break
+		}
+		q.After = next
+	}
+
+	var pruned []*data.Snapshot
+	for _, candidate := range policy.Prune(candidates, now) {
+		pruned = append(pruned, byID[candidate.ID])
+	}
+	return pruned, nil
+}
+
+// loadParsed loads and parses a stored snapshot. It returns nil if the
+// snapshot is gone or can't be parsed.
+func (s *Server) loadParsed(id string) (*diff.HostSnapshot, error) {
+	snap, err := s.db.GetSnapshotByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot %s: %w", id, err)
+	}
+	if snap == nil {
+		return nil, nil
+	}
+	parsed, err := diff.ParseSnapshot(snap.Data)
+	if err != nil {
+		return nil, nil
+	}
+	return parsed, nil
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/retention"
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+// uploadRetentionFixtures stores five daily snapshots of 10.0.0.1, of which
+// the first and third change something, and returns their IDs.
+func uploadRetentionFixtures(t *testing.T, server *Server) []string {
+	t.Helper()
+	var ids []string
+	for i, services := range []string{`[{"port": 22}]`, `[{"port": 22}]`, `[{"port": 22}, {"port": 80}]`, `[{"port": 22}, {"port": 80}]`, `[{"port": 22}, {"port": 80}]`} {
+		ts := []string{"01", "02", "03", "04", "05"}[i]
+		ids = append(ids, upload(t, server, "host_10.0.0.1_2025-01-"+ts+"T00-00-00Z.json", `{"timestamp": "2025-01-`+ts+`T00:00:00Z", "services": `+services+`}`))
+	}
+	return ids
+}
+
+func historyIDs(t *testing.T, server *Server) []string {
+	t.Helper()
+	history, err := server.GetHostHistory(context.Background(), &proto.GetHostHistoryRequest{IpAddress: "10.0.0.1", Order: proto.HistoryOrder_HISTORY_ORDER_OLDEST_FIRST})
+	if err != nil {
+		t.Fatalf("GetHostHistory failed: %v", err)
+	}
+	var ids []string
+	for _, snap := range history.Snapshots {
+		ids = append(ids, snap.Id)
+	}
+	return ids
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	ids := uploadRetentionFixtures(t, server)
+
+	for _, req := range []*proto.PruneSnapshotsRequest{
+		{DryRun: true},
+		{Policy: "sometimes", DryRun: true},
+	} {
+		if _, err := server.PruneSnapshots(ctx, req); err == nil {
+			t.Errorf("Expected error for %v", req)
+		}
+	}
+
+	// Everything is older than a week: only changes and the latest snapshot stay
+	report, err := server.PruneSnapshots(ctx, &proto.PruneSnapshotsRequest{Policy: "all:7d", DryRun: true})
+	if err != nil {
+		t.Fatalf("PruneSnapshots failed: %v", err)
+	}
+	if len(report.Snapshots) != 2 || report.Snapshots[0].Id != ids[1] || report.Snapshots[1].Id != ids[3] {
+		t.Errorf("Expected %s and %s to be pruned, got %v", ids[1], ids[3], report.Snapshots)
+	}
+	if got := historyIDs(t, server); len(got) != 5 {
+		t.Errorf("Expected a dry run to keep every snapshot, got %v", got)
+	}
+
+	pruned, err := server.PruneSnapshots(ctx, &proto.PruneSnapshotsRequest{Policy: "all:7d", Actor: "alice"})
+	if err != nil {
+		t.Fatalf("PruneSnapshots failed: %v", err)
+	}
+	if len(pruned.Snapshots) != 2 {
+		t.Errorf("Expected 2 pruned snapshots, got %v", pruned.Snapshots)
+	}
+	if got := historyIDs(t, server); len(got) != 3 || got[0] != ids[0] || got[1] != ids[2] || got[2] != ids[4] {
+		t.Errorf("Expected %v to be kept, got %v", []string{ids[0], ids[2], ids[4]}, got)
+	}
+	if again, err := server.PruneSnapshots(ctx, &proto.PruneSnapshotsRequest{Policy: "all:7d"}); err != nil || len(again.Snapshots) != 0 {
+		t.Errorf("Expected nothing left to prune, got %v, %v", again, err)
+	}
+}
+
+func TestRunRetention(t *testing.T) {
+	policy, err := retention.ParsePolicy("daily:30d")
+	if err != nil {
+		t.Fatalf("ParsePolicy failed: %v", err)
+	}
+	server := newTestServer(t, WithRetention(policy))
+	uploadRetentionFixtures(t, server)
+
+	// A cancelled context stops the job after its first run
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	server.RunRetention(ctx, time.Hour, true)
+	if got := historyIDs(t, server); len(got) != 5 {
+		t.Errorf("Expected a dry run to keep every snapshot, got %v", got)
+	}
+	server.RunRetention(ctx, time.Hour, false)
+	if got := historyIDs(t, server); len(got) != 3 {
+		t.Errorf("Expected the configured policy to prune 2 snapshots, got %v", got)
+	}
+
+	// Requests without a policy use the configured one
+	report, err := server.PruneSnapshots(context.Background(), &proto.PruneSnapshotsRequest{DryRun: true})
+	if err != nil || len(report.Snapshots) != 0 {
+		t.Errorf("Expected nothing left to prune, got %v, %v", report, err)
+	}
+}
+
+func TestPruneSnapshots_IdenticalContent(t *testing.T) {
+	server := newTestServer(t)
+	var ids []string
+	for _, ts := range []string{"01", "02", "03"} {
+		id, err := server.db.InsertSnapshot("10.0.0.1", "2025-01-"+ts+"T00:00:00Z", []byte(`{"services": [{"port": 22}]}`))
+		if err != nil {
+			t.Fatalf("InsertSnapshot failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	// Snapshots with the same content as their predecessor are unchanged
+	report, err := server.PruneSnapshots(context.Background(), &proto.PruneSnapshotsRequest{Policy: "all:7d", DryRun: true})
+	if err != nil {
+		t.Fatalf("PruneSnapshots failed: %v", err)
+	}
+	if len(report.Snapshots) != 1 || report.Snapshots[0].Id != ids[1] {
+		t.Errorf("Expected only %s to be pruned, got %v", ids[1], report.Snapshots)
+	}
+}
@@ -1,13 +1,19 @@
 package server
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/justicecaban/host-diff-tool/backend/internal/data"
 	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+	"github.com/justicecaban/host-diff-tool/backend/internal/render"
+	"github.com/justicecaban/host-diff-tool/backend/internal/retention"
+	"github.com/justicecaban/host-diff-tool/backend/internal/scoring"
 	"github.com/justicecaban/host-diff-tool/backend/internal/validation"
 	"github.com/justicecaban/host-diff-tool/proto"
 )
@@ -15,62 +21,235 @@ import (
 // Server is the gRPC server.
 type Server struct {
 	proto.UnimplementedHostServiceServer
-	db *data.DB
+	db      data.Store
+	scoring *scoring.Config
+	rules   *diff.RuleSet
+	// maxUploadSize caps the size of a streamed snapshot upload in bytes.
+	maxUploadSize int64
+	// maxArchiveSize caps the total size of the files in a bulk upload,
+	// after decompression.
+	maxArchiveSize   int64
+	metadataSource   MetadataSource
+	schemaStrictness validation.Strictness
+	// retention is the policy RunRetention enforces, if any.
+	retention *retention.Policy
+}
+
+// DefaultMaxUploadSize is the streamed upload limit used unless
+// WithMaxUploadSize overrides it.
+const DefaultMaxUploadSize = 64 << 20
+
+// Option configures optional Server behavior.
+type Option func(*Server)
+
+// WithScoringConfig sets the weights used to score diff reports.
+func WithScoringConfig(cfg *scoring.Config) Option {
+	return func(s *Server) {
+		s.scoring = cfg
+	}
+}
+
+// WithRules sets the ignore rules applied to every comparison.
+func WithRules(rules *diff.RuleSet) Option {
+	return func(s *Server) {
+		s.rules = rules
+	}
+}
+
+// WithMaxUploadSize sets the largest snapshot, in bytes, accepted by
+// UploadSnapshotStream.
+func WithMaxUploadSize(size int64) Option {
+	return func(s *Server) {
+		s.maxUploadSize = size
+	}
+}
+
+// WithMaxArchiveSize sets the largest total size, in bytes, of the files in
+// a BulkUpload archive after decompression.
+func WithMaxArchiveSize(size int64) Option {
+	return func(s *Server) {
+		s.maxArchiveSize = size
+	}
+}
+
+// WithSchemaStrictness sets which schema issues in an uploaded snapshot
+// cause it to be rejected.
+func WithSchemaStrictness(level validation.Strictness) Option {
+	return func(s *Server) {
+		s.schemaStrictness = level
+	}
 }
 
 // NewServer creates a new server.
-func NewServer(db *data.DB) *Server {
-	return &Server{db: db}
+func NewServer(db data.Store, opts ...Option) *Server {
+	s := &Server{
+		db:               db,
+		scoring:          scoring.DefaultConfig(),
+		maxUploadSize:    DefaultMaxUploadSize,
+		maxArchiveSize:   DefaultMaxArchiveSize,
+		metadataSource:   MetadataFromFilename,
+		schemaStrictness: validation.StrictnessLenient,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // UploadSnapshot handles the UploadSnapshot RPC.
 func (s *Server) UploadSnapshot(ctx context.Context, req *proto.UploadSnapshotRequest) (*proto.UploadSnapshotResponse, error) {
-	parsed, err := validation.ParseFilename(req.GetFilename())
+	resp, err := s.storeSnapshot(ctx, req.GetFilename(), req.GetFileContent(), req.GetConflictPolicy())
 	if err != nil {
 		log.Printf("UploadSnapshot error: %v", err)
-		return nil, fmt.Errorf("invalid filename: %w", err)
+		return nil, err
 	}
+	return resp, nil
+}
 
-	// Validate that the file content is valid JSON
-	var jsonData interface{}
-	if err := json.Unmarshal(req.GetFileContent(), &jsonData); err != nil {
-		log.Printf("UploadSnapshot error: %v", err)
-		return nil, fmt.Errorf("invalid JSON content: %w", err)
+// storeSnapshot validates an uploaded file and stores it, resolving a clash
+// with a stored snapshot according to policy.
+func (s *Server) storeSnapshot(ctx context.Context, filename string, content []byte, policy proto.ConflictPolicy) (*proto.UploadSnapshotResponse, error) {
+	conflictPolicy, ok := conflictPolicies[policy]
+	if !ok {
+		return nil, fmt.Errorf("unknown conflict policy %v", policy)
+	}
+	meta, warnings, err := s.checkUpload(filename, content)
+	if err != nil {
+		return nil, err
 	}
 
-	id, err := s.db.InsertSnapshot(parsed.IPAddress, parsed.Timestamp, req.GetFileContent())
+	result, err := s.db.StoreSnapshot(
+		data.NewSnapshot{IPAddress: meta.IPAddress, Timestamp: meta.Timestamp, Data: content},
+		conflictPolicy,
+		audit(ctx, "", "replaced by upload "+filename),
+	)
+	var conflict *data.ConflictError
+	if errors.As(err, &conflict) {
+		return nil, fmt.Errorf("%w; set conflict_policy to replace it or keep both", conflict)
+	}
 	if err != nil {
-		log.Printf("UploadSnapshot error: %v", err)
 		return nil, fmt.Errorf("failed to insert snapshot: %w", err)
 	}
 
 	return &proto.UploadSnapshotResponse{
-		Id:        id,
-		IpAddress: parsed.IPAddress,
-		Timestamp: parsed.Timestamp,
+		Id:          result.ID,
+		IpAddress:   meta.IPAddress,
+		Timestamp:   meta.Timestamp,
+		Warnings:    warnings,
+		Outcome:     uploadOutcomes[result.Outcome],
+		ReplacedIds: result.ReplacedIDs,
+		Revision:    int32(result.Revision),
 	}, nil
 }
 
+var conflictPolicies = map[proto.ConflictPolicy]data.ConflictPolicy{
+	proto.ConflictPolicy_CONFLICT_POLICY_UNSPECIFIED: data.ConflictReject,
+	proto.ConflictPolicy_CONFLICT_POLICY_REJECT:      data.ConflictReject,
+	proto.ConflictPolicy_CONFLICT_POLICY_REPLACE:     data.ConflictReplace,
+	proto.ConflictPolicy_CONFLICT_POLICY_KEEP_BOTH:   data.ConflictKeepBoth,
+}
+
+var uploadOutcomes = map[data.StoreOutcome]proto.UploadOutcome{
+	data.StoreCreated:   proto.UploadOutcome_UPLOAD_OUTCOME_CREATED,
+	data.StoreUnchanged: proto.UploadOutcome_UPLOAD_OUTCOME_UNCHANGED,
+	data.StoreReplaced:  proto.UploadOutcome_UPLOAD_OUTCOME_REPLACED,
+	data.StoreRevision:  proto.UploadOutcome_UPLOAD_OUTCOME_REVISION,
+}
+
+// checkSchema validates an uploaded snapshot against the HostSnapshot schema.
+// Issues that the server's strictness level lets through are returned as
+// warnings; any others reject the upload.
+func (s *Server) checkSchema(content []byte) ([]*proto.UploadWarning, error) {
+	issues, err := validation.ValidateSnapshot(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []*proto.UploadWarning
+	var blocking []string
+	for _, issue := range issues {
+		if s.schemaStrictness.Blocks(issue) {
+			blocking = append(blocking, issue.String())
+			continue
+		}
+		code := proto.UploadWarningCode_UPLOAD_WARNING_CODE_SCHEMA_WARNING
+		if issue.Severity == validation.SeverityError {
+			code = proto.UploadWarningCode_UPLOAD_WARNING_CODE_SCHEMA_ERROR
+		}
+		warnings = append(warnings, &proto.UploadWarning{Code: code, Pointer: issue.Pointer, Message: issue.Message})
+	}
+	if len(blocking) > 0 {
+		return nil, fmt.Errorf("invalid snapshot: %s", strings.Join(blocking, "; "))
+	}
+	return warnings, nil
+}
+
 // GetHostHistory handles the GetHostHistory RPC.
 func (s *Server) GetHostHistory(ctx context.Context, req *proto.GetHostHistoryRequest) (*proto.GetHostHistoryResponse, error) {
-	snapshots, err := s.db.GetSnapshotsByIP(req.GetIpAddress())
+	resp, err := s.getHostHistory(req)
 	if err != nil {
 		log.Printf("GetHostHistory error: %v", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) getHostHistory(req *proto.GetHostHistoryRequest) (*proto.GetHostHistoryResponse, error) {
+	limit, err := pageSize(req.GetPageSize())
+	if err != nil {
+		return nil, err
+	}
+	order, ok := historyOrders[req.GetOrder()]
+	if !ok {
+		return nil, fmt.Errorf("unknown history order %v", req.GetOrder())
+	}
+
+	q := data.HistoryQuery{IPAddress: req.GetIpAddress(), Order: order, Limit: limit}
+	if req.GetIpRange() != "" {
+		if req.GetIpAddress() != "" {
+			return nil, fmt.Errorf("set either ip_address or ip_range, not both")
+		}
+		r, err := parseIPRange(req.GetIpRange())
+		if err != nil {
+			return nil, err
+		}
+		q.Range = &r
+	}
+	if q.Start, err = normalizeTimeBound(req.GetStartTime()); err != nil {
+		return nil, fmt.Errorf("invalid start_time: %w", err)
+	}
+	if q.End, err = normalizeTimeBound(req.GetEndTime()); err != nil {
+		return nil, fmt.Errorf("invalid end_time: %w", err)
+	}
+	if q.Start != "" && q.End != "" && q.Start > q.End {
+		return nil, fmt.Errorf("start_time %s is after end_time %s", q.Start, q.End)
+	}
+	if req.GetPageToken() != "" {
+		q.After = &data.HistoryCursor{}
+		if err := decodePageToken(req.GetPageToken(), q.After); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshots, next, err := s.db.ListSnapshots(q)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get snapshots by IP: %w", err)
 	}
 
-	protoSnapshots := make([]*proto.SnapshotInfo, len(snapshots))
+	resp := &proto.GetHostHistoryResponse{Snapshots: make([]*proto.SnapshotInfo, len(snapshots))}
 	for i, snap := range snapshots {
-		protoSnapshots[i] = &proto.SnapshotInfo{
-			Id:        snap.ID,
-			IpAddress: snap.IPAddress,
-			Timestamp: snap.Timestamp,
-		}
+		resp.Snapshots[i] = snapshotInfoToProto(snap)
+	}
+	if next != nil {
+		resp.NextPageToken = encodePageToken(next)
 	}
+	return resp, nil
+}
 
-	return &proto.GetHostHistoryResponse{
-		Snapshots: protoSnapshots,
-	}, nil
+var historyOrders = map[proto.HistoryOrder]data.HistoryOrder{
+	proto.HistoryOrder_HISTORY_ORDER_UNSPECIFIED:  data.HistoryOrderNewestFirst,
+	proto.HistoryOrder_HISTORY_ORDER_NEWEST_FIRST: data.HistoryOrderNewestFirst,
+	proto.HistoryOrder_HISTORY_ORDER_OLDEST_FIRST: data.HistoryOrderOldestFirst,
 }
 
 // CompareSnapshots handles the CompareSnapshots RPC.
@@ -95,50 +274,116 @@ func (s *Server) CompareSnapshots(ctx context.Context, req *proto.CompareSnapsho
 		return nil, fmt.Errorf("cannot compare snapshots from different IP addresses: %s vs %s", snapA.IPAddress, snapB.IPAddress)
 	}
 
-	report, err := diff.DiffSnapshots(snapA.Data, snapB.Data)
+	requestRules, err := rulesFromProto(req.GetRules())
+	if err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+
+	report, err := diff.DiffSnapshots(snapA.Data, snapB.Data,
+		diff.WithHost(snapA.IPAddress),
+		diff.WithRules(s.mergeRules(requestRules)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to diff snapshots: %w", err)
 	}
 
-	protoReport := &proto.DiffReport{
-		Summary: report.Summary,
+	risk := scoring.Score(report, s.scoring)
+	resp := &proto.CompareSnapshotsResponse{
+		Report: reportToProto(report),
+		Risk:   riskScoreToProto(risk),
 	}
 
-	for _, s := range report.AddedServices {
-		protoReport.AddedPorts = append(protoReport.AddedPorts, &proto.PortChange{
-			Port:     int32(s.Port),
-			Protocol: s.Protocol,
+	if req.GetFormat() != proto.ReportFormat_REPORT_FORMAT_UNSPECIFIED {
+		format, ok := reportFormatFromProto(req.GetFormat())
+		if !ok {
+			return nil, fmt.Errorf("unsupported report format: %s", req.GetFormat())
+		}
+		var buf bytes.Buffer
+		err := render.Render(&buf, format, &render.Document{
+			Host:   snapA.IPAddress,
+			From:   render.Snapshot{ID: snapA.ID, Timestamp: snapA.Timestamp},
+			To:     render.Snapshot{ID: snapB.ID, Timestamp: snapB.Timestamp},
+			Report: report,
+			Risk:   risk,
 		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to render report: %w", err)
+		}
+		resp.Rendered = &proto.RenderedReport{
+			Format:      req.GetFormat(),
+			ContentType: render.ContentType(format),
+			Content:     buf.Bytes(),
+		}
 	}
 
-	for _, s := range report.RemovedServices {
-		protoReport.RemovedPorts = append(protoReport.RemovedPorts, &proto.PortChange{
-			Port:     int32(s.Port),
-			Protocol: s.Protocol,
-		})
+	return resp, nil
+}
+
+// GetHostTimeline handles the GetHostTimeline RPC.
+func (s *Server) GetHostTimeline(ctx context.Context, req *proto.GetHostTimelineRequest) (*proto.GetHostTimelineResponse, error) {
+	start, err := normalizeTimeBound(req.GetStartTime())
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_time: %w", err)
+	}
+	end, err := normalizeTimeBound(req.GetEndTime())
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_time: %w", err)
+	}
+	if start != "" && end != "" && start > end {
+		return nil, fmt.Errorf("start_time %s is after end_time %s", start, end)
 	}
 
-	for _, sc := range report.ChangedServices {
-		protoReport.ChangedPorts = append(protoReport.ChangedPorts, &proto.PortChange{
-			Port:     int32(sc.Port),
-			Protocol: sc.Protocol,
-			Changes:  sc.Changes,
-		})
+	snapshots, err := s.db.GetSnapshotsInRange(req.GetIpAddress(), start, end)
+	if err != nil {
+		log.Printf("GetHostTimeline error: %v", err)
+		return nil, fmt.Errorf("failed to get snapshots for timeline: %w", err)
 	}
 
-	for _, cve := range report.AddedCVEs {
-		protoReport.AddedCves = append(protoReport.AddedCves, &proto.CVEChange{
-			CveId: cve.CVEID,
-		})
+	resp := &proto.GetHostTimelineResponse{}
+	var prev *diff.HostSnapshot
+	for i, snap := range snapshots {
+		// Each snapshot is parsed once and reused as the "from" side of the next pair
+		parsed, err := diff.ParseSnapshot(snap.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot %s: %w", snap.ID, err)
+		}
+		resp.Snapshots = append(resp.Snapshots, snapshotInfoToProto(snap))
+
+		if prev != nil {
+			report := diff.Compare(prev, parsed, diff.WithHost(snap.IPAddress), diff.WithRules(s.rules))
+			resp.Entries = append(resp.Entries, &proto.TimelineEntry{
+				From:   snapshotInfoToProto(snapshots[i-1]),
+				To:     snapshotInfoToProto(snap),
+				Report: reportToProto(report),
+				Risk:   riskScoreToProto(scoring.Score(report, s.scoring)),
+			})
+		}
+		prev = parsed
 	}
 
-	for _, cve := range report.RemovedCVEs {
-		protoReport.RemovedCves = append(protoReport.RemovedCves, &proto.CVEChange{
-			CveId: cve.CVEID,
-		})
+	return resp, nil
+}
+
+// mergeRules returns request rules followed by the server's configured rules.
+func (s *Server) mergeRules(requestRules *diff.RuleSet) *diff.RuleSet {
+	merged := &diff.RuleSet{}
+	if requestRules != nil {
+		merged.Rules = append(merged.Rules, requestRules.Rules...)
+	}
+	if s.rules != nil {
+		merged.Rules = append(merged.Rules, s.rules.Rules...)
 	}
+	return merged
+}
 
-	return &proto.CompareSnapshotsResponse{
-		Report: protoReport,
-	}, nil
+// normalizeTimeBound validates an optional RFC 3339 time bound and converts it
+// to the UTC form used for stored snapshot timestamps.
+func normalizeTimeBound(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return "", err
+	}
+	return t.UTC().Format(time.RFC3339), nil
 }
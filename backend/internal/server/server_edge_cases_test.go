@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/justicecaban/host-diff-tool/backend/internal/data"
@@ -125,15 +126,25 @@ func TestUploadSnapshot_Duplicate(t *testing.T) {
 	}
 
 	// First upload should succeed
-	_, err = server.UploadSnapshot(ctx, req)
+	first, err := server.UploadSnapshot(ctx, req)
 	if err != nil {
 		t.Fatalf("First upload failed: %v", err)
 	}
 
-	// Second upload with same IP and timestamp should fail
+	// Re-uploading identical content succeeds and returns the stored snapshot
+	again, err := server.UploadSnapshot(ctx, req)
+	if err != nil {
+		t.Fatalf("Identical re-upload failed: %v", err)
+	}
+	if again.Id != first.Id || again.Outcome != proto.UploadOutcome_UPLOAD_OUTCOME_UNCHANGED {
+		t.Errorf("Expected unchanged snapshot %s, got %v", first.Id, again)
+	}
+
+	// Different content with same IP and timestamp should fail
+	req.FileContent = []byte(`{"ip": "127.0.0.1", "services": [{"port": 22}]}`)
 	_, err = server.UploadSnapshot(ctx, req)
-	if err == nil {
-		t.Error("Expected error for duplicate snapshot (same IP + timestamp)")
+	if err == nil || !strings.Contains(err.Error(), "already stored as ID "+first.Id) {
+		t.Errorf("Expected conflict error naming snapshot %s, got %v", first.Id, err)
 	}
 }
 
@@ -453,34 +464,34 @@ func TestUploadSnapshot_Concurrent(t *testing.T) {
 	}
 
 	// Try to upload the same snapshot concurrently
-	errorCount := 0
-	successCount := 0
-
-	done := make(chan bool, 10)
-
+	results := make(chan *proto.UploadSnapshotResponse, 10)
 	for i := 0; i < 10; i++ {
 		go func() {
-			_, err := server.UploadSnapshot(ctx, req)
+			resp, err := server.UploadSnapshot(ctx, req)
 			if err != nil {
-				errorCount++
-			} else {
-				successCount++
+				t.Errorf("Concurrent upload failed: %v", err)
 			}
-			done <- true
+			results <- resp
 		}()
 	}
 
-	// Wait for all goroutines
+	// Exactly one should store the snapshot; the others find it unchanged
+	created := 0
+	ids := make(map[string]bool)
 	for i := 0; i < 10; i++ {
-		<-done
+		resp := <-results
+		if resp == nil {
+			continue
+		}
+		ids[resp.Id] = true
+		if resp.Outcome == proto.UploadOutcome_UPLOAD_OUTCOME_CREATED {
+			created++
+		}
 	}
-
-	// Exactly one should succeed, others should fail due to UNIQUE constraint
-	if successCount != 1 {
-		t.Errorf("Expected exactly 1 successful upload in concurrent scenario, got %d", successCount)
+	if created != 1 {
+		t.Errorf("Expected exactly 1 upload to create the snapshot, got %d", created)
 	}
-
-	if errorCount != 9 {
-		t.Errorf("Expected 9 failed uploads in concurrent scenario, got %d", errorCount)
+	if len(ids) != 1 {
+		t.Errorf("Expected every upload to return the same ID, got %v", ids)
 	}
 }
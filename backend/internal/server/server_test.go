@@ -1,4 +1,547 @@
 package server
 
 // Note: parseFilename tests have been moved to backend/internal/validation/filename_test.go
-// This file is kept for potential future server-specific tests.
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/data"
+	"github.com/justicecaban/host-diff-tool/backend/internal/diff"
+	"github.com/justicecaban/host-diff-tool/backend/internal/validation"
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+// newTestServer creates a server backed by an in-memory database.
+func newTestServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	db, err := data.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewServer(db, opts...)
+}
+
+// upload stores a snapshot and returns its ID.
+func upload(t *testing.T, server *Server, filename, content string) string {
+	t.Helper()
+	resp, err := server.UploadSnapshot(context.Background(), &proto.UploadSnapshotRequest{
+		Filename:    filename,
+		FileContent: []byte(content),
+	})
+	if err != nil {
+		t.Fatalf("Failed to upload %s: %v", filename, err)
+	}
+	return resp.Id
+}
+
+func TestCompareSnapshots_RiskScore(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	idA := upload(t, server, "host_127.0.0.1_2025-01-01T00-00-00Z.json",
+		`{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"version": "tlsv1_3"}}]}`)
+	idB := upload(t, server, "host_127.0.0.1_2025-01-02T00-00-00Z.json",
+		`{"services": [{"port": 443, "protocol": "HTTPS"}, {"port": 3389, "protocol": "RDP"}]}`)
+
+	resp, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{SnapshotIdA: idA, SnapshotIdB: idB})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+
+	risk := resp.GetRisk()
+	if risk == nil {
+		t.Fatal("Expected risk score on response")
+	}
+	// TLS removed (8) + new exposed port (5)
+	if risk.Score != 13 {
+		t.Errorf("Expected risk score 13, got %v", risk.Score)
+	}
+	if risk.Severity != proto.Severity_SEVERITY_HIGH {
+		t.Errorf("Expected high severity, got %s", risk.Severity)
+	}
+	if len(risk.Changes) != 2 {
+		t.Errorf("Expected 2 scored changes, got %d", len(risk.Changes))
+	}
+}
+
+func TestGetHostTimeline(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	upload(t, server, "host_127.0.0.1_2025-01-03T00-00-00Z.json",
+		`{"services": [{"port": 80, "protocol": "HTTP"}, {"port": 443, "protocol": "HTTPS"}, {"port": 22, "protocol": "SSH"}]}`)
+	upload(t, server, "host_127.0.0.1_2025-01-01T00-00-00Z.json",
+		`{"services": [{"port": 80, "protocol": "HTTP"}]}`)
+	upload(t, server, "host_127.0.0.1_2025-01-02T00-00-00Z.json",
+		`{"services": [{"port": 80, "protocol": "HTTP"}, {"port": 443, "protocol": "HTTPS"}]}`)
+	upload(t, server, "host_10.0.0.1_2025-01-02T00-00-00Z.json",
+		`{"services": []}`)
+
+	resp, err := server.GetHostTimeline(ctx, &proto.GetHostTimelineRequest{IpAddress: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("GetHostTimeline failed: %v", err)
+	}
+
+	if len(resp.Snapshots) != 3 {
+		t.Fatalf("Expected 3 snapshots, got %d", len(resp.Snapshots))
+	}
+	if len(resp.Entries) != 2 {
+		t.Fatalf("Expected 2 timeline entries, got %d", len(resp.Entries))
+	}
+	wantPorts := []int32{443, 22}
+	for i, entry := range resp.Entries {
+		if entry.From.Timestamp >= entry.To.Timestamp {
+			t.Errorf("Entry %d not in ascending order: %s -> %s", i, entry.From.Timestamp, entry.To.Timestamp)
+		}
+		if len(entry.Report.AddedPorts) != 1 || entry.Report.AddedPorts[0].Port != wantPorts[i] {
+			t.Errorf("Entry %d: expected port %d added, got %v", i, wantPorts[i], entry.Report.AddedPorts)
+		}
+		if entry.Risk == nil || entry.Risk.Score == 0 {
+			t.Errorf("Entry %d: expected a risk score", i)
+		}
+	}
+}
+
+func TestGetHostTimeline_TimeWindow(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	for _, day := range []string{"01", "02", "03", "04"} {
+		upload(t, server, "host_127.0.0.1_2025-01-"+day+"T00-00-00Z.json", `{"services": []}`)
+	}
+
+	resp, err := server.GetHostTimeline(ctx, &proto.GetHostTimelineRequest{
+		IpAddress: "127.0.0.1",
+		StartTime: "2025-01-02T00:00:00Z",
+		EndTime:   "2025-01-03T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("GetHostTimeline failed: %v", err)
+	}
+	if len(resp.Snapshots) != 2 || len(resp.Entries) != 1 {
+		t.Fatalf("Expected 2 snapshots and 1 entry, got %d and %d", len(resp.Snapshots), len(resp.Entries))
+	}
+	if resp.Entries[0].From.Timestamp != "2025-01-02T00:00:00Z" {
+		t.Errorf("Unexpected window start: %s", resp.Entries[0].From.Timestamp)
+	}
+}
+
+func TestGetHostTimeline_InvalidWindow(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	requests := []*proto.GetHostTimelineRequest{
+		{IpAddress: "127.0.0.1", StartTime: "yesterday"},
+		{IpAddress: "127.0.0.1", EndTime: "2025-13-01T00:00:00Z"},
+		{IpAddress: "127.0.0.1", StartTime: "2025-01-03T00:00:00Z", EndTime: "2025-01-01T00:00:00Z"},
+	}
+	for _, req := range requests {
+		if _, err := server.GetHostTimeline(ctx, req); err == nil {
+			t.Errorf("Expected error for window %q - %q", req.StartTime, req.EndTime)
+		}
+	}
+}
+
+func TestGetHostTimeline_SingleSnapshot(t *testing.T) {
+	server := newTestServer(t)
+	upload(t, server, "host_127.0.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`)
+
+	resp, err := server.GetHostTimeline(context.Background(), &proto.GetHostTimelineRequest{IpAddress: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("GetHostTimeline failed: %v", err)
+	}
+	if len(resp.Snapshots) != 1 || len(resp.Entries) != 0 {
+		t.Errorf("Expected 1 snapshot and no entries, got %d and %d", len(resp.Snapshots), len(resp.Entries))
+	}
+}
+
+func TestCompareSnapshots_Rules(t *testing.T) {
+	serverRules := &diff.RuleSet{Rules: []diff.Rule{{Name: "server-ports", Field: "service", Action: diff.ActionSuppress}}}
+	if err := serverRules.Compile(); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	server := newTestServer(t, WithRules(serverRules))
+	ctx := context.Background()
+
+	idA := upload(t, server, "host_127.0.0.1_2025-01-01T00-00-00Z.json",
+		`{"services": [{"port": 80, "protocol": "HTTP", "status": 200}]}`)
+	idB := upload(t, server, "host_127.0.0.1_2025-01-02T00-00-00Z.json",
+		`{"services": [{"port": 80, "protocol": "HTTP", "status": 500}, {"port": 3389, "protocol": "RDP"}]}`)
+
+	resp, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{
+		SnapshotIdA: idA,
+		SnapshotIdB: idB,
+		Rules: []*proto.SuppressionRule{{
+			Name:   "status",
+			Host:   "127.0.0.0/8",
+			Ports:  "80",
+			Field:  "status",
+			Action: proto.RuleAction_RULE_ACTION_SUPPRESS,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+
+	report := resp.GetReport()
+	if len(report.AddedPorts) != 0 || len(report.ChangedPorts) != 0 {
+		t.Errorf("Expected all changes suppressed, got %v added and %v changed", report.AddedPorts, report.ChangedPorts)
+	}
+	if report.SuppressedCount != 2 || len(report.SuppressedChanges) != 2 {
+		t.Fatalf("Expected 2 suppressed changes, got %d: %v", report.SuppressedCount, report.SuppressedChanges)
+	}
+	rules := map[string]bool{}
+	for _, sc := range report.SuppressedChanges {
+		rules[sc.Rule] = true
+	}
+	if !rules["status"] || !rules["server-ports"] {
+		t.Errorf("Expected both the request and the server rule to suppress a change, got %v", report.SuppressedChanges)
+	}
+	if resp.GetRisk().Score != 0 {
+		t.Errorf("Expected zero risk, got %v", resp.GetRisk().Score)
+	}
+
+	// Downgrading keeps the change but caps its severity
+	downgraded, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{
+		SnapshotIdA: idA,
+		SnapshotIdB: idB,
+		Rules: []*proto.SuppressionRule{{
+			Name:     "status-low",
+			Field:    "status",
+			Action:   proto.RuleAction_RULE_ACTION_DOWNGRADE,
+			Severity: proto.Severity_SEVERITY_LOW,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+	if ports := downgraded.GetReport().ChangedPorts; len(ports) != 1 || ports[0].Port != 80 {
+		t.Errorf("Expected port 80 to stay changed, got %v", ports)
+	}
+	if downgraded.GetReport().SuppressedCount != 1 {
+		t.Errorf("Expected only the server rule to suppress, got %d", downgraded.GetReport().SuppressedCount)
+	}
+	var capped int
+	for _, change := range downgraded.GetRisk().Changes {
+		if change.DowngradedBy == "status-low" {
+			capped++
+			if change.Severity != proto.Severity_SEVERITY_LOW {
+				t.Errorf("Expected the status change capped at low, got %v", change.Severity)
+			}
+		}
+	}
+	if capped != 1 {
+		t.Errorf("Expected 1 downgraded change, got %v", downgraded.GetRisk().Changes)
+	}
+
+	for _, rule := range []*proto.SuppressionRule{
+		{Host: "not-a-host", Action: proto.RuleAction_RULE_ACTION_SUPPRESS},
+		{Field: "status", Action: proto.RuleAction_RULE_ACTION_DOWNGRADE},
+		{Field: "status", Action: proto.RuleAction_RULE_ACTION_DOWNGRADE, Severity: proto.Severity_SEVERITY_CRITICAL},
+	} {
+		_, err = server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{
+			SnapshotIdA: idA,
+			SnapshotIdB: idB,
+			Rules:       []*proto.SuppressionRule{rule},
+		})
+		if err == nil {
+			t.Errorf("Expected error for invalid rule %v", rule)
+		}
+	}
+}
+
+func TestCompareSnapshots_ContentHash(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	idA := upload(t, server, "host_127.0.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`)
+	idB := upload(t, server, "host_127.0.0.1_2025-01-02T00-00-00Z.json", `{"services": [{"port": 22, "protocol": "SSH"}]}`)
+	idC := upload(t, server, "host_10.0.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`)
+	idD := upload(t, server, "host_10.0.0.1_2025-01-02T00-00-00Z.json", `{"services": [{"port": 22, "protocol": "SSH"}]}`)
+
+	first, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{SnapshotIdA: idA, SnapshotIdB: idB})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+	second, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{SnapshotIdA: idC, SnapshotIdB: idD})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+	if first.Report.ContentHash == "" || first.Report.ContentHash != second.Report.ContentHash {
+		t.Errorf("Expected matching content hashes, got %q and %q", first.Report.ContentHash, second.Report.ContentHash)
+	}
+}
+
+func TestCompareSnapshots_RenderedFormat(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	idA := upload(t, server, "host_127.0.0.1_2025-01-01T00-00-00Z.json", `{"services": []}`)
+	idB := upload(t, server, "host_127.0.0.1_2025-01-02T00-00-00Z.json", `{"services": [{"port": 22, "protocol": "SSH"}]}`)
+
+	resp, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{
+		SnapshotIdA: idA,
+		SnapshotIdB: idB,
+		Format:      proto.ReportFormat_REPORT_FORMAT_MARKDOWN,
+	})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+	rendered := resp.GetRendered()
+	if rendered == nil {
+		t.Fatal("Expected a rendered report")
+	}
+	if rendered.ContentType != "text/markdown; charset=utf-8" {
+		t.Errorf("Unexpected content type %q", rendered.ContentType)
+	}
+	if !strings.Contains(string(rendered.Content), "## Host diff: 127.0.0.1") {
+		t.Errorf("Unexpected Markdown:\n%s", rendered.Content)
+	}
+
+	plain, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{SnapshotIdA: idA, SnapshotIdB: idB})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+	if plain.Rendered != nil {
+		t.Error("Expected no rendered report without a format")
+	}
+}
+
+func TestCompareSnapshots_MovedService(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	idA := upload(t, server, "host_127.0.0.1_2025-01-01T00-00-00Z.json",
+		`{"services": [{"port": 8443, "protocol": "HTTPS", "tls": {"cert_fingerprint_sha256": "ab12"}}]}`)
+	idB := upload(t, server, "host_127.0.0.1_2025-01-02T00-00-00Z.json",
+		`{"services": [{"port": 443, "protocol": "HTTPS", "tls": {"cert_fingerprint_sha256": "ab12"}}]}`)
+
+	resp, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{SnapshotIdA: idA, SnapshotIdB: idB})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+	report := resp.GetReport()
+	if len(report.AddedPorts) != 0 || len(report.RemovedPorts) != 0 {
+		t.Errorf("Expected no added or removed ports, got %v and %v", report.AddedPorts, report.RemovedPorts)
+	}
+	if len(report.MovedServices) != 1 || report.MovedServices[0].OldPort != 8443 || report.MovedServices[0].NewPort != 443 {
+		t.Errorf("Expected a move from 8443 to 443, got %v", report.MovedServices)
+	}
+}
+
+func TestCompareSnapshots_ProtoMapping(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	idA := upload(t, server, "host_127.0.0.1_2025-01-01T00-00-00Z.json", `{
+		"os": {"product": "ubuntu", "version": "20.04"},
+		"services": [
+			{"port": 80, "protocol": "HTTP", "status": 200, "software": {"product": "nginx", "version": "1.24.0"}},
+			{"port": 21, "protocol": "FTP", "software": {"product": "vsftpd", "version": "3.0.3"}}
+		]}`)
+	idB := upload(t, server, "host_127.0.0.1_2025-01-02T00-00-00Z.json", `{
+		"os": {"product": "ubuntu", "version": "22.04"},
+		"services": [
+			{"port": 80, "protocol": "HTTP", "status": 301, "software": {"product": "nginx", "version": "1.25.0"}},
+			{"port": 3306, "protocol": "MYSQL", "vulnerabilities": ["CVE-2024-0001"]}
+		]}`)
+
+	resp, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{SnapshotIdA: idA, SnapshotIdB: idB})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+	report := resp.GetReport()
+
+	if os := report.GetOsChanges(); os.GetOldname() != "ubuntu 20.04" || os.GetNewname() != "ubuntu 22.04" {
+		t.Errorf("Unexpected OS change: %v", os)
+	}
+
+	if len(report.AddedPorts) != 1 || report.AddedPorts[0].NewState != "open" || report.AddedPorts[0].NewService != "MYSQL" {
+		t.Errorf("Unexpected added ports: %v", report.AddedPorts)
+	}
+	if len(report.RemovedPorts) != 1 || report.RemovedPorts[0].OldSoftware.GetProduct() != "vsftpd" ||
+		report.RemovedPorts[0].NewState != "closed" {
+		t.Errorf("Unexpected removed ports: %v", report.RemovedPorts)
+	}
+	if len(report.AddedServices) != 1 || len(report.RemovedServices) != 1 || report.RemovedServices[0].OldVersion != "3.0.3" {
+		t.Errorf("Unexpected added/removed services: %v / %v", report.AddedServices, report.RemovedServices)
+	}
+
+	if len(report.ChangedServices) != 1 {
+		t.Fatalf("Expected 1 changed service, got %d", len(report.ChangedServices))
+	}
+	changed := report.ChangedServices[0]
+	if changed.Name != "nginx" || changed.OldVersion != "1.24.0" || changed.NewVersion != "1.25.0" || changed.Port != 80 {
+		t.Errorf("Unexpected changed service: %v", changed)
+	}
+	attributes := make(map[string]*proto.AttributeChange)
+	for _, a := range changed.Attributes {
+		attributes[a.Field] = a
+	}
+	if status := attributes["status"]; status.GetOldValue() != "200" || status.GetNewValue() != "301" {
+		t.Errorf("Unexpected status attribute: %v", status)
+	}
+	if len(report.ChangedPorts) != 1 || report.ChangedPorts[0].OldService != "nginx 1.24.0" || report.ChangedPorts[0].NewService != "nginx 1.25.0" {
+		t.Errorf("Unexpected changed ports: %v", report.ChangedPorts)
+	}
+
+	if len(report.AddedCves) != 1 || report.AddedCves[0].Port != 3306 || report.AddedCves[0].Protocol != "MYSQL" {
+		t.Errorf("Expected CVE location on proto, got %v", report.AddedCves)
+	}
+}
+
+func TestUploadSnapshot_IPv6(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	resp, err := server.UploadSnapshot(ctx, &proto.UploadSnapshotRequest{
+		Filename:    "host_2001-DB8-0-0-0-0-0-1_2025-01-01T00-00-00Z.json",
+		FileContent: []byte(`{"services": [{"port": 22, "protocol": "SSH"}]}`),
+	})
+	if err != nil {
+		t.Fatalf("UploadSnapshot failed: %v", err)
+	}
+	if resp.IpAddress != "2001:db8::1" {
+		t.Errorf("Expected canonical IP 2001:db8::1, got %s", resp.IpAddress)
+	}
+	upload(t, server, "host_2001:db8::1_2025-01-02T00-00-00Z.json",
+		`{"services": [{"port": 22, "protocol": "SSH"}, {"port": 443, "protocol": "HTTPS"}]}`)
+
+	for _, form := range []string{"2001:db8::1", "2001:0db8:0000:0000:0000:0000:0000:0001", "2001:DB8::1"} {
+		history, err := server.GetHostHistory(ctx, &proto.GetHostHistoryRequest{IpAddress: form})
+		if err != nil {
+			t.Fatalf("GetHostHistory failed: %v", err)
+		}
+		if len(history.Snapshots) != 2 {
+			t.Errorf("GetHostHistory(%s): expected 2 snapshots, got %d", form, len(history.Snapshots))
+		}
+	}
+
+	history, err := server.GetHostHistory(ctx, &proto.GetHostHistoryRequest{IpRange: "2001:db8::/32"})
+	if err != nil || len(history.Snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots in 2001:db8::/32, got %v, %v", history, err)
+	}
+
+	compare, err := server.CompareSnapshots(ctx, &proto.CompareSnapshotsRequest{
+		SnapshotIdA: history.Snapshots[1].Id,
+		SnapshotIdB: history.Snapshots[0].Id,
+	})
+	if err != nil {
+		t.Fatalf("CompareSnapshots failed: %v", err)
+	}
+	if len(compare.GetReport().GetAddedPorts()) != 1 {
+		t.Errorf("Expected 1 added port, got %v", compare.GetReport())
+	}
+}
+
+func TestUploadSnapshot_SchemaStrictness(t *testing.T) {
+	// One error (port out of range) and one warning (service_count mismatch)
+	const content = `{"services": [{"port": 70000, "protocol": "HTTP"}], "service_count": 2}`
+
+	tests := []struct {
+		level        validation.Strictness
+		wantErr      bool
+		wantWarnings []proto.UploadWarningCode
+	}{
+		{validation.StrictnessLenient, false, []proto.UploadWarningCode{
+			proto.UploadWarningCode_UPLOAD_WARNING_CODE_SCHEMA_ERROR,
+			proto.UploadWarningCode_UPLOAD_WARNING_CODE_SCHEMA_WARNING,
+		}},
+		{validation.StrictnessStandard, true, nil},
+		{validation.StrictnessStrict, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.level), func(t *testing.T) {
+			server := newTestServer(t, WithSchemaStrictness(tt.level))
+			resp, err := server.UploadSnapshot(context.Background(), &proto.UploadSnapshotRequest{
+				Filename:    "host_10.0.0.1_2025-01-01T00-00-00Z.json",
+				FileContent: []byte(content),
+			})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "/services/0/port: port 70000 is out of range") {
+					t.Fatalf("Expected a schema error naming the port, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UploadSnapshot failed: %v", err)
+			}
+			if len(resp.Warnings) != len(tt.wantWarnings) {
+				t.Fatalf("Expected %d warnings, got %v", len(tt.wantWarnings), resp.Warnings)
+			}
+			for i, code := range tt.wantWarnings {
+				if resp.Warnings[i].Code != code {
+					t.Errorf("Warning %d: expected %v, got %v", i, code, resp.Warnings[i])
+				}
+			}
+			if resp.Warnings[0].Pointer != "/services/0/port" {
+				t.Errorf("Expected pointer /services/0/port, got %q", resp.Warnings[0].Pointer)
+			}
+		})
+	}
+
+	// Standard only rejects errors
+	server := newTestServer(t, WithSchemaStrictness(validation.StrictnessStandard))
+	resp, err := server.UploadSnapshot(context.Background(), &proto.UploadSnapshotRequest{
+		Filename:    "host_10.0.0.1_2025-01-01T00-00-00Z.json",
+		FileContent: []byte(`{"services": [{"port": 80, "protocol": "HTTP"}], "service_count": 2}`),
+	})
+	if err != nil {
+		t.Fatalf("UploadSnapshot failed: %v", err)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0].Pointer != "/service_count" {
+		t.Errorf("Expected a service_count warning, got %v", resp.Warnings)
+	}
+}
+
+func TestUploadSnapshot_ConflictPolicy(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	const filename = "host_10.0.0.1_2025-01-01T00-00-00Z.json"
+	first := upload(t, server, filename, `{"services": [{"port": 22}]}`)
+
+	kept, err := server.UploadSnapshot(ctx, &proto.UploadSnapshotRequest{
+		Filename:       filename,
+		FileContent:    []byte(`{"services": [{"port": 80}]}`),
+		ConflictPolicy: proto.ConflictPolicy_CONFLICT_POLICY_KEEP_BOTH,
+	})
+	if err != nil {
+		t.Fatalf("UploadSnapshot(keep both) failed: %v", err)
+	}
+	if kept.Outcome != proto.UploadOutcome_UPLOAD_OUTCOME_REVISION || kept.Revision != 1 {
+		t.Errorf("Expected revision 1, got %v", kept)
+	}
+	history, err := server.GetHostHistory(ctx, &proto.GetHostHistoryRequest{IpAddress: "10.0.0.1"})
+	if err != nil || len(history.Snapshots) != 2 || history.Snapshots[0].Revision != 1 {
+		t.Errorf("Expected both revisions in history, got %v, %v", history, err)
+	}
+
+	replaced, err := server.UploadSnapshot(ctx, &proto.UploadSnapshotRequest{
+		Filename:       filename,
+		FileContent:    []byte(`{"services": [{"port": 443}]}`),
+		ConflictPolicy: proto.ConflictPolicy_CONFLICT_POLICY_REPLACE,
+	})
+	if err != nil {
+		t.Fatalf("UploadSnapshot(replace) failed: %v", err)
+	}
+	if replaced.Outcome != proto.UploadOutcome_UPLOAD_OUTCOME_REPLACED || len(replaced.ReplacedIds) != 2 || replaced.ReplacedIds[0] != first {
+		t.Errorf("Expected both revisions to be replaced, got %v", replaced)
+	}
+	history, err = server.GetHostHistory(ctx, &proto.GetHostHistoryRequest{IpAddress: "10.0.0.1"})
+	if err != nil || len(history.Snapshots) != 1 || history.Snapshots[0].Id != replaced.Id {
+		t.Errorf("Expected only the replacement in history, got %v, %v", history, err)
+	}
+
+	// Replaced snapshots are soft-deleted and can be restored once the slot is free
+	if _, err := server.DeleteSnapshot(ctx, &proto.DeleteSnapshotRequest{Id: replaced.Id, Reason: "test"}); err != nil {
+		t.Fatalf("DeleteSnapshot failed: %v", err)
+	}
+	if _, err := server.RestoreSnapshot(ctx, &proto.RestoreSnapshotRequest{Id: first}); err != nil {
+		t.Errorf("RestoreSnapshot failed: %v", err)
+	}
+}
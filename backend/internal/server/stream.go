@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"github.com/justicecaban/host-diff-tool/backend/internal/validation"
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+// UploadSnapshotStream handles the UploadSnapshotStream RPC. The file is
+// reassembled in memory while its size and checksum are checked chunk by
+// chunk, and only stored once the whole stream has been received and
+// verified, so a failed or cancelled upload never leaves a partial snapshot.
+func (s *Server) UploadSnapshotStream(stream grpc.ClientStreamingServer[proto.UploadSnapshotChunk, proto.UploadSnapshotResponse]) error {
+	resp, err := s.receiveSnapshot(stream)
+	if err != nil {
+		log.Printf("UploadSnapshotStream error: %v", err)
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+func (s *Server) receiveSnapshot(stream grpc.ClientStreamingServer[proto.UploadSnapshotChunk, proto.UploadSnapshotResponse]) (*proto.UploadSnapshotResponse, error) {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return nil, errors.New("empty upload stream: expected a header frame")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive header: %w", err)
+	}
+	header := first.GetHeader()
+	if header == nil {
+		return nil, errors.New("first frame of an upload must be a header")
+	}
+
+	// Reject a bad filename before receiving the data, unless the content
+	// is what names the host
+	if s.metadataSource != MetadataFromContent {
+		if _, err := validation.ParseFilename(header.GetFilename()); err != nil {
+			return nil, fmt.Errorf("invalid filename: %w", err)
+		}
+	}
+
+	expectedSize := header.GetSize()
+	if expectedSize < 0 {
+		return nil, fmt.Errorf("invalid size %d", expectedSize)
+	}
+	if expectedSize > s.maxUploadSize {
+		return nil, fmt.Errorf("snapshot size %d exceeds the %d byte limit", expectedSize, s.maxUploadSize)
+	}
+	limit := s.maxUploadSize
+	if expectedSize > 0 {
+		limit = expectedSize
+	}
+
+	var expectedSum []byte
+	if header.GetSha256() != "" {
+		expectedSum, err = hex.DecodeString(strings.TrimSpace(header.GetSha256()))
+		if err != nil || len(expectedSum) != sha256.Size {
+			return nil, fmt.Errorf("invalid sha256 %q: expected %d hex-encoded bytes", header.GetSha256(), sha256.Size)
+		}
+	}
+
+	var content bytes.Buffer
+	hash := sha256.New()
+	for {
+		frame, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive chunk: %w", err)
+		}
+		if frame.GetHeader() != nil {
+			return nil, errors.New("unexpected second header frame")
+		}
+
+		chunk := frame.GetData()
+		if int64(content.Len()+len(chunk)) > limit {
+			if expectedSize > 0 {
+				return nil, fmt.Errorf("upload is larger than the declared size of %d bytes", expectedSize)
+			}
+			return nil, fmt.Errorf("upload exceeds the %d byte limit", s.maxUploadSize)
+		}
+		content.Write(chunk)
+		hash.Write(chunk)
+	}
+
+	if expectedSize > 0 && int64(content.Len()) != expectedSize {
+		return nil, fmt.Errorf("upload ended after %d of %d declared bytes", content.Len(), expectedSize)
+	}
+	if expectedSum != nil && !bytes.Equal(hash.Sum(nil), expectedSum) {
+		return nil, fmt.Errorf("checksum mismatch: expected %x, got %x", expectedSum, hash.Sum(nil))
+	}
+
+	return s.storeSnapshot(stream.Context(), header.GetFilename(), content.Bytes(), header.GetConflictPolicy())
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/justicecaban/host-diff-tool/proto"
+)
+
+// fakeUploadStream replays frames to UploadSnapshotStream.
+type fakeUploadStream struct {
+	grpc.ServerStream
+	frames []*proto.UploadSnapshotChunk
+	resp   *proto.UploadSnapshotResponse
+}
+
+func (f *fakeUploadStream) Recv() (*proto.UploadSnapshotChunk, error) {
+	if len(f.frames) == 0 {
+		return nil, io.EOF
+	}
+	frame := f.frames[0]
+	f.frames = f.frames[1:]
+	return frame, nil
+}
+
+func (f *fakeUploadStream) SendAndClose(resp *proto.UploadSnapshotResponse) error {
+	f.resp = resp
+	return nil
+}
+
+func (f *fakeUploadStream) Context() context.Context {
+	return context.Background()
+}
+
+// uploadFrames splits content into a header frame and data frames of chunkSize bytes.
+func uploadFrames(header *proto.UploadSnapshotHeader, content string, chunkSize int) []*proto.UploadSnapshotChunk {
+	frames := []*proto.UploadSnapshotChunk{{Frame: &proto.UploadSnapshotChunk_Header{Header: header}}}
+	for start := 0; start < len(content); start += chunkSize {
+		end := min(start+chunkSize, len(content))
+		frames = append(frames, &proto.UploadSnapshotChunk{
+			Frame: &proto.UploadSnapshotChunk_Data{Data: []byte(content[start:end])},
+		})
+	}
+	return frames
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadSnapshotStream(t *testing.T) {
+	server := newTestServer(t)
+	content := `{"services": [{"port": 22, "protocol": "SSH"}, {"port": 80, "protocol": "HTTP"}]}`
+
+	stream := &fakeUploadStream{frames: uploadFrames(&proto.UploadSnapshotHeader{
+		Filename: "host_127.0.0.1_2025-01-01T00-00-00Z.json",
+		Size:     int64(len(content)),
+		Sha256:   strings.ToUpper(checksum(content)),
+	}, content, 7)}
+
+	if err := server.UploadSnapshotStream(stream); err != nil {
+		t.Fatalf("UploadSnapshotStream failed: %v", err)
+	}
+	if stream.resp == nil || stream.resp.Id == "" || stream.resp.IpAddress != "127.0.0.1" {
+		t.Fatalf("Unexpected response: %v", stream.resp)
+	}
+
+	snap, err := server.db.GetSnapshotByID(stream.resp.Id)
+	if err != nil || snap == nil {
+		t.Fatalf("Failed to read back snapshot: %v", err)
+	}
+	if string(snap.Data) != content {
+		t.Errorf("Stored content differs from upload:\n%s", snap.Data)
+	}
+}
+
+func TestUploadSnapshotStream_Errors(t *testing.T) {
+	content := `{"services": []}`
+	filename := "host_127.0.0.1_2025-01-01T00-00-00Z.json"
+	dataFrame := &proto.UploadSnapshotChunk{Frame: &proto.UploadSnapshotChunk_Data{Data: []byte(content)}}
+
+	tests := map[string][]*proto.UploadSnapshotChunk{
+		"empty stream":   nil,
+		"missing header": {dataFrame},
+		"bad filename":   uploadFrames(&proto.UploadSnapshotHeader{Filename: "snapshot.json"}, content, 4),
+		"second header": append(uploadFrames(&proto.UploadSnapshotHeader{Filename: filename}, content, 4),
+			&proto.UploadSnapshotChunk{Frame: &proto.UploadSnapshotChunk_Header{Header: &proto.UploadSnapshotHeader{}}}),
+		"declared size over limit": uploadFrames(&proto.UploadSnapshotHeader{Filename: filename, Size: 1 << 20}, content, 4),
+		"longer than declared":     uploadFrames(&proto.UploadSnapshotHeader{Filename: filename, Size: 4}, content, 4),
+		"shorter than declared":    uploadFrames(&proto.UploadSnapshotHeader{Filename: filename, Size: 64}, content, 4),
+		"checksum mismatch":        uploadFrames(&proto.UploadSnapshotHeader{Filename: filename, Sha256: checksum("other")}, content, 4),
+		"malformed checksum":       uploadFrames(&proto.UploadSnapshotHeader{Filename: filename, Sha256: "abc"}, content, 4),
+		"invalid JSON":             uploadFrames(&proto.UploadSnapshotHeader{Filename: filename}, `{"services": [`, 4),
+	}
+
+	for name, frames := range tests {
+		server := newTestServer(t, WithMaxUploadSize(64))
+		stream := &fakeUploadStream{frames: frames}
+		if err := server.UploadSnapshotStream(stream); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+
+		history, err := server.db.GetSnapshotsByIP("127.0.0.1")
+		if err != nil {
+			t.Fatalf("%s: GetSnapshotsByIP failed: %v", name, err)
+		}
+		if len(history) != 0 {
+			t.Errorf("%s: expected nothing stored after a failed upload, got %d snapshots", name, len(history))
+		}
+	}
+}
+
+func TestUploadSnapshotStream_SizeLimit(t *testing.T) {
+	server := newTestServer(t, WithMaxUploadSize(32))
+	content := `{"services": [{"port": 22, "protocol": "SSH"}]}`
+
+	stream := &fakeUploadStream{frames: uploadFrames(&proto.UploadSnapshotHeader{
+		Filename: "host_127.0.0.1_2025-01-01T00-00-00Z.json",
+	}, content, 8)}
+	err := server.UploadSnapshotStream(stream)
+	if err == nil || !strings.Contains(err.Error(), "32 byte limit") {
+		t.Errorf("Expected size limit error, got %v", err)
+	}
+}
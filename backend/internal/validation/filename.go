@@ -3,12 +3,15 @@ package validation
 
 import (
 	"fmt"
+	"net/netip"
 	"regexp"
 	"strings"
 )
 
-// filenamePattern matches the expected filename format: host_<ip>_<timestamp>.json
-var filenamePattern = regexp.MustCompile(`host_([0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3})_([0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}-[0-9]{2}-[0-9]{2}Z)\.json`)
+// filenamePattern matches the expected filename format: host_<ip>_<timestamp>.json.
+// IPv6 addresses may write their colons as hyphens, since colons aren't
+// allowed in file names on every filesystem.
+var filenamePattern = regexp.MustCompile(`host_([0-9A-Fa-f.:-]+)_([0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}-[0-9]{2}-[0-9]{2}Z)\.json`)
 
 // ParsedFilename contains the extracted metadata from a snapshot filename.
 type ParsedFilename struct {
@@ -17,11 +20,13 @@ type ParsedFilename struct {
 }
 
 // ParseFilename extracts IP address and timestamp from a filename like
-// "host_127.0.0.1_2025-10-16T12-00-00Z.json" and validates both components.
+// "host_127.0.0.1_2025-10-16T12-00-00Z.json" or
+// "host_2001-db8--1_2025-10-16T12-00-00Z.json" and validates both components.
+// The IP address is returned in canonical form, e.g. "2001:db8::1".
 //
 // Returns an error if:
 //   - The filename doesn't match the expected format
-//   - The IP address octets are outside the 0-255 range
+//   - The IP address is not a valid IPv4 or IPv6 address
 //   - The timestamp components are invalid (e.g., month > 12)
 func ParseFilename(filename string) (*ParsedFilename, error) {
 	matches := filenamePattern.FindStringSubmatch(filename)
@@ -29,11 +34,11 @@ func ParseFilename(filename string) (*ParsedFilename, error) {
 		return nil, fmt.Errorf("filename does not match expected format 'host_<ip>_<timestamp>.json': %s", filename)
 	}
 
-	ipAddress := matches[1]
 	timestampStr := matches[2]
 
-	// Validate IP address
-	if err := validateIPAddress(ipAddress); err != nil {
+	// Validate IP address, decoding hyphens back into colons
+	ipAddress, err := CanonicalIP(strings.ReplaceAll(matches[1], "-", ":"))
+	if err != nil {
 		return nil, err
 	}
 
@@ -49,20 +54,18 @@ func ParseFilename(filename string) (*ParsedFilename, error) {
 	}, nil
 }
 
-// validateIPAddress checks that each octet is in the valid range 0-255.
-func validateIPAddress(ip string) error {
-	var octets [4]int
-	if _, err := fmt.Sscanf(ip, "%d.%d.%d.%d", &octets[0], &octets[1], &octets[2], &octets[3]); err != nil {
-		return fmt.Errorf("invalid IP address format: %s", ip)
+// CanonicalIP validates an IPv4 or IPv6 address and returns its canonical
+// form: IPv6 in lower case with the longest run of zeros compressed, and
+// IPv4-mapped IPv6 addresses as plain IPv4.
+func CanonicalIP(ip string) (string, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP address format: %s", ip)
 	}
-
-	for i, octet := range octets {
-		if octet < 0 || octet > 255 {
-			return fmt.Errorf("invalid IP address octet [%d]: %d (must be 0-255)", i, octet)
-		}
+	if addr.Zone() != "" {
+		return "", fmt.Errorf("invalid IP address %s: zones are not allowed", ip)
 	}
-
-	return nil
+	return addr.Unmap().String(), nil
 }
 
 // validateAndNormalizeTimestamp validates date/time components and converts
@@ -85,6 +85,37 @@ func TestParseFilename(t *testing.T) {
 			wantTimestamp: "2025-10-16T12:00:00Z",
 			wantErr:       false,
 		},
+		{
+			name:          "IPv6 with hyphens for colons",
+			filename:      "host_2001-db8--1_2025-10-16T12-00-00Z.json",
+			wantIP:        "2001:db8::1",
+			wantTimestamp: "2025-10-16T12:00:00Z",
+			wantErr:       false,
+		},
+		{
+			name:          "IPv6 with colons is canonicalized",
+			filename:      "host_2001:DB8:0:0:0:0:0:1_2025-10-16T12-00-00Z.json",
+			wantIP:        "2001:db8::1",
+			wantTimestamp: "2025-10-16T12:00:00Z",
+			wantErr:       false,
+		},
+		{
+			name:          "IPv4-mapped IPv6 becomes IPv4",
+			filename:      "host_--ffff-10.0.0.1_2025-10-16T12-00-00Z.json",
+			wantIP:        "10.0.0.1",
+			wantTimestamp: "2025-10-16T12:00:00Z",
+			wantErr:       false,
+		},
+		{
+			name:     "invalid IPv6 - two compressions",
+			filename: "host_2001--db8--1_2025-10-16T12-00-00Z.json",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid IPv6 - too many groups",
+			filename: "host_1-2-3-4-5-6-7-8-9_2025-10-16T12-00-00Z.json",
+			wantErr:  true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -108,25 +139,36 @@ func TestParseFilename(t *testing.T) {
 	}
 }
 
-func TestValidateIPAddress(t *testing.T) {
+func TestCanonicalIP(t *testing.T) {
 	tests := []struct {
 		name    string
 		ip      string
+		want    string
 		wantErr bool
 	}{
-		{"valid IP", "192.168.1.1", false},
-		{"valid IP all zeros", "0.0.0.0", false},
-		{"valid IP all max", "255.255.255.255", false},
-		{"invalid octet 256", "256.0.0.1", true},
-		{"invalid octet 999", "999.999.999.999", true},
-		{"invalid format", "192.168.1", true},
+		{"valid IP", "192.168.1.1", "192.168.1.1", false},
+		{"valid IP all zeros", "0.0.0.0", "0.0.0.0", false},
+		{"valid IP all max", "255.255.255.255", "255.255.255.255", false},
+		{"invalid octet 256", "256.0.0.1", "", true},
+		{"invalid octet 999", "999.999.999.999", "", true},
+		{"invalid format", "192.168.1", "", true},
+		{"leading zeros", "192.168.01.1", "", true},
+		{"IPv6 compressed", "2001:db8::1", "2001:db8::1", false},
+		{"IPv6 expanded", "2001:0DB8:0000:0000:0000:0000:0000:0001", "2001:db8::1", false},
+		{"IPv6 loopback", "0:0:0:0:0:0:0:1", "::1", false},
+		{"IPv4-mapped", "::ffff:192.168.1.1", "192.168.1.1", false},
+		{"IPv6 zone", "fe80::1%eth0", "", true},
+		{"IPv6 invalid group", "2001:db8::g", "", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateIPAddress(tt.ip)
+			got, err := CanonicalIP(tt.ip)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("validateIPAddress() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("CanonicalIP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalIP() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,279 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Severity ranks a problem found in a snapshot.
+type Severity string
+
+const (
+	// SeverityError marks content that breaks the snapshot schema and would
+	// make comparisons wrong, e.g. a service without a port.
+	SeverityError Severity = "error"
+	// SeverityWarning marks content that is suspicious but usable, e.g. a
+	// service_count that doesn't match the services listed.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a snapshot. Pointer is the JSON pointer
+// (RFC 6901) of the value concerned, e.g. "/services/2/port", and is empty
+// for the document itself.
+type Issue struct {
+	Severity Severity
+	Pointer  string
+	Message  string
+}
+
+// String formats the issue as "<pointer>: <message>".
+func (i Issue) String() string {
+	pointer := i.Pointer
+	if pointer == "" {
+		pointer = "(root)"
+	}
+	return pointer + ": " + i.Message
+}
+
+// Strictness decides which issues block ingestion of a snapshot.
+type Strictness string
+
+const (
+	// StrictnessLenient stores every snapshot that is valid JSON and only
+	// reports issues. This is the default.
+	StrictnessLenient Strictness = "lenient"
+	// StrictnessStandard rejects snapshots with errors.
+	StrictnessStandard Strictness = "standard"
+	// StrictnessStrict rejects snapshots with errors or warnings.
+	StrictnessStrict Strictness = "strict"
+)
+
+// ParseStrictness parses a strictness level name.
+func ParseStrictness(name string) (Strictness, error) {
+	switch level := Strictness(strings.ToLower(strings.TrimSpace(name))); level {
+	case StrictnessLenient, StrictnessStandard, StrictnessStrict:
+		return level, nil
+	default:
+		return "", fmt.Errorf("unknown strictness %q: expected lenient, standard or strict", name)
+	}
+}
+
+// Blocks reports whether an issue prevents a snapshot from being stored.
+func (s Strictness) Blocks(issue Issue) bool {
+	switch s {
+	case StrictnessStrict:
+		return true
+	case StrictnessStandard:
+		return issue.Severity == SeverityError
+	default:
+		return false
+	}
+}
+
+// cvePattern matches CVE IDs such as "CVE-2023-12345".
+var cvePattern = regexp.MustCompile(`^(?i)CVE-[0-9]{4}-[0-9]{4,}$`)
+
+// ValidateSnapshot checks snapshot content against the HostSnapshot schema
+// and returns the issues found, in document order. It returns an error only
+// if the content is not valid JSON.
+//
+// The "ip" and "timestamp" fields are not checked here: they are read, and
+// reported on, when an upload's metadata is reconciled with its filename.
+func ValidateSnapshot(content []byte) ([]Issue, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON content: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON content: unexpected data after the top-level value")
+	}
+
+	var c checker
+	c.snapshot(doc)
+	return c.issues, nil
+}
+
+// checker collects the issues found while walking a snapshot.
+type checker struct {
+	issues []Issue
+}
+
+func (c *checker) errorf(pointer, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{Severity: SeverityError, Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) warnf(pointer, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{Severity: SeverityWarning, Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) snapshot(doc interface{}) {
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		c.errorf("", "snapshot must be a JSON object")
+		return
+	}
+
+	if osInfo, ok := root["os"]; ok && osInfo != nil {
+		c.stringFields("/os", osInfo, "vendor", "product", "version")
+	}
+
+	services, hasServices := root["services"].([]interface{})
+	switch {
+	case root["services"] == nil:
+		c.warnf("/services", "services is missing")
+	case !hasServices:
+		c.errorf("/services", "services must be an array")
+	default:
+		c.services(services)
+	}
+
+	if raw, ok := root["service_count"]; ok {
+		count, isInt := integer(raw)
+		switch {
+		case !isInt || count < 0:
+			c.errorf("/service_count", "service_count must be a non-negative integer")
+		case hasServices && count != int64(len(services)):
+			c.warnf("/service_count", "service_count is %d but %d services are listed", count, len(services))
+		}
+	}
+}
+
+func (c *checker) services(services []interface{}) {
+	// Services are compared by port and protocol, so each pair must be unique
+	seen := make(map[string]int)
+	for i, raw := range services {
+		pointer := fmt.Sprintf("/services/%d", i)
+		svc, ok := raw.(map[string]interface{})
+		if !ok {
+			c.errorf(pointer, "service must be an object")
+			continue
+		}
+
+		port, portOK := c.port(pointer, svc)
+		protocol, protocolOK := c.protocol(pointer, svc)
+		if portOK && protocolOK {
+			key := fmt.Sprintf("%d-%s", port, protocol)
+			if first, dup := seen[key]; dup {
+				c.errorf(pointer, "duplicate service %d/%s, first listed at /services/%d", port, protocol, first)
+			} else {
+				seen[key] = i
+			}
+		}
+
+		if raw, ok := svc["status"]; ok && raw != nil {
+			if status, isInt := integer(raw); !isInt || status < 0 {
+				c.errorf(pointer+"/status", "status must be a non-negative integer")
+			}
+		}
+		if software, ok := svc["software"]; ok && software != nil {
+			c.stringFields(pointer+"/software", software, "vendor", "product", "version")
+		}
+		if tls, ok := svc["tls"]; ok && tls != nil {
+			if c.stringFields(pointer+"/tls", tls, "version", "cipher", "cert_fingerprint_sha256") {
+				if cert, ok := tls.(map[string]interface{})["certificate"]; ok && cert != nil {
+					if _, isObject := cert.(map[string]interface{}); !isObject {
+						c.errorf(pointer+"/tls/certificate", "certificate must be an object")
+					}
+				}
+			}
+		}
+		if vulns, ok := svc["vulnerabilities"]; ok && vulns != nil {
+			c.vulnerabilities(pointer+"/vulnerabilities", vulns)
+		}
+	}
+}
+
+// port checks a service's port and returns it if it is valid.
+func (c *checker) port(pointer string, svc map[string]interface{}) (int64, bool) {
+	raw, ok := svc["port"]
+	if !ok || raw == nil {
+		c.errorf(pointer+"/port", "port is required")
+		return 0, false
+	}
+	port, isInt := integer(raw)
+	if !isInt {
+		c.errorf(pointer+"/port", "port must be an integer")
+		return 0, false
+	}
+	if port < 1 || port > 65535 {
+		c.errorf(pointer+"/port", "port %d is out of range 1-65535", port)
+		return 0, false
+	}
+	return port, true
+}
+
+// protocol checks a service's protocol and returns it if it is valid.
+func (c *checker) protocol(pointer string, svc map[string]interface{}) (string, bool) {
+	raw, ok := svc["protocol"]
+	if !ok || raw == nil {
+		c.errorf(pointer+"/protocol", "protocol is required")
+		return "", false
+	}
+	protocol, isString := raw.(string)
+	if !isString {
+		c.errorf(pointer+"/protocol", "protocol must be a string")
+		return "", false
+	}
+	if protocol == "" {
+		c.errorf(pointer+"/protocol", "protocol must not be empty")
+		return "", false
+	}
+	return protocol, true
+}
+
+func (c *checker) vulnerabilities(pointer string, raw interface{}) {
+	vulns, ok := raw.([]interface{})
+	if !ok {
+		c.errorf(pointer, "vulnerabilities must be an array")
+		return
+	}
+	seen := make(map[string]bool)
+	for i, v := range vulns {
+		itemPointer := fmt.Sprintf("%s/%d", pointer, i)
+		cve, isString := v.(string)
+		switch {
+		case !isString:
+			c.errorf(itemPointer, "CVE ID must be a string")
+		case !cvePattern.MatchString(cve):
+			c.warnf(itemPointer, "%q is not a CVE ID", cve)
+		case seen[strings.ToUpper(cve)]:
+			c.warnf(itemPointer, "%s is listed more than once", cve)
+		default:
+			seen[strings.ToUpper(cve)] = true
+		}
+	}
+}
+
+// stringFields checks that value is an object whose named fields, where
+// present, are strings. It reports whether value is an object.
+func (c *checker) stringFields(pointer string, value interface{}, names ...string) bool {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		c.errorf(pointer, "%s must be an object", pointer[strings.LastIndex(pointer, "/")+1:])
+		return false
+	}
+	for _, name := range names {
+		if raw, ok := obj[name]; ok && raw != nil {
+			if _, isString := raw.(string); !isString {
+				c.errorf(pointer+"/"+name, "%s must be a string", name)
+			}
+		}
+	}
+	return true
+}
+
+// integer returns a decoded JSON number as an integer, if it is one.
+func integer(value interface{}) (int64, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	n, err := number.Int64()
+	return n, err == nil
+}
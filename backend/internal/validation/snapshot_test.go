@@ -0,0 +1,166 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Issue
+	}{
+		{
+			name:    "valid snapshot",
+			content: `{"ip": "10.0.0.1", "os": {"vendor": "canonical"}, "services": [{"port": 22, "protocol": "SSH", "status": 0, "vulnerabilities": ["CVE-2023-38408"]}], "service_count": 1}`,
+		},
+		{
+			name:    "not an object",
+			content: `[1, 2]`,
+			want:    []Issue{{SeverityError, "", "snapshot must be a JSON object"}},
+		},
+		{
+			name:    "services missing",
+			content: `{"ip": "10.0.0.1"}`,
+			want:    []Issue{{SeverityWarning, "/services", "services is missing"}},
+		},
+		{
+			name:    "services not an array",
+			content: `{"services": {}}`,
+			want:    []Issue{{SeverityError, "/services", "services must be an array"}},
+		},
+		{
+			name:    "missing port",
+			content: `{"services": [{"protocol": "HTTP"}]}`,
+			want:    []Issue{{SeverityError, "/services/0/port", "port is required"}},
+		},
+		{
+			name:    "port out of range",
+			content: `{"services": [{"port": 80, "protocol": "HTTP"}, {"port": 70000, "protocol": "HTTP"}]}`,
+			want:    []Issue{{SeverityError, "/services/1/port", "port 70000 is out of range 1-65535"}},
+		},
+		{
+			name:    "fractional port",
+			content: `{"services": [{"port": 80.5, "protocol": "HTTP"}]}`,
+			want:    []Issue{{SeverityError, "/services/0/port", "port must be an integer"}},
+		},
+		{
+			name:    "missing protocol",
+			content: `{"services": [{"port": 80}]}`,
+			want:    []Issue{{SeverityError, "/services/0/protocol", "protocol is required"}},
+		},
+		{
+			name:    "negative status",
+			content: `{"services": [{"port": 80, "protocol": "HTTP", "status": -1}]}`,
+			want:    []Issue{{SeverityError, "/services/0/status", "status must be a non-negative integer"}},
+		},
+		{
+			name:    "duplicate port and protocol",
+			content: `{"services": [{"port": 80, "protocol": "HTTP"}, {"port": 80, "protocol": "HTTP"}, {"port": 80, "protocol": "HTTPS"}]}`,
+			want:    []Issue{{SeverityError, "/services/1", "duplicate service 80/HTTP, first listed at /services/0"}},
+		},
+		{
+			name:    "CVE problems",
+			content: `{"services": [{"port": 80, "protocol": "HTTP", "vulnerabilities": ["CVE-2023-1234", 42, "not-a-cve", "cve-2023-1234"]}]}`,
+			want: []Issue{
+				{SeverityError, "/services/0/vulnerabilities/1", "CVE ID must be a string"},
+				{SeverityWarning, "/services/0/vulnerabilities/2", `"not-a-cve" is not a CVE ID`},
+				{SeverityWarning, "/services/0/vulnerabilities/3", "cve-2023-1234 is listed more than once"},
+			},
+		},
+		{
+			name:    "vulnerabilities not an array",
+			content: `{"services": [{"port": 80, "protocol": "HTTP", "vulnerabilities": "CVE-2023-1234"}]}`,
+			want:    []Issue{{SeverityError, "/services/0/vulnerabilities", "vulnerabilities must be an array"}},
+		},
+		{
+			name:    "wrongly typed nested fields",
+			content: `{"os": "linux", "services": [{"port": 443, "protocol": "HTTPS", "software": {"version": 8.5}, "tls": {"cipher": "x", "certificate": "pem"}}]}`,
+			want: []Issue{
+				{SeverityError, "/os", "os must be an object"},
+				{SeverityError, "/services/0/software/version", "version must be a string"},
+				{SeverityError, "/services/0/tls/certificate", "certificate must be an object"},
+			},
+		},
+		{
+			name:    "service_count mismatch",
+			content: `{"services": [{"port": 80, "protocol": "HTTP"}], "service_count": 2}`,
+			want:    []Issue{{SeverityWarning, "/service_count", "service_count is 2 but 1 services are listed"}},
+		},
+		{
+			name:    "negative service_count",
+			content: `{"services": [], "service_count": -1}`,
+			want:    []Issue{{SeverityError, "/service_count", "service_count must be a non-negative integer"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateSnapshot([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("ValidateSnapshot() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateSnapshot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSnapshot_InvalidJSON(t *testing.T) {
+	for _, content := range []string{`{"services": [`, `{} {}`, `{}}`, ``} {
+		if _, err := ValidateSnapshot([]byte(content)); err == nil {
+			t.Errorf("ValidateSnapshot(%q) expected an error", content)
+		}
+	}
+}
+
+func TestValidateSnapshot_SampleSnapshots(t *testing.T) {
+	paths, err := filepath.Glob("../../../assets/host_snapshots/*.json")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("Failed to find sample snapshots: %v", err)
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		issues, err := ValidateSnapshot(content)
+		if err != nil || len(issues) > 0 {
+			t.Errorf("%s: issues %v, error %v", filepath.Base(path), issues, err)
+		}
+	}
+}
+
+func TestStrictness_Blocks(t *testing.T) {
+	problem := Issue{Severity: SeverityError}
+	warning := Issue{Severity: SeverityWarning}
+	tests := []struct {
+		level                 Strictness
+		blockError, blockWarn bool
+	}{
+		{StrictnessLenient, false, false},
+		{StrictnessStandard, true, false},
+		{StrictnessStrict, true, true},
+	}
+	for _, tt := range tests {
+		if got := tt.level.Blocks(problem); got != tt.blockError {
+			t.Errorf("%s: Blocks(error) = %v, want %v", tt.level, got, tt.blockError)
+		}
+		if got := tt.level.Blocks(warning); got != tt.blockWarn {
+			t.Errorf("%s: Blocks(warning) = %v, want %v", tt.level, got, tt.blockWarn)
+		}
+	}
+}
+
+func TestParseStrictness(t *testing.T) {
+	if got, err := ParseStrictness(" Standard "); err != nil || got != StrictnessStandard {
+		t.Errorf("ParseStrictness() = %q, %v, want standard", got, err)
+	}
+	if _, err := ParseStrictness("paranoid"); err == nil {
+		t.Error("ParseStrictness() expected an error for an unknown level")
+	}
+}
@@ -102,6 +102,35 @@ describe('App Component', () => {
     });
   });
 
+  test('follows history pages until the last one', async () => {
+    mockGetHostHistory.mockImplementation((req: GetHostHistoryRequest, metadata: Metadata, callback: (err: RpcError | null, response: GetHostHistoryResponse) => void) => {
+      const res = new GetHostHistoryResponse();
+      const snap = new SnapshotInfo();
+      snap.setIpAddress('127.0.0.1');
+      if (req.getPageToken() === '') {
+        snap.setId('3');
+        snap.setTimestamp('2023-01-03T00:00:00Z');
+        res.setNextPageToken('page-2');
+      } else {
+        expect(req.getPageToken()).toBe('page-2');
+        snap.setId('1');
+        snap.setTimestamp('2023-01-01T00:00:00Z');
+      }
+      res.setSnapshotsList([snap]);
+      callback(null, res);
+    });
+
+    render(<App />);
+    fireEvent.change(screen.getByPlaceholderText(/Enter IP Address/i), { target: { value: '127.0.0.1' } });
+    fireEvent.click(screen.getByRole('button', { name: /Get History/i }));
+
+    await waitFor(() => {
+      expect(mockGetHostHistory).toHaveBeenCalledTimes(2);
+      expect(screen.getByText(/ID: 3, Timestamp: 2023-01-03T00:00:00Z/i)).toBeInTheDocument();
+      expect(screen.getByText(/ID: 1, Timestamp: 2023-01-01T00:00:00Z/i)).toBeInTheDocument();
+    });
+  });
+
   test('selects snapshots and compares them', async () => {
     render(<App />);
     const ipInput = screen.getByPlaceholderText(/Enter IP Address/i);
@@ -70,22 +70,34 @@ function App() {
     }
   };
 
-  const handleGetHistory = () => {
+  // The server returns history in pages, so follow next_page_token until
+  // every snapshot has been fetched
+  const fetchHistoryPage = (pageToken: string, collected: SnapshotInfo[]) => {
     const request = new GetHostHistoryRequest();
     request.setIpAddress(ipAddress);
-    console.log('Getting history for:', ipAddress);
+    request.setPageToken(pageToken);
     client.getHostHistory(request, {}, (err, response) => {
       if (err) {
         setResult(`Error: ${err.message}`);
         setHostHistory([]);
+        return;
+      }
+      const snapshots = [...collected, ...response.getSnapshotsList().map(s => s.toObject())];
+      const nextPageToken = response.getNextPageToken();
+      if (nextPageToken) {
+        fetchHistoryPage(nextPageToken, snapshots);
       } else {
-        const snapshots = response.getSnapshotsList().map(s => s.toObject());
         setHostHistory(snapshots);
         setResult(''); // Clear previous result
       }
     });
   };
 
+  const handleGetHistory = () => {
+    console.log('Getting history for:', ipAddress);
+    fetchHistoryPage('', []);
+  };
+
   const handleSelectSnapshot = (id: string) => {
     setSelectedSnapshots(prev => {
       if (prev.includes(id)) {
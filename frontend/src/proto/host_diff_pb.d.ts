@@ -82,6 +82,9 @@ export class GetHostHistoryRequest extends jspb.Message {
   getIpAddress(): string;
   setIpAddress(value: string): GetHostHistoryRequest;
 
+  getPageToken(): string;
+  setPageToken(value: string): GetHostHistoryRequest;
+
   serializeBinary(): Uint8Array;
   toObject(includeInstance?: boolean): GetHostHistoryRequest.AsObject;
   static toObject(includeInstance: boolean, msg: GetHostHistoryRequest): GetHostHistoryRequest.AsObject;
@@ -93,6 +96,7 @@ export class GetHostHistoryRequest extends jspb.Message {
 export namespace GetHostHistoryRequest {
   export type AsObject = {
     ipAddress: string,
+    pageToken: string,
   }
 }
 
@@ -102,6 +106,9 @@ export class GetHostHistoryResponse extends jspb.Message {
   clearSnapshotsList(): GetHostHistoryResponse;
   addSnapshots(value?: SnapshotInfo, index?: number): SnapshotInfo;
 
+  getNextPageToken(): string;
+  setNextPageToken(value: string): GetHostHistoryResponse;
+
   serializeBinary(): Uint8Array;
   toObject(includeInstance?: boolean): GetHostHistoryResponse.AsObject;
   static toObject(includeInstance: boolean, msg: GetHostHistoryResponse): GetHostHistoryResponse.AsObject;
@@ -113,6 +120,7 @@ export class GetHostHistoryResponse extends jspb.Message {
 export namespace GetHostHistoryResponse {
   export type AsObject = {
     snapshotsList: Array<SnapshotInfo.AsObject>,
+    nextPageToken: string,
   }
 }
 
@@ -881,7 +881,8 @@ proto.hostdiff.GetHostHistoryRequest.prototype.toObject = function(opt_includeIn
  */
 proto.hostdiff.GetHostHistoryRequest.toObject = function(includeInstance, msg) {
   var f, obj = {
-ipAddress: jspb.Message.getFieldWithDefault(msg, 1, "")
+ipAddress: jspb.Message.getFieldWithDefault(msg, 1, ""),
+pageToken: jspb.Message.getFieldWithDefault(msg, 4, "")
   };
 
   if (includeInstance) {
@@ -922,6 +923,10 @@ proto.hostdiff.GetHostHistoryRequest.deserializeBinaryFromReader = function(msg,
       var value = /** @type {string} */ (reader.readString());
       msg.setIpAddress(value);
       break;
+    case 4:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setPageToken(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -958,6 +963,13 @@ proto.hostdiff.GetHostHistoryRequest.serializeBinaryToWriter = function(message,
       f
     );
   }
+  f = message.getPageToken();
+  if (f.length > 0) {
+    writer.writeString(
+      4,
+      f
+    );
+  }
 };
 
 
@@ -979,6 +991,24 @@ proto.hostdiff.GetHostHistoryRequest.prototype.setIpAddress = function(value) {
 };
 
 
+/**
+ * optional string page_token = 4;
+ * @return {string}
+ */
+proto.hostdiff.GetHostHistoryRequest.prototype.getPageToken = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 4, ""));
+};
+
+
+/**
+ * @param {string} value
+ * @return {!proto.hostdiff.GetHostHistoryRequest} returns this
+ */
+proto.hostdiff.GetHostHistoryRequest.prototype.setPageToken = function(value) {
+  return jspb.Message.setProto3StringField(this, 4, value);
+};
+
+
 
 /**
  * List of repeated fields within this message type.
@@ -1019,7 +1049,8 @@ proto.hostdiff.GetHostHistoryResponse.prototype.toObject = function(opt_includeI
 proto.hostdiff.GetHostHistoryResponse.toObject = function(includeInstance, msg) {
   var f, obj = {
 snapshotsList: jspb.Message.toObjectList(msg.getSnapshotsList(),
-    proto.hostdiff.SnapshotInfo.toObject, includeInstance)
+    proto.hostdiff.SnapshotInfo.toObject, includeInstance),
+nextPageToken: jspb.Message.getFieldWithDefault(msg, 2, "")
   };
 
   if (includeInstance) {
@@ -1061,6 +1092,10 @@ proto.hostdiff.GetHostHistoryResponse.deserializeBinaryFromReader = function(msg
       reader.readMessage(value,proto.hostdiff.SnapshotInfo.deserializeBinaryFromReader);
       msg.addSnapshots(value);
       break;
+    case 2:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setNextPageToken(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -1098,6 +1133,13 @@ proto.hostdiff.GetHostHistoryResponse.serializeBinaryToWriter = function(message
       proto.hostdiff.SnapshotInfo.serializeBinaryToWriter
     );
   }
+  f = message.getNextPageToken();
+  if (f.length > 0) {
+    writer.writeString(
+      2,
+      f
+    );
+  }
 };
 
 
@@ -1139,6 +1181,24 @@ proto.hostdiff.GetHostHistoryResponse.prototype.clearSnapshotsList = function()
 };
 
 
+/**
+ * optional string next_page_token = 2;
+ * @return {string}
+ */
+proto.hostdiff.GetHostHistoryResponse.prototype.getNextPageToken = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 2, ""));
+};
+
+
+/**
+ * @param {string} value
+ * @return {!proto.hostdiff.GetHostHistoryResponse} returns this
+ */
+proto.hostdiff.GetHostHistoryResponse.prototype.setNextPageToken = function(value) {
+  return jspb.Message.setProto3StringField(this, 2, value);
+};
+
+
 
 
 
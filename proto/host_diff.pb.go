@@ -21,31 +21,3476 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// ConflictPolicy decides what an upload does when a snapshot with different
+// content is already stored for the same IP address and timestamp.
+// Re-uploading identical content always succeeds and returns the stored
+// snapshot's ID.
+type ConflictPolicy int32
+
+const (
+	// Same as CONFLICT_POLICY_REJECT.
+	ConflictPolicy_CONFLICT_POLICY_UNSPECIFIED ConflictPolicy = 0
+	// Fail the upload.
+	ConflictPolicy_CONFLICT_POLICY_REJECT ConflictPolicy = 1
+	// Soft-delete the stored snapshot and store the upload in its place.
+	ConflictPolicy_CONFLICT_POLICY_REPLACE ConflictPolicy = 2
+	// Keep the stored snapshot and store the upload as its next revision.
+	ConflictPolicy_CONFLICT_POLICY_KEEP_BOTH ConflictPolicy = 3
+)
+
+// Enum value maps for ConflictPolicy.
+var (
+	ConflictPolicy_name = map[int32]string{
+		0: "CONFLICT_POLICY_UNSPECIFIED",
+		1: "CONFLICT_POLICY_REJECT",
+		2: "CONFLICT_POLICY_REPLACE",
+		3: "CONFLICT_POLICY_KEEP_BOTH",
+	}
+	ConflictPolicy_value = map[string]int32{
+		"CONFLICT_POLICY_UNSPECIFIED": 0,
+		"CONFLICT_POLICY_REJECT":      1,
+		"CONFLICT_POLICY_REPLACE":     2,
+		"CONFLICT_POLICY_KEEP_BOTH":   3,
+	}
+)
+
+func (x ConflictPolicy) Enum() *ConflictPolicy {
+	p := new(ConflictPolicy)
+	*p = x
+	return p
+}
+
+func (x ConflictPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConflictPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[0].Descriptor()
+}
+
+func (ConflictPolicy) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[0]
+}
+
+func (x ConflictPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConflictPolicy.Descriptor instead.
+func (ConflictPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{0}
+}
+
+// UploadOutcome says what an upload did.
+type UploadOutcome int32
+
+const (
+	UploadOutcome_UPLOAD_OUTCOME_UNSPECIFIED UploadOutcome = 0
+	UploadOutcome_UPLOAD_OUTCOME_CREATED     UploadOutcome = 1
+	// Identical content was already stored; id is the stored snapshot.
+	UploadOutcome_UPLOAD_OUTCOME_UNCHANGED UploadOutcome = 2
+	// The upload replaced the snapshots in replaced_ids.
+	UploadOutcome_UPLOAD_OUTCOME_REPLACED UploadOutcome = 3
+	// The upload was stored as a new revision next to the existing snapshot.
+	UploadOutcome_UPLOAD_OUTCOME_REVISION UploadOutcome = 4
+)
+
+// Enum value maps for UploadOutcome.
+var (
+	UploadOutcome_name = map[int32]string{
+		0: "UPLOAD_OUTCOME_UNSPECIFIED",
+		1: "UPLOAD_OUTCOME_CREATED",
+		2: "UPLOAD_OUTCOME_UNCHANGED",
+		3: "UPLOAD_OUTCOME_REPLACED",
+		4: "UPLOAD_OUTCOME_REVISION",
+	}
+	UploadOutcome_value = map[string]int32{
+		"UPLOAD_OUTCOME_UNSPECIFIED": 0,
+		"UPLOAD_OUTCOME_CREATED":     1,
+		"UPLOAD_OUTCOME_UNCHANGED":   2,
+		"UPLOAD_OUTCOME_REPLACED":    3,
+		"UPLOAD_OUTCOME_REVISION":    4,
+	}
+)
+
+func (x UploadOutcome) Enum() *UploadOutcome {
+	p := new(UploadOutcome)
+	*p = x
+	return p
+}
+
+func (x UploadOutcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UploadOutcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[1].Descriptor()
+}
+
+func (UploadOutcome) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[1]
+}
+
+func (x UploadOutcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UploadOutcome.Descriptor instead.
+func (UploadOutcome) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{1}
+}
+
+type UploadWarningCode int32
+
+const (
+	UploadWarningCode_UPLOAD_WARNING_CODE_UNSPECIFIED UploadWarningCode = 0
+	// The filename and the content name different hosts.
+	UploadWarningCode_UPLOAD_WARNING_CODE_IP_MISMATCH UploadWarningCode = 1
+	// The filename and the content give different times.
+	UploadWarningCode_UPLOAD_WARNING_CODE_TIMESTAMP_MISMATCH UploadWarningCode = 2
+	// The content's ip or timestamp is not valid and was ignored.
+	UploadWarningCode_UPLOAD_WARNING_CODE_INVALID_CONTENT_FIELD UploadWarningCode = 3
+	// The filename doesn't follow the naming convention and was ignored.
+	UploadWarningCode_UPLOAD_WARNING_CODE_INVALID_FILENAME UploadWarningCode = 4
+	// The content breaks the snapshot schema, e.g. a port above 65535. Such
+	// content is only stored when the schema strictness is lenient.
+	UploadWarningCode_UPLOAD_WARNING_CODE_SCHEMA_ERROR UploadWarningCode = 5
+	// The content is suspicious but usable, e.g. a service_count that doesn't
+	// match the services listed.
+	UploadWarningCode_UPLOAD_WARNING_CODE_SCHEMA_WARNING UploadWarningCode = 6
+)
+
+// Enum value maps for UploadWarningCode.
+var (
+	UploadWarningCode_name = map[int32]string{
+		0: "UPLOAD_WARNING_CODE_UNSPECIFIED",
+		1: "UPLOAD_WARNING_CODE_IP_MISMATCH",
+		2: "UPLOAD_WARNING_CODE_TIMESTAMP_MISMATCH",
+		3: "UPLOAD_WARNING_CODE_INVALID_CONTENT_FIELD",
+		4: "UPLOAD_WARNING_CODE_INVALID_FILENAME",
+		5: "UPLOAD_WARNING_CODE_SCHEMA_ERROR",
+		6: "UPLOAD_WARNING_CODE_SCHEMA_WARNING",
+	}
+	UploadWarningCode_value = map[string]int32{
+		"UPLOAD_WARNING_CODE_UNSPECIFIED":           0,
+		"UPLOAD_WARNING_CODE_IP_MISMATCH":           1,
+		"UPLOAD_WARNING_CODE_TIMESTAMP_MISMATCH":    2,
+		"UPLOAD_WARNING_CODE_INVALID_CONTENT_FIELD": 3,
+		"UPLOAD_WARNING_CODE_INVALID_FILENAME":      4,
+		"UPLOAD_WARNING_CODE_SCHEMA_ERROR":          5,
+		"UPLOAD_WARNING_CODE_SCHEMA_WARNING":        6,
+	}
+)
+
+func (x UploadWarningCode) Enum() *UploadWarningCode {
+	p := new(UploadWarningCode)
+	*p = x
+	return p
+}
+
+func (x UploadWarningCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UploadWarningCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[2].Descriptor()
+}
+
+func (UploadWarningCode) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[2]
+}
+
+func (x UploadWarningCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UploadWarningCode.Descriptor instead.
+func (UploadWarningCode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{2}
+}
+
+// ArchiveFormat is the container format of a bulk upload.
+type ArchiveFormat int32
+
+const (
+	// Detect the format from the archive's leading bytes.
+	ArchiveFormat_ARCHIVE_FORMAT_UNSPECIFIED ArchiveFormat = 0
+	ArchiveFormat_ARCHIVE_FORMAT_TAR_GZ      ArchiveFormat = 1
+	ArchiveFormat_ARCHIVE_FORMAT_ZIP         ArchiveFormat = 2
+)
+
+// Enum value maps for ArchiveFormat.
+var (
+	ArchiveFormat_name = map[int32]string{
+		0: "ARCHIVE_FORMAT_UNSPECIFIED",
+		1: "ARCHIVE_FORMAT_TAR_GZ",
+		2: "ARCHIVE_FORMAT_ZIP",
+	}
+	ArchiveFormat_value = map[string]int32{
+		"ARCHIVE_FORMAT_UNSPECIFIED": 0,
+		"ARCHIVE_FORMAT_TAR_GZ":      1,
+		"ARCHIVE_FORMAT_ZIP":         2,
+	}
+)
+
+func (x ArchiveFormat) Enum() *ArchiveFormat {
+	p := new(ArchiveFormat)
+	*p = x
+	return p
+}
+
+func (x ArchiveFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ArchiveFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[3].Descriptor()
+}
+
+func (ArchiveFormat) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[3]
+}
+
+func (x ArchiveFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ArchiveFormat.Descriptor instead.
+func (ArchiveFormat) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{3}
+}
+
+type BulkUploadStatus int32
+
+const (
+	BulkUploadStatus_BULK_UPLOAD_STATUS_UNSPECIFIED BulkUploadStatus = 0
+	BulkUploadStatus_BULK_UPLOAD_STATUS_INSERTED    BulkUploadStatus = 1
+	// A snapshot with the same IP address, timestamp and content already
+	// exists; id is the existing snapshot.
+	BulkUploadStatus_BULK_UPLOAD_STATUS_DUPLICATE BulkUploadStatus = 2
+	// The file is invalid, or different content is already stored for its IP
+	// address and timestamp; reason says which.
+	BulkUploadStatus_BULK_UPLOAD_STATUS_REJECTED BulkUploadStatus = 3
+	// The file is valid but was not stored because an atomic upload was
+	// rolled back.
+	BulkUploadStatus_BULK_UPLOAD_STATUS_SKIPPED BulkUploadStatus = 4
+)
+
+// Enum value maps for BulkUploadStatus.
+var (
+	BulkUploadStatus_name = map[int32]string{
+		0: "BULK_UPLOAD_STATUS_UNSPECIFIED",
+		1: "BULK_UPLOAD_STATUS_INSERTED",
+		2: "BULK_UPLOAD_STATUS_DUPLICATE",
+		3: "BULK_UPLOAD_STATUS_REJECTED",
+		4: "BULK_UPLOAD_STATUS_SKIPPED",
+	}
+	BulkUploadStatus_value = map[string]int32{
+		"BULK_UPLOAD_STATUS_UNSPECIFIED": 0,
+		"BULK_UPLOAD_STATUS_INSERTED":    1,
+		"BULK_UPLOAD_STATUS_DUPLICATE":   2,
+		"BULK_UPLOAD_STATUS_REJECTED":    3,
+		"BULK_UPLOAD_STATUS_SKIPPED":     4,
+	}
+)
+
+func (x BulkUploadStatus) Enum() *BulkUploadStatus {
+	p := new(BulkUploadStatus)
+	*p = x
+	return p
+}
+
+func (x BulkUploadStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BulkUploadStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[4].Descriptor()
+}
+
+func (BulkUploadStatus) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[4]
+}
+
+func (x BulkUploadStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BulkUploadStatus.Descriptor instead.
+func (BulkUploadStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{4}
+}
+
+// HistoryOrder selects how GetHostHistory sorts snapshots.
+type HistoryOrder int32
+
+const (
+	// Newest first.
+	HistoryOrder_HISTORY_ORDER_UNSPECIFIED  HistoryOrder = 0
+	HistoryOrder_HISTORY_ORDER_NEWEST_FIRST HistoryOrder = 1
+	HistoryOrder_HISTORY_ORDER_OLDEST_FIRST HistoryOrder = 2
+)
+
+// Enum value maps for HistoryOrder.
+var (
+	HistoryOrder_name = map[int32]string{
+		0: "HISTORY_ORDER_UNSPECIFIED",
+		1: "HISTORY_ORDER_NEWEST_FIRST",
+		2: "HISTORY_ORDER_OLDEST_FIRST",
+	}
+	HistoryOrder_value = map[string]int32{
+		"HISTORY_ORDER_UNSPECIFIED":  0,
+		"HISTORY_ORDER_NEWEST_FIRST": 1,
+		"HISTORY_ORDER_OLDEST_FIRST": 2,
+	}
+)
+
+func (x HistoryOrder) Enum() *HistoryOrder {
+	p := new(HistoryOrder)
+	*p = x
+	return p
+}
+
+func (x HistoryOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HistoryOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[5].Descriptor()
+}
+
+func (HistoryOrder) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[5]
+}
+
+func (x HistoryOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HistoryOrder.Descriptor instead.
+func (HistoryOrder) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{5}
+}
+
+// ReportFormat selects how a diff report is rendered for export.
+type ReportFormat int32
+
+const (
+	ReportFormat_REPORT_FORMAT_UNSPECIFIED ReportFormat = 0
+	ReportFormat_REPORT_FORMAT_TEXT        ReportFormat = 1
+	ReportFormat_REPORT_FORMAT_MARKDOWN    ReportFormat = 2
+	// Standalone HTML page with inline styles.
+	ReportFormat_REPORT_FORMAT_HTML ReportFormat = 3
+	// One row per change.
+	ReportFormat_REPORT_FORMAT_CSV ReportFormat = 4
+	// Canonical JSON: the same report always encodes to the same bytes.
+	ReportFormat_REPORT_FORMAT_JSON ReportFormat = 5
+	// SARIF 2.1.0, for code-scanning dashboards.
+	ReportFormat_REPORT_FORMAT_SARIF ReportFormat = 6
+)
+
+// Enum value maps for ReportFormat.
+var (
+	ReportFormat_name = map[int32]string{
+		0: "REPORT_FORMAT_UNSPECIFIED",
+		1: "REPORT_FORMAT_TEXT",
+		2: "REPORT_FORMAT_MARKDOWN",
+		3: "REPORT_FORMAT_HTML",
+		4: "REPORT_FORMAT_CSV",
+		5: "REPORT_FORMAT_JSON",
+		6: "REPORT_FORMAT_SARIF",
+	}
+	ReportFormat_value = map[string]int32{
+		"REPORT_FORMAT_UNSPECIFIED": 0,
+		"REPORT_FORMAT_TEXT":        1,
+		"REPORT_FORMAT_MARKDOWN":    2,
+		"REPORT_FORMAT_HTML":        3,
+		"REPORT_FORMAT_CSV":         4,
+		"REPORT_FORMAT_JSON":        5,
+		"REPORT_FORMAT_SARIF":       6,
+	}
+)
+
+func (x ReportFormat) Enum() *ReportFormat {
+	p := new(ReportFormat)
+	*p = x
+	return p
+}
+
+func (x ReportFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReportFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[6].Descriptor()
+}
+
+func (ReportFormat) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[6]
+}
+
+func (x ReportFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReportFormat.Descriptor instead.
+func (ReportFormat) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{6}
+}
+
+// RuleAction says what happens to a change matched by a rule.
+type RuleAction int32
+
+const (
+	RuleAction_RULE_ACTION_UNSPECIFIED RuleAction = 0
+	// Remove the change from the report but still count it.
+	RuleAction_RULE_ACTION_SUPPRESS RuleAction = 1
+	// Keep the change but cap its severity.
+	RuleAction_RULE_ACTION_DOWNGRADE RuleAction = 2
+)
+
+// Enum value maps for RuleAction.
+var (
+	RuleAction_name = map[int32]string{
+		0: "RULE_ACTION_UNSPECIFIED",
+		1: "RULE_ACTION_SUPPRESS",
+		2: "RULE_ACTION_DOWNGRADE",
+	}
+	RuleAction_value = map[string]int32{
+		"RULE_ACTION_UNSPECIFIED": 0,
+		"RULE_ACTION_SUPPRESS":    1,
+		"RULE_ACTION_DOWNGRADE":   2,
+	}
+)
+
+func (x RuleAction) Enum() *RuleAction {
+	p := new(RuleAction)
+	*p = x
+	return p
+}
+
+func (x RuleAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RuleAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[7].Descriptor()
+}
+
+func (RuleAction) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[7]
+}
+
+func (x RuleAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RuleAction.Descriptor instead.
+func (RuleAction) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{7}
+}
+
+// CertificateField identifies which attribute of a TLS certificate changed.
+type CertificateField int32
+
+const (
+	CertificateField_CERTIFICATE_FIELD_UNSPECIFIED CertificateField = 0
+	CertificateField_CERTIFICATE_FIELD_FINGERPRINT CertificateField = 1
+	CertificateField_CERTIFICATE_FIELD_SUBJECT     CertificateField = 2
+	CertificateField_CERTIFICATE_FIELD_ISSUER      CertificateField = 3
+	CertificateField_CERTIFICATE_FIELD_SANS        CertificateField = 4
+	CertificateField_CERTIFICATE_FIELD_NOT_BEFORE  CertificateField = 5
+	CertificateField_CERTIFICATE_FIELD_NOT_AFTER   CertificateField = 6
+	CertificateField_CERTIFICATE_FIELD_KEY_TYPE    CertificateField = 7
+	CertificateField_CERTIFICATE_FIELD_KEY_SIZE    CertificateField = 8
+	CertificateField_CERTIFICATE_FIELD_SELF_SIGNED CertificateField = 9
+)
+
+// Enum value maps for CertificateField.
+var (
+	CertificateField_name = map[int32]string{
+		0: "CERTIFICATE_FIELD_UNSPECIFIED",
+		1: "CERTIFICATE_FIELD_FINGERPRINT",
+		2: "CERTIFICATE_FIELD_SUBJECT",
+		3: "CERTIFICATE_FIELD_ISSUER",
+		4: "CERTIFICATE_FIELD_SANS",
+		5: "CERTIFICATE_FIELD_NOT_BEFORE",
+		6: "CERTIFICATE_FIELD_NOT_AFTER",
+		7: "CERTIFICATE_FIELD_KEY_TYPE",
+		8: "CERTIFICATE_FIELD_KEY_SIZE",
+		9: "CERTIFICATE_FIELD_SELF_SIGNED",
+	}
+	CertificateField_value = map[string]int32{
+		"CERTIFICATE_FIELD_UNSPECIFIED": 0,
+		"CERTIFICATE_FIELD_FINGERPRINT": 1,
+		"CERTIFICATE_FIELD_SUBJECT":     2,
+		"CERTIFICATE_FIELD_ISSUER":      3,
+		"CERTIFICATE_FIELD_SANS":        4,
+		"CERTIFICATE_FIELD_NOT_BEFORE":  5,
+		"CERTIFICATE_FIELD_NOT_AFTER":   6,
+		"CERTIFICATE_FIELD_KEY_TYPE":    7,
+		"CERTIFICATE_FIELD_KEY_SIZE":    8,
+		"CERTIFICATE_FIELD_SELF_SIGNED": 9,
+	}
+)
+
+func (x CertificateField) Enum() *CertificateField {
+	p := new(CertificateField)
+	*p = x
+	return p
+}
+
+func (x CertificateField) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CertificateField) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[8].Descriptor()
+}
+
+func (CertificateField) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[8]
+}
+
+func (x CertificateField) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CertificateField.Descriptor instead.
+func (CertificateField) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{8}
+}
+
+// FieldChangeKind describes how a value at a JSON path changed.
+type FieldChangeKind int32
+
+const (
+	FieldChangeKind_FIELD_CHANGE_KIND_UNSPECIFIED FieldChangeKind = 0
+	FieldChangeKind_FIELD_CHANGE_KIND_ADDED       FieldChangeKind = 1
+	FieldChangeKind_FIELD_CHANGE_KIND_REMOVED     FieldChangeKind = 2
+	FieldChangeKind_FIELD_CHANGE_KIND_CHANGED     FieldChangeKind = 3
+)
+
+// Enum value maps for FieldChangeKind.
+var (
+	FieldChangeKind_name = map[int32]string{
+		0: "FIELD_CHANGE_KIND_UNSPECIFIED",
+		1: "FIELD_CHANGE_KIND_ADDED",
+		2: "FIELD_CHANGE_KIND_REMOVED",
+		3: "FIELD_CHANGE_KIND_CHANGED",
+	}
+	FieldChangeKind_value = map[string]int32{
+		"FIELD_CHANGE_KIND_UNSPECIFIED": 0,
+		"FIELD_CHANGE_KIND_ADDED":       1,
+		"FIELD_CHANGE_KIND_REMOVED":     2,
+		"FIELD_CHANGE_KIND_CHANGED":     3,
+	}
+)
+
+func (x FieldChangeKind) Enum() *FieldChangeKind {
+	p := new(FieldChangeKind)
+	*p = x
+	return p
+}
+
+func (x FieldChangeKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FieldChangeKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[9].Descriptor()
+}
+
+func (FieldChangeKind) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[9]
+}
+
+func (x FieldChangeKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FieldChangeKind.Descriptor instead.
+func (FieldChangeKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{9}
+}
+
+// VersionChangeKind classifies a software version change.
+type VersionChangeKind int32
+
+const (
+	VersionChangeKind_VERSION_CHANGE_KIND_UNSPECIFIED     VersionChangeKind = 0
+	VersionChangeKind_VERSION_CHANGE_KIND_MAJOR_UPGRADE   VersionChangeKind = 1
+	VersionChangeKind_VERSION_CHANGE_KIND_MINOR_UPGRADE   VersionChangeKind = 2
+	VersionChangeKind_VERSION_CHANGE_KIND_PATCH_UPGRADE   VersionChangeKind = 3
+	VersionChangeKind_VERSION_CHANGE_KIND_MAJOR_DOWNGRADE VersionChangeKind = 4
+	VersionChangeKind_VERSION_CHANGE_KIND_MINOR_DOWNGRADE VersionChangeKind = 5
+	VersionChangeKind_VERSION_CHANGE_KIND_PATCH_DOWNGRADE VersionChangeKind = 6
+	VersionChangeKind_VERSION_CHANGE_KIND_EQUIVALENT      VersionChangeKind = 7
+	VersionChangeKind_VERSION_CHANGE_KIND_UNPARSEABLE     VersionChangeKind = 8
+)
+
+// Enum value maps for VersionChangeKind.
+var (
+	VersionChangeKind_name = map[int32]string{
+		0: "VERSION_CHANGE_KIND_UNSPECIFIED",
+		1: "VERSION_CHANGE_KIND_MAJOR_UPGRADE",
+		2: "VERSION_CHANGE_KIND_MINOR_UPGRADE",
+		3: "VERSION_CHANGE_KIND_PATCH_UPGRADE",
+		4: "VERSION_CHANGE_KIND_MAJOR_DOWNGRADE",
+		5: "VERSION_CHANGE_KIND_MINOR_DOWNGRADE",
+		6: "VERSION_CHANGE_KIND_PATCH_DOWNGRADE",
+		7: "VERSION_CHANGE_KIND_EQUIVALENT",
+		8: "VERSION_CHANGE_KIND_UNPARSEABLE",
+	}
+	VersionChangeKind_value = map[string]int32{
+		"VERSION_CHANGE_KIND_UNSPECIFIED":     0,
+		"VERSION_CHANGE_KIND_MAJOR_UPGRADE":   1,
+		"VERSION_CHANGE_KIND_MINOR_UPGRADE":   2,
+		"VERSION_CHANGE_KIND_PATCH_UPGRADE":   3,
+		"VERSION_CHANGE_KIND_MAJOR_DOWNGRADE": 4,
+		"VERSION_CHANGE_KIND_MINOR_DOWNGRADE": 5,
+		"VERSION_CHANGE_KIND_PATCH_DOWNGRADE": 6,
+		"VERSION_CHANGE_KIND_EQUIVALENT":      7,
+		"VERSION_CHANGE_KIND_UNPARSEABLE":     8,
+	}
+)
+
+func (x VersionChangeKind) Enum() *VersionChangeKind {
+	p := new(VersionChangeKind)
+	*p = x
+	return p
+}
+
+func (x VersionChangeKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VersionChangeKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[10].Descriptor()
+}
+
+func (VersionChangeKind) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[10]
+}
+
+func (x VersionChangeKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use VersionChangeKind.Descriptor instead.
+func (VersionChangeKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{10}
+}
+
+// Severity ranks how concerning a change is.
+type Severity int32
+
+const (
+	Severity_SEVERITY_UNSPECIFIED Severity = 0
+	Severity_SEVERITY_INFO        Severity = 1
+	Severity_SEVERITY_LOW         Severity = 2
+	Severity_SEVERITY_MEDIUM      Severity = 3
+	Severity_SEVERITY_HIGH        Severity = 4
+	Severity_SEVERITY_CRITICAL    Severity = 5
+)
+
+// Enum value maps for Severity.
+var (
+	Severity_name = map[int32]string{
+		0: "SEVERITY_UNSPECIFIED",
+		1: "SEVERITY_INFO",
+		2: "SEVERITY_LOW",
+		3: "SEVERITY_MEDIUM",
+		4: "SEVERITY_HIGH",
+		5: "SEVERITY_CRITICAL",
+	}
+	Severity_value = map[string]int32{
+		"SEVERITY_UNSPECIFIED": 0,
+		"SEVERITY_INFO":        1,
+		"SEVERITY_LOW":         2,
+		"SEVERITY_MEDIUM":      3,
+		"SEVERITY_HIGH":        4,
+		"SEVERITY_CRITICAL":    5,
+	}
+)
+
+func (x Severity) Enum() *Severity {
+	p := new(Severity)
+	*p = x
+	return p
+}
+
+func (x Severity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Severity) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[11].Descriptor()
+}
+
+func (Severity) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[11]
+}
+
+func (x Severity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Severity.Descriptor instead.
+func (Severity) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{11}
+}
+
+// HostOrder selects how ListHosts sorts hosts.
+type HostOrder int32
+
+const (
+	// By IP address text.
+	HostOrder_HOST_ORDER_UNSPECIFIED HostOrder = 0
+	// Most recently seen first.
+	HostOrder_HOST_ORDER_LAST_SEEN_DESC HostOrder = 1
+	// Least recently seen first.
+	HostOrder_HOST_ORDER_LAST_SEEN_ASC HostOrder = 2
+)
+
+// Enum value maps for HostOrder.
+var (
+	HostOrder_name = map[int32]string{
+		0: "HOST_ORDER_UNSPECIFIED",
+		1: "HOST_ORDER_LAST_SEEN_DESC",
+		2: "HOST_ORDER_LAST_SEEN_ASC",
+	}
+	HostOrder_value = map[string]int32{
+		"HOST_ORDER_UNSPECIFIED":    0,
+		"HOST_ORDER_LAST_SEEN_DESC": 1,
+		"HOST_ORDER_LAST_SEEN_ASC":  2,
+	}
+)
+
+func (x HostOrder) Enum() *HostOrder {
+	p := new(HostOrder)
+	*p = x
+	return p
+}
+
+func (x HostOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HostOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_host_diff_proto_enumTypes[12].Descriptor()
+}
+
+func (HostOrder) Type() protoreflect.EnumType {
+	return &file_proto_host_diff_proto_enumTypes[12]
+}
+
+func (x HostOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HostOrder.Descriptor instead.
+func (HostOrder) EnumDescriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{12}
+}
+
 // SnapshotInfo contains the metadata for a single snapshot.
 type SnapshotInfo struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	IpAddress string                 `protobuf:"bytes,2,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Timestamp string                 `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Tells apart snapshots with the same IP address and timestamp, stored
+	// with CONFLICT_POLICY_KEEP_BOTH. 0 unless there are several.
+	Revision      int32 `protobuf:"varint,4,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotInfo) Reset() {
+	*x = SnapshotInfo{}
+	mi := &file_proto_host_diff_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotInfo) ProtoMessage() {}
+
+func (x *SnapshotInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotInfo.ProtoReflect.Descriptor instead.
+func (*SnapshotInfo) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SnapshotInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SnapshotInfo) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *SnapshotInfo) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *SnapshotInfo) GetRevision() int32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+// UploadSnapshot: Allows uploading a snapshot JSON file.
+type UploadSnapshotRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The raw content of the JSON file.
+	FileContent []byte `protobuf:"bytes,1,opt,name=file_content,json=fileContent,proto3" json:"file_content,omitempty"`
+	// The original filename, host_<ip>_<timestamp>.json. Optional when the
+	// server takes snapshot metadata from the file content.
+	Filename       string         `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	ConflictPolicy ConflictPolicy `protobuf:"varint,3,opt,name=conflict_policy,json=conflictPolicy,proto3,enum=hostdiff.ConflictPolicy" json:"conflict_policy,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UploadSnapshotRequest) Reset() {
+	*x = UploadSnapshotRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSnapshotRequest) ProtoMessage() {}
+
+func (x *UploadSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*UploadSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UploadSnapshotRequest) GetFileContent() []byte {
+	if x != nil {
+		return x.FileContent
+	}
+	return nil
+}
+
+func (x *UploadSnapshotRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UploadSnapshotRequest) GetConflictPolicy() ConflictPolicy {
+	if x != nil {
+		return x.ConflictPolicy
+	}
+	return ConflictPolicy_CONFLICT_POLICY_UNSPECIFIED
+}
+
+type UploadSnapshotResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ID of the newly created snapshot record.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The IP address associated with the snapshot.
+	IpAddress string `protobuf:"bytes,2,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	// The timestamp of the snapshot.
+	Timestamp string `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Problems that didn't stop the upload, e.g. a filename that disagrees
+	// with the content.
+	Warnings []*UploadWarning `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Outcome  UploadOutcome    `protobuf:"varint,5,opt,name=outcome,proto3,enum=hostdiff.UploadOutcome" json:"outcome,omitempty"`
+	// Snapshots soft-deleted by CONFLICT_POLICY_REPLACE.
+	ReplacedIds   []string `protobuf:"bytes,6,rep,name=replaced_ids,json=replacedIds,proto3" json:"replaced_ids,omitempty"`
+	Revision      int32    `protobuf:"varint,7,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSnapshotResponse) Reset() {
+	*x = UploadSnapshotResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSnapshotResponse) ProtoMessage() {}
+
+func (x *UploadSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*UploadSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UploadSnapshotResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UploadSnapshotResponse) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *UploadSnapshotResponse) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *UploadSnapshotResponse) GetWarnings() []*UploadWarning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *UploadSnapshotResponse) GetOutcome() UploadOutcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return UploadOutcome_UPLOAD_OUTCOME_UNSPECIFIED
+}
+
+func (x *UploadSnapshotResponse) GetReplacedIds() []string {
+	if x != nil {
+		return x.ReplacedIds
+	}
+	return nil
+}
+
+func (x *UploadSnapshotResponse) GetRevision() int32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+// UploadWarning describes a problem with an uploaded file that was accepted
+// anyway.
+type UploadWarning struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  UploadWarningCode      `protobuf:"varint,1,opt,name=code,proto3,enum=hostdiff.UploadWarningCode" json:"code,omitempty"`
+	// JSON pointer to the content field concerned, e.g. "/ip".
+	Pointer string `protobuf:"bytes,2,opt,name=pointer,proto3" json:"pointer,omitempty"`
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// For mismatches, the value taken from each source.
+	FilenameValue string `protobuf:"bytes,4,opt,name=filename_value,json=filenameValue,proto3" json:"filename_value,omitempty"`
+	ContentValue  string `protobuf:"bytes,5,opt,name=content_value,json=contentValue,proto3" json:"content_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadWarning) Reset() {
+	*x = UploadWarning{}
+	mi := &file_proto_host_diff_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadWarning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadWarning) ProtoMessage() {}
+
+func (x *UploadWarning) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadWarning.ProtoReflect.Descriptor instead.
+func (*UploadWarning) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UploadWarning) GetCode() UploadWarningCode {
+	if x != nil {
+		return x.Code
+	}
+	return UploadWarningCode_UPLOAD_WARNING_CODE_UNSPECIFIED
+}
+
+func (x *UploadWarning) GetPointer() string {
+	if x != nil {
+		return x.Pointer
+	}
+	return ""
+}
+
+func (x *UploadWarning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UploadWarning) GetFilenameValue() string {
+	if x != nil {
+		return x.FilenameValue
+	}
+	return ""
+}
+
+func (x *UploadWarning) GetContentValue() string {
+	if x != nil {
+		return x.ContentValue
+	}
+	return ""
+}
+
+// UploadSnapshotChunk is one frame of a streamed upload. The first frame must
+// be a header; every following frame carries the next chunk of the file.
+type UploadSnapshotChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Frame:
+	//
+	//	*UploadSnapshotChunk_Header
+	//	*UploadSnapshotChunk_Data
+	Frame         isUploadSnapshotChunk_Frame `protobuf_oneof:"frame"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSnapshotChunk) Reset() {
+	*x = UploadSnapshotChunk{}
+	mi := &file_proto_host_diff_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSnapshotChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSnapshotChunk) ProtoMessage() {}
+
+func (x *UploadSnapshotChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSnapshotChunk.ProtoReflect.Descriptor instead.
+func (*UploadSnapshotChunk) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UploadSnapshotChunk) GetFrame() isUploadSnapshotChunk_Frame {
+	if x != nil {
+		return x.Frame
+	}
+	return nil
+}
+
+func (x *UploadSnapshotChunk) GetHeader() *UploadSnapshotHeader {
+	if x != nil {
+		if x, ok := x.Frame.(*UploadSnapshotChunk_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *UploadSnapshotChunk) GetData() []byte {
+	if x != nil {
+		if x, ok := x.Frame.(*UploadSnapshotChunk_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+type isUploadSnapshotChunk_Frame interface {
+	isUploadSnapshotChunk_Frame()
+}
+
+type UploadSnapshotChunk_Header struct {
+	Header *UploadSnapshotHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type UploadSnapshotChunk_Data struct {
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+func (*UploadSnapshotChunk_Header) isUploadSnapshotChunk_Frame() {}
+
+func (*UploadSnapshotChunk_Data) isUploadSnapshotChunk_Frame() {}
+
+// UploadSnapshotHeader describes a streamed upload.
+type UploadSnapshotHeader struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// Size of the complete file in bytes. Optional; checked when set.
+	Size int64 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// Hex-encoded SHA-256 of the complete file. Optional; checked when set.
+	Sha256         string         `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	ConflictPolicy ConflictPolicy `protobuf:"varint,4,opt,name=conflict_policy,json=conflictPolicy,proto3,enum=hostdiff.ConflictPolicy" json:"conflict_policy,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UploadSnapshotHeader) Reset() {
+	*x = UploadSnapshotHeader{}
+	mi := &file_proto_host_diff_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSnapshotHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSnapshotHeader) ProtoMessage() {}
+
+func (x *UploadSnapshotHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSnapshotHeader.ProtoReflect.Descriptor instead.
+func (*UploadSnapshotHeader) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UploadSnapshotHeader) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UploadSnapshotHeader) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *UploadSnapshotHeader) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *UploadSnapshotHeader) GetConflictPolicy() ConflictPolicy {
+	if x != nil {
+		return x.ConflictPolicy
+	}
+	return ConflictPolicy_CONFLICT_POLICY_UNSPECIFIED
+}
+
+// BulkUpload: Uploads an archive of host_<ip>_<timestamp>.json files.
+type BulkUploadRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Archive []byte                 `protobuf:"bytes,1,opt,name=archive,proto3" json:"archive,omitempty"`
+	Format  ArchiveFormat          `protobuf:"varint,2,opt,name=format,proto3,enum=hostdiff.ArchiveFormat" json:"format,omitempty"`
+	// Store the archive in a single transaction: if any file is rejected,
+	// nothing is stored. Otherwise every valid file is stored on its own.
+	Atomic        bool `protobuf:"varint,3,opt,name=atomic,proto3" json:"atomic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkUploadRequest) Reset() {
+	*x = BulkUploadRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkUploadRequest) ProtoMessage() {}
+
+func (x *BulkUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkUploadRequest.ProtoReflect.Descriptor instead.
+func (*BulkUploadRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BulkUploadRequest) GetArchive() []byte {
+	if x != nil {
+		return x.Archive
+	}
+	return nil
+}
+
+func (x *BulkUploadRequest) GetFormat() ArchiveFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ArchiveFormat_ARCHIVE_FORMAT_UNSPECIFIED
+}
+
+func (x *BulkUploadRequest) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
+// BulkUploadResult is the outcome for one file in the archive.
+type BulkUploadResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path of the file inside the archive.
+	Filename  string           `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Status    BulkUploadStatus `protobuf:"varint,2,opt,name=status,proto3,enum=hostdiff.BulkUploadStatus" json:"status,omitempty"`
+	Id        string           `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	IpAddress string           `protobuf:"bytes,4,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Timestamp string           `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Why the file was rejected or skipped.
+	Reason        string           `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	Warnings      []*UploadWarning `protobuf:"bytes,7,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkUploadResult) Reset() {
+	*x = BulkUploadResult{}
+	mi := &file_proto_host_diff_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkUploadResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkUploadResult) ProtoMessage() {}
+
+func (x *BulkUploadResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkUploadResult.ProtoReflect.Descriptor instead.
+func (*BulkUploadResult) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BulkUploadResult) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *BulkUploadResult) GetStatus() BulkUploadStatus {
+	if x != nil {
+		return x.Status
+	}
+	return BulkUploadStatus_BULK_UPLOAD_STATUS_UNSPECIFIED
+}
+
+func (x *BulkUploadResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BulkUploadResult) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *BulkUploadResult) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *BulkUploadResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *BulkUploadResult) GetWarnings() []*UploadWarning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type BulkUploadResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per file, in archive order. Directories are not listed.
+	Results []*BulkUploadResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// False when an atomic upload was rolled back.
+	Committed     bool  `protobuf:"varint,2,opt,name=committed,proto3" json:"committed,omitempty"`
+	Inserted      int32 `protobuf:"varint,3,opt,name=inserted,proto3" json:"inserted,omitempty"`
+	Duplicates    int32 `protobuf:"varint,4,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	Rejected      int32 `protobuf:"varint,5,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkUploadResponse) Reset() {
+	*x = BulkUploadResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkUploadResponse) ProtoMessage() {}
+
+func (x *BulkUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkUploadResponse.ProtoReflect.Descriptor instead.
+func (*BulkUploadResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BulkUploadResponse) GetResults() []*BulkUploadResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *BulkUploadResponse) GetCommitted() bool {
+	if x != nil {
+		return x.Committed
+	}
+	return false
+}
+
+func (x *BulkUploadResponse) GetInserted() int32 {
+	if x != nil {
+		return x.Inserted
+	}
+	return 0
+}
+
+func (x *BulkUploadResponse) GetDuplicates() int32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+func (x *BulkUploadResponse) GetRejected() int32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+// GetHostHistory: Retrieves all snapshots for a specific host, or for every
+// host in an IP range, newest first. Set one of ip_address and ip_range.
+type GetHostHistoryRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	IpAddress string                 `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	// A CIDR block such as "10.0.0.0/24" or "2001:db8::/48", or an inclusive
+	// range such as "10.0.0.1-10.0.0.99".
+	IpRange string `protobuf:"bytes,2,opt,name=ip_range,json=ipRange,proto3" json:"ip_range,omitempty"`
+	// Results per page; defaults to 100, at most 1000.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token from the previous response, made with the same filters
+	// and order.
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Optional RFC 3339 bounds on the snapshot timestamps; both inclusive.
+	StartTime     string       `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       string       `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Order         HistoryOrder `protobuf:"varint,7,opt,name=order,proto3,enum=hostdiff.HistoryOrder" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHostHistoryRequest) Reset() {
+	*x = GetHostHistoryRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHostHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHostHistoryRequest) ProtoMessage() {}
+
+func (x *GetHostHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHostHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHostHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetHostHistoryRequest) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *GetHostHistoryRequest) GetIpRange() string {
+	if x != nil {
+		return x.IpRange
+	}
+	return ""
+}
+
+func (x *GetHostHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetHostHistoryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *GetHostHistoryRequest) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *GetHostHistoryRequest) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *GetHostHistoryRequest) GetOrder() HistoryOrder {
+	if x != nil {
+		return x.Order
+	}
+	return HistoryOrder_HISTORY_ORDER_UNSPECIFIED
+}
+
+type GetHostHistoryResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Snapshots []*SnapshotInfo        `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHostHistoryResponse) Reset() {
+	*x = GetHostHistoryResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHostHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHostHistoryResponse) ProtoMessage() {}
+
+func (x *GetHostHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHostHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHostHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetHostHistoryResponse) GetSnapshots() []*SnapshotInfo {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+func (x *GetHostHistoryResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// CompareSnapshots: Requests a comparison between two snapshots.
+type CompareSnapshotsRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	SnapshotIdA string                 `protobuf:"bytes,1,opt,name=snapshot_id_a,json=snapshotIdA,proto3" json:"snapshot_id_a,omitempty"`
+	SnapshotIdB string                 `protobuf:"bytes,2,opt,name=snapshot_id_b,json=snapshotIdB,proto3" json:"snapshot_id_b,omitempty"`
+	// Ignore rules for this comparison. They are evaluated before the
+	// server's configured rules; the first matching rule wins.
+	Rules []*SuppressionRule `protobuf:"bytes,3,rep,name=rules,proto3" json:"rules,omitempty"`
+	// Optional export format. When set, the response also carries the report
+	// rendered in that format.
+	Format        ReportFormat `protobuf:"varint,4,opt,name=format,proto3,enum=hostdiff.ReportFormat" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareSnapshotsRequest) Reset() {
+	*x = CompareSnapshotsRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareSnapshotsRequest) ProtoMessage() {}
+
+func (x *CompareSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*CompareSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CompareSnapshotsRequest) GetSnapshotIdA() string {
+	if x != nil {
+		return x.SnapshotIdA
+	}
+	return ""
+}
+
+func (x *CompareSnapshotsRequest) GetSnapshotIdB() string {
+	if x != nil {
+		return x.SnapshotIdB
+	}
+	return ""
+}
+
+func (x *CompareSnapshotsRequest) GetRules() []*SuppressionRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *CompareSnapshotsRequest) GetFormat() ReportFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ReportFormat_REPORT_FORMAT_UNSPECIFIED
+}
+
+// RenderedReport is a diff report rendered in an export format.
+type RenderedReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        ReportFormat           `protobuf:"varint,1,opt,name=format,proto3,enum=hostdiff.ReportFormat" json:"format,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Content       []byte                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderedReport) Reset() {
+	*x = RenderedReport{}
+	mi := &file_proto_host_diff_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderedReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderedReport) ProtoMessage() {}
+
+func (x *RenderedReport) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderedReport.ProtoReflect.Descriptor instead.
+func (*RenderedReport) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RenderedReport) GetFormat() ReportFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ReportFormat_REPORT_FORMAT_UNSPECIFIED
+}
+
+func (x *RenderedReport) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *RenderedReport) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+// SuppressionRule matches changes by host, port, protocol and field.
+// Empty matchers match everything.
+type SuppressionRule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// IP address or CIDR block.
+	Host string `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	// Comma-separated ports and ranges, e.g. "80,443,49152-65535".
+	Ports    string `protobuf:"bytes,3,opt,name=ports,proto3" json:"ports,omitempty"`
+	Protocol string `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// Glob over change field names, e.g. "status", "tls_*" or "/http/headers/*".
+	Field  string     `protobuf:"bytes,5,opt,name=field,proto3" json:"field,omitempty"`
+	Action RuleAction `protobuf:"varint,6,opt,name=action,proto3,enum=hostdiff.RuleAction" json:"action,omitempty"`
+	// Severity cap for downgrade rules (info, low, medium or high).
+	Severity      Severity `protobuf:"varint,7,opt,name=severity,proto3,enum=hostdiff.Severity" json:"severity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuppressionRule) Reset() {
+	*x = SuppressionRule{}
+	mi := &file_proto_host_diff_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuppressionRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuppressionRule) ProtoMessage() {}
+
+func (x *SuppressionRule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuppressionRule.ProtoReflect.Descriptor instead.
+func (*SuppressionRule) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SuppressionRule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SuppressionRule) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *SuppressionRule) GetPorts() string {
+	if x != nil {
+		return x.Ports
+	}
+	return ""
+}
+
+func (x *SuppressionRule) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *SuppressionRule) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *SuppressionRule) GetAction() RuleAction {
+	if x != nil {
+		return x.Action
+	}
+	return RuleAction_RULE_ACTION_UNSPECIFIED
+}
+
+func (x *SuppressionRule) GetSeverity() Severity {
+	if x != nil {
+		return x.Severity
+	}
+	return Severity_SEVERITY_UNSPECIFIED
+}
+
+// SuppressedChange is a change removed from a report by a suppression rule.
+type SuppressedChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Field         string                 `protobuf:"bytes,4,opt,name=field,proto3" json:"field,omitempty"`
+	Detail        string                 `protobuf:"bytes,5,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuppressedChange) Reset() {
+	*x = SuppressedChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuppressedChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuppressedChange) ProtoMessage() {}
+
+func (x *SuppressedChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuppressedChange.ProtoReflect.Descriptor instead.
+func (*SuppressedChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SuppressedChange) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *SuppressedChange) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *SuppressedChange) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *SuppressedChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *SuppressedChange) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+// DiffReport contains the structured differences between two snapshots.
+type DiffReport struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// This will be expanded with structured fields for ports, services, CVEs, etc.
+	// For now, we'll use a simple text representation.
+	Summary            string               `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
+	OsChanges          *OSChange            `protobuf:"bytes,2,opt,name=os_changes,json=osChanges,proto3" json:"os_changes,omitempty"`
+	AddedPorts         []*PortChange        `protobuf:"bytes,3,rep,name=added_ports,json=addedPorts,proto3" json:"added_ports,omitempty"`
+	RemovedPorts       []*PortChange        `protobuf:"bytes,4,rep,name=removed_ports,json=removedPorts,proto3" json:"removed_ports,omitempty"`
+	ChangedPorts       []*PortChange        `protobuf:"bytes,5,rep,name=changed_ports,json=changedPorts,proto3" json:"changed_ports,omitempty"`
+	AddedServices      []*ServiceChange     `protobuf:"bytes,6,rep,name=added_services,json=addedServices,proto3" json:"added_services,omitempty"`
+	RemovedServices    []*ServiceChange     `protobuf:"bytes,7,rep,name=removed_services,json=removedServices,proto3" json:"removed_services,omitempty"`
+	ChangedServices    []*ServiceChange     `protobuf:"bytes,8,rep,name=changed_services,json=changedServices,proto3" json:"changed_services,omitempty"`
+	AddedCves          []*CVEChange         `protobuf:"bytes,9,rep,name=added_cves,json=addedCves,proto3" json:"added_cves,omitempty"`
+	RemovedCves        []*CVEChange         `protobuf:"bytes,10,rep,name=removed_cves,json=removedCves,proto3" json:"removed_cves,omitempty"`
+	CertificateChanges []*CertificateChange `protobuf:"bytes,11,rep,name=certificate_changes,json=certificateChanges,proto3" json:"certificate_changes,omitempty"`
+	FieldChanges       []*FieldChange       `protobuf:"bytes,12,rep,name=field_changes,json=fieldChanges,proto3" json:"field_changes,omitempty"`
+	VersionChanges     []*VersionChange     `protobuf:"bytes,13,rep,name=version_changes,json=versionChanges,proto3" json:"version_changes,omitempty"`
+	// True when any software version moved to an older release.
+	HasSoftwareDowngrade bool `protobuf:"varint,14,opt,name=has_software_downgrade,json=hasSoftwareDowngrade,proto3" json:"has_software_downgrade,omitempty"`
+	// Changes hidden by suppression rules, so nothing disappears silently.
+	SuppressedChanges []*SuppressedChange `protobuf:"bytes,15,rep,name=suppressed_changes,json=suppressedChanges,proto3" json:"suppressed_changes,omitempty"`
+	SuppressedCount   int32               `protobuf:"varint,16,opt,name=suppressed_count,json=suppressedCount,proto3" json:"suppressed_count,omitempty"`
+	// Hex SHA-256 of the report's changes in canonical order. Identical diffs
+	// share a hash, so they can be deduplicated.
+	ContentHash string `protobuf:"bytes,17,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	// Services that moved to a different port, instead of being reported as
+	// one removed and one added port.
+	MovedServices []*ServiceMove `protobuf:"bytes,18,rep,name=moved_services,json=movedServices,proto3" json:"moved_services,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffReport) Reset() {
+	*x = DiffReport{}
+	mi := &file_proto_host_diff_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffReport) ProtoMessage() {}
+
+func (x *DiffReport) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffReport.ProtoReflect.Descriptor instead.
+func (*DiffReport) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DiffReport) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *DiffReport) GetOsChanges() *OSChange {
+	if x != nil {
+		return x.OsChanges
+	}
+	return nil
+}
+
+func (x *DiffReport) GetAddedPorts() []*PortChange {
+	if x != nil {
+		return x.AddedPorts
+	}
+	return nil
+}
+
+func (x *DiffReport) GetRemovedPorts() []*PortChange {
+	if x != nil {
+		return x.RemovedPorts
+	}
+	return nil
+}
+
+func (x *DiffReport) GetChangedPorts() []*PortChange {
+	if x != nil {
+		return x.ChangedPorts
+	}
+	return nil
+}
+
+func (x *DiffReport) GetAddedServices() []*ServiceChange {
+	if x != nil {
+		return x.AddedServices
+	}
+	return nil
+}
+
+func (x *DiffReport) GetRemovedServices() []*ServiceChange {
+	if x != nil {
+		return x.RemovedServices
+	}
+	return nil
+}
+
+func (x *DiffReport) GetChangedServices() []*ServiceChange {
+	if x != nil {
+		return x.ChangedServices
+	}
+	return nil
+}
+
+func (x *DiffReport) GetAddedCves() []*CVEChange {
+	if x != nil {
+		return x.AddedCves
+	}
+	return nil
+}
+
+func (x *DiffReport) GetRemovedCves() []*CVEChange {
+	if x != nil {
+		return x.RemovedCves
+	}
+	return nil
+}
+
+func (x *DiffReport) GetCertificateChanges() []*CertificateChange {
+	if x != nil {
+		return x.CertificateChanges
+	}
+	return nil
+}
+
+func (x *DiffReport) GetFieldChanges() []*FieldChange {
+	if x != nil {
+		return x.FieldChanges
+	}
+	return nil
+}
+
+func (x *DiffReport) GetVersionChanges() []*VersionChange {
+	if x != nil {
+		return x.VersionChanges
+	}
+	return nil
+}
+
+func (x *DiffReport) GetHasSoftwareDowngrade() bool {
+	if x != nil {
+		return x.HasSoftwareDowngrade
+	}
+	return false
+}
+
+func (x *DiffReport) GetSuppressedChanges() []*SuppressedChange {
+	if x != nil {
+		return x.SuppressedChanges
+	}
+	return nil
+}
+
+func (x *DiffReport) GetSuppressedCount() int32 {
+	if x != nil {
+		return x.SuppressedCount
+	}
+	return 0
+}
+
+func (x *DiffReport) GetContentHash() string {
+	if x != nil {
+		return x.ContentHash
+	}
+	return ""
+}
+
+func (x *DiffReport) GetMovedServices() []*ServiceMove {
+	if x != nil {
+		return x.MovedServices
+	}
+	return nil
+}
+
+// ServiceMove is a service that disappeared from one port and reappeared on
+// another with the same certificate or software identity.
+type ServiceMove struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	OldPort  int32                  `protobuf:"varint,1,opt,name=old_port,json=oldPort,proto3" json:"old_port,omitempty"`
+	NewPort  int32                  `protobuf:"varint,2,opt,name=new_port,json=newPort,proto3" json:"new_port,omitempty"`
+	Protocol string                 `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// Signals the match was based on: "tls_fingerprint" and/or "software".
+	MatchedOn     []string `protobuf:"bytes,4,rep,name=matched_on,json=matchedOn,proto3" json:"matched_on,omitempty"`
+	Product       string   `protobuf:"bytes,5,opt,name=product,proto3" json:"product,omitempty"`
+	Version       string   `protobuf:"bytes,6,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceMove) Reset() {
+	*x = ServiceMove{}
+	mi := &file_proto_host_diff_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceMove) ProtoMessage() {}
+
+func (x *ServiceMove) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceMove.ProtoReflect.Descriptor instead.
+func (*ServiceMove) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ServiceMove) GetOldPort() int32 {
+	if x != nil {
+		return x.OldPort
+	}
+	return 0
+}
+
+func (x *ServiceMove) GetNewPort() int32 {
+	if x != nil {
+		return x.NewPort
+	}
+	return 0
+}
+
+func (x *ServiceMove) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ServiceMove) GetMatchedOn() []string {
+	if x != nil {
+		return x.MatchedOn
+	}
+	return nil
+}
+
+func (x *ServiceMove) GetProduct() string {
+	if x != nil {
+		return x.Product
+	}
+	return ""
+}
+
+func (x *ServiceMove) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// PortChange describes a port that was opened, closed or changed. States are
+// "open" or "closed"; services are the software label, or the protocol when
+// the scanner didn't identify any software.
+type PortChange struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Port       int32                  `protobuf:"varint,1,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol   string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	OldState   string                 `protobuf:"bytes,3,opt,name=old_state,json=oldState,proto3" json:"old_state,omitempty"`
+	NewState   string                 `protobuf:"bytes,4,opt,name=new_state,json=newState,proto3" json:"new_state,omitempty"`
+	OldService string                 `protobuf:"bytes,5,opt,name=old_service,json=oldService,proto3" json:"old_service,omitempty"`
+	NewService string                 `protobuf:"bytes,6,opt,name=new_service,json=newService,proto3" json:"new_service,omitempty"`
+	Changes    map[string]string      `protobuf:"bytes,7,rep,name=changes,proto3" json:"changes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Old and new value of each entry in changes.
+	Attributes    []*AttributeChange `protobuf:"bytes,8,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	OldSoftware   *Software          `protobuf:"bytes,9,opt,name=old_software,json=oldSoftware,proto3" json:"old_software,omitempty"`
+	NewSoftware   *Software          `protobuf:"bytes,10,opt,name=new_software,json=newSoftware,proto3" json:"new_software,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortChange) Reset() {
+	*x = PortChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortChange) ProtoMessage() {}
+
+func (x *PortChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortChange.ProtoReflect.Descriptor instead.
+func (*PortChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PortChange) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *PortChange) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *PortChange) GetOldState() string {
+	if x != nil {
+		return x.OldState
+	}
+	return ""
+}
+
+func (x *PortChange) GetNewState() string {
+	if x != nil {
+		return x.NewState
+	}
+	return ""
+}
+
+func (x *PortChange) GetOldService() string {
+	if x != nil {
+		return x.OldService
+	}
+	return ""
+}
+
+func (x *PortChange) GetNewService() string {
+	if x != nil {
+		return x.NewService
+	}
+	return ""
+}
+
+func (x *PortChange) GetChanges() map[string]string {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *PortChange) GetAttributes() []*AttributeChange {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *PortChange) GetOldSoftware() *Software {
+	if x != nil {
+		return x.OldSoftware
+	}
+	return nil
+}
+
+func (x *PortChange) GetNewSoftware() *Software {
+	if x != nil {
+		return x.NewSoftware
+	}
+	return nil
+}
+
+// ServiceChange describes a service that was added, removed or changed.
+type ServiceChange struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Name       string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	OldVersion string                 `protobuf:"bytes,2,opt,name=old_version,json=oldVersion,proto3" json:"old_version,omitempty"`
+	NewVersion string                 `protobuf:"bytes,3,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	Changes    map[string]string      `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Port       int32                  `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol   string                 `protobuf:"bytes,6,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// Old and new value of each entry in changes.
+	Attributes    []*AttributeChange `protobuf:"bytes,7,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceChange) Reset() {
+	*x = ServiceChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceChange) ProtoMessage() {}
+
+func (x *ServiceChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceChange.ProtoReflect.Descriptor instead.
+func (*ServiceChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ServiceChange) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ServiceChange) GetOldVersion() string {
+	if x != nil {
+		return x.OldVersion
+	}
+	return ""
+}
+
+func (x *ServiceChange) GetNewVersion() string {
+	if x != nil {
+		return x.NewVersion
+	}
+	return ""
+}
+
+func (x *ServiceChange) GetChanges() map[string]string {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ServiceChange) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ServiceChange) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ServiceChange) GetAttributes() []*AttributeChange {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+// AttributeChange is the old and new value of a single service attribute.
+// A value absent on one side is empty.
+type AttributeChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	OldValue      string                 `protobuf:"bytes,2,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue      string                 `protobuf:"bytes,3,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttributeChange) Reset() {
+	*x = AttributeChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeChange) ProtoMessage() {}
+
+func (x *AttributeChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeChange.ProtoReflect.Descriptor instead.
+func (*AttributeChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AttributeChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *AttributeChange) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *AttributeChange) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+// Software identifies the software running on a port.
+type Software struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vendor        string                 `protobuf:"bytes,1,opt,name=vendor,proto3" json:"vendor,omitempty"`
+	Product       string                 `protobuf:"bytes,2,opt,name=product,proto3" json:"product,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Software) Reset() {
+	*x = Software{}
+	mi := &file_proto_host_diff_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Software) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Software) ProtoMessage() {}
+
+func (x *Software) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Software.ProtoReflect.Descriptor instead.
+func (*Software) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Software) GetVendor() string {
+	if x != nil {
+		return x.Vendor
+	}
+	return ""
+}
+
+func (x *Software) GetProduct() string {
+	if x != nil {
+		return x.Product
+	}
+	return ""
+}
+
+func (x *Software) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type CVEChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CveId         string                 `protobuf:"bytes,1,opt,name=cve_id,json=cveId,proto3" json:"cve_id,omitempty"`
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CVEChange) Reset() {
+	*x = CVEChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CVEChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CVEChange) ProtoMessage() {}
+
+func (x *CVEChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CVEChange.ProtoReflect.Descriptor instead.
+func (*CVEChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CVEChange) GetCveId() string {
+	if x != nil {
+		return x.CveId
+	}
+	return ""
+}
+
+func (x *CVEChange) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *CVEChange) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+// CertificateChange describes a change to one attribute of a service's TLS certificate.
+type CertificateChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          int32                  `protobuf:"varint,1,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Field         CertificateField       `protobuf:"varint,3,opt,name=field,proto3,enum=hostdiff.CertificateField" json:"field,omitempty"`
+	OldValue      string                 `protobuf:"bytes,4,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue      string                 `protobuf:"bytes,5,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CertificateChange) Reset() {
+	*x = CertificateChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CertificateChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CertificateChange) ProtoMessage() {}
+
+func (x *CertificateChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CertificateChange.ProtoReflect.Descriptor instead.
+func (*CertificateChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CertificateChange) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *CertificateChange) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *CertificateChange) GetField() CertificateField {
+	if x != nil {
+		return x.Field
+	}
+	return CertificateField_CERTIFICATE_FIELD_UNSPECIFIED
+}
+
+func (x *CertificateChange) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *CertificateChange) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+// FieldChange describes a change in a scanner field that has no typed counterpart.
+// The path is a JSON pointer relative to the service object, and the values are
+// compact JSON encodings.
+type FieldChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          int32                  `protobuf:"varint,1,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Kind          FieldChangeKind        `protobuf:"varint,4,opt,name=kind,proto3,enum=hostdiff.FieldChangeKind" json:"kind,omitempty"`
+	OldValue      string                 `protobuf:"bytes,5,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue      string                 `protobuf:"bytes,6,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldChange) Reset() {
+	*x = FieldChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldChange) ProtoMessage() {}
+
+func (x *FieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldChange.ProtoReflect.Descriptor instead.
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *FieldChange) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *FieldChange) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *FieldChange) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FieldChange) GetKind() FieldChangeKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FieldChangeKind_FIELD_CHANGE_KIND_UNSPECIFIED
+}
+
+func (x *FieldChange) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *FieldChange) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+// VersionChange describes a classified software version change on a service.
+type VersionChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          int32                  `protobuf:"varint,1,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Product       string                 `protobuf:"bytes,3,opt,name=product,proto3" json:"product,omitempty"`
+	OldVersion    string                 `protobuf:"bytes,4,opt,name=old_version,json=oldVersion,proto3" json:"old_version,omitempty"`
+	NewVersion    string                 `protobuf:"bytes,5,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	Kind          VersionChangeKind      `protobuf:"varint,6,opt,name=kind,proto3,enum=hostdiff.VersionChangeKind" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionChange) Reset() {
+	*x = VersionChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionChange) ProtoMessage() {}
+
+func (x *VersionChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionChange.ProtoReflect.Descriptor instead.
+func (*VersionChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *VersionChange) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *VersionChange) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *VersionChange) GetProduct() string {
+	if x != nil {
+		return x.Product
+	}
+	return ""
+}
+
+func (x *VersionChange) GetOldVersion() string {
+	if x != nil {
+		return x.OldVersion
+	}
+	return ""
+}
+
+func (x *VersionChange) GetNewVersion() string {
+	if x != nil {
+		return x.NewVersion
+	}
+	return ""
+}
+
+func (x *VersionChange) GetKind() VersionChangeKind {
+	if x != nil {
+		return x.Kind
+	}
+	return VersionChangeKind_VERSION_CHANGE_KIND_UNSPECIFIED
+}
+
+type OSChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Oldname       string                 `protobuf:"bytes,1,opt,name=oldname,proto3" json:"oldname,omitempty"`
+	Newname       string                 `protobuf:"bytes,2,opt,name=newname,proto3" json:"newname,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OSChange) Reset() {
+	*x = OSChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OSChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSChange) ProtoMessage() {}
+
+func (x *OSChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSChange.ProtoReflect.Descriptor instead.
+func (*OSChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *OSChange) GetOldname() string {
+	if x != nil {
+		return x.Oldname
+	}
+	return ""
+}
+
+func (x *OSChange) GetNewname() string {
+	if x != nil {
+		return x.Newname
+	}
+	return ""
+}
+
+// ScoredChange is a single change from a diff report with its weight and severity.
+type ScoredChange struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Type     string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Port     int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol string                 `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Detail   string                 `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
+	Weight   float64                `protobuf:"fixed64,5,opt,name=weight,proto3" json:"weight,omitempty"`
+	Severity Severity               `protobuf:"varint,6,opt,name=severity,proto3,enum=hostdiff.Severity" json:"severity,omitempty"`
+	// Field name the change was matched against by suppression rules.
+	Field string `protobuf:"bytes,7,opt,name=field,proto3" json:"field,omitempty"`
+	// Name of the rule that capped this change's severity, if any.
+	DowngradedBy  string `protobuf:"bytes,8,opt,name=downgraded_by,json=downgradedBy,proto3" json:"downgraded_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoredChange) Reset() {
+	*x = ScoredChange{}
+	mi := &file_proto_host_diff_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoredChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoredChange) ProtoMessage() {}
+
+func (x *ScoredChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoredChange.ProtoReflect.Descriptor instead.
+func (*ScoredChange) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ScoredChange) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ScoredChange) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ScoredChange) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ScoredChange) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *ScoredChange) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *ScoredChange) GetSeverity() Severity {
+	if x != nil {
+		return x.Severity
+	}
+	return Severity_SEVERITY_UNSPECIFIED
+}
+
+func (x *ScoredChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *ScoredChange) GetDowngradedBy() string {
+	if x != nil {
+		return x.DowngradedBy
+	}
+	return ""
+}
+
+// RiskScore is the overall host-change risk assessment of a diff report.
+type RiskScore struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sum of all change weights, capped at the configured maximum.
+	Score float64 `protobuf:"fixed64,1,opt,name=score,proto3" json:"score,omitempty"`
+	// Highest severity of any single change.
+	Severity      Severity        `protobuf:"varint,2,opt,name=severity,proto3,enum=hostdiff.Severity" json:"severity,omitempty"`
+	Changes       []*ScoredChange `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RiskScore) Reset() {
+	*x = RiskScore{}
+	mi := &file_proto_host_diff_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RiskScore) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RiskScore) ProtoMessage() {}
+
+func (x *RiskScore) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RiskScore.ProtoReflect.Descriptor instead.
+func (*RiskScore) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RiskScore) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RiskScore) GetSeverity() Severity {
+	if x != nil {
+		return x.Severity
+	}
+	return Severity_SEVERITY_UNSPECIFIED
+}
+
+func (x *RiskScore) GetChanges() []*ScoredChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type CompareSnapshotsResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Report *DiffReport            `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+	Risk   *RiskScore             `protobuf:"bytes,2,opt,name=risk,proto3" json:"risk,omitempty"`
+	// Set when the request asked for an export format.
+	Rendered      *RenderedReport `protobuf:"bytes,3,opt,name=rendered,proto3" json:"rendered,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareSnapshotsResponse) Reset() {
+	*x = CompareSnapshotsResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareSnapshotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareSnapshotsResponse) ProtoMessage() {}
+
+func (x *CompareSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*CompareSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CompareSnapshotsResponse) GetReport() *DiffReport {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+func (x *CompareSnapshotsResponse) GetRisk() *RiskScore {
+	if x != nil {
+		return x.Risk
+	}
+	return nil
+}
+
+func (x *CompareSnapshotsResponse) GetRendered() *RenderedReport {
+	if x != nil {
+		return x.Rendered
+	}
+	return nil
+}
+
+// GetHostTimeline: Diffs each consecutive pair of snapshots for a host.
+type GetHostTimelineRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	IpAddress string                 `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	// Optional inclusive time window in ISO-8601 format (e.g. 2025-09-10T03:00:00Z).
+	StartTime     string `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       string `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHostTimelineRequest) Reset() {
+	*x = GetHostTimelineRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHostTimelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHostTimelineRequest) ProtoMessage() {}
+
+func (x *GetHostTimelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHostTimelineRequest.ProtoReflect.Descriptor instead.
+func (*GetHostTimelineRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetHostTimelineRequest) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *GetHostTimelineRequest) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *GetHostTimelineRequest) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+// TimelineEntry is the diff between two consecutive snapshots.
+type TimelineEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *SnapshotInfo          `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *SnapshotInfo          `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Report        *DiffReport            `protobuf:"bytes,3,opt,name=report,proto3" json:"report,omitempty"`
+	Risk          *RiskScore             `protobuf:"bytes,4,opt,name=risk,proto3" json:"risk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimelineEntry) Reset() {
+	*x = TimelineEntry{}
+	mi := &file_proto_host_diff_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimelineEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimelineEntry) ProtoMessage() {}
+
+func (x *TimelineEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimelineEntry.ProtoReflect.Descriptor instead.
+func (*TimelineEntry) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *TimelineEntry) GetFrom() *SnapshotInfo {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *TimelineEntry) GetTo() *SnapshotInfo {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *TimelineEntry) GetReport() *DiffReport {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+func (x *TimelineEntry) GetRisk() *RiskScore {
+	if x != nil {
+		return x.Risk
+	}
+	return nil
+}
+
+type GetHostTimelineResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Snapshots in the window, oldest first.
+	Snapshots     []*SnapshotInfo  `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	Entries       []*TimelineEntry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHostTimelineResponse) Reset() {
+	*x = GetHostTimelineResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHostTimelineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHostTimelineResponse) ProtoMessage() {}
+
+func (x *GetHostTimelineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHostTimelineResponse.ProtoReflect.Descriptor instead.
+func (*GetHostTimelineResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetHostTimelineResponse) GetSnapshots() []*SnapshotInfo {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+func (x *GetHostTimelineResponse) GetEntries() []*TimelineEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// QueryServices: Finds services across hosts. Every filter is optional and
+// string filters are case-insensitive.
+type QueryServicesRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Port     int32                  `protobuf:"varint,1,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Product  string                 `protobuf:"bytes,3,opt,name=product,proto3" json:"product,omitempty"`
+	// Inclusive software version bounds. Services whose version can't be
+	// parsed never match a range.
+	MinVersion string `protobuf:"bytes,4,opt,name=min_version,json=minVersion,proto3" json:"min_version,omitempty"`
+	MaxVersion string `protobuf:"bytes,5,opt,name=max_version,json=maxVersion,proto3" json:"max_version,omitempty"`
+	CveId      string `protobuf:"bytes,6,opt,name=cve_id,json=cveId,proto3" json:"cve_id,omitempty"`
+	// Only search each host's most recent snapshot, e.g. to find hosts that
+	// currently have a port open.
+	LatestOnly bool `protobuf:"varint,7,opt,name=latest_only,json=latestOnly,proto3" json:"latest_only,omitempty"`
+	// Results per page; defaults to 100, at most 1000.
+	PageSize int32 `protobuf:"varint,8,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token from the previous response.
+	PageToken     string `protobuf:"bytes,9,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryServicesRequest) Reset() {
+	*x = QueryServicesRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryServicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryServicesRequest) ProtoMessage() {}
+
+func (x *QueryServicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryServicesRequest.ProtoReflect.Descriptor instead.
+func (*QueryServicesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *QueryServicesRequest) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *QueryServicesRequest) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *QueryServicesRequest) GetProduct() string {
+	if x != nil {
+		return x.Product
+	}
+	return ""
+}
+
+func (x *QueryServicesRequest) GetMinVersion() string {
+	if x != nil {
+		return x.MinVersion
+	}
+	return ""
+}
+
+func (x *QueryServicesRequest) GetMaxVersion() string {
+	if x != nil {
+		return x.MaxVersion
+	}
+	return ""
+}
+
+func (x *QueryServicesRequest) GetCveId() string {
+	if x != nil {
+		return x.CveId
+	}
+	return ""
+}
+
+func (x *QueryServicesRequest) GetLatestOnly() bool {
+	if x != nil {
+		return x.LatestOnly
+	}
+	return false
+}
+
+func (x *QueryServicesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *QueryServicesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// ServiceRecord is a service found by QueryServices. Protocol and CVE IDs
+// are upper case, vendor and product lower case.
+type ServiceRecord struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SnapshotId    string                 `protobuf:"bytes,1,opt,name=snapshot_id,json=snapshotId,proto3" json:"snapshot_id,omitempty"`
 	IpAddress     string                 `protobuf:"bytes,2,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
 	Timestamp     string                 `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Port          int32                  `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,5,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Software      *Software              `protobuf:"bytes,6,opt,name=software,proto3" json:"software,omitempty"`
+	CveIds        []string               `protobuf:"bytes,7,rep,name=cve_ids,json=cveIds,proto3" json:"cve_ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SnapshotInfo) Reset() {
-	*x = SnapshotInfo{}
-	mi := &file_proto_host_diff_proto_msgTypes[0]
+func (x *ServiceRecord) Reset() {
+	*x = ServiceRecord{}
+	mi := &file_proto_host_diff_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SnapshotInfo) String() string {
+func (x *ServiceRecord) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SnapshotInfo) ProtoMessage() {}
+func (*ServiceRecord) ProtoMessage() {}
 
-func (x *SnapshotInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[0]
+func (x *ServiceRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -56,58 +3501,84 @@ func (x *SnapshotInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SnapshotInfo.ProtoReflect.Descriptor instead.
-func (*SnapshotInfo) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use ServiceRecord.ProtoReflect.Descriptor instead.
+func (*ServiceRecord) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *SnapshotInfo) GetId() string {
+func (x *ServiceRecord) GetSnapshotId() string {
 	if x != nil {
-		return x.Id
+		return x.SnapshotId
 	}
 	return ""
 }
 
-func (x *SnapshotInfo) GetIpAddress() string {
+func (x *ServiceRecord) GetIpAddress() string {
 	if x != nil {
 		return x.IpAddress
 	}
 	return ""
 }
 
-func (x *SnapshotInfo) GetTimestamp() string {
+func (x *ServiceRecord) GetTimestamp() string {
 	if x != nil {
 		return x.Timestamp
 	}
 	return ""
 }
 
-// UploadSnapshot: Allows uploading a snapshot JSON file.
-type UploadSnapshotRequest struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// The raw content of the JSON file.
-	FileContent []byte `protobuf:"bytes,1,opt,name=file_content,json=fileContent,proto3" json:"file_content,omitempty"`
-	// The original filename, used to extract metadata if needed.
-	Filename      string `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+func (x *ServiceRecord) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ServiceRecord) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ServiceRecord) GetSoftware() *Software {
+	if x != nil {
+		return x.Software
+	}
+	return nil
+}
+
+func (x *ServiceRecord) GetCveIds() []string {
+	if x != nil {
+		return x.CveIds
+	}
+	return nil
+}
+
+type QueryServicesResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Services []*ServiceRecord       `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadSnapshotRequest) Reset() {
-	*x = UploadSnapshotRequest{}
-	mi := &file_proto_host_diff_proto_msgTypes[1]
+func (x *QueryServicesResponse) Reset() {
+	*x = QueryServicesResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadSnapshotRequest) String() string {
+func (x *QueryServicesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadSnapshotRequest) ProtoMessage() {}
+func (*QueryServicesResponse) ProtoMessage() {}
 
-func (x *UploadSnapshotRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[1]
+func (x *QueryServicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -118,52 +3589,56 @@ func (x *UploadSnapshotRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadSnapshotRequest.ProtoReflect.Descriptor instead.
-func (*UploadSnapshotRequest) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use QueryServicesResponse.ProtoReflect.Descriptor instead.
+func (*QueryServicesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *UploadSnapshotRequest) GetFileContent() []byte {
+func (x *QueryServicesResponse) GetServices() []*ServiceRecord {
 	if x != nil {
-		return x.FileContent
+		return x.Services
 	}
 	return nil
 }
 
-func (x *UploadSnapshotRequest) GetFilename() string {
+func (x *QueryServicesResponse) GetNextPageToken() string {
 	if x != nil {
-		return x.Filename
+		return x.NextPageToken
 	}
 	return ""
 }
 
-type UploadSnapshotResponse struct {
+// QueryFleet: Finds the state of a range of hosts.
+type QueryFleetRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The ID of the newly created snapshot record.
-	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	// The IP address associated with the snapshot.
-	IpAddress string `protobuf:"bytes,2,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
-	// The timestamp of the snapshot.
-	Timestamp     string `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// A CIDR block or an inclusive range, as in GetHostHistoryRequest.
+	IpRange string `protobuf:"bytes,1,opt,name=ip_range,json=ipRange,proto3" json:"ip_range,omitempty"`
+	// Optional RFC 3339 time. When set, each host's newest snapshot at or
+	// before it is returned, and hosts first seen later are left out.
+	AsOf string `protobuf:"bytes,2,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	// Results per page; defaults to 100, at most 1000.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token from the previous response.
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadSnapshotResponse) Reset() {
-	*x = UploadSnapshotResponse{}
-	mi := &file_proto_host_diff_proto_msgTypes[2]
+func (x *QueryFleetRequest) Reset() {
+	*x = QueryFleetRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadSnapshotResponse) String() string {
+func (x *QueryFleetRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadSnapshotResponse) ProtoMessage() {}
+func (*QueryFleetRequest) ProtoMessage() {}
 
-func (x *UploadSnapshotResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[2]
+func (x *QueryFleetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -174,55 +3649,64 @@ func (x *UploadSnapshotResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadSnapshotResponse.ProtoReflect.Descriptor instead.
-func (*UploadSnapshotResponse) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use QueryFleetRequest.ProtoReflect.Descriptor instead.
+func (*QueryFleetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *UploadSnapshotResponse) GetId() string {
+func (x *QueryFleetRequest) GetIpRange() string {
 	if x != nil {
-		return x.Id
+		return x.IpRange
 	}
 	return ""
 }
 
-func (x *UploadSnapshotResponse) GetIpAddress() string {
+func (x *QueryFleetRequest) GetAsOf() string {
 	if x != nil {
-		return x.IpAddress
+		return x.AsOf
 	}
 	return ""
 }
 
-func (x *UploadSnapshotResponse) GetTimestamp() string {
+func (x *QueryFleetRequest) GetPageSize() int32 {
 	if x != nil {
-		return x.Timestamp
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *QueryFleetRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
 	}
 	return ""
 }
 
-// GetHostHistory: Retrieves all snapshots for a specific host.
-type GetHostHistoryRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	IpAddress     string                 `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+type QueryFleetResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One snapshot per host, ordered by IP address with IPv4 hosts first.
+	Snapshots []*SnapshotInfo `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetHostHistoryRequest) Reset() {
-	*x = GetHostHistoryRequest{}
-	mi := &file_proto_host_diff_proto_msgTypes[3]
+func (x *QueryFleetResponse) Reset() {
+	*x = QueryFleetResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetHostHistoryRequest) String() string {
+func (x *QueryFleetResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetHostHistoryRequest) ProtoMessage() {}
+func (*QueryFleetResponse) ProtoMessage() {}
 
-func (x *GetHostHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[3]
+func (x *QueryFleetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -233,40 +3717,138 @@ func (x *GetHostHistoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetHostHistoryRequest.ProtoReflect.Descriptor instead.
-func (*GetHostHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use QueryFleetResponse.ProtoReflect.Descriptor instead.
+func (*QueryFleetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *GetHostHistoryRequest) GetIpAddress() string {
+func (x *QueryFleetResponse) GetSnapshots() []*SnapshotInfo {
 	if x != nil {
-		return x.IpAddress
+		return x.Snapshots
+	}
+	return nil
+}
+
+func (x *QueryFleetResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
 	}
 	return ""
 }
 
-type GetHostHistoryResponse struct {
+// ListHosts: Lists known hosts. ip_prefix and cidr are optional and combine.
+type ListHostsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only hosts whose IP address starts with this text, e.g. "10.1.".
+	IpPrefix string `protobuf:"bytes,1,opt,name=ip_prefix,json=ipPrefix,proto3" json:"ip_prefix,omitempty"`
+	// Only hosts inside this network, e.g. "10.0.0.0/8" or "2001:db8::/32".
+	Cidr  string    `protobuf:"bytes,2,opt,name=cidr,proto3" json:"cidr,omitempty"`
+	Order HostOrder `protobuf:"varint,3,opt,name=order,proto3,enum=hostdiff.HostOrder" json:"order,omitempty"`
+	// Results per page; defaults to 100, at most 1000.
+	PageSize int32 `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token from the previous response, made with the same filters
+	// and order.
+	PageToken     string `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHostsRequest) Reset() {
+	*x = ListHostsRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHostsRequest) ProtoMessage() {}
+
+func (x *ListHostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHostsRequest.ProtoReflect.Descriptor instead.
+func (*ListHostsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ListHostsRequest) GetIpPrefix() string {
+	if x != nil {
+		return x.IpPrefix
+	}
+	return ""
+}
+
+func (x *ListHostsRequest) GetCidr() string {
+	if x != nil {
+		return x.Cidr
+	}
+	return ""
+}
+
+func (x *ListHostsRequest) GetOrder() HostOrder {
+	if x != nil {
+		return x.Order
+	}
+	return HostOrder_HOST_ORDER_UNSPECIFIED
+}
+
+func (x *ListHostsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListHostsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// HostSummary describes a host and its most recent snapshot.
+type HostSummary struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Snapshots     []*SnapshotInfo        `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	IpAddress     string                 `protobuf:"bytes,1,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	SnapshotCount int32                  `protobuf:"varint,2,opt,name=snapshot_count,json=snapshotCount,proto3" json:"snapshot_count,omitempty"`
+	// Timestamps of the oldest and newest snapshot.
+	FirstSeen        string `protobuf:"bytes,3,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen         string `protobuf:"bytes,4,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	LatestSnapshotId string `protobuf:"bytes,5,opt,name=latest_snapshot_id,json=latestSnapshotId,proto3" json:"latest_snapshot_id,omitempty"`
+	// Distinct open ports and CVEs in the newest snapshot.
+	OpenPortCount int32 `protobuf:"varint,6,opt,name=open_port_count,json=openPortCount,proto3" json:"open_port_count,omitempty"`
+	CveCount      int32 `protobuf:"varint,7,opt,name=cve_count,json=cveCount,proto3" json:"cve_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetHostHistoryResponse) Reset() {
-	*x = GetHostHistoryResponse{}
-	mi := &file_proto_host_diff_proto_msgTypes[4]
+func (x *HostSummary) Reset() {
+	*x = HostSummary{}
+	mi := &file_proto_host_diff_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetHostHistoryResponse) String() string {
+func (x *HostSummary) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetHostHistoryResponse) ProtoMessage() {}
+func (*HostSummary) ProtoMessage() {}
 
-func (x *GetHostHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[4]
+func (x *HostSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -277,42 +3859,84 @@ func (x *GetHostHistoryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetHostHistoryResponse.ProtoReflect.Descriptor instead.
-func (*GetHostHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use HostSummary.ProtoReflect.Descriptor instead.
+func (*HostSummary) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *GetHostHistoryResponse) GetSnapshots() []*SnapshotInfo {
+func (x *HostSummary) GetIpAddress() string {
 	if x != nil {
-		return x.Snapshots
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *HostSummary) GetSnapshotCount() int32 {
+	if x != nil {
+		return x.SnapshotCount
+	}
+	return 0
+}
+
+func (x *HostSummary) GetFirstSeen() string {
+	if x != nil {
+		return x.FirstSeen
+	}
+	return ""
+}
+
+func (x *HostSummary) GetLastSeen() string {
+	if x != nil {
+		return x.LastSeen
+	}
+	return ""
+}
+
+func (x *HostSummary) GetLatestSnapshotId() string {
+	if x != nil {
+		return x.LatestSnapshotId
+	}
+	return ""
+}
+
+func (x *HostSummary) GetOpenPortCount() int32 {
+	if x != nil {
+		return x.OpenPortCount
+	}
+	return 0
+}
+
+func (x *HostSummary) GetCveCount() int32 {
+	if x != nil {
+		return x.CveCount
 	}
-	return nil
+	return 0
 }
 
-// CompareSnapshots: Requests a comparison between two snapshots.
-type CompareSnapshotsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SnapshotIdA   string                 `protobuf:"bytes,1,opt,name=snapshot_id_a,json=snapshotIdA,proto3" json:"snapshot_id_a,omitempty"`
-	SnapshotIdB   string                 `protobuf:"bytes,2,opt,name=snapshot_id_b,json=snapshotIdB,proto3" json:"snapshot_id_b,omitempty"`
+type ListHostsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Hosts []*HostSummary         `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CompareSnapshotsRequest) Reset() {
-	*x = CompareSnapshotsRequest{}
-	mi := &file_proto_host_diff_proto_msgTypes[5]
+func (x *ListHostsResponse) Reset() {
+	*x = ListHostsResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CompareSnapshotsRequest) String() string {
+func (x *ListHostsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CompareSnapshotsRequest) ProtoMessage() {}
+func (*ListHostsResponse) ProtoMessage() {}
 
-func (x *CompareSnapshotsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[5]
+func (x *ListHostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -323,59 +3947,53 @@ func (x *CompareSnapshotsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CompareSnapshotsRequest.ProtoReflect.Descriptor instead.
-func (*CompareSnapshotsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ListHostsResponse.ProtoReflect.Descriptor instead.
+func (*ListHostsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *CompareSnapshotsRequest) GetSnapshotIdA() string {
+func (x *ListHostsResponse) GetHosts() []*HostSummary {
 	if x != nil {
-		return x.SnapshotIdA
+		return x.Hosts
 	}
-	return ""
+	return nil
 }
 
-func (x *CompareSnapshotsRequest) GetSnapshotIdB() string {
+func (x *ListHostsResponse) GetNextPageToken() string {
 	if x != nil {
-		return x.SnapshotIdB
+		return x.NextPageToken
 	}
 	return ""
 }
 
-// DiffReport contains the structured differences between two snapshots.
-type DiffReport struct {
+// DeleteSnapshot: Soft-deletes a snapshot. Every delete, restore and purge
+// is recorded with its actor and reason.
+type DeleteSnapshotRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// This will be expanded with structured fields for ports, services, CVEs, etc.
-	// For now, we'll use a simple text representation.
-	Summary         string           `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
-	OsChanges       *OSChange        `protobuf:"bytes,2,opt,name=os_changes,json=osChanges,proto3" json:"os_changes,omitempty"`
-	AddedPorts      []*PortChange    `protobuf:"bytes,3,rep,name=added_ports,json=addedPorts,proto3" json:"added_ports,omitempty"`
-	RemovedPorts    []*PortChange    `protobuf:"bytes,4,rep,name=removed_ports,json=removedPorts,proto3" json:"removed_ports,omitempty"`
-	ChangedPorts    []*PortChange    `protobuf:"bytes,5,rep,name=changed_ports,json=changedPorts,proto3" json:"changed_ports,omitempty"`
-	AddedServices   []*ServiceChange `protobuf:"bytes,6,rep,name=added_services,json=addedServices,proto3" json:"added_services,omitempty"`
-	RemovedServices []*ServiceChange `protobuf:"bytes,7,rep,name=removed_services,json=removedServices,proto3" json:"removed_services,omitempty"`
-	ChangedServices []*ServiceChange `protobuf:"bytes,8,rep,name=changed_services,json=changedServices,proto3" json:"changed_services,omitempty"`
-	AddedCves       []*CVEChange     `protobuf:"bytes,9,rep,name=added_cves,json=addedCves,proto3" json:"added_cves,omitempty"`
-	RemovedCves     []*CVEChange     `protobuf:"bytes,10,rep,name=removed_cves,json=removedCves,proto3" json:"removed_cves,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Who is deleting the snapshot. Defaults to the client's address.
+	Actor string `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Why the snapshot is being deleted. Required.
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DiffReport) Reset() {
-	*x = DiffReport{}
-	mi := &file_proto_host_diff_proto_msgTypes[6]
+func (x *DeleteSnapshotRequest) Reset() {
+	*x = DeleteSnapshotRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiffReport) String() string {
+func (x *DeleteSnapshotRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiffReport) ProtoMessage() {}
+func (*DeleteSnapshotRequest) ProtoMessage() {}
 
-func (x *DiffReport) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[6]
+func (x *DeleteSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -386,109 +4004,101 @@ func (x *DiffReport) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiffReport.ProtoReflect.Descriptor instead.
-func (*DiffReport) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use DeleteSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *DiffReport) GetSummary() string {
+func (x *DeleteSnapshotRequest) GetId() string {
 	if x != nil {
-		return x.Summary
+		return x.Id
 	}
 	return ""
 }
 
-func (x *DiffReport) GetOsChanges() *OSChange {
+func (x *DeleteSnapshotRequest) GetActor() string {
 	if x != nil {
-		return x.OsChanges
+		return x.Actor
 	}
-	return nil
+	return ""
 }
 
-func (x *DiffReport) GetAddedPorts() []*PortChange {
+func (x *DeleteSnapshotRequest) GetReason() string {
 	if x != nil {
-		return x.AddedPorts
+		return x.Reason
 	}
-	return nil
+	return ""
 }
 
-func (x *DiffReport) GetRemovedPorts() []*PortChange {
-	if x != nil {
-		return x.RemovedPorts
-	}
-	return nil
+type DeleteSnapshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snapshot      *SnapshotInfo          `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DiffReport) GetChangedPorts() []*PortChange {
-	if x != nil {
-		return x.ChangedPorts
-	}
-	return nil
+func (x *DeleteSnapshotResponse) Reset() {
+	*x = DeleteSnapshotResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiffReport) GetAddedServices() []*ServiceChange {
-	if x != nil {
-		return x.AddedServices
-	}
-	return nil
+func (x *DeleteSnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *DiffReport) GetRemovedServices() []*ServiceChange {
-	if x != nil {
-		return x.RemovedServices
-	}
-	return nil
-}
+func (*DeleteSnapshotResponse) ProtoMessage() {}
 
-func (x *DiffReport) GetChangedServices() []*ServiceChange {
+func (x *DeleteSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[41]
 	if x != nil {
-		return x.ChangedServices
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *DiffReport) GetAddedCves() []*CVEChange {
-	if x != nil {
-		return x.AddedCves
-	}
-	return nil
+// Deprecated: Use DeleteSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *DiffReport) GetRemovedCves() []*CVEChange {
+func (x *DeleteSnapshotResponse) GetSnapshot() *SnapshotInfo {
 	if x != nil {
-		return x.RemovedCves
+		return x.Snapshot
 	}
 	return nil
 }
 
-type PortChange struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Port          int32                  `protobuf:"varint,1,opt,name=port,proto3" json:"port,omitempty"`
-	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
-	OldState      string                 `protobuf:"bytes,3,opt,name=old_state,json=oldState,proto3" json:"old_state,omitempty"`
-	NewState      string                 `protobuf:"bytes,4,opt,name=new_state,json=newState,proto3" json:"new_state,omitempty"`
-	OldService    string                 `protobuf:"bytes,5,opt,name=old_service,json=oldService,proto3" json:"old_service,omitempty"`
-	NewService    string                 `protobuf:"bytes,6,opt,name=new_service,json=newService,proto3" json:"new_service,omitempty"`
-	Changes       map[string]string      `protobuf:"bytes,7,rep,name=changes,proto3" json:"changes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+type RestoreSnapshotRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Who is restoring the snapshot. Defaults to the client's address.
+	Actor         string `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PortChange) Reset() {
-	*x = PortChange{}
-	mi := &file_proto_host_diff_proto_msgTypes[7]
+func (x *RestoreSnapshotRequest) Reset() {
+	*x = RestoreSnapshotRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PortChange) String() string {
+func (x *RestoreSnapshotRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PortChange) ProtoMessage() {}
+func (*RestoreSnapshotRequest) ProtoMessage() {}
 
-func (x *PortChange) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[7]
+func (x *RestoreSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -499,85 +4109,104 @@ func (x *PortChange) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PortChange.ProtoReflect.Descriptor instead.
-func (*PortChange) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use RestoreSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*RestoreSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *PortChange) GetPort() int32 {
+func (x *RestoreSnapshotRequest) GetId() string {
 	if x != nil {
-		return x.Port
+		return x.Id
 	}
-	return 0
+	return ""
 }
 
-func (x *PortChange) GetProtocol() string {
+func (x *RestoreSnapshotRequest) GetActor() string {
 	if x != nil {
-		return x.Protocol
+		return x.Actor
 	}
 	return ""
 }
 
-func (x *PortChange) GetOldState() string {
+func (x *RestoreSnapshotRequest) GetReason() string {
 	if x != nil {
-		return x.OldState
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *PortChange) GetNewState() string {
-	if x != nil {
-		return x.NewState
-	}
-	return ""
+type RestoreSnapshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snapshot      *SnapshotInfo          `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PortChange) GetOldService() string {
-	if x != nil {
-		return x.OldService
-	}
-	return ""
+func (x *RestoreSnapshotResponse) Reset() {
+	*x = RestoreSnapshotResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *PortChange) GetNewService() string {
+func (x *RestoreSnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreSnapshotResponse) ProtoMessage() {}
+
+func (x *RestoreSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[43]
 	if x != nil {
-		return x.NewService
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *PortChange) GetChanges() map[string]string {
+// Deprecated: Use RestoreSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*RestoreSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *RestoreSnapshotResponse) GetSnapshot() *SnapshotInfo {
 	if x != nil {
-		return x.Changes
+		return x.Snapshot
 	}
 	return nil
 }
 
-type ServiceChange struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	OldVersion    string                 `protobuf:"bytes,2,opt,name=old_version,json=oldVersion,proto3" json:"old_version,omitempty"`
-	NewVersion    string                 `protobuf:"bytes,3,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
-	Changes       map[string]string      `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+type PurgeDeletedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional RFC 3339 time: only snapshots deleted at or before it are
+	// purged. All deleted snapshots are purged when empty.
+	DeletedBefore string `protobuf:"bytes,1,opt,name=deleted_before,json=deletedBefore,proto3" json:"deleted_before,omitempty"`
+	// Who is purging. Defaults to the client's address.
+	Actor string `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Why the snapshots are being purged. Required.
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServiceChange) Reset() {
-	*x = ServiceChange{}
-	mi := &file_proto_host_diff_proto_msgTypes[8]
+func (x *PurgeDeletedRequest) Reset() {
+	*x = PurgeDeletedRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServiceChange) String() string {
+func (x *PurgeDeletedRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServiceChange) ProtoMessage() {}
+func (*PurgeDeletedRequest) ProtoMessage() {}
 
-func (x *ServiceChange) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[8]
+func (x *PurgeDeletedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -588,61 +4217,54 @@ func (x *ServiceChange) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServiceChange.ProtoReflect.Descriptor instead.
-func (*ServiceChange) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use PurgeDeletedRequest.ProtoReflect.Descriptor instead.
+func (*PurgeDeletedRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *ServiceChange) GetName() string {
+func (x *PurgeDeletedRequest) GetDeletedBefore() string {
 	if x != nil {
-		return x.Name
+		return x.DeletedBefore
 	}
 	return ""
 }
 
-func (x *ServiceChange) GetOldVersion() string {
+func (x *PurgeDeletedRequest) GetActor() string {
 	if x != nil {
-		return x.OldVersion
+		return x.Actor
 	}
 	return ""
 }
 
-func (x *ServiceChange) GetNewVersion() string {
+func (x *PurgeDeletedRequest) GetReason() string {
 	if x != nil {
-		return x.NewVersion
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *ServiceChange) GetChanges() map[string]string {
-	if x != nil {
-		return x.Changes
-	}
-	return nil
-}
-
-type CVEChange struct {
+type PurgeDeletedResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CveId         string                 `protobuf:"bytes,1,opt,name=cve_id,json=cveId,proto3" json:"cve_id,omitempty"`
+	Snapshots     []*SnapshotInfo        `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CVEChange) Reset() {
-	*x = CVEChange{}
-	mi := &file_proto_host_diff_proto_msgTypes[9]
+func (x *PurgeDeletedResponse) Reset() {
+	*x = PurgeDeletedResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CVEChange) String() string {
+func (x *PurgeDeletedResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CVEChange) ProtoMessage() {}
+func (*PurgeDeletedResponse) ProtoMessage() {}
 
-func (x *CVEChange) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[9]
+func (x *PurgeDeletedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -653,41 +4275,46 @@ func (x *CVEChange) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CVEChange.ProtoReflect.Descriptor instead.
-func (*CVEChange) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use PurgeDeletedResponse.ProtoReflect.Descriptor instead.
+func (*PurgeDeletedResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *CVEChange) GetCveId() string {
+func (x *PurgeDeletedResponse) GetSnapshots() []*SnapshotInfo {
 	if x != nil {
-		return x.CveId
+		return x.Snapshots
 	}
-	return ""
+	return nil
 }
 
-type OSChange struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Oldname       string                 `protobuf:"bytes,1,opt,name=oldname,proto3" json:"oldname,omitempty"`
-	Newname       string                 `protobuf:"bytes,2,opt,name=newname,proto3" json:"newname,omitempty"`
+type PruneSnapshotsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Retention policy such as "all:30d,daily:1y,monthly". Defaults to the
+	// server's RETENTION_POLICY.
+	Policy string `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+	// Only report what would be pruned.
+	DryRun bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Who is pruning. Defaults to the client's address.
+	Actor         string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *OSChange) Reset() {
-	*x = OSChange{}
-	mi := &file_proto_host_diff_proto_msgTypes[10]
+func (x *PruneSnapshotsRequest) Reset() {
+	*x = PruneSnapshotsRequest{}
+	mi := &file_proto_host_diff_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *OSChange) String() string {
+func (x *PruneSnapshotsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*OSChange) ProtoMessage() {}
+func (*PruneSnapshotsRequest) ProtoMessage() {}
 
-func (x *OSChange) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[10]
+func (x *PruneSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -698,47 +4325,55 @@ func (x *OSChange) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use OSChange.ProtoReflect.Descriptor instead.
-func (*OSChange) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use PruneSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*PruneSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *OSChange) GetOldname() string {
+func (x *PruneSnapshotsRequest) GetPolicy() string {
 	if x != nil {
-		return x.Oldname
+		return x.Policy
 	}
 	return ""
 }
 
-func (x *OSChange) GetNewname() string {
+func (x *PruneSnapshotsRequest) GetDryRun() bool {
 	if x != nil {
-		return x.Newname
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *PruneSnapshotsRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
 	}
 	return ""
 }
 
-type CompareSnapshotsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Report        *DiffReport            `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+type PruneSnapshotsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The snapshots pruned, or that would be with dry_run.
+	Snapshots     []*SnapshotInfo `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CompareSnapshotsResponse) Reset() {
-	*x = CompareSnapshotsResponse{}
-	mi := &file_proto_host_diff_proto_msgTypes[11]
+func (x *PruneSnapshotsResponse) Reset() {
+	*x = PruneSnapshotsResponse{}
+	mi := &file_proto_host_diff_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CompareSnapshotsResponse) String() string {
+func (x *PruneSnapshotsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CompareSnapshotsResponse) ProtoMessage() {}
+func (*PruneSnapshotsResponse) ProtoMessage() {}
 
-func (x *CompareSnapshotsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_host_diff_proto_msgTypes[11]
+func (x *PruneSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_host_diff_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -749,14 +4384,14 @@ func (x *CompareSnapshotsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CompareSnapshotsResponse.ProtoReflect.Descriptor instead.
-func (*CompareSnapshotsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_host_diff_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use PruneSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*PruneSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_host_diff_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *CompareSnapshotsResponse) GetReport() *DiffReport {
+func (x *PruneSnapshotsResponse) GetSnapshots() []*SnapshotInfo {
 	if x != nil {
-		return x.Report
+		return x.Snapshots
 	}
 	return nil
 }
@@ -765,28 +4400,99 @@ var File_proto_host_diff_proto protoreflect.FileDescriptor
 
 const file_proto_host_diff_proto_rawDesc = "" +
 	"\n" +
-	"\x15proto/host_diff.proto\x12\bhostdiff\"[\n" +
+	"\x15proto/host_diff.proto\x12\bhostdiff\"w\n" +
 	"\fSnapshotInfo\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
 	"\n" +
 	"ip_address\x18\x02 \x01(\tR\tipAddress\x12\x1c\n" +
-	"\ttimestamp\x18\x03 \x01(\tR\ttimestamp\"V\n" +
+	"\ttimestamp\x18\x03 \x01(\tR\ttimestamp\x12\x1a\n" +
+	"\brevision\x18\x04 \x01(\x05R\brevision\"\x99\x01\n" +
 	"\x15UploadSnapshotRequest\x12!\n" +
 	"\ffile_content\x18\x01 \x01(\fR\vfileContent\x12\x1a\n" +
-	"\bfilename\x18\x02 \x01(\tR\bfilename\"e\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12A\n" +
+	"\x0fconflict_policy\x18\x03 \x01(\x0e2\x18.hostdiff.ConflictPolicyR\x0econflictPolicy\"\x8c\x02\n" +
 	"\x16UploadSnapshotResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
 	"\n" +
 	"ip_address\x18\x02 \x01(\tR\tipAddress\x12\x1c\n" +
-	"\ttimestamp\x18\x03 \x01(\tR\ttimestamp\"6\n" +
+	"\ttimestamp\x18\x03 \x01(\tR\ttimestamp\x123\n" +
+	"\bwarnings\x18\x04 \x03(\v2\x17.hostdiff.UploadWarningR\bwarnings\x121\n" +
+	"\aoutcome\x18\x05 \x01(\x0e2\x17.hostdiff.UploadOutcomeR\aoutcome\x12!\n" +
+	"\freplaced_ids\x18\x06 \x03(\tR\vreplacedIds\x12\x1a\n" +
+	"\brevision\x18\a \x01(\x05R\brevision\"\xc0\x01\n" +
+	"\rUploadWarning\x12/\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x1b.hostdiff.UploadWarningCodeR\x04code\x12\x18\n" +
+	"\apointer\x18\x02 \x01(\tR\apointer\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12%\n" +
+	"\x0efilename_value\x18\x04 \x01(\tR\rfilenameValue\x12#\n" +
+	"\rcontent_value\x18\x05 \x01(\tR\fcontentValue\"n\n" +
+	"\x13UploadSnapshotChunk\x128\n" +
+	"\x06header\x18\x01 \x01(\v2\x1e.hostdiff.UploadSnapshotHeaderH\x00R\x06header\x12\x14\n" +
+	"\x04data\x18\x02 \x01(\fH\x00R\x04dataB\a\n" +
+	"\x05frame\"\xa1\x01\n" +
+	"\x14UploadSnapshotHeader\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12A\n" +
+	"\x0fconflict_policy\x18\x04 \x01(\x0e2\x18.hostdiff.ConflictPolicyR\x0econflictPolicy\"v\n" +
+	"\x11BulkUploadRequest\x12\x18\n" +
+	"\aarchive\x18\x01 \x01(\fR\aarchive\x12/\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x17.hostdiff.ArchiveFormatR\x06format\x12\x16\n" +
+	"\x06atomic\x18\x03 \x01(\bR\x06atomic\"\xfc\x01\n" +
+	"\x10BulkUploadResult\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x122\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x1a.hostdiff.BulkUploadStatusR\x06status\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x04 \x01(\tR\tipAddress\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\tR\ttimestamp\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x123\n" +
+	"\bwarnings\x18\a \x03(\v2\x17.hostdiff.UploadWarningR\bwarnings\"\xc0\x01\n" +
+	"\x12BulkUploadResponse\x124\n" +
+	"\aresults\x18\x01 \x03(\v2\x1a.hostdiff.BulkUploadResultR\aresults\x12\x1c\n" +
+	"\tcommitted\x18\x02 \x01(\bR\tcommitted\x12\x1a\n" +
+	"\binserted\x18\x03 \x01(\x05R\binserted\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\x04 \x01(\x05R\n" +
+	"duplicates\x12\x1a\n" +
+	"\brejected\x18\x05 \x01(\x05R\brejected\"\xf5\x01\n" +
 	"\x15GetHostHistoryRequest\x12\x1d\n" +
 	"\n" +
-	"ip_address\x18\x01 \x01(\tR\tipAddress\"N\n" +
+	"ip_address\x18\x01 \x01(\tR\tipAddress\x12\x19\n" +
+	"\bip_range\x18\x02 \x01(\tR\aipRange\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x06 \x01(\tR\aendTime\x12,\n" +
+	"\x05order\x18\a \x01(\x0e2\x16.hostdiff.HistoryOrderR\x05order\"v\n" +
 	"\x16GetHostHistoryResponse\x124\n" +
-	"\tsnapshots\x18\x01 \x03(\v2\x16.hostdiff.SnapshotInfoR\tsnapshots\"a\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x16.hostdiff.SnapshotInfoR\tsnapshots\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xc2\x01\n" +
 	"\x17CompareSnapshotsRequest\x12\"\n" +
 	"\rsnapshot_id_a\x18\x01 \x01(\tR\vsnapshotIdA\x12\"\n" +
-	"\rsnapshot_id_b\x18\x02 \x01(\tR\vsnapshotIdB\"\xba\x04\n" +
+	"\rsnapshot_id_b\x18\x02 \x01(\tR\vsnapshotIdB\x12/\n" +
+	"\x05rules\x18\x03 \x03(\v2\x19.hostdiff.SuppressionRuleR\x05rules\x12.\n" +
+	"\x06format\x18\x04 \x01(\x0e2\x16.hostdiff.ReportFormatR\x06format\"}\n" +
+	"\x0eRenderedReport\x12.\n" +
+	"\x06format\x18\x01 \x01(\x0e2\x16.hostdiff.ReportFormatR\x06format\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\fR\acontent\"\xdf\x01\n" +
+	"\x0fSuppressionRule\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04host\x18\x02 \x01(\tR\x04host\x12\x14\n" +
+	"\x05ports\x18\x03 \x01(\tR\x05ports\x12\x1a\n" +
+	"\bprotocol\x18\x04 \x01(\tR\bprotocol\x12\x14\n" +
+	"\x05field\x18\x05 \x01(\tR\x05field\x12,\n" +
+	"\x06action\x18\x06 \x01(\x0e2\x14.hostdiff.RuleActionR\x06action\x12.\n" +
+	"\bseverity\x18\a \x01(\x0e2\x12.hostdiff.SeverityR\bseverity\"\x84\x01\n" +
+	"\x10SuppressedChange\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\x12\x14\n" +
+	"\x05field\x18\x04 \x01(\tR\x05field\x12\x16\n" +
+	"\x06detail\x18\x05 \x01(\tR\x06detail\"\x93\b\n" +
 	"\n" +
 	"DiffReport\x12\x18\n" +
 	"\asummary\x18\x01 \x01(\tR\asummary\x121\n" +
@@ -802,7 +4508,23 @@ const file_proto_host_diff_proto_rawDesc = "" +
 	"\n" +
 	"added_cves\x18\t \x03(\v2\x13.hostdiff.CVEChangeR\taddedCves\x126\n" +
 	"\fremoved_cves\x18\n" +
-	" \x03(\v2\x13.hostdiff.CVEChangeR\vremovedCves\"\xb1\x02\n" +
+	" \x03(\v2\x13.hostdiff.CVEChangeR\vremovedCves\x12L\n" +
+	"\x13certificate_changes\x18\v \x03(\v2\x1b.hostdiff.CertificateChangeR\x12certificateChanges\x12:\n" +
+	"\rfield_changes\x18\f \x03(\v2\x15.hostdiff.FieldChangeR\ffieldChanges\x12@\n" +
+	"\x0fversion_changes\x18\r \x03(\v2\x17.hostdiff.VersionChangeR\x0eversionChanges\x124\n" +
+	"\x16has_software_downgrade\x18\x0e \x01(\bR\x14hasSoftwareDowngrade\x12I\n" +
+	"\x12suppressed_changes\x18\x0f \x03(\v2\x1a.hostdiff.SuppressedChangeR\x11suppressedChanges\x12)\n" +
+	"\x10suppressed_count\x18\x10 \x01(\x05R\x0fsuppressedCount\x12!\n" +
+	"\fcontent_hash\x18\x11 \x01(\tR\vcontentHash\x12<\n" +
+	"\x0emoved_services\x18\x12 \x03(\v2\x15.hostdiff.ServiceMoveR\rmovedServices\"\xb2\x01\n" +
+	"\vServiceMove\x12\x19\n" +
+	"\bold_port\x18\x01 \x01(\x05R\aoldPort\x12\x19\n" +
+	"\bnew_port\x18\x02 \x01(\x05R\anewPort\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\x12\x1d\n" +
+	"\n" +
+	"matched_on\x18\x04 \x03(\tR\tmatchedOn\x12\x18\n" +
+	"\aproduct\x18\x05 \x01(\tR\aproduct\x12\x18\n" +
+	"\aversion\x18\x06 \x01(\tR\aversion\"\xda\x03\n" +
 	"\n" +
 	"PortChange\x12\x12\n" +
 	"\x04port\x18\x01 \x01(\x05R\x04port\x12\x1a\n" +
@@ -813,31 +4535,278 @@ const file_proto_host_diff_proto_rawDesc = "" +
 	"oldService\x12\x1f\n" +
 	"\vnew_service\x18\x06 \x01(\tR\n" +
 	"newService\x12;\n" +
-	"\achanges\x18\a \x03(\v2!.hostdiff.PortChange.ChangesEntryR\achanges\x1a:\n" +
+	"\achanges\x18\a \x03(\v2!.hostdiff.PortChange.ChangesEntryR\achanges\x129\n" +
+	"\n" +
+	"attributes\x18\b \x03(\v2\x19.hostdiff.AttributeChangeR\n" +
+	"attributes\x125\n" +
+	"\fold_software\x18\t \x01(\v2\x12.hostdiff.SoftwareR\voldSoftware\x125\n" +
+	"\fnew_software\x18\n" +
+	" \x01(\v2\x12.hostdiff.SoftwareR\vnewSoftware\x1a:\n" +
 	"\fChangesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe1\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xcc\x02\n" +
 	"\rServiceChange\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1f\n" +
 	"\vold_version\x18\x02 \x01(\tR\n" +
 	"oldVersion\x12\x1f\n" +
 	"\vnew_version\x18\x03 \x01(\tR\n" +
 	"newVersion\x12>\n" +
-	"\achanges\x18\x04 \x03(\v2$.hostdiff.ServiceChange.ChangesEntryR\achanges\x1a:\n" +
+	"\achanges\x18\x04 \x03(\v2$.hostdiff.ServiceChange.ChangesEntryR\achanges\x12\x12\n" +
+	"\x04port\x18\x05 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x06 \x01(\tR\bprotocol\x129\n" +
+	"\n" +
+	"attributes\x18\a \x03(\v2\x19.hostdiff.AttributeChangeR\n" +
+	"attributes\x1a:\n" +
 	"\fChangesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\"\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"a\n" +
+	"\x0fAttributeChange\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x1b\n" +
+	"\told_value\x18\x02 \x01(\tR\boldValue\x12\x1b\n" +
+	"\tnew_value\x18\x03 \x01(\tR\bnewValue\"V\n" +
+	"\bSoftware\x12\x16\n" +
+	"\x06vendor\x18\x01 \x01(\tR\x06vendor\x12\x18\n" +
+	"\aproduct\x18\x02 \x01(\tR\aproduct\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"R\n" +
 	"\tCVEChange\x12\x15\n" +
-	"\x06cve_id\x18\x01 \x01(\tR\x05cveId\">\n" +
+	"\x06cve_id\x18\x01 \x01(\tR\x05cveId\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\"\xaf\x01\n" +
+	"\x11CertificateChange\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x120\n" +
+	"\x05field\x18\x03 \x01(\x0e2\x1a.hostdiff.CertificateFieldR\x05field\x12\x1b\n" +
+	"\told_value\x18\x04 \x01(\tR\boldValue\x12\x1b\n" +
+	"\tnew_value\x18\x05 \x01(\tR\bnewValue\"\xba\x01\n" +
+	"\vFieldChange\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12-\n" +
+	"\x04kind\x18\x04 \x01(\x0e2\x19.hostdiff.FieldChangeKindR\x04kind\x12\x1b\n" +
+	"\told_value\x18\x05 \x01(\tR\boldValue\x12\x1b\n" +
+	"\tnew_value\x18\x06 \x01(\tR\bnewValue\"\xcc\x01\n" +
+	"\rVersionChange\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x18\n" +
+	"\aproduct\x18\x03 \x01(\tR\aproduct\x12\x1f\n" +
+	"\vold_version\x18\x04 \x01(\tR\n" +
+	"oldVersion\x12\x1f\n" +
+	"\vnew_version\x18\x05 \x01(\tR\n" +
+	"newVersion\x12/\n" +
+	"\x04kind\x18\x06 \x01(\x0e2\x1b.hostdiff.VersionChangeKindR\x04kind\">\n" +
 	"\bOSChange\x12\x18\n" +
 	"\aoldname\x18\x01 \x01(\tR\aoldname\x12\x18\n" +
-	"\anewname\x18\x02 \x01(\tR\anewname\"H\n" +
+	"\anewname\x18\x02 \x01(\tR\anewname\"\xed\x01\n" +
+	"\fScoredChange\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\x12\x16\n" +
+	"\x06weight\x18\x05 \x01(\x01R\x06weight\x12.\n" +
+	"\bseverity\x18\x06 \x01(\x0e2\x12.hostdiff.SeverityR\bseverity\x12\x14\n" +
+	"\x05field\x18\a \x01(\tR\x05field\x12#\n" +
+	"\rdowngraded_by\x18\b \x01(\tR\fdowngradedBy\"\x83\x01\n" +
+	"\tRiskScore\x12\x14\n" +
+	"\x05score\x18\x01 \x01(\x01R\x05score\x12.\n" +
+	"\bseverity\x18\x02 \x01(\x0e2\x12.hostdiff.SeverityR\bseverity\x120\n" +
+	"\achanges\x18\x03 \x03(\v2\x16.hostdiff.ScoredChangeR\achanges\"\xa7\x01\n" +
 	"\x18CompareSnapshotsResponse\x12,\n" +
-	"\x06report\x18\x01 \x01(\v2\x14.hostdiff.DiffReportR\x06report2\x92\x02\n" +
+	"\x06report\x18\x01 \x01(\v2\x14.hostdiff.DiffReportR\x06report\x12'\n" +
+	"\x04risk\x18\x02 \x01(\v2\x13.hostdiff.RiskScoreR\x04risk\x124\n" +
+	"\brendered\x18\x03 \x01(\v2\x18.hostdiff.RenderedReportR\brendered\"q\n" +
+	"\x16GetHostTimelineRequest\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x01 \x01(\tR\tipAddress\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x03 \x01(\tR\aendTime\"\xba\x01\n" +
+	"\rTimelineEntry\x12*\n" +
+	"\x04from\x18\x01 \x01(\v2\x16.hostdiff.SnapshotInfoR\x04from\x12&\n" +
+	"\x02to\x18\x02 \x01(\v2\x16.hostdiff.SnapshotInfoR\x02to\x12,\n" +
+	"\x06report\x18\x03 \x01(\v2\x14.hostdiff.DiffReportR\x06report\x12'\n" +
+	"\x04risk\x18\x04 \x01(\v2\x13.hostdiff.RiskScoreR\x04risk\"\x82\x01\n" +
+	"\x17GetHostTimelineResponse\x124\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x16.hostdiff.SnapshotInfoR\tsnapshots\x121\n" +
+	"\aentries\x18\x02 \x03(\v2\x17.hostdiff.TimelineEntryR\aentries\"\x96\x02\n" +
+	"\x14QueryServicesRequest\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x18\n" +
+	"\aproduct\x18\x03 \x01(\tR\aproduct\x12\x1f\n" +
+	"\vmin_version\x18\x04 \x01(\tR\n" +
+	"minVersion\x12\x1f\n" +
+	"\vmax_version\x18\x05 \x01(\tR\n" +
+	"maxVersion\x12\x15\n" +
+	"\x06cve_id\x18\x06 \x01(\tR\x05cveId\x12\x1f\n" +
+	"\vlatest_only\x18\a \x01(\bR\n" +
+	"latestOnly\x12\x1b\n" +
+	"\tpage_size\x18\b \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\t \x01(\tR\tpageToken\"\xe6\x01\n" +
+	"\rServiceRecord\x12\x1f\n" +
+	"\vsnapshot_id\x18\x01 \x01(\tR\n" +
+	"snapshotId\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x02 \x01(\tR\tipAddress\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\tR\ttimestamp\x12\x12\n" +
+	"\x04port\x18\x04 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x05 \x01(\tR\bprotocol\x12.\n" +
+	"\bsoftware\x18\x06 \x01(\v2\x12.hostdiff.SoftwareR\bsoftware\x12\x17\n" +
+	"\acve_ids\x18\a \x03(\tR\x06cveIds\"t\n" +
+	"\x15QueryServicesResponse\x123\n" +
+	"\bservices\x18\x01 \x03(\v2\x17.hostdiff.ServiceRecordR\bservices\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x7f\n" +
+	"\x11QueryFleetRequest\x12\x19\n" +
+	"\bip_range\x18\x01 \x01(\tR\aipRange\x12\x13\n" +
+	"\x05as_of\x18\x02 \x01(\tR\x04asOf\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"r\n" +
+	"\x12QueryFleetResponse\x124\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x16.hostdiff.SnapshotInfoR\tsnapshots\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xaa\x01\n" +
+	"\x10ListHostsRequest\x12\x1b\n" +
+	"\tip_prefix\x18\x01 \x01(\tR\bipPrefix\x12\x12\n" +
+	"\x04cidr\x18\x02 \x01(\tR\x04cidr\x12)\n" +
+	"\x05order\x18\x03 \x01(\x0e2\x13.hostdiff.HostOrderR\x05order\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\"\x82\x02\n" +
+	"\vHostSummary\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x01 \x01(\tR\tipAddress\x12%\n" +
+	"\x0esnapshot_count\x18\x02 \x01(\x05R\rsnapshotCount\x12\x1d\n" +
+	"\n" +
+	"first_seen\x18\x03 \x01(\tR\tfirstSeen\x12\x1b\n" +
+	"\tlast_seen\x18\x04 \x01(\tR\blastSeen\x12,\n" +
+	"\x12latest_snapshot_id\x18\x05 \x01(\tR\x10latestSnapshotId\x12&\n" +
+	"\x0fopen_port_count\x18\x06 \x01(\x05R\ropenPortCount\x12\x1b\n" +
+	"\tcve_count\x18\a \x01(\x05R\bcveCount\"h\n" +
+	"\x11ListHostsResponse\x12+\n" +
+	"\x05hosts\x18\x01 \x03(\v2\x15.hostdiff.HostSummaryR\x05hosts\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"U\n" +
+	"\x15DeleteSnapshotRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05actor\x18\x02 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"L\n" +
+	"\x16DeleteSnapshotResponse\x122\n" +
+	"\bsnapshot\x18\x01 \x01(\v2\x16.hostdiff.SnapshotInfoR\bsnapshot\"V\n" +
+	"\x16RestoreSnapshotRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05actor\x18\x02 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"M\n" +
+	"\x17RestoreSnapshotResponse\x122\n" +
+	"\bsnapshot\x18\x01 \x01(\v2\x16.hostdiff.SnapshotInfoR\bsnapshot\"j\n" +
+	"\x13PurgeDeletedRequest\x12%\n" +
+	"\x0edeleted_before\x18\x01 \x01(\tR\rdeletedBefore\x12\x14\n" +
+	"\x05actor\x18\x02 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"L\n" +
+	"\x14PurgeDeletedResponse\x124\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x16.hostdiff.SnapshotInfoR\tsnapshots\"^\n" +
+	"\x15PruneSnapshotsRequest\x12\x16\n" +
+	"\x06policy\x18\x01 \x01(\tR\x06policy\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\"N\n" +
+	"\x16PruneSnapshotsResponse\x124\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x16.hostdiff.SnapshotInfoR\tsnapshots*\x89\x01\n" +
+	"\x0eConflictPolicy\x12\x1f\n" +
+	"\x1bCONFLICT_POLICY_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16CONFLICT_POLICY_REJECT\x10\x01\x12\x1b\n" +
+	"\x17CONFLICT_POLICY_REPLACE\x10\x02\x12\x1d\n" +
+	"\x19CONFLICT_POLICY_KEEP_BOTH\x10\x03*\xa3\x01\n" +
+	"\rUploadOutcome\x12\x1e\n" +
+	"\x1aUPLOAD_OUTCOME_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16UPLOAD_OUTCOME_CREATED\x10\x01\x12\x1c\n" +
+	"\x18UPLOAD_OUTCOME_UNCHANGED\x10\x02\x12\x1b\n" +
+	"\x17UPLOAD_OUTCOME_REPLACED\x10\x03\x12\x1b\n" +
+	"\x17UPLOAD_OUTCOME_REVISION\x10\x04*\xb0\x02\n" +
+	"\x11UploadWarningCode\x12#\n" +
+	"\x1fUPLOAD_WARNING_CODE_UNSPECIFIED\x10\x00\x12#\n" +
+	"\x1fUPLOAD_WARNING_CODE_IP_MISMATCH\x10\x01\x12*\n" +
+	"&UPLOAD_WARNING_CODE_TIMESTAMP_MISMATCH\x10\x02\x12-\n" +
+	")UPLOAD_WARNING_CODE_INVALID_CONTENT_FIELD\x10\x03\x12(\n" +
+	"$UPLOAD_WARNING_CODE_INVALID_FILENAME\x10\x04\x12$\n" +
+	" UPLOAD_WARNING_CODE_SCHEMA_ERROR\x10\x05\x12&\n" +
+	"\"UPLOAD_WARNING_CODE_SCHEMA_WARNING\x10\x06*b\n" +
+	"\rArchiveFormat\x12\x1e\n" +
+	"\x1aARCHIVE_FORMAT_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15ARCHIVE_FORMAT_TAR_GZ\x10\x01\x12\x16\n" +
+	"\x12ARCHIVE_FORMAT_ZIP\x10\x02*\xba\x01\n" +
+	"\x10BulkUploadStatus\x12\"\n" +
+	"\x1eBULK_UPLOAD_STATUS_UNSPECIFIED\x10\x00\x12\x1f\n" +
+	"\x1bBULK_UPLOAD_STATUS_INSERTED\x10\x01\x12 \n" +
+	"\x1cBULK_UPLOAD_STATUS_DUPLICATE\x10\x02\x12\x1f\n" +
+	"\x1bBULK_UPLOAD_STATUS_REJECTED\x10\x03\x12\x1e\n" +
+	"\x1aBULK_UPLOAD_STATUS_SKIPPED\x10\x04*m\n" +
+	"\fHistoryOrder\x12\x1d\n" +
+	"\x19HISTORY_ORDER_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aHISTORY_ORDER_NEWEST_FIRST\x10\x01\x12\x1e\n" +
+	"\x1aHISTORY_ORDER_OLDEST_FIRST\x10\x02*\xc1\x01\n" +
+	"\fReportFormat\x12\x1d\n" +
+	"\x19REPORT_FORMAT_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12REPORT_FORMAT_TEXT\x10\x01\x12\x1a\n" +
+	"\x16REPORT_FORMAT_MARKDOWN\x10\x02\x12\x16\n" +
+	"\x12REPORT_FORMAT_HTML\x10\x03\x12\x15\n" +
+	"\x11REPORT_FORMAT_CSV\x10\x04\x12\x16\n" +
+	"\x12REPORT_FORMAT_JSON\x10\x05\x12\x17\n" +
+	"\x13REPORT_FORMAT_SARIF\x10\x06*^\n" +
+	"\n" +
+	"RuleAction\x12\x1b\n" +
+	"\x17RULE_ACTION_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14RULE_ACTION_SUPPRESS\x10\x01\x12\x19\n" +
+	"\x15RULE_ACTION_DOWNGRADE\x10\x02*\xd7\x02\n" +
+	"\x10CertificateField\x12!\n" +
+	"\x1dCERTIFICATE_FIELD_UNSPECIFIED\x10\x00\x12!\n" +
+	"\x1dCERTIFICATE_FIELD_FINGERPRINT\x10\x01\x12\x1d\n" +
+	"\x19CERTIFICATE_FIELD_SUBJECT\x10\x02\x12\x1c\n" +
+	"\x18CERTIFICATE_FIELD_ISSUER\x10\x03\x12\x1a\n" +
+	"\x16CERTIFICATE_FIELD_SANS\x10\x04\x12 \n" +
+	"\x1cCERTIFICATE_FIELD_NOT_BEFORE\x10\x05\x12\x1f\n" +
+	"\x1bCERTIFICATE_FIELD_NOT_AFTER\x10\x06\x12\x1e\n" +
+	"\x1aCERTIFICATE_FIELD_KEY_TYPE\x10\a\x12\x1e\n" +
+	"\x1aCERTIFICATE_FIELD_KEY_SIZE\x10\b\x12!\n" +
+	"\x1dCERTIFICATE_FIELD_SELF_SIGNED\x10\t*\x8f\x01\n" +
+	"\x0fFieldChangeKind\x12!\n" +
+	"\x1dFIELD_CHANGE_KIND_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17FIELD_CHANGE_KIND_ADDED\x10\x01\x12\x1d\n" +
+	"\x19FIELD_CHANGE_KIND_REMOVED\x10\x02\x12\x1d\n" +
+	"\x19FIELD_CHANGE_KIND_CHANGED\x10\x03*\xf1\x02\n" +
+	"\x11VersionChangeKind\x12#\n" +
+	"\x1fVERSION_CHANGE_KIND_UNSPECIFIED\x10\x00\x12%\n" +
+	"!VERSION_CHANGE_KIND_MAJOR_UPGRADE\x10\x01\x12%\n" +
+	"!VERSION_CHANGE_KIND_MINOR_UPGRADE\x10\x02\x12%\n" +
+	"!VERSION_CHANGE_KIND_PATCH_UPGRADE\x10\x03\x12'\n" +
+	"#VERSION_CHANGE_KIND_MAJOR_DOWNGRADE\x10\x04\x12'\n" +
+	"#VERSION_CHANGE_KIND_MINOR_DOWNGRADE\x10\x05\x12'\n" +
+	"#VERSION_CHANGE_KIND_PATCH_DOWNGRADE\x10\x06\x12\"\n" +
+	"\x1eVERSION_CHANGE_KIND_EQUIVALENT\x10\a\x12#\n" +
+	"\x1fVERSION_CHANGE_KIND_UNPARSEABLE\x10\b*\x88\x01\n" +
+	"\bSeverity\x12\x18\n" +
+	"\x14SEVERITY_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rSEVERITY_INFO\x10\x01\x12\x10\n" +
+	"\fSEVERITY_LOW\x10\x02\x12\x13\n" +
+	"\x0fSEVERITY_MEDIUM\x10\x03\x12\x11\n" +
+	"\rSEVERITY_HIGH\x10\x04\x12\x15\n" +
+	"\x11SEVERITY_CRITICAL\x10\x05*d\n" +
+	"\tHostOrder\x12\x1a\n" +
+	"\x16HOST_ORDER_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19HOST_ORDER_LAST_SEEN_DESC\x10\x01\x12\x1c\n" +
+	"\x18HOST_ORDER_LAST_SEEN_ASC\x10\x022\xc0\b\n" +
 	"\vHostService\x12S\n" +
-	"\x0eUploadSnapshot\x12\x1f.hostdiff.UploadSnapshotRequest\x1a .hostdiff.UploadSnapshotResponse\x12S\n" +
+	"\x0eUploadSnapshot\x12\x1f.hostdiff.UploadSnapshotRequest\x1a .hostdiff.UploadSnapshotResponse\x12Y\n" +
+	"\x14UploadSnapshotStream\x12\x1d.hostdiff.UploadSnapshotChunk\x1a .hostdiff.UploadSnapshotResponse(\x01\x12G\n" +
+	"\n" +
+	"BulkUpload\x12\x1b.hostdiff.BulkUploadRequest\x1a\x1c.hostdiff.BulkUploadResponse\x12S\n" +
 	"\x0eGetHostHistory\x12\x1f.hostdiff.GetHostHistoryRequest\x1a .hostdiff.GetHostHistoryResponse\x12Y\n" +
-	"\x10CompareSnapshots\x12!.hostdiff.CompareSnapshotsRequest\x1a\".hostdiff.CompareSnapshotsResponseB.Z,github.com/justicecaban/host-diff-tool/protob\x06proto3"
+	"\x10CompareSnapshots\x12!.hostdiff.CompareSnapshotsRequest\x1a\".hostdiff.CompareSnapshotsResponse\x12V\n" +
+	"\x0fGetHostTimeline\x12 .hostdiff.GetHostTimelineRequest\x1a!.hostdiff.GetHostTimelineResponse\x12P\n" +
+	"\rQueryServices\x12\x1e.hostdiff.QueryServicesRequest\x1a\x1f.hostdiff.QueryServicesResponse\x12G\n" +
+	"\n" +
+	"QueryFleet\x12\x1b.hostdiff.QueryFleetRequest\x1a\x1c.hostdiff.QueryFleetResponse\x12D\n" +
+	"\tListHosts\x12\x1a.hostdiff.ListHostsRequest\x1a\x1b.hostdiff.ListHostsResponse\x12S\n" +
+	"\x0eDeleteSnapshot\x12\x1f.hostdiff.DeleteSnapshotRequest\x1a .hostdiff.DeleteSnapshotResponse\x12V\n" +
+	"\x0fRestoreSnapshot\x12 .hostdiff.RestoreSnapshotRequest\x1a!.hostdiff.RestoreSnapshotResponse\x12M\n" +
+	"\fPurgeDeleted\x12\x1d.hostdiff.PurgeDeletedRequest\x1a\x1e.hostdiff.PurgeDeletedResponse\x12S\n" +
+	"\x0ePruneSnapshots\x12\x1f.hostdiff.PruneSnapshotsRequest\x1a .hostdiff.PruneSnapshotsResponseB.Z,github.com/justicecaban/host-diff-tool/protob\x06proto3"
 
 var (
 	file_proto_host_diff_proto_rawDescOnce sync.Once
@@ -851,48 +4820,166 @@ func file_proto_host_diff_proto_rawDescGZIP() []byte {
 	return file_proto_host_diff_proto_rawDescData
 }
 
-var file_proto_host_diff_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_proto_host_diff_proto_enumTypes = make([]protoimpl.EnumInfo, 13)
+var file_proto_host_diff_proto_msgTypes = make([]protoimpl.MessageInfo, 50)
 var file_proto_host_diff_proto_goTypes = []any{
-	(*SnapshotInfo)(nil),             // 0: hostdiff.SnapshotInfo
-	(*UploadSnapshotRequest)(nil),    // 1: hostdiff.UploadSnapshotRequest
-	(*UploadSnapshotResponse)(nil),   // 2: hostdiff.UploadSnapshotResponse
-	(*GetHostHistoryRequest)(nil),    // 3: hostdiff.GetHostHistoryRequest
-	(*GetHostHistoryResponse)(nil),   // 4: hostdiff.GetHostHistoryResponse
-	(*CompareSnapshotsRequest)(nil),  // 5: hostdiff.CompareSnapshotsRequest
-	(*DiffReport)(nil),               // 6: hostdiff.DiffReport
-	(*PortChange)(nil),               // 7: hostdiff.PortChange
-	(*ServiceChange)(nil),            // 8: hostdiff.ServiceChange
-	(*CVEChange)(nil),                // 9: hostdiff.CVEChange
-	(*OSChange)(nil),                 // 10: hostdiff.OSChange
-	(*CompareSnapshotsResponse)(nil), // 11: hostdiff.CompareSnapshotsResponse
-	nil,                              // 12: hostdiff.PortChange.ChangesEntry
-	nil,                              // 13: hostdiff.ServiceChange.ChangesEntry
+	(ConflictPolicy)(0),              // 0: hostdiff.ConflictPolicy
+	(UploadOutcome)(0),               // 1: hostdiff.UploadOutcome
+	(UploadWarningCode)(0),           // 2: hostdiff.UploadWarningCode
+	(ArchiveFormat)(0),               // 3: hostdiff.ArchiveFormat
+	(BulkUploadStatus)(0),            // 4: hostdiff.BulkUploadStatus
+	(HistoryOrder)(0),                // 5: hostdiff.HistoryOrder
+	(ReportFormat)(0),                // 6: hostdiff.ReportFormat
+	(RuleAction)(0),                  // 7: hostdiff.RuleAction
+	(CertificateField)(0),            // 8: hostdiff.CertificateField
+	(FieldChangeKind)(0),             // 9: hostdiff.FieldChangeKind
+	(VersionChangeKind)(0),           // 10: hostdiff.VersionChangeKind
+	(Severity)(0),                    // 11: hostdiff.Severity
+	(HostOrder)(0),                   // 12: hostdiff.HostOrder
+	(*SnapshotInfo)(nil),             // 13: hostdiff.SnapshotInfo
+	(*UploadSnapshotRequest)(nil),    // 14: hostdiff.UploadSnapshotRequest
+	(*UploadSnapshotResponse)(nil),   // 15: hostdiff.UploadSnapshotResponse
+	(*UploadWarning)(nil),            // 16: hostdiff.UploadWarning
+	(*UploadSnapshotChunk)(nil),      // 17: hostdiff.UploadSnapshotChunk
+	(*UploadSnapshotHeader)(nil),     // 18: hostdiff.UploadSnapshotHeader
+	(*BulkUploadRequest)(nil),        // 19: hostdiff.BulkUploadRequest
+	(*BulkUploadResult)(nil),         // 20: hostdiff.BulkUploadResult
+	(*BulkUploadResponse)(nil),       // 21: hostdiff.BulkUploadResponse
+	(*GetHostHistoryRequest)(nil),    // 22: hostdiff.GetHostHistoryRequest
+	(*GetHostHistoryResponse)(nil),   // 23: hostdiff.GetHostHistoryResponse
+	(*CompareSnapshotsRequest)(nil),  // 24: hostdiff.CompareSnapshotsRequest
+	(*RenderedReport)(nil),           // 25: hostdiff.RenderedReport
+	(*SuppressionRule)(nil),          // 26: hostdiff.SuppressionRule
+	(*SuppressedChange)(nil),         // 27: hostdiff.SuppressedChange
+	(*DiffReport)(nil),               // 28: hostdiff.DiffReport
+	(*ServiceMove)(nil),              // 29: hostdiff.ServiceMove
+	(*PortChange)(nil),               // 30: hostdiff.PortChange
+	(*ServiceChange)(nil),            // 31: hostdiff.ServiceChange
+	(*AttributeChange)(nil),          // 32: hostdiff.AttributeChange
+	(*Software)(nil),                 // 33: hostdiff.Software
+	(*CVEChange)(nil),                // 34: hostdiff.CVEChange
+	(*CertificateChange)(nil),        // 35: hostdiff.CertificateChange
+	(*FieldChange)(nil),              // 36: hostdiff.FieldChange
+	(*VersionChange)(nil),            // 37: hostdiff.VersionChange
+	(*OSChange)(nil),                 // 38: hostdiff.OSChange
+	(*ScoredChange)(nil),             // 39: hostdiff.ScoredChange
+	(*RiskScore)(nil),                // 40: hostdiff.RiskScore
+	(*CompareSnapshotsResponse)(nil), // 41: hostdiff.CompareSnapshotsResponse
+	(*GetHostTimelineRequest)(nil),   // 42: hostdiff.GetHostTimelineRequest
+	(*TimelineEntry)(nil),            // 43: hostdiff.TimelineEntry
+	(*GetHostTimelineResponse)(nil),  // 44: hostdiff.GetHostTimelineResponse
+	(*QueryServicesRequest)(nil),     // 45: hostdiff.QueryServicesRequest
+	(*ServiceRecord)(nil),            // 46: hostdiff.ServiceRecord
+	(*QueryServicesResponse)(nil),    // 47: hostdiff.QueryServicesResponse
+	(*QueryFleetRequest)(nil),        // 48: hostdiff.QueryFleetRequest
+	(*QueryFleetResponse)(nil),       // 49: hostdiff.QueryFleetResponse
+	(*ListHostsRequest)(nil),         // 50: hostdiff.ListHostsRequest
+	(*HostSummary)(nil),              // 51: hostdiff.HostSummary
+	(*ListHostsResponse)(nil),        // 52: hostdiff.ListHostsResponse
+	(*DeleteSnapshotRequest)(nil),    // 53: hostdiff.DeleteSnapshotRequest
+	(*DeleteSnapshotResponse)(nil),   // 54: hostdiff.DeleteSnapshotResponse
+	(*RestoreSnapshotRequest)(nil),   // 55: hostdiff.RestoreSnapshotRequest
+	(*RestoreSnapshotResponse)(nil),  // 56: hostdiff.RestoreSnapshotResponse
+	(*PurgeDeletedRequest)(nil),      // 57: hostdiff.PurgeDeletedRequest
+	(*PurgeDeletedResponse)(nil),     // 58: hostdiff.PurgeDeletedResponse
+	(*PruneSnapshotsRequest)(nil),    // 59: hostdiff.PruneSnapshotsRequest
+	(*PruneSnapshotsResponse)(nil),   // 60: hostdiff.PruneSnapshotsResponse
+	nil,                              // 61: hostdiff.PortChange.ChangesEntry
+	nil,                              // 62: hostdiff.ServiceChange.ChangesEntry
 }
 var file_proto_host_diff_proto_depIdxs = []int32{
-	0,  // 0: hostdiff.GetHostHistoryResponse.snapshots:type_name -> hostdiff.SnapshotInfo
-	10, // 1: hostdiff.DiffReport.os_changes:type_name -> hostdiff.OSChange
-	7,  // 2: hostdiff.DiffReport.added_ports:type_name -> hostdiff.PortChange
-	7,  // 3: hostdiff.DiffReport.removed_ports:type_name -> hostdiff.PortChange
-	7,  // 4: hostdiff.DiffReport.changed_ports:type_name -> hostdiff.PortChange
-	8,  // 5: hostdiff.DiffReport.added_services:type_name -> hostdiff.ServiceChange
-	8,  // 6: hostdiff.DiffReport.removed_services:type_name -> hostdiff.ServiceChange
-	8,  // 7: hostdiff.DiffReport.changed_services:type_name -> hostdiff.ServiceChange
-	9,  // 8: hostdiff.DiffReport.added_cves:type_name -> hostdiff.CVEChange
-	9,  // 9: hostdiff.DiffReport.removed_cves:type_name -> hostdiff.CVEChange
-	12, // 10: hostdiff.PortChange.changes:type_name -> hostdiff.PortChange.ChangesEntry
-	13, // 11: hostdiff.ServiceChange.changes:type_name -> hostdiff.ServiceChange.ChangesEntry
-	6,  // 12: hostdiff.CompareSnapshotsResponse.report:type_name -> hostdiff.DiffReport
-	1,  // 13: hostdiff.HostService.UploadSnapshot:input_type -> hostdiff.UploadSnapshotRequest
-	3,  // 14: hostdiff.HostService.GetHostHistory:input_type -> hostdiff.GetHostHistoryRequest
-	5,  // 15: hostdiff.HostService.CompareSnapshots:input_type -> hostdiff.CompareSnapshotsRequest
-	2,  // 16: hostdiff.HostService.UploadSnapshot:output_type -> hostdiff.UploadSnapshotResponse
-	4,  // 17: hostdiff.HostService.GetHostHistory:output_type -> hostdiff.GetHostHistoryResponse
-	11, // 18: hostdiff.HostService.CompareSnapshots:output_type -> hostdiff.CompareSnapshotsResponse
-	16, // [16:19] is the sub-list for method output_type
-	13, // [13:16] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	0,  // 0: hostdiff.UploadSnapshotRequest.conflict_policy:type_name -> hostdiff.ConflictPolicy
+	16, // 1: hostdiff.UploadSnapshotResponse.warnings:type_name -> hostdiff.UploadWarning
+	1,  // 2: hostdiff.UploadSnapshotResponse.outcome:type_name -> hostdiff.UploadOutcome
+	2,  // 3: hostdiff.UploadWarning.code:type_name -> hostdiff.UploadWarningCode
+	18, // 4: hostdiff.UploadSnapshotChunk.header:type_name -> hostdiff.UploadSnapshotHeader
+	0,  // 5: hostdiff.UploadSnapshotHeader.conflict_policy:type_name -> hostdiff.ConflictPolicy
+	3,  // 6: hostdiff.BulkUploadRequest.format:type_name -> hostdiff.ArchiveFormat
+	4,  // 7: hostdiff.BulkUploadResult.status:type_name -> hostdiff.BulkUploadStatus
+	16, // 8: hostdiff.BulkUploadResult.warnings:type_name -> hostdiff.UploadWarning
+	20, // 9: hostdiff.BulkUploadResponse.results:type_name -> hostdiff.BulkUploadResult
+	5,  // 10: hostdiff.GetHostHistoryRequest.order:type_name -> hostdiff.HistoryOrder
+	13, // 11: hostdiff.GetHostHistoryResponse.snapshots:type_name -> hostdiff.SnapshotInfo
+	26, // 12: hostdiff.CompareSnapshotsRequest.rules:type_name -> hostdiff.SuppressionRule
+	6,  // 13: hostdiff.CompareSnapshotsRequest.format:type_name -> hostdiff.ReportFormat
+	6,  // 14: hostdiff.RenderedReport.format:type_name -> hostdiff.ReportFormat
+	7,  // 15: hostdiff.SuppressionRule.action:type_name -> hostdiff.RuleAction
+	11, // 16: hostdiff.SuppressionRule.severity:type_name -> hostdiff.Severity
+	38, // 17: hostdiff.DiffReport.os_changes:type_name -> hostdiff.OSChange
+	30, // 18: hostdiff.DiffReport.added_ports:type_name -> hostdiff.PortChange
+	30, // 19: hostdiff.DiffReport.removed_ports:type_name -> hostdiff.PortChange
+	30, // 20: hostdiff.DiffReport.changed_ports:type_name -> hostdiff.PortChange
+	31, // 21: hostdiff.DiffReport.added_services:type_name -> hostdiff.ServiceChange
+	31, // 22: hostdiff.DiffReport.removed_services:type_name -> hostdiff.ServiceChange
+	31, // 23: hostdiff.DiffReport.changed_services:type_name -> hostdiff.ServiceChange
+	34, // 24: hostdiff.DiffReport.added_cves:type_name -> hostdiff.CVEChange
+	34, // 25: hostdiff.DiffReport.removed_cves:type_name -> hostdiff.CVEChange
+	35, // 26: hostdiff.DiffReport.certificate_changes:type_name -> hostdiff.CertificateChange
+	36, // 27: hostdiff.DiffReport.field_changes:type_name -> hostdiff.FieldChange
+	37, // 28: hostdiff.DiffReport.version_changes:type_name -> hostdiff.VersionChange
+	27, // 29: hostdiff.DiffReport.suppressed_changes:type_name -> hostdiff.SuppressedChange
+	29, // 30: hostdiff.DiffReport.moved_services:type_name -> hostdiff.ServiceMove
+	61, // 31: hostdiff.PortChange.changes:type_name -> hostdiff.PortChange.ChangesEntry
+	32, // 32: hostdiff.PortChange.attributes:type_name -> hostdiff.AttributeChange
+	33, // 33: hostdiff.PortChange.old_software:type_name -> hostdiff.Software
+	33, // 34: hostdiff.PortChange.new_software:type_name -> hostdiff.Software
+	62, // 35: hostdiff.ServiceChange.changes:type_name -> hostdiff.ServiceChange.ChangesEntry
+	32, // 36: hostdiff.ServiceChange.attributes:type_name -> hostdiff.AttributeChange
+	8,  // 37: hostdiff.CertificateChange.field:type_name -> hostdiff.CertificateField
+	9,  // 38: hostdiff.FieldChange.kind:type_name -> hostdiff.FieldChangeKind
+	10, // 39: hostdiff.VersionChange.kind:type_name -> hostdiff.VersionChangeKind
+	11, // 40: hostdiff.ScoredChange.severity:type_name -> hostdiff.Severity
+	11, // 41: hostdiff.RiskScore.severity:type_name -> hostdiff.Severity
+	39, // 42: hostdiff.RiskScore.changes:type_name -> hostdiff.ScoredChange
+	28, // 43: hostdiff.CompareSnapshotsResponse.report:type_name -> hostdiff.DiffReport
+	40, // 44: hostdiff.CompareSnapshotsResponse.risk:type_name -> hostdiff.RiskScore
+	25, // 45: hostdiff.CompareSnapshotsResponse.rendered:type_name -> hostdiff.RenderedReport
+	13, // 46: hostdiff.TimelineEntry.from:type_name -> hostdiff.SnapshotInfo
+	13, // 47: hostdiff.TimelineEntry.to:type_name -> hostdiff.SnapshotInfo
+	28, // 48: hostdiff.TimelineEntry.report:type_name -> hostdiff.DiffReport
+	40, // 49: hostdiff.TimelineEntry.risk:type_name -> hostdiff.RiskScore
+	13, // 50: hostdiff.GetHostTimelineResponse.snapshots:type_name -> hostdiff.SnapshotInfo
+	43, // 51: hostdiff.GetHostTimelineResponse.entries:type_name -> hostdiff.TimelineEntry
+	33, // 52: hostdiff.ServiceRecord.software:type_name -> hostdiff.Software
+	46, // 53: hostdiff.QueryServicesResponse.services:type_name -> hostdiff.ServiceRecord
+	13, // 54: hostdiff.QueryFleetResponse.snapshots:type_name -> hostdiff.SnapshotInfo
+	12, // 55: hostdiff.ListHostsRequest.order:type_name -> hostdiff.HostOrder
+	51, // 56: hostdiff.ListHostsResponse.hosts:type_name -> hostdiff.HostSummary
+	13, // 57: hostdiff.DeleteSnapshotResponse.snapshot:type_name -> hostdiff.SnapshotInfo
+	13, // 58: hostdiff.RestoreSnapshotResponse.snapshot:type_name -> hostdiff.SnapshotInfo
+	13, // 59: hostdiff.PurgeDeletedResponse.snapshots:type_name -> hostdiff.SnapshotInfo
+	13, // 60: hostdiff.PruneSnapshotsResponse.snapshots:type_name -> hostdiff.SnapshotInfo
+	14, // 61: hostdiff.HostService.UploadSnapshot:input_type -> hostdiff.UploadSnapshotRequest
+	17, // 62: hostdiff.HostService.UploadSnapshotStream:input_type -> hostdiff.UploadSnapshotChunk
+	19, // 63: hostdiff.HostService.BulkUpload:input_type -> hostdiff.BulkUploadRequest
+	22, // 64: hostdiff.HostService.GetHostHistory:input_type -> hostdiff.GetHostHistoryRequest
+	24, // 65: hostdiff.HostService.CompareSnapshots:input_type -> hostdiff.CompareSnapshotsRequest
+	42, // 66: hostdiff.HostService.GetHostTimeline:input_type -> hostdiff.GetHostTimelineRequest
+	45, // 67: hostdiff.HostService.QueryServices:input_type -> hostdiff.QueryServicesRequest
+	48, // 68: hostdiff.HostService.QueryFleet:input_type -> hostdiff.QueryFleetRequest
+	50, // 69: hostdiff.HostService.ListHosts:input_type -> hostdiff.ListHostsRequest
+	53, // 70: hostdiff.HostService.DeleteSnapshot:input_type -> hostdiff.DeleteSnapshotRequest
+	55, // 71: hostdiff.HostService.RestoreSnapshot:input_type -> hostdiff.RestoreSnapshotRequest
+	57, // 72: hostdiff.HostService.PurgeDeleted:input_type -> hostdiff.PurgeDeletedRequest
+	59, // 73: hostdiff.HostService.PruneSnapshots:input_type -> hostdiff.PruneSnapshotsRequest
+	15, // 74: hostdiff.HostService.UploadSnapshot:output_type -> hostdiff.UploadSnapshotResponse
+	15, // 75: hostdiff.HostService.UploadSnapshotStream:output_type -> hostdiff.UploadSnapshotResponse
+	21, // 76: hostdiff.HostService.BulkUpload:output_type -> hostdiff.BulkUploadResponse
+	23, // 77: hostdiff.HostService.GetHostHistory:output_type -> hostdiff.GetHostHistoryResponse
+	41, // 78: hostdiff.HostService.CompareSnapshots:output_type -> hostdiff.CompareSnapshotsResponse
+	44, // 79: hostdiff.HostService.GetHostTimeline:output_type -> hostdiff.GetHostTimelineResponse
+	47, // 80: hostdiff.HostService.QueryServices:output_type -> hostdiff.QueryServicesResponse
+	49, // 81: hostdiff.HostService.QueryFleet:output_type -> hostdiff.QueryFleetResponse
+	52, // 82: hostdiff.HostService.ListHosts:output_type -> hostdiff.ListHostsResponse
+	54, // 83: hostdiff.HostService.DeleteSnapshot:output_type -> hostdiff.DeleteSnapshotResponse
+	56, // 84: hostdiff.HostService.RestoreSnapshot:output_type -> hostdiff.RestoreSnapshotResponse
+	58, // 85: hostdiff.HostService.PurgeDeleted:output_type -> hostdiff.PurgeDeletedResponse
+	60, // 86: hostdiff.HostService.PruneSnapshots:output_type -> hostdiff.PruneSnapshotsResponse
+	74, // [74:87] is the sub-list for method output_type
+	61, // [61:74] is the sub-list for method input_type
+	61, // [61:61] is the sub-list for extension type_name
+	61, // [61:61] is the sub-list for extension extendee
+	0,  // [0:61] is the sub-list for field type_name
 }
 
 func init() { file_proto_host_diff_proto_init() }
@@ -900,18 +4987,23 @@ func file_proto_host_diff_proto_init() {
 	if File_proto_host_diff_proto != nil {
 		return
 	}
+	file_proto_host_diff_proto_msgTypes[4].OneofWrappers = []any{
+		(*UploadSnapshotChunk_Header)(nil),
+		(*UploadSnapshotChunk_Data)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_host_diff_proto_rawDesc), len(file_proto_host_diff_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   14,
+			NumEnums:      13,
+			NumMessages:   50,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_host_diff_proto_goTypes,
 		DependencyIndexes: file_proto_host_diff_proto_depIdxs,
+		EnumInfos:         file_proto_host_diff_proto_enumTypes,
 		MessageInfos:      file_proto_host_diff_proto_msgTypes,
 	}.Build()
 	File_proto_host_diff_proto = out.File
@@ -19,9 +19,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	HostService_UploadSnapshot_FullMethodName   = "/hostdiff.HostService/UploadSnapshot"
-	HostService_GetHostHistory_FullMethodName   = "/hostdiff.HostService/GetHostHistory"
-	HostService_CompareSnapshots_FullMethodName = "/hostdiff.HostService/CompareSnapshots"
+	HostService_UploadSnapshot_FullMethodName       = "/hostdiff.HostService/UploadSnapshot"
+	HostService_UploadSnapshotStream_FullMethodName = "/hostdiff.HostService/UploadSnapshotStream"
+	HostService_BulkUpload_FullMethodName           = "/hostdiff.HostService/BulkUpload"
+	HostService_GetHostHistory_FullMethodName       = "/hostdiff.HostService/GetHostHistory"
+	HostService_CompareSnapshots_FullMethodName     = "/hostdiff.HostService/CompareSnapshots"
+	HostService_GetHostTimeline_FullMethodName      = "/hostdiff.HostService/GetHostTimeline"
+	HostService_QueryServices_FullMethodName        = "/hostdiff.HostService/QueryServices"
+	HostService_QueryFleet_FullMethodName           = "/hostdiff.HostService/QueryFleet"
+	HostService_ListHosts_FullMethodName            = "/hostdiff.HostService/ListHosts"
+	HostService_DeleteSnapshot_FullMethodName       = "/hostdiff.HostService/DeleteSnapshot"
+	HostService_RestoreSnapshot_FullMethodName      = "/hostdiff.HostService/RestoreSnapshot"
+	HostService_PurgeDeleted_FullMethodName         = "/hostdiff.HostService/PurgeDeleted"
+	HostService_PruneSnapshots_FullMethodName       = "/hostdiff.HostService/PruneSnapshots"
 )
 
 // HostServiceClient is the client API for HostService service.
@@ -30,12 +40,38 @@ const (
 //
 // HostService defines the main gRPC service for the application.
 type HostServiceClient interface {
-	// Uploads a snapshot file. The client sends a stream of bytes.
+	// Uploads a snapshot file in a single message.
 	UploadSnapshot(ctx context.Context, in *UploadSnapshotRequest, opts ...grpc.CallOption) (*UploadSnapshotResponse, error)
+	// Uploads a snapshot file as a stream: a header frame followed by data
+	// chunks. Use this for files larger than the gRPC message size limit.
+	UploadSnapshotStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadSnapshotChunk, UploadSnapshotResponse], error)
+	// Uploads a tar.gz or zip archive of snapshot files in one call and
+	// reports what happened to each file.
+	BulkUpload(ctx context.Context, in *BulkUploadRequest, opts ...grpc.CallOption) (*BulkUploadResponse, error)
 	// Retrieves the history of snapshots for a given IP address.
 	GetHostHistory(ctx context.Context, in *GetHostHistoryRequest, opts ...grpc.CallOption) (*GetHostHistoryResponse, error)
 	// Compares two snapshots and returns a structured diff report.
 	CompareSnapshots(ctx context.Context, in *CompareSnapshotsRequest, opts ...grpc.CallOption) (*CompareSnapshotsResponse, error)
+	// Diffs every consecutive pair of a host's snapshots, oldest first.
+	GetHostTimeline(ctx context.Context, in *GetHostTimelineRequest, opts ...grpc.CallOption) (*GetHostTimelineResponse, error)
+	// Searches the services of every stored snapshot, across hosts.
+	QueryServices(ctx context.Context, in *QueryServicesRequest, opts ...grpc.CallOption) (*QueryServicesResponse, error)
+	// Returns the newest snapshot of every host in an IP range, optionally as
+	// of a point in time.
+	QueryFleet(ctx context.Context, in *QueryFleetRequest, opts ...grpc.CallOption) (*QueryFleetResponse, error)
+	// Lists the hosts that have snapshots, with summary stats for each.
+	ListHosts(ctx context.Context, in *ListHostsRequest, opts ...grpc.CallOption) (*ListHostsResponse, error)
+	// Soft-deletes a snapshot: it is hidden from history and comparisons and
+	// no longer blocks an upload for the same IP address and timestamp.
+	DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*DeleteSnapshotResponse, error)
+	// Brings back a soft-deleted snapshot, unless a live snapshot has taken
+	// its IP address, timestamp and revision.
+	RestoreSnapshot(ctx context.Context, in *RestoreSnapshotRequest, opts ...grpc.CallOption) (*RestoreSnapshotResponse, error)
+	// Permanently removes soft-deleted snapshots.
+	PurgeDeleted(ctx context.Context, in *PurgeDeletedRequest, opts ...grpc.CallOption) (*PurgeDeletedResponse, error)
+	// Applies a retention policy, permanently removing the snapshots it
+	// doesn't keep, or only reports them.
+	PruneSnapshots(ctx context.Context, in *PruneSnapshotsRequest, opts ...grpc.CallOption) (*PruneSnapshotsResponse, error)
 }
 
 type hostServiceClient struct {
@@ -56,6 +92,29 @@ func (c *hostServiceClient) UploadSnapshot(ctx context.Context, in *UploadSnapsh
 	return out, nil
 }
 
+func (c *hostServiceClient) UploadSnapshotStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadSnapshotChunk, UploadSnapshotResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &HostService_ServiceDesc.Streams[0], HostService_UploadSnapshotStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadSnapshotChunk, UploadSnapshotResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HostService_UploadSnapshotStreamClient = grpc.ClientStreamingClient[UploadSnapshotChunk, UploadSnapshotResponse]
+
+func (c *hostServiceClient) BulkUpload(ctx context.Context, in *BulkUploadRequest, opts ...grpc.CallOption) (*BulkUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BulkUploadResponse)
+	err := c.cc.Invoke(ctx, HostService_BulkUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *hostServiceClient) GetHostHistory(ctx context.Context, in *GetHostHistoryRequest, opts ...grpc.CallOption) (*GetHostHistoryResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetHostHistoryResponse)
@@ -76,18 +135,124 @@ func (c *hostServiceClient) CompareSnapshots(ctx context.Context, in *CompareSna
 	return out, nil
 }
 
+func (c *hostServiceClient) GetHostTimeline(ctx context.Context, in *GetHostTimelineRequest, opts ...grpc.CallOption) (*GetHostTimelineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHostTimelineResponse)
+	err := c.cc.Invoke(ctx, HostService_GetHostTimeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) QueryServices(ctx context.Context, in *QueryServicesRequest, opts ...grpc.CallOption) (*QueryServicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryServicesResponse)
+	err := c.cc.Invoke(ctx, HostService_QueryServices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) QueryFleet(ctx context.Context, in *QueryFleetRequest, opts ...grpc.CallOption) (*QueryFleetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryFleetResponse)
+	err := c.cc.Invoke(ctx, HostService_QueryFleet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) ListHosts(ctx context.Context, in *ListHostsRequest, opts ...grpc.CallOption) (*ListHostsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListHostsResponse)
+	err := c.cc.Invoke(ctx, HostService_ListHosts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*DeleteSnapshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSnapshotResponse)
+	err := c.cc.Invoke(ctx, HostService_DeleteSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) RestoreSnapshot(ctx context.Context, in *RestoreSnapshotRequest, opts ...grpc.CallOption) (*RestoreSnapshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreSnapshotResponse)
+	err := c.cc.Invoke(ctx, HostService_RestoreSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) PurgeDeleted(ctx context.Context, in *PurgeDeletedRequest, opts ...grpc.CallOption) (*PurgeDeletedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeDeletedResponse)
+	err := c.cc.Invoke(ctx, HostService_PurgeDeleted_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) PruneSnapshots(ctx context.Context, in *PruneSnapshotsRequest, opts ...grpc.CallOption) (*PruneSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PruneSnapshotsResponse)
+	err := c.cc.Invoke(ctx, HostService_PruneSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // HostServiceServer is the server API for HostService service.
 // All implementations must embed UnimplementedHostServiceServer
 // for forward compatibility.
 //
 // HostService defines the main gRPC service for the application.
 type HostServiceServer interface {
-	// Uploads a snapshot file. The client sends a stream of bytes.
+	// Uploads a snapshot file in a single message.
 	UploadSnapshot(context.Context, *UploadSnapshotRequest) (*UploadSnapshotResponse, error)
+	// Uploads a snapshot file as a stream: a header frame followed by data
+	// chunks. Use this for files larger than the gRPC message size limit.
+	UploadSnapshotStream(grpc.ClientStreamingServer[UploadSnapshotChunk, UploadSnapshotResponse]) error
+	// Uploads a tar.gz or zip archive of snapshot files in one call and
+	// reports what happened to each file.
+	BulkUpload(context.Context, *BulkUploadRequest) (*BulkUploadResponse, error)
 	// Retrieves the history of snapshots for a given IP address.
 	GetHostHistory(context.Context, *GetHostHistoryRequest) (*GetHostHistoryResponse, error)
 	// Compares two snapshots and returns a structured diff report.
 	CompareSnapshots(context.Context, *CompareSnapshotsRequest) (*CompareSnapshotsResponse, error)
+	// Diffs every consecutive pair of a host's snapshots, oldest first.
+	GetHostTimeline(context.Context, *GetHostTimelineRequest) (*GetHostTimelineResponse, error)
+	// Searches the services of every stored snapshot, across hosts.
+	QueryServices(context.Context, *QueryServicesRequest) (*QueryServicesResponse, error)
+	// Returns the newest snapshot of every host in an IP range, optionally as
+	// of a point in time.
+	QueryFleet(context.Context, *QueryFleetRequest) (*QueryFleetResponse, error)
+	// Lists the hosts that have snapshots, with summary stats for each.
+	ListHosts(context.Context, *ListHostsRequest) (*ListHostsResponse, error)
+	// Soft-deletes a snapshot: it is hidden from history and comparisons and
+	// no longer blocks an upload for the same IP address and timestamp.
+	DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*DeleteSnapshotResponse, error)
+	// Brings back a soft-deleted snapshot, unless a live snapshot has taken
+	// its IP address, timestamp and revision.
+	RestoreSnapshot(context.Context, *RestoreSnapshotRequest) (*RestoreSnapshotResponse, error)
+	// Permanently removes soft-deleted snapshots.
+	PurgeDeleted(context.Context, *PurgeDeletedRequest) (*PurgeDeletedResponse, error)
+	// Applies a retention policy, permanently removing the snapshots it
+	// doesn't keep, or only reports them.
+	PruneSnapshots(context.Context, *PruneSnapshotsRequest) (*PruneSnapshotsResponse, error)
 	mustEmbedUnimplementedHostServiceServer()
 }
 
@@ -101,12 +266,42 @@ type UnimplementedHostServiceServer struct{}
 func (UnimplementedHostServiceServer) UploadSnapshot(context.Context, *UploadSnapshotRequest) (*UploadSnapshotResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UploadSnapshot not implemented")
 }
+func (UnimplementedHostServiceServer) UploadSnapshotStream(grpc.ClientStreamingServer[UploadSnapshotChunk, UploadSnapshotResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadSnapshotStream not implemented")
+}
+func (UnimplementedHostServiceServer) BulkUpload(context.Context, *BulkUploadRequest) (*BulkUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkUpload not implemented")
+}
 func (UnimplementedHostServiceServer) GetHostHistory(context.Context, *GetHostHistoryRequest) (*GetHostHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHostHistory not implemented")
 }
 func (UnimplementedHostServiceServer) CompareSnapshots(context.Context, *CompareSnapshotsRequest) (*CompareSnapshotsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CompareSnapshots not implemented")
 }
+func (UnimplementedHostServiceServer) GetHostTimeline(context.Context, *GetHostTimelineRequest) (*GetHostTimelineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHostTimeline not implemented")
+}
+func (UnimplementedHostServiceServer) QueryServices(context.Context, *QueryServicesRequest) (*QueryServicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryServices not implemented")
+}
+func (UnimplementedHostServiceServer) QueryFleet(context.Context, *QueryFleetRequest) (*QueryFleetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryFleet not implemented")
+}
+func (UnimplementedHostServiceServer) ListHosts(context.Context, *ListHostsRequest) (*ListHostsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHosts not implemented")
+}
+func (UnimplementedHostServiceServer) DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*DeleteSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSnapshot not implemented")
+}
+func (UnimplementedHostServiceServer) RestoreSnapshot(context.Context, *RestoreSnapshotRequest) (*RestoreSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSnapshot not implemented")
+}
+func (UnimplementedHostServiceServer) PurgeDeleted(context.Context, *PurgeDeletedRequest) (*PurgeDeletedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeDeleted not implemented")
+}
+func (UnimplementedHostServiceServer) PruneSnapshots(context.Context, *PruneSnapshotsRequest) (*PruneSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PruneSnapshots not implemented")
+}
 func (UnimplementedHostServiceServer) mustEmbedUnimplementedHostServiceServer() {}
 func (UnimplementedHostServiceServer) testEmbeddedByValue()                     {}
 
@@ -146,6 +341,31 @@ func _HostService_UploadSnapshot_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _HostService_UploadSnapshotStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(HostServiceServer).UploadSnapshotStream(&grpc.GenericServerStream[UploadSnapshotChunk, UploadSnapshotResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HostService_UploadSnapshotStreamServer = grpc.ClientStreamingServer[UploadSnapshotChunk, UploadSnapshotResponse]
+
+func _HostService_BulkUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).BulkUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_BulkUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).BulkUpload(ctx, req.(*BulkUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _HostService_GetHostHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetHostHistoryRequest)
 	if err := dec(in); err != nil {
@@ -182,6 +402,150 @@ func _HostService_CompareSnapshots_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _HostService_GetHostTimeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHostTimelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).GetHostTimeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_GetHostTimeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).GetHostTimeline(ctx, req.(*GetHostTimelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_QueryServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryServicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).QueryServices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_QueryServices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).QueryServices(ctx, req.(*QueryServicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_QueryFleet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryFleetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).QueryFleet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_QueryFleet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).QueryFleet(ctx, req.(*QueryFleetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_ListHosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).ListHosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_ListHosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).ListHosts(ctx, req.(*ListHostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_DeleteSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).DeleteSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_DeleteSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).DeleteSnapshot(ctx, req.(*DeleteSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_RestoreSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).RestoreSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_RestoreSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).RestoreSnapshot(ctx, req.(*RestoreSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_PurgeDeleted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeDeletedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).PurgeDeleted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_PurgeDeleted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).PurgeDeleted(ctx, req.(*PurgeDeletedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_PruneSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruneSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).PruneSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_PruneSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).PruneSnapshots(ctx, req.(*PruneSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // HostService_ServiceDesc is the grpc.ServiceDesc for HostService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -193,6 +557,10 @@ var HostService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UploadSnapshot",
 			Handler:    _HostService_UploadSnapshot_Handler,
 		},
+		{
+			MethodName: "BulkUpload",
+			Handler:    _HostService_BulkUpload_Handler,
+		},
 		{
 			MethodName: "GetHostHistory",
 			Handler:    _HostService_GetHostHistory_Handler,
@@ -201,7 +569,45 @@ var HostService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CompareSnapshots",
 			Handler:    _HostService_CompareSnapshots_Handler,
 		},
+		{
+			MethodName: "GetHostTimeline",
+			Handler:    _HostService_GetHostTimeline_Handler,
+		},
+		{
+			MethodName: "QueryServices",
+			Handler:    _HostService_QueryServices_Handler,
+		},
+		{
+			MethodName: "QueryFleet",
+			Handler:    _HostService_QueryFleet_Handler,
+		},
+		{
+			MethodName: "ListHosts",
+			Handler:    _HostService_ListHosts_Handler,
+		},
+		{
+			MethodName: "DeleteSnapshot",
+			Handler:    _HostService_DeleteSnapshot_Handler,
+		},
+		{
+			MethodName: "RestoreSnapshot",
+			Handler:    _HostService_RestoreSnapshot_Handler,
+		},
+		{
+			MethodName: "PurgeDeleted",
+			Handler:    _HostService_PurgeDeleted_Handler,
+		},
+		{
+			MethodName: "PruneSnapshots",
+			Handler:    _HostService_PruneSnapshots_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadSnapshotStream",
+			Handler:       _HostService_UploadSnapshotStream_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/host_diff.proto",
 }